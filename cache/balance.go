@@ -0,0 +1,51 @@
+// Package cache holds short-lived copies of data that is slow to fetch, such as on-chain
+// balances.
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Balance is a wallet balance as read from the chain at AsOf
+type Balance struct {
+	Value string
+	AsOf  time.Time
+}
+
+// BalanceStore keeps the last balance read for each wallet. Implementations must be safe
+// for concurrent use; the in-memory store can be swapped for a shared one such as Redis.
+type BalanceStore interface {
+	// Get returns the stored balance and whether there was one
+	Get(ctx context.Context, wallet string) (Balance, bool, error)
+	Set(ctx context.Context, wallet string, balance Balance) error
+}
+
+// MemoryBalanceStore is a BalanceStore local to this process
+type MemoryBalanceStore struct {
+	mu       sync.RWMutex
+	balances map[string]Balance
+}
+
+// NewMemoryBalanceStore creates an empty MemoryBalanceStore
+func NewMemoryBalanceStore() *MemoryBalanceStore {
+	return &MemoryBalanceStore{balances: map[string]Balance{}}
+}
+
+// Get implements BalanceStore. Wallets are matched case-insensitively.
+func (s *MemoryBalanceStore) Get(ctx context.Context, wallet string) (Balance, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	balance, ok := s.balances[strings.ToLower(wallet)]
+	return balance, ok, nil
+}
+
+// Set implements BalanceStore
+func (s *MemoryBalanceStore) Set(ctx context.Context, wallet string, balance Balance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[strings.ToLower(wallet)] = balance
+	return nil
+}
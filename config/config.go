@@ -0,0 +1,490 @@
+// Package config loads the server's configuration from the environment once, at startup,
+// and validates it there: a missing database URL, a malformed RPC URL or a non-numeric
+// chain ID stops the server before it serves instead of surfacing later as odd behavior.
+// The loaded Config is passed to whatever needs it rather than read from the environment
+// again.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"atfi-backend/contracts"
+	"atfi-backend/logging"
+	"atfi-backend/server"
+)
+
+// Defaults for settings left unset
+const (
+	DefaultPort               = "8080"
+	DefaultTxConfirmations    = 3
+	DefaultClaimWindow        = 30 * 24 * time.Hour
+	DefaultCheckInOpensBefore = 2 * time.Hour
+	DefaultCheckInClosesAfter = 6 * time.Hour
+	DefaultCORSAllowedOrigin  = "*"
+	DefaultRequestTimeout     = 15 * time.Second
+	DefaultExportTimeout      = 5 * time.Minute
+	redactedValue             = "[redacted]"
+	unsetValue                = "(unset)"
+)
+
+// Config is the server's configuration
+type Config struct {
+	Port        string
+	DatabaseURL string
+	RPC         RPC
+	Chain       contracts.Chain
+	// AppBaseURL is the frontend links in emails point at, without a trailing slash
+	AppBaseURL     string
+	CORS           CORS
+	TrustedProxies []string
+	Auth           Auth
+	Factory        Factory
+	// TxConfirmations is how many blocks a submitted transaction needs before it is trusted
+	TxConfirmations uint64
+	// ClaimWindow is how long attendees may claim after an event is settled
+	ClaimWindow   time.Duration
+	CheckInWindow CheckInWindow
+	// ShutdownTimeout bounds the wait for in-flight requests after SIGINT or SIGTERM
+	ShutdownTimeout time.Duration
+	RequestTimeout  RequestTimeout
+	Workers         Workers
+	Log             Log
+}
+
+// RequestTimeout bounds how long a request may run before it is answered with a 504
+type RequestTimeout struct {
+	Default time.Duration
+	// Export applies to the CSV exports, which read whole events
+	Export time.Duration
+}
+
+// Log controls what the server logs and how
+type Log struct {
+	// Level is the least severe level logged; SQL text and email addresses are at debug
+	Level slog.Level
+	// Format is logging.FormatJSON or logging.FormatText
+	Format string
+}
+
+// RPC lists the Ethereum nodes the server reads from
+type RPC struct {
+	// URLs are failed over between in order of preference
+	URLs []string
+	// WebSocketURL is subscribed to by the factory watcher, which polls when it is empty
+	WebSocketURL string
+}
+
+// CORS controls which browser origins may call the API
+type CORS struct {
+	// AllowedOrigins holds origins such as https://atfi.app, or "*" for any
+	AllowedOrigins []string
+}
+
+// Auth holds who is trusted and the secrets used to prove it
+type Auth struct {
+	// AdminAddresses are lowercased wallet addresses allowed on admin routes
+	AdminAddresses []string
+	// IndexerAPIKey is the shared key the indexer sends; empty disables indexer routes
+	IndexerAPIKey string
+	// QRSigningSecret is the HMAC key of check-in QR payloads; empty disables them
+	QRSigningSecret string
+}
+
+// Factory configures the watcher copying new events from the event factory
+type Factory struct {
+	// Address is the factory on the configured chain; Enabled is false when there is none
+	Address common.Address
+	Enabled bool
+	// StartBlock is where the watcher starts without a checkpoint; nil finds the deploy block
+	StartBlock *uint64
+	// Confirmations is how deep a factory log must be before its row is finalized
+	Confirmations uint64
+}
+
+// CheckInWindow is the time around an event's date during which attendees may check in
+type CheckInWindow struct {
+	OpensBefore time.Duration
+	ClosesAfter time.Duration
+}
+
+// Workers says which background jobs run. All run unless named in DISABLED_WORKERS.
+type Workers struct {
+	ClaimExpiry    bool
+	Phase          bool
+	Reputation     bool
+	FactoryWatcher bool
+	EmailOutbox    bool
+}
+
+// workerNames maps the names accepted in DISABLED_WORKERS to their toggles
+var workerNames = map[string]func(*Workers) *bool{
+	"claim-expiry":    func(w *Workers) *bool { return &w.ClaimExpiry },
+	"phase":           func(w *Workers) *bool { return &w.Phase },
+	"reputation":      func(w *Workers) *bool { return &w.Reputation },
+	"factory-watcher": func(w *Workers) *bool { return &w.FactoryWatcher },
+	"email-outbox":    func(w *Workers) *bool { return &w.EmailOutbox },
+}
+
+// Load reads the configuration from the environment and validates it. Every problem found
+// is reported in the one error, so a misconfigured deployment is fixed in one go.
+func Load() (*Config, error) {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	cfg := &Config{
+		Port:            env("PORT"),
+		DatabaseURL:     env("DATABASE_URL"),
+		TxConfirmations: DefaultTxConfirmations,
+		ClaimWindow:     DefaultClaimWindow,
+		CheckInWindow:   CheckInWindow{OpensBefore: DefaultCheckInOpensBefore, ClosesAfter: DefaultCheckInClosesAfter},
+		ShutdownTimeout: server.DefaultShutdownTimeout,
+		RequestTimeout:  RequestTimeout{Default: DefaultRequestTimeout, Export: DefaultExportTimeout},
+		Workers:         Workers{ClaimExpiry: true, Phase: true, Reputation: true, FactoryWatcher: true, EmailOutbox: true},
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = DefaultPort
+	} else if port, err := strconv.Atoi(cfg.Port); err != nil || port <= 0 || port > 65535 {
+		fail("invalid PORT %q: must be a number between 1 and 65535", cfg.Port)
+	}
+
+	if cfg.DatabaseURL == "" {
+		fail("DATABASE_URL is required")
+	} else if _, err := pgxpool.ParseConfig(cfg.DatabaseURL); err != nil {
+		fail("invalid DATABASE_URL: %v", err)
+	}
+
+	chain, chainErr := contracts.ChainFromEnv()
+	if chainErr != nil {
+		fail("%v: must be a numeric chain ID", chainErr)
+	}
+	cfg.Chain = chain
+	if chain.ExplorerURL != "" {
+		if err := checkURL(chain.ExplorerURL, "http", "https"); err != nil {
+			fail("invalid EXPLORER_URL: %v", err)
+		}
+	}
+
+	cfg.RPC.URLs = contracts.ParseRPCURLs(env("RPC_URLS"))
+	if len(cfg.RPC.URLs) == 0 {
+		if rpcURL := env("RPC_URL"); rpcURL != "" {
+			cfg.RPC.URLs = []string{rpcURL}
+		} else if chain.DefaultRPCURL != "" {
+			cfg.RPC.URLs = []string{chain.DefaultRPCURL}
+		} else if chainErr == nil {
+			fail("RPC_URL is required for %s", chain.Name)
+		}
+	}
+	for _, rpcURL := range cfg.RPC.URLs {
+		if err := checkURL(rpcURL, "http", "https", "ws", "wss"); err != nil {
+			fail("invalid RPC URL %s: %v", redactRPCURL(rpcURL), err)
+		}
+	}
+	if cfg.RPC.WebSocketURL = env("RPC_WS_URL"); cfg.RPC.WebSocketURL != "" {
+		if err := checkURL(cfg.RPC.WebSocketURL, "ws", "wss"); err != nil {
+			fail("invalid RPC_WS_URL %s: %v", redactRPCURL(cfg.RPC.WebSocketURL), err)
+		}
+	}
+
+	if cfg.AppBaseURL = strings.TrimRight(env("APP_BASE_URL"), "/"); cfg.AppBaseURL != "" {
+		if err := checkURL(cfg.AppBaseURL, "http", "https"); err != nil {
+			fail("invalid APP_BASE_URL: %v", err)
+		}
+	}
+
+	cfg.CORS.AllowedOrigins = splitList(env("CORS_ALLOWED_ORIGINS"))
+	if len(cfg.CORS.AllowedOrigins) == 0 {
+		cfg.CORS.AllowedOrigins = []string{DefaultCORSAllowedOrigin}
+	}
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if origin == "*" && len(cfg.CORS.AllowedOrigins) > 1 {
+			fail("invalid CORS_ALLOWED_ORIGINS: \"*\" allows every origin and cannot be combined with others")
+		} else if err := checkOrigin(origin); err != nil {
+			fail("invalid CORS_ALLOWED_ORIGINS entry %q: %v", origin, err)
+		}
+	}
+
+	cfg.TrustedProxies = splitList(env("TRUSTED_PROXIES"))
+	for _, proxy := range cfg.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			fail("invalid TRUSTED_PROXIES entry %q: must be an IP or CIDR", proxy)
+		}
+	}
+
+	for _, admin := range splitList(env("ADMIN_ADDRESSES")) {
+		if !common.IsHexAddress(admin) {
+			fail("invalid ADMIN_ADDRESSES entry %q: must be a wallet address", admin)
+			continue
+		}
+		cfg.Auth.AdminAddresses = append(cfg.Auth.AdminAddresses, strings.ToLower(admin))
+	}
+	cfg.Auth.IndexerAPIKey = env("INDEXER_API_KEY")
+	cfg.Auth.QRSigningSecret = env("QR_SIGNING_SECRET")
+
+	// Without a valid chain the preset factory is unknown, and that was reported already
+	if chainErr == nil {
+		if address, ok, err := contracts.FactoryAddressFromEnv(); err != nil {
+			fail("%v", err)
+		} else {
+			cfg.Factory.Address, cfg.Factory.Enabled = address, ok
+		}
+	}
+	cfg.Factory.Confirmations = chain.Confirmations
+	if raw := env("FACTORY_CONFIRMATIONS"); raw != "" {
+		if confirmations, err := strconv.ParseUint(raw, 10, 64); err != nil || confirmations == 0 {
+			fail("invalid FACTORY_CONFIRMATIONS %q: must be a positive number of blocks", raw)
+		} else {
+			cfg.Factory.Confirmations = confirmations
+		}
+	}
+	if raw := env("FACTORY_START_BLOCK"); raw != "" {
+		if block, err := strconv.ParseUint(raw, 10, 64); err != nil {
+			fail("invalid FACTORY_START_BLOCK %q: must be a block number", raw)
+		} else {
+			cfg.Factory.StartBlock = &block
+		}
+	}
+
+	if raw := env("TX_CONFIRMATIONS"); raw != "" {
+		if confirmations, err := strconv.ParseUint(raw, 10, 64); err != nil || confirmations == 0 {
+			fail("invalid TX_CONFIRMATIONS %q: must be a positive number of blocks", raw)
+		} else {
+			cfg.TxConfirmations = confirmations
+		}
+	}
+	if raw := env("CLAIM_WINDOW_DAYS"); raw != "" {
+		if days, err := strconv.Atoi(raw); err != nil || days <= 0 {
+			fail("invalid CLAIM_WINDOW_DAYS %q: must be a positive number of days", raw)
+		} else {
+			cfg.ClaimWindow = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	for _, window := range []struct {
+		key    string
+		target *time.Duration
+	}{
+		{"CHECKIN_WINDOW_BEFORE_MINUTES", &cfg.CheckInWindow.OpensBefore},
+		{"CHECKIN_WINDOW_AFTER_MINUTES", &cfg.CheckInWindow.ClosesAfter},
+	} {
+		if raw := env(window.key); raw != "" {
+			if minutes, err := strconv.Atoi(raw); err != nil || minutes <= 0 {
+				fail("invalid %s %q: must be a positive number of minutes", window.key, raw)
+			} else {
+				*window.target = time.Duration(minutes) * time.Minute
+			}
+		}
+	}
+
+	if raw := env("SHUTDOWN_TIMEOUT"); raw != "" {
+		if timeout, err := time.ParseDuration(raw); err != nil || timeout <= 0 {
+			fail("invalid SHUTDOWN_TIMEOUT %q: must be a Go duration such as 45s", raw)
+		} else {
+			cfg.ShutdownTimeout = timeout
+		}
+	}
+
+	for _, name := range splitList(env("DISABLED_WORKERS")) {
+		toggle, ok := workerNames[strings.ToLower(name)]
+		if !ok {
+			fail("unknown worker %q in DISABLED_WORKERS: known workers are %s", name, strings.Join(knownWorkers(), ", "))
+			continue
+		}
+		*toggle(&cfg.Workers) = false
+	}
+
+	for _, setting := range []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"REQUEST_TIMEOUT", &cfg.RequestTimeout.Default},
+		{"EXPORT_REQUEST_TIMEOUT", &cfg.RequestTimeout.Export},
+	} {
+		if raw := env(setting.key); raw != "" {
+			if timeout, err := time.ParseDuration(raw); err != nil || timeout <= 0 {
+				fail("invalid %s %q: must be a Go duration such as 15s", setting.key, raw)
+			} else {
+				*setting.value = timeout
+			}
+		}
+	}
+
+	if raw := env("LOG_LEVEL"); raw != "" {
+		if err := cfg.Log.Level.UnmarshalText([]byte(raw)); err != nil {
+			fail("invalid LOG_LEVEL %q: must be debug, info, warn or error", raw)
+		}
+	}
+	if format, err := logging.ParseFormat(env("LOG_FORMAT")); err != nil {
+		fail("invalid LOG_FORMAT: %v", err)
+	} else {
+		cfg.Log.Format = format
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// Summary describes the configuration for the boot log, one setting per line. Secrets
+// only show whether they are set, and credentials in URLs are hidden.
+func (c *Config) Summary() string {
+	lines := []string{
+		"port=" + c.Port,
+		"database_url=" + redactURL(c.DatabaseURL),
+		fmt.Sprintf("chain=%s (%d)", c.Chain.Name, c.Chain.ID),
+		"rpc_urls=" + strings.Join(mapStrings(c.RPC.URLs, redactRPCURL), ","),
+		"rpc_ws_url=" + redactRPCURL(c.RPC.WebSocketURL),
+		"explorer_url=" + orUnset(c.Chain.ExplorerURL),
+		"app_base_url=" + orUnset(c.AppBaseURL),
+		"cors_allowed_origins=" + strings.Join(c.CORS.AllowedOrigins, ","),
+		"trusted_proxies=" + orUnset(strings.Join(c.TrustedProxies, ",")),
+		fmt.Sprintf("admin_addresses=%d", len(c.Auth.AdminAddresses)),
+		"indexer_api_key=" + secret(c.Auth.IndexerAPIKey),
+		"qr_signing_secret=" + secret(c.Auth.QRSigningSecret),
+		fmt.Sprintf("tx_confirmations=%d", c.TxConfirmations),
+		fmt.Sprintf("claim_window=%s", c.ClaimWindow),
+		fmt.Sprintf("checkin_window=-%s/+%s", c.CheckInWindow.OpensBefore, c.CheckInWindow.ClosesAfter),
+		fmt.Sprintf("shutdown_timeout=%s", c.ShutdownTimeout),
+		fmt.Sprintf("request_timeout=%s (exports %s)", c.RequestTimeout.Default, c.RequestTimeout.Export),
+		"disabled_workers=" + orUnset(strings.Join(c.Workers.disabled(), ",")),
+		fmt.Sprintf("log=%s (%s)", c.Log.Level, c.Log.Format),
+	}
+	if c.Factory.Enabled {
+		lines = append(lines, fmt.Sprintf("factory=%s (confirmations %d)", c.Factory.Address.Hex(), c.Factory.Confirmations))
+	} else {
+		lines = append(lines, "factory="+unsetValue)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// disabled lists the names of the workers that do not run
+func (w Workers) disabled() []string {
+	var names []string
+	for _, name := range knownWorkers() {
+		if !*workerNames[name](&w) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func knownWorkers() []string {
+	names := make([]string, 0, len(workerNames))
+	for name := range workerNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// env returns the trimmed value of the environment variable key
+func env(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkURL reports why raw is not an absolute URL with one of the given schemes
+func checkURL(raw string, schemes ...string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("not a URL")
+	}
+	if parsed.Host == "" {
+		return errors.New("missing host")
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("scheme must be one of %s", strings.Join(schemes, ", "))
+}
+
+// checkOrigin reports why origin is neither "*" nor a bare scheme://host[:port] origin
+func checkOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	if err := checkURL(origin, "http", "https"); err != nil {
+		return err
+	}
+	if parsed, _ := url.Parse(origin); parsed.Path != "" || parsed.RawQuery != "" {
+		return errors.New("an origin has no path or query")
+	}
+	return nil
+}
+
+// redactURL hides the password in raw, as in a database URL
+func redactURL(raw string) string {
+	if raw == "" {
+		return unsetValue
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return redactedValue
+	}
+	return parsed.Redacted()
+}
+
+// redactRPCURL keeps only the scheme and host of raw: RPC providers carry API keys in the
+// path or query
+func redactRPCURL(raw string) string {
+	if raw == "" {
+		return unsetValue
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return redactedValue
+	}
+	redacted := parsed.Scheme + "://" + parsed.Host
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.User != nil {
+		redacted += "/" + redactedValue
+	}
+	return redacted
+}
+
+func secret(value string) string {
+	if value == "" {
+		return unsetValue
+	}
+	return redactedValue
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return unsetValue
+	}
+	return value
+}
+
+func mapStrings(values []string, f func(string) string) []string {
+	mapped := make([]string, len(values))
+	for i, value := range values {
+		mapped[i] = f(value)
+	}
+	return mapped
+}
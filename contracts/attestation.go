@@ -0,0 +1,245 @@
+package contracts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-712 domain of ATFi attendance receipts
+const (
+	AttestationDomainName    = "ATFi Attendance"
+	AttestationDomainVersion = "1"
+	AttendancePrimaryType    = "Attendance"
+)
+
+var (
+	eip712DomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	attendanceTypeHash   = crypto.Keccak256Hash([]byte("Attendance(uint256 eventId,address vault,address wallet,uint256 checkedInAt)"))
+)
+
+// ErrInvalidProof is returned when a submitted attendance proof is malformed
+var ErrInvalidProof = errors.New("invalid attendance proof")
+
+// Attendance is the attested fact: wallet checked in to the event staked in vault
+type Attendance struct {
+	EventID     int64
+	Vault       common.Address
+	Wallet      common.Address
+	CheckedInAt int64 // unix seconds
+}
+
+// TypedDataField is one field of an EIP-712 struct type
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AttestationDomain is the EIP-712 domain of an attendance proof
+type AttestationDomain struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	ChainID string `json:"chainId"`
+}
+
+// AttendanceMessage is the Attendance struct in its JSON typed-data form. Integers are
+// decimal strings so they survive JSON clients without precision loss.
+type AttendanceMessage struct {
+	EventID     string `json:"eventId"`
+	Vault       string `json:"vault"`
+	Wallet      string `json:"wallet"`
+	CheckedInAt string `json:"checkedInAt"`
+}
+
+// AttendanceProof is a signed attendance receipt laid out like eth_signTypedData_v4
+// input, so wallets and ethers/viem can verify it without our API
+type AttendanceProof struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      AttestationDomain           `json:"domain"`
+	Message     AttendanceMessage           `json:"message"`
+	Signature   string                      `json:"signature"`
+	Signer      string                      `json:"signer"`
+}
+
+// Attestor signs attendance receipts with the key configured in ATTESTOR_PRIVATE_KEY
+type Attestor struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	chainID *big.Int
+}
+
+// NewAttestor creates an attestor signing for chainID
+func NewAttestor(key *ecdsa.PrivateKey, chainID *big.Int) *Attestor {
+	return &Attestor{key: key, address: crypto.PubkeyToAddress(key.PublicKey), chainID: chainID}
+}
+
+// NewAttestorFromEnv loads the attestor key from ATTESTOR_PRIVATE_KEY and the chain ID
+// from the RPC node. It returns nil when no key is configured.
+func NewAttestorFromEnv(ctx context.Context, client Caller) (*Attestor, error) {
+	hexKey := strings.TrimPrefix(strings.TrimSpace(os.Getenv("ATTESTOR_PRIVATE_KEY")), "0x")
+	if hexKey == "" {
+		return nil, nil
+	}
+
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTESTOR_PRIVATE_KEY: %w", err)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	return NewAttestor(key, chainID), nil
+}
+
+// Address is the attestor's signing address
+func (a *Attestor) Address() common.Address {
+	return a.address
+}
+
+// ChainID is the chain the attestor signs for
+func (a *Attestor) ChainID() *big.Int {
+	return new(big.Int).Set(a.chainID)
+}
+
+// SignAttendance produces a signed attendance proof. Signing is deterministic, so the
+// same attendance always yields the same proof.
+func (a *Attestor) SignAttendance(att Attendance) (*AttendanceProof, error) {
+	proof := &AttendanceProof{
+		Types:       attendanceTypes(),
+		PrimaryType: AttendancePrimaryType,
+		Domain: AttestationDomain{
+			Name:    AttestationDomainName,
+			Version: AttestationDomainVersion,
+			ChainID: a.chainID.String(),
+		},
+		Message: AttendanceMessage{
+			EventID:     big.NewInt(att.EventID).String(),
+			Vault:       att.Vault.Hex(),
+			Wallet:      att.Wallet.Hex(),
+			CheckedInAt: big.NewInt(att.CheckedInAt).String(),
+		},
+		Signer: a.address.Hex(),
+	}
+
+	digest, err := attendanceDigest(proof.Domain, proof.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := crypto.Sign(digest, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attendance: %w", err)
+	}
+	// Wallet tooling expects v as 27/28
+	sig[crypto.RecoveryIDOffset] += 27
+	proof.Signature = hexutil.Encode(sig)
+
+	return proof, nil
+}
+
+// RecoverAttendanceSigner returns the address that signed the proof's domain and message.
+// The proof's types and signer fields are ignored; the digest is always rebuilt from the
+// fixed Attendance type.
+func RecoverAttendanceSigner(proof AttendanceProof) (common.Address, error) {
+	if proof.PrimaryType != AttendancePrimaryType || proof.Domain.Name != AttestationDomainName || proof.Domain.Version != AttestationDomainVersion {
+		return common.Address{}, fmt.Errorf("%w: not an %s v%s proof", ErrInvalidProof, AttestationDomainName, AttestationDomainVersion)
+	}
+
+	digest, err := attendanceDigest(proof.Domain, proof.Message)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	sig, err := hexutil.Decode(proof.Signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: bad signature encoding", ErrInvalidProof)
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// ParseAttendance converts a proof message back into an Attendance
+func ParseAttendance(msg AttendanceMessage) (Attendance, error) {
+	eventID, ok := new(big.Int).SetString(msg.EventID, 10)
+	if !ok || eventID.Sign() < 0 || !eventID.IsInt64() {
+		return Attendance{}, fmt.Errorf("%w: bad eventId", ErrInvalidProof)
+	}
+	checkedInAt, ok := new(big.Int).SetString(msg.CheckedInAt, 10)
+	if !ok || checkedInAt.Sign() < 0 || !checkedInAt.IsInt64() {
+		return Attendance{}, fmt.Errorf("%w: bad checkedInAt", ErrInvalidProof)
+	}
+	if !common.IsHexAddress(msg.Vault) || !common.IsHexAddress(msg.Wallet) {
+		return Attendance{}, fmt.Errorf("%w: bad address", ErrInvalidProof)
+	}
+
+	return Attendance{
+		EventID:     eventID.Int64(),
+		Vault:       common.HexToAddress(msg.Vault),
+		Wallet:      common.HexToAddress(msg.Wallet),
+		CheckedInAt: checkedInAt.Int64(),
+	}, nil
+}
+
+func attendanceTypes() map[string][]TypedDataField {
+	return map[string][]TypedDataField{
+		"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+		},
+		AttendancePrimaryType: {
+			{Name: "eventId", Type: "uint256"},
+			{Name: "vault", Type: "address"},
+			{Name: "wallet", Type: "address"},
+			{Name: "checkedInAt", Type: "uint256"},
+		},
+	}
+}
+
+// attendanceDigest is the EIP-712 hash keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+func attendanceDigest(domain AttestationDomain, msg AttendanceMessage) ([]byte, error) {
+	chainID, ok := new(big.Int).SetString(domain.ChainID, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: bad chainId", ErrInvalidProof)
+	}
+	att, err := ParseAttendance(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	domainSeparator := crypto.Keccak256(
+		eip712DomainTypeHash.Bytes(),
+		crypto.Keccak256([]byte(domain.Name)),
+		crypto.Keccak256([]byte(domain.Version)),
+		common.LeftPadBytes(chainID.Bytes(), 32),
+	)
+
+	structHash := crypto.Keccak256(
+		attendanceTypeHash.Bytes(),
+		common.LeftPadBytes(big.NewInt(att.EventID).Bytes(), 32),
+		common.LeftPadBytes(att.Vault.Bytes(), 32),
+		common.LeftPadBytes(att.Wallet.Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(att.CheckedInAt).Bytes(), 32),
+	)
+
+	return crypto.Keccak256([]byte("\x19\x01"), domainSeparator, structHash), nil
+}
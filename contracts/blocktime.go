@@ -0,0 +1,135 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrArchiveUnavailable is returned for reads at a past block the node has pruned the
+// state of. Only archive nodes serve state far back.
+var ErrArchiveUnavailable = errors.New("the RPC node does not serve historical state")
+
+// ErrBeforeGenesis is returned by BlockAtTime for a time before the chain's first block
+var ErrBeforeGenesis = errors.New("time is before the first block")
+
+// Messages nodes answer with when the state at a block was pruned, lowercased
+var missingStateMessages = []string{
+	"missing trie node",
+	"historical state",
+	"state not available",
+	"state is not available",
+	"state unavailable",
+	"pruned",
+	"archive state",
+}
+
+// isMissingState reports whether err is a node refusing a read for pruned state
+func isMissingState(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, missing := range missingStateMessages {
+		if strings.Contains(message, missing) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultBlockTimesSize caps the timestamps a BlockTimes remembers
+const DefaultBlockTimesSize = 4096
+
+// BlockTimes resolves timestamps to the last block mined at or before them, with a
+// binary search over block headers. Resolved timestamps are remembered once a later
+// block exists, so the answer can no longer change short of a reorg.
+type BlockTimes struct {
+	client HeaderReader
+
+	mu     sync.Mutex
+	blocks map[uint64]uint64
+}
+
+// NewBlockTimes returns a resolver reading headers through client
+func NewBlockTimes(client HeaderReader) *BlockTimes {
+	return &BlockTimes{client: client, blocks: map[uint64]uint64{}}
+}
+
+// BlockAtTime returns the number of the last block whose timestamp is at or before
+// timestamp. A timestamp at or after the head's resolves to the head. It returns
+// ErrBeforeGenesis for a time before the first block.
+func (bt *BlockTimes) BlockAtTime(ctx context.Context, timestamp uint64) (uint64, error) {
+	bt.mu.Lock()
+	block, ok := bt.blocks[timestamp]
+	bt.mu.Unlock()
+	if ok {
+		return block, nil
+	}
+
+	var latest uint64
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		latest, err = Retry(ctx, DefaultRetryPolicy, bt.client.BlockNumber)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	head, err := bt.header(ctx, latest)
+	if err != nil {
+		return 0, err
+	}
+	if head.Time <= timestamp {
+		return latest, nil
+	}
+	first, err := bt.header(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	if first.Time > timestamp {
+		return 0, ErrBeforeGenesis
+	}
+
+	// Invariant: block lo is at or before timestamp, block hi after it
+	lo, hi := uint64(0), latest
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		header, err := bt.header(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if header.Time <= timestamp {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	bt.mu.Lock()
+	if len(bt.blocks) >= DefaultBlockTimesSize {
+		bt.blocks = map[uint64]uint64{}
+	}
+	bt.blocks[timestamp] = lo
+	bt.mu.Unlock()
+	return lo, nil
+}
+
+func (bt *BlockTimes) header(ctx context.Context, number uint64) (*types.Header, error) {
+	var header *types.Header
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		header, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (*types.Header, error) {
+			return bt.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %d: %w", number, err)
+	}
+	return header, nil
+}
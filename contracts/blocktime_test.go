@@ -0,0 +1,79 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestBlockAtTime(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(1000)
+	times := contracts.NewBlockTimes(client)
+
+	tests := []struct {
+		timestamp uint64
+		want      uint64
+	}{
+		{0, 0},
+		{1, 0},
+		{2 * contractstest.BlockTime, 2},
+		{51 * contractstest.BlockTime, 51},
+		{51*contractstest.BlockTime + 1, 51},
+		{999 * contractstest.BlockTime, 999},
+		// At or past the head
+		{1000 * contractstest.BlockTime, 1000},
+		{5000 * contractstest.BlockTime, 1000},
+	}
+	for _, tt := range tests {
+		got, err := times.BlockAtTime(context.Background(), tt.timestamp)
+		if err != nil || got != tt.want {
+			t.Errorf("BlockAtTime(%d) = %d, %v; want %d", tt.timestamp, got, err, tt.want)
+		}
+	}
+
+	// Resolved times are served without the node
+	client.Down(errors.New("connection refused"))
+	if got, err := times.BlockAtTime(context.Background(), 51*contractstest.BlockTime); err != nil || got != 51 {
+		t.Errorf("cached BlockAtTime = %d, %v; want 51", got, err)
+	}
+	// The head's answer may still change, so it is not remembered
+	if _, err := times.BlockAtTime(context.Background(), 5000*contractstest.BlockTime); err == nil {
+		t.Error("BlockAtTime past the head was served from the cache")
+	}
+}
+
+func TestParticipantCountAt(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+	client.SetParticipantCount(vault, 9)
+	client.SetParticipantCountAt(vault, 10, 3)
+	client.SetParticipantCountAt(vault, 20, 7)
+
+	vc, err := contracts.NewVaultContract(client, vault.Hex())
+	if err != nil {
+		t.Fatalf("NewVaultContract: %v", err)
+	}
+
+	for block, want := range map[int64]int64{15: 3, 20: 7, 99: 7} {
+		count, err := vc.ParticipantCountAt(context.Background(), big.NewInt(block))
+		if err != nil || count.Int64() != want {
+			t.Errorf("ParticipantCountAt(%d) = %v, %v; want %d", block, count, err, want)
+		}
+	}
+	if count, err := vc.ParticipantCountAt(context.Background(), nil); err != nil || count.Int64() != 9 {
+		t.Errorf("ParticipantCountAt(latest) = %v, %v; want 9", count, err)
+	}
+
+	client.Prune(50)
+	if _, err := vc.ParticipantCountAt(context.Background(), big.NewInt(15)); !errors.Is(err, contracts.ErrArchiveUnavailable) {
+		t.Errorf("ParticipantCountAt on a pruned block: %v, want ErrArchiveUnavailable", err)
+	}
+	if count, err := vc.ParticipantCountAt(context.Background(), big.NewInt(60)); err != nil || count.Int64() != 7 {
+		t.Errorf("ParticipantCountAt(60) after pruning = %v, %v; want 7", count, err)
+	}
+}
@@ -0,0 +1,221 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+var (
+	usdc        = common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	vault       = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	otherVault  = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	participant = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func TestBalancesOfWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBalance(usdc, participant, big.NewInt(2500000))
+
+	balances, err := contracts.BalancesOf(context.Background(), client, participant, []contracts.Token{
+		{Symbol: "USDC", Address: usdc.Hex(), Decimals: 6},
+		{Symbol: "DAI", Address: otherVault.Hex(), Decimals: 18},
+		{Symbol: "BAD", Address: "nope", Decimals: 6},
+	})
+	if err != nil {
+		t.Fatalf("BalancesOf: %v", err)
+	}
+	if balances[0].Err != nil || balances[0].Raw.Int64() != 2500000 {
+		t.Errorf("USDC = %v, %v; want 2500000", balances[0].Raw, balances[0].Err)
+	}
+	if !errors.Is(balances[1].Err, contractstest.ErrReverted) {
+		t.Errorf("DAI error = %v, want the revert", balances[1].Err)
+	}
+	if balances[2].Err == nil {
+		t.Error("invalid token read without an error")
+	}
+
+	client.Down(errors.New("401 Unauthorized"))
+	if _, err := contracts.BalancesOf(context.Background(), client, participant, []contracts.Token{{Symbol: "USDC", Address: usdc.Hex(), Decimals: 6}}); err == nil {
+		t.Error("BalancesOf succeeded with the node down")
+	}
+}
+
+func TestAllowanceAndBalanceWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetAllowance(usdc, participant, vault, big.NewInt(10))
+	client.SetBalance(usdc, participant, big.NewInt(20))
+
+	allowance, balance, err := contracts.AllowanceAndBalance(context.Background(), client,
+		contracts.Token{Symbol: "USDC", Address: usdc.Hex(), Decimals: 6}, participant, vault)
+	if err != nil || allowance.Int64() != 10 || balance.Int64() != 20 {
+		t.Errorf("AllowanceAndBalance = %v, %v, %v; want 10, 20", allowance, balance, err)
+	}
+}
+
+func TestVaultContractWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetParticipantCount(vault, 7)
+	client.SetHasClaimed(vault, participant, true)
+
+	vc, err := contracts.NewVaultContract(client, vault.Hex())
+	if err != nil {
+		t.Fatalf("NewVaultContract: %v", err)
+	}
+	if count, err := vc.GetParticipantCount(context.Background()); err != nil || count.Int64() != 7 {
+		t.Errorf("GetParticipantCount = %v, %v; want 7", count, err)
+	}
+	if claimed, err := vc.HasClaimed(context.Background(), participant); err != nil || !claimed {
+		t.Errorf("HasClaimed = %v, %v; want true", claimed, err)
+	}
+}
+
+func TestParticipantCountsWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetParticipantCount(vault, 4)
+
+	counts, err := contracts.NewMulticaller(client).ParticipantCounts(context.Background(), []common.Address{vault, otherVault})
+	if err != nil {
+		t.Fatalf("ParticipantCounts: %v", err)
+	}
+	if counts[0].Err != nil || counts[0].Count.Int64() != 4 {
+		t.Errorf("vault count = %v, %v; want 4", counts[0].Count, counts[0].Err)
+	}
+	if counts[1].Err == nil {
+		t.Error("unprogrammed vault read without an error")
+	}
+	if calls := client.Calls(); len(calls) != 1 {
+		t.Errorf("got %d eth_calls, want one aggregate3", len(calls))
+	}
+}
+
+func TestClaimableAmountsWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetClaimable(vault, participant, big.NewInt(12_500_000))
+
+	amounts, err := contracts.NewMulticaller(client).ClaimableAmounts(context.Background(), participant, []common.Address{vault, otherVault})
+	if err != nil {
+		t.Fatalf("ClaimableAmounts: %v", err)
+	}
+	if amounts[0].Err != nil || amounts[0].Amount.Int64() != 12_500_000 {
+		t.Errorf("vault claimable = %v, %v; want 12500000", amounts[0].Amount, amounts[0].Err)
+	}
+	if amounts[1].Err == nil {
+		t.Error("unprogrammed vault read without an error")
+	}
+
+	vc, _ := contracts.NewVaultContract(client, vault.Hex())
+	if amount, err := vc.ClaimableAmount(context.Background(), participant); err != nil || amount.Int64() != 12_500_000 {
+		t.Errorf("ClaimableAmount = %v, %v; want 12500000", amount, err)
+	}
+}
+
+func TestFetchDepositorsWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(500)
+	client.Deploy(vault, 120)
+	client.AddLogs(
+		types.Log{Address: vault, BlockNumber: 130, Topics: []common.Hash{contracts.RegisteredEventTopic, contracts.ParticipantTopic(participant)}},
+		types.Log{Address: vault, BlockNumber: 140, Topics: []common.Hash{contracts.WithdrawnEventTopic, contracts.ParticipantTopic(otherVault)}},
+		types.Log{Address: otherVault, BlockNumber: 150, Topics: []common.Hash{contracts.DepositedEventTopic, contracts.ParticipantTopic(otherVault)}},
+	)
+
+	deployBlock, err := contracts.FindDeployBlock(context.Background(), client, vault)
+	if err != nil || deployBlock != 120 {
+		t.Fatalf("FindDeployBlock = %d, %v; want 120", deployBlock, err)
+	}
+
+	depositors, err := contracts.FetchDepositors(context.Background(), client, vault, deployBlock)
+	if err != nil {
+		t.Fatalf("FetchDepositors: %v", err)
+	}
+	if len(depositors) != 1 || !depositors[strings.ToLower(participant.Hex())] {
+		t.Errorf("depositors = %v, want only the registered participant", depositors)
+	}
+}
+
+func TestVerifyRegistrationTxWithFakeClient(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &vault})
+	client.AddTransaction(tx, &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(100),
+		Logs: []*types.Log{{
+			Address: vault,
+			Topics:  []common.Hash{contracts.DepositedEventTopic, contracts.ParticipantTopic(participant)},
+		}},
+	})
+
+	client.SetBlockNumber(100)
+	v, err := contracts.VerifyRegistrationTx(context.Background(), client, tx.Hash().Hex(), vault, participant, 3)
+	if err != nil || v.State != contracts.TxPending {
+		t.Fatalf("VerifyRegistrationTx = %+v, %v; want pending with one confirmation", v, err)
+	}
+
+	client.SetBlockNumber(102)
+	v, err = contracts.VerifyRegistrationTx(context.Background(), client, tx.Hash().Hex(), vault, participant, 3)
+	if err != nil || v.State != contracts.TxConfirmed {
+		t.Errorf("VerifyRegistrationTx = %+v, %v; want confirmed", v, err)
+	}
+
+	v, err = contracts.VerifyClaimTx(context.Background(), client, tx.Hash().Hex(), vault, participant, 3)
+	if err != nil || v.State != contracts.TxWrongTarget {
+		t.Errorf("VerifyClaimTx = %+v, %v; want wrong_target for a deposit", v, err)
+	}
+}
+
+func TestTokensFromEnvWithFakeClient(t *testing.T) {
+	t.Setenv("TOKEN_LIST", "")
+	tokens, err := contracts.TokensFromEnv(context.Background(), contractstest.NewClient(84532))
+	if err != nil || len(tokens) != 1 || tokens[0].Symbol != "USDC" {
+		t.Errorf("TokensFromEnv = %v, %v; want Base Sepolia USDC", tokens, err)
+	}
+}
+
+func TestStakeTokensReadsEachVaultsToken(t *testing.T) {
+	dai := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	client := contractstest.NewClient(84532)
+	client.SetVaultView(vault, "token", usdc)
+	client.SetVaultView(otherVault, "token", dai)
+	client.SetTokenMetadata(usdc, "USDC", 6)
+	client.SetTokenMetadata(dai, "DAI", 18)
+
+	tokens := contracts.NewStakeTokens(client)
+	want := map[common.Address]contracts.Token{
+		vault:      {Symbol: "USDC", Address: usdc.Hex(), Decimals: 6},
+		otherVault: {Symbol: "DAI", Address: dai.Hex(), Decimals: 18},
+	}
+	for address, token := range want {
+		got, err := tokens.Of(context.Background(), address)
+		if err != nil || got != token {
+			t.Errorf("Of(%s) = %+v, %v; want %+v", address.Hex(), got, err, token)
+		}
+	}
+
+	// A vault's token never changes, so it is read once
+	calls := len(client.Calls())
+	if _, err := tokens.Of(context.Background(), vault); err != nil {
+		t.Fatalf("Of from the cache: %v", err)
+	}
+	if len(client.Calls()) != calls {
+		t.Errorf("Of read the chain again for a cached vault")
+	}
+
+	// Failed reads are not remembered
+	third := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	if _, err := tokens.Of(context.Background(), third); err == nil {
+		t.Fatal("Of succeeded for a vault without a token")
+	}
+	client.SetVaultView(third, "token", usdc)
+	if got, err := tokens.Of(context.Background(), third); err != nil || got.Decimals != 6 {
+		t.Errorf("Of after the vault answered = %+v, %v", got, err)
+	}
+}
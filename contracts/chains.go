@@ -0,0 +1,120 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultChainID is the chain the backend runs against when CHAIN_ID is unset: Base Sepolia
+const DefaultChainID = 84532
+
+// Chain is what the backend knows about a chain it can run against
+type Chain struct {
+	ID            int64
+	Name          string
+	DefaultRPCURL string // used when neither RPC_URLS nor RPC_URL is set
+	ExplorerURL   string // block explorer, without a trailing slash
+	// Tokens the API reports balances for; the first is the primary token
+	Tokens []Token
+	// FactoryAddress is the ATFi event factory, used when FACTORY_ADDRESS is unset
+	FactoryAddress string
+	// NameRegistry is the ENS or Basenames registry, used when ENS_REGISTRY_ADDRESS is unset
+	NameRegistry string
+	// Confirmations is how deep a block must be before data the watchers copy from it is final
+	Confirmations uint64
+}
+
+// Presets by chain ID. ATFi factories are not published for these chains, so
+// FACTORY_ADDRESS still has to be set to enable the factory.
+var chains = map[int64]Chain{
+	1: {
+		ID:            1,
+		Name:          "Ethereum",
+		ExplorerURL:   "https://etherscan.io",
+		NameRegistry:  "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e",
+		Confirmations: DefaultConfirmationDepth,
+	},
+	11155111: {
+		ID:            11155111,
+		Name:          "Sepolia",
+		ExplorerURL:   "https://sepolia.etherscan.io",
+		NameRegistry:  "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e",
+		Confirmations: DefaultConfirmationDepth,
+	},
+	8453: {
+		ID:            8453,
+		Name:          "Base",
+		DefaultRPCURL: "https://mainnet.base.org",
+		ExplorerURL:   "https://basescan.org",
+		Tokens:        []Token{{Symbol: "USDC", Address: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", Decimals: 6}},
+		NameRegistry:  "0xB94704422c2a1E396835A571837Aa5AE53285a95",
+		Confirmations: DefaultConfirmationDepth,
+	},
+	84532: {
+		ID:            84532,
+		Name:          "Base Sepolia",
+		DefaultRPCURL: "https://base-sepolia-rpc.publicnode.com",
+		ExplorerURL:   "https://sepolia.basescan.org",
+		Tokens:        []Token{{Symbol: "USDC", Address: "0x036CbD53842c5426634e7929541eC2318f3dCF7e", Decimals: 6}},
+		NameRegistry:  "0x1493b2567056c2181630115660963E13A8E32735",
+		Confirmations: DefaultConfirmationDepth,
+	},
+}
+
+// LookupChain returns the preset for chain ID id
+func LookupChain(id int64) (Chain, bool) {
+	chain, ok := chains[id]
+	return chain, ok
+}
+
+// ChainFromEnv returns the chain configured by CHAIN_ID, DefaultChainID when it is unset.
+// A chain without a preset is allowed and starts out empty, with DefaultConfirmationDepth.
+// EXPLORER_URL overrides the preset's block explorer.
+func ChainFromEnv() (Chain, error) {
+	id := int64(DefaultChainID)
+	if raw := strings.TrimSpace(os.Getenv("CHAIN_ID")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return Chain{}, fmt.Errorf("invalid CHAIN_ID %q", raw)
+		}
+		id = parsed
+	}
+
+	chain, ok := LookupChain(id)
+	if !ok {
+		chain = Chain{ID: id, Name: "chain " + strconv.FormatInt(id, 10), Confirmations: DefaultConfirmationDepth}
+	}
+	if explorer := strings.TrimSpace(os.Getenv("EXPLORER_URL")); explorer != "" {
+		chain.ExplorerURL = explorer
+	}
+	chain.ExplorerURL = strings.TrimRight(chain.ExplorerURL, "/")
+	return chain, nil
+}
+
+// VerifyChainID checks that client is connected to chain, so that a node on another chain
+// is refused instead of answering with another chain's balances
+func VerifyChainID(ctx context.Context, client interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}, chain Chain) error {
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if !id.IsInt64() || id.Int64() != chain.ID {
+		return fmt.Errorf("RPC node is on chain %s, but CHAIN_ID is %d (%s)", id, chain.ID, chain.Name)
+	}
+	return nil
+}
+
+// TxURL links to transaction hash on the chain's block explorer, or is empty when the
+// chain has none
+func (c Chain) TxURL(hash string) string {
+	if c.ExplorerURL == "" {
+		return ""
+	}
+	return c.ExplorerURL + "/tx/" + hash
+}
@@ -0,0 +1,49 @@
+package contracts_test
+
+import (
+	"context"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestChainFromEnv(t *testing.T) {
+	t.Setenv("CHAIN_ID", "")
+	t.Setenv("EXPLORER_URL", "")
+	chain, err := contracts.ChainFromEnv()
+	if err != nil || chain.ID != contracts.DefaultChainID {
+		t.Fatalf("ChainFromEnv without CHAIN_ID = %d, %v; want %d", chain.ID, err, contracts.DefaultChainID)
+	}
+	if got, want := chain.TxURL("0xabc"), "https://sepolia.basescan.org/tx/0xabc"; got != want {
+		t.Errorf("TxURL = %q, want %q", got, want)
+	}
+
+	t.Setenv("CHAIN_ID", "8453")
+	t.Setenv("EXPLORER_URL", "https://explorer.example/")
+	chain, err = contracts.ChainFromEnv()
+	if err != nil || chain.Name != "Base" || len(chain.Tokens) == 0 {
+		t.Fatalf("ChainFromEnv for Base = %+v, %v", chain, err)
+	}
+	if got, want := chain.TxURL("0xabc"), "https://explorer.example/tx/0xabc"; got != want {
+		t.Errorf("TxURL with EXPLORER_URL = %q, want %q", got, want)
+	}
+
+	t.Setenv("CHAIN_ID", "base")
+	if _, err := contracts.ChainFromEnv(); err == nil {
+		t.Error("ChainFromEnv accepted a non-numeric CHAIN_ID")
+	}
+}
+
+func TestVerifyChainID(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	sepolia, _ := contracts.LookupChain(84532)
+	mainnet, _ := contracts.LookupChain(8453)
+
+	if err := contracts.VerifyChainID(context.Background(), client, sepolia); err != nil {
+		t.Errorf("VerifyChainID on the configured chain: %v", err)
+	}
+	if err := contracts.VerifyChainID(context.Background(), client, mainnet); err == nil {
+		t.Error("VerifyChainID accepted a node on another chain")
+	}
+}
@@ -0,0 +1,62 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// VerifyRegistrationTx checks that txHash emitted a Registered or Deposited event from
+// vault for participant
+func VerifyRegistrationTx(ctx context.Context, client TxReader, txHash string, vault, participant common.Address, confirmations uint64) (*TxVerification, error) {
+	return verifyParticipantTx(ctx, client, txHash, vault, participant, confirmations, RegisteredEventTopic, DepositedEventTopic)
+}
+
+// VerifyClaimTx checks that txHash emitted a Claimed event from vault for participant
+func VerifyClaimTx(ctx context.Context, client TxReader, txHash string, vault, participant common.Address, confirmations uint64) (*TxVerification, error) {
+	return verifyParticipantTx(ctx, client, txHash, vault, participant, confirmations, ClaimedEventTopic)
+}
+
+// VerifyWithdrawTx checks that txHash emitted a Withdrawn event from vault for participant
+func VerifyWithdrawTx(ctx context.Context, client TxReader, txHash string, vault, participant common.Address, confirmations uint64) (*TxVerification, error) {
+	return verifyParticipantTx(ctx, client, txHash, vault, participant, confirmations, WithdrawnEventTopic)
+}
+
+// VerifySettlementTx checks that txHash emitted a Settled event from vault. Like the
+// participant checks it matches the log rather than the target, so organizers may settle
+// through a smart wallet.
+func VerifySettlementTx(ctx context.Context, client TxReader, txHash string, vault common.Address, confirmations uint64) (*TxVerification, error) {
+	return VerifyTx(ctx, client, common.HexToHash(txHash), VerifyOpts{
+		Log: &LogMatch{
+			Address: vault,
+			Topics:  [][]common.Hash{{SettledEventTopic}},
+		},
+		Confirmations: confirmations,
+	})
+}
+
+// SettledInReceipt decodes the Settled event vault emitted in receipt
+func SettledInReceipt(receipt *types.Receipt, vault common.Address) (SettledEvent, error) {
+	match := LogMatch{Address: vault, Topics: [][]common.Hash{{SettledEventTopic}}}
+	for _, vLog := range receipt.Logs {
+		if match.Matches(vLog) {
+			return ParseSettled(*vLog)
+		}
+	}
+	return SettledEvent{}, errors.New("receipt has no Settled log from the vault")
+}
+
+// verifyParticipantTx verifies a transaction by its vault log rather than its target, so
+// calls routed through smart wallets are accepted. The log's first indexed argument must
+// be participant.
+func verifyParticipantTx(ctx context.Context, client TxReader, txHash string, vault, participant common.Address, confirmations uint64, topics ...common.Hash) (*TxVerification, error) {
+	return VerifyTx(ctx, client, common.HexToHash(txHash), VerifyOpts{
+		Log: &LogMatch{
+			Address: vault,
+			Topics:  [][]common.Hash{topics, {ParticipantTopic(participant)}},
+		},
+		Confirmations: confirmations,
+	})
+}
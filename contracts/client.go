@@ -0,0 +1,108 @@
+package contracts
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Caller is the part of an Ethereum client the contract helpers and handlers use.
+// *ethclient.Client implements it; contractstest.Client is a programmable fake for tests.
+type Caller interface {
+	bind.ContractCaller
+	ethereum.LogFilterer
+	TxReader
+	GasReader
+	HeaderReader
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+var _ Caller = (*ethclient.Client)(nil)
+
+// batchClient is implemented by *ethclient.Client, whose calls can share one RPC request
+type batchClient interface {
+	Client() *rpc.Client
+}
+
+// callBatch runs calls at the latest block under CallTimeout, in one batched RPC request
+// when client supports it and one after the other otherwise. Results line up with calls.
+// A failed call is reported in its own result; the returned error is only set when the
+// batch as a whole fails.
+func callBatch(ctx context.Context, client Caller, calls []Call) ([]CallResult, error) {
+	results := make([]CallResult, len(calls))
+	if len(calls) == 0 {
+		return results, nil
+	}
+
+	// Batched calls are per wallet and gain nothing from a ReadCache
+	if cache, ok := client.(*ReadCache); ok {
+		client = cache.Unwrap()
+	}
+	batcher, ok := client.(batchClient)
+	if !ok {
+		err := withCallTimeout(ctx, func(ctx context.Context) error {
+			for i, call := range calls {
+				msg := ethereum.CallMsg{To: &calls[i].Target, Data: call.CallData}
+				out, err := Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) ([]byte, error) {
+					return client.CallContract(ctx, msg, nil)
+				})
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Only reverts belong to the call; anything else would have failed a batch
+				if err != nil && !isExecutionError(err) {
+					return err
+				}
+				results[i] = CallResult{ReturnData: out, Err: err}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	returned := make([]hexutil.Bytes, len(calls))
+	batch := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{"to": call.Target, "data": hexutil.Bytes(call.CallData)},
+				"latest",
+			},
+			Result: &returned[i],
+		}
+	}
+
+	// A rate-limited call fails on its own inside a successful batch, so those retry the
+	// batch too. Once retries run out they are reported like any other failed call.
+	var batchErr error
+	err := withCallTimeout(ctx, func(ctx context.Context) error {
+		return retryCall(ctx, func(ctx context.Context) error {
+			if batchErr = batcher.Client().BatchCallContext(ctx, batch); batchErr != nil {
+				return batchErr
+			}
+			for _, elem := range batch {
+				if elem.Error != nil && IsTransient(elem.Error) {
+					return elem.Error
+				}
+			}
+			return nil
+		})
+	})
+	if batchErr != nil || IsCallTimeout(err) {
+		return nil, err
+	}
+
+	for i, elem := range batch {
+		results[i] = CallResult{ReturnData: returned[i], Err: elem.Error}
+	}
+	return results, nil
+}
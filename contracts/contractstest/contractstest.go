@@ -0,0 +1,721 @@
+// Package contractstest provides Client, a programmable fake of contracts.Caller, so code
+// that reads contracts can be tested without an RPC node.
+package contractstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/gen"
+)
+
+// ErrReverted is what calls without a programmed response fail with, like a call to a
+// function the contract does not have
+var ErrReverted = errors.New("execution reverted")
+
+// Gas figures a new Client answers with until SetGas and SetGasPrice say otherwise
+const (
+	DefaultGas       = 100_000
+	DefaultGasPrice  = 1_000_000_000 // 1 gwei
+	DefaultGasTipCap = 100_000_000   // 0.1 gwei
+)
+
+var (
+	vaultABI     = mustParseABI(gen.VaultMetaData)
+	erc20ABI     = mustParseABI(gen.ERC20MetaData)
+	multicallABI = mustParseABI(gen.Multicall3MetaData)
+	factoryABI   = mustParseABI(gen.FactoryMetaData)
+	erc1271ABI   = mustParseABI(gen.ERC1271MetaData)
+
+	multicallAddress = common.HexToAddress(contracts.Multicall3Address)
+)
+
+func mustParseABI(meta interface{ GetAbi() (*abi.ABI, error) }) abi.ABI {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return *parsed
+}
+
+type callKey struct {
+	to   common.Address
+	data string
+}
+
+type response struct {
+	out []byte
+	err error
+}
+
+// Client is an in-memory chain. Calls are answered from programmed responses, keyed by
+// target and exact calldata; Multicall3 aggregate3 requests are split up and answered the
+// same way. Every address has code unless Deploy or SetEOA says otherwise. Client is safe
+// for concurrent use.
+type Client struct {
+	// Delay holds every request for this long, or until its context ends
+	Delay time.Duration
+
+	mu        sync.Mutex
+	chainID   *big.Int
+	block     uint64
+	down      error
+	responses map[callKey]response
+	deployed  map[common.Address]uint64
+	eoas      map[common.Address]bool
+	txs       map[common.Hash]*types.Transaction
+	receipts  map[common.Hash]*types.Receipt
+	logs      []types.Log
+	calls     []ethereum.CallMsg
+	gas       uint64
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+	nonces    map[common.Address]uint64
+	sent      []*types.Transaction
+	reorgs    []uint64
+	mined     map[common.Address]uint64
+	pruned    uint64
+	history   map[callKey]map[uint64][]byte
+}
+
+var (
+	_ contracts.Caller   = (*Client)(nil)
+	_ contracts.TxSender = (*Client)(nil)
+
+	_ contracts.HeaderReader = (*Client)(nil)
+)
+
+// NewClient returns an empty chain with the given chain ID
+func NewClient(chainID int64) *Client {
+	return &Client{
+		chainID:   big.NewInt(chainID),
+		responses: map[callKey]response{},
+		deployed:  map[common.Address]uint64{},
+		eoas:      map[common.Address]bool{},
+		txs:       map[common.Hash]*types.Transaction{},
+		receipts:  map[common.Hash]*types.Receipt{},
+		gas:       DefaultGas,
+		gasPrice:  big.NewInt(DefaultGasPrice),
+		gasTipCap: big.NewInt(DefaultGasTipCap),
+		nonces:    map[common.Address]uint64{},
+		mined:     map[common.Address]uint64{},
+		history:   map[callKey]map[uint64][]byte{},
+	}
+}
+
+// Respond answers calls to to with callData with out
+func (c *Client) Respond(to common.Address, callData, out []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[callKey{to, string(callData)}] = response{out: out}
+}
+
+// Fail makes calls to to with callData fail with err
+func (c *Client) Fail(to common.Address, callData []byte, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[callKey{to, string(callData)}] = response{err: err}
+}
+
+// Down makes every request fail with err, as if the node were unreachable. Pass nil to
+// bring it back.
+func (c *Client) Down(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = err
+}
+
+// SetBlockNumber sets the latest block
+func (c *Client) SetBlockNumber(block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.block = block
+}
+
+// Deploy gives address code from block onwards only
+func (c *Client) Deploy(address common.Address, block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deployed[address] = block
+}
+
+// SetEOA makes address an externally owned account, with no code at any block
+func (c *Client) SetEOA(address common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eoas[address] = true
+}
+
+// AddTransaction mines tx with receipt, whose TxHash is set to tx's hash. A signed tx
+// uses up its sender's nonce.
+func (c *Client) AddTransaction(tx *types.Transaction, receipt *types.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt.TxHash = tx.Hash()
+	c.txs[tx.Hash()] = tx
+	c.receipts[tx.Hash()] = receipt
+	if from, err := types.Sender(types.LatestSignerForChainID(c.chainID), tx); err == nil && tx.Nonce() >= c.mined[from] {
+		c.mined[from] = tx.Nonce() + 1
+		if c.nonces[from] < c.mined[from] {
+			c.nonces[from] = c.mined[from]
+		}
+	}
+}
+
+// Reorg replaces every block from block on: their hashes change, and the logs in them
+// are dropped, as if the chain had forked just before block
+func (c *Client) Reorg(block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reorgs = append(c.reorgs, block)
+	kept := c.logs[:0]
+	for _, vLog := range c.logs {
+		if vLog.BlockNumber < block {
+			kept = append(kept, vLog)
+		}
+	}
+	c.logs = kept
+}
+
+// BlockHash is the hash of the canonical block at number, for logs added to it
+func (c *Client) BlockHash(number uint64) common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.header(number).Hash()
+}
+
+// BlockTime is the seconds between the fake chain's blocks; block n is mined at n*BlockTime
+const BlockTime = 2
+
+// Prune drops the state of every block below block, as a full node does: calls at those
+// blocks fail with "missing trie node"
+func (c *Client) Prune(block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruned = block
+}
+
+// SetParticipantCountAt answers vault's getParticipantCount with count for calls at block
+// and later, until a later block sets another
+func (c *Client) SetParticipantCountAt(vault common.Address, block uint64, count int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := callKey{vault, string(pack(vaultABI, "getParticipantCount"))}
+	if c.history[key] == nil {
+		c.history[key] = map[uint64][]byte{}
+	}
+	c.history[key][block] = packOutputs(vaultABI, "getParticipantCount", big.NewInt(count))
+}
+
+// header builds the canonical header at number; its hash changes with every Reorg at or
+// below it. c.mu must be held.
+func (c *Client) header(number uint64) *types.Header {
+	fork := 0
+	for _, from := range c.reorgs {
+		if from <= number {
+			fork++
+		}
+	}
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: new(big.Int),
+		Time:       number * BlockTime,
+		Extra:      []byte(fmt.Sprintf("fork %d", fork)),
+	}
+}
+
+// AddLogs adds logs for FilterLogs to return
+func (c *Client) AddLogs(logs ...types.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, logs...)
+}
+
+// Calls lists every eth_call received, in order. aggregate3 requests count as one.
+func (c *Client) Calls() []ethereum.CallMsg {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ethereum.CallMsg(nil), c.calls...)
+}
+
+// SetGas makes every gas estimate that is not programmed to fail return gas
+func (c *Client) SetGas(gas uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gas = gas
+}
+
+// SetGasPrice sets the suggested gas price and tip cap, in wei
+func (c *Client) SetGasPrice(gasPrice, tipCap *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gasPrice = new(big.Int).Set(gasPrice)
+	c.gasTipCap = new(big.Int).Set(tipCap)
+}
+
+// SetNonce sets account's pending nonce, as if it had sent transactions elsewhere
+func (c *Client) SetNonce(account common.Address, nonce uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonces[account] = nonce
+}
+
+// Sent returns the transactions accepted by SendTransaction, oldest first
+func (c *Client) Sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sent...)
+}
+
+// SetParticipantCount answers vault's getParticipantCount with count
+func (c *Client) SetParticipantCount(vault common.Address, count int64) {
+	c.SetVaultView(vault, "getParticipantCount", big.NewInt(count))
+}
+
+// SetVaultView answers vault's argument-less view function method with outputs
+func (c *Client) SetVaultView(vault common.Address, method string, outputs ...interface{}) {
+	c.Respond(vault, pack(vaultABI, method), packOutputs(vaultABI, method, outputs...))
+}
+
+// SetHasClaimed answers vault's hasClaimed(participant) with claimed
+func (c *Client) SetHasClaimed(vault, participant common.Address, claimed bool) {
+	c.Respond(vault, pack(vaultABI, "hasClaimed", participant), packOutputs(vaultABI, "hasClaimed", claimed))
+}
+
+// SetClaimable answers vault's claimableAmount(participant) with amount
+func (c *Client) SetClaimable(vault, participant common.Address, amount *big.Int) {
+	c.Respond(vault, pack(vaultABI, "claimableAmount", participant), packOutputs(vaultABI, "claimableAmount", amount))
+}
+
+// SetFactoryVault makes factory report vault as deployed for eventID, through both
+// getVault and isVault
+func (c *Client) SetFactoryVault(factory common.Address, eventID int64, vault common.Address) {
+	c.Respond(factory, pack(factoryABI, "getVault", big.NewInt(eventID)), packOutputs(factoryABI, "getVault", vault))
+	c.Respond(factory, pack(factoryABI, "isVault", vault), packOutputs(factoryABI, "isVault", true))
+}
+
+// SetBalance answers token's balanceOf(owner) with amount
+func (c *Client) SetBalance(token, owner common.Address, amount *big.Int) {
+	c.Respond(token, pack(erc20ABI, "balanceOf", owner), packOutputs(erc20ABI, "balanceOf", amount))
+}
+
+// SetAllowance answers token's allowance(owner, spender) with amount
+func (c *Client) SetAllowance(token, owner, spender common.Address, amount *big.Int) {
+	c.Respond(token, pack(erc20ABI, "allowance", owner, spender), packOutputs(erc20ABI, "allowance", amount))
+}
+
+// SetTokenMetadata answers token's symbol and decimals
+func (c *Client) SetTokenMetadata(token common.Address, symbol string, decimals uint8) {
+	c.Respond(token, pack(erc20ABI, "symbol"), packOutputs(erc20ABI, "symbol", symbol))
+	c.Respond(token, pack(erc20ABI, "decimals"), packOutputs(erc20ABI, "decimals", decimals))
+}
+
+// SetSignatureResult answers wallet's EIP-1271 isValidSignature(hash, signature) with
+// magicValue
+func (c *Client) SetSignatureResult(wallet common.Address, hash [32]byte, signature []byte, magicValue [4]byte) {
+	c.Respond(wallet, pack(erc1271ABI, "isValidSignature", hash, signature), packOutputs(erc1271ABI, "isValidSignature", magicValue))
+}
+
+func pack(contractABI abi.ABI, method string, args ...interface{}) []byte {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		panic(fmt.Sprintf("contractstest: packing %s: %v", method, err))
+	}
+	return data
+}
+
+func packOutputs(contractABI abi.ABI, method string, values ...interface{}) []byte {
+	data, err := contractABI.Methods[method].Outputs.Pack(values...)
+	if err != nil {
+		panic(fmt.Sprintf("contractstest: packing %s outputs: %v", method, err))
+	}
+	return data
+}
+
+// begin waits out Delay and reports the error a request fails with, if any
+func (c *Client) begin(ctx context.Context) error {
+	if c.Delay > 0 {
+		timer := time.NewTimer(c.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.down
+}
+
+// CallContract implements bind.ContractCaller
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+	if call.To == nil {
+		return nil, errors.New("contractstest: call without a target")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+
+	if blockNumber != nil {
+		if blockNumber.Uint64() < c.pruned {
+			return nil, errors.New("missing trie node 0x0000000000000000000000000000000000000000000000000000000000000000 (path ) state is not available")
+		}
+		if out, ok := c.respondAt(*call.To, call.Data, blockNumber.Uint64()); ok {
+			return out, nil
+		}
+	}
+	if *call.To == multicallAddress && bytes.HasPrefix(call.Data, multicallABI.Methods["aggregate3"].ID) {
+		return c.aggregate3(call.Data)
+	}
+	return c.respond(*call.To, call.Data)
+}
+
+// respondAt looks up the response set for the latest block at or below block by
+// SetParticipantCountAt. c.mu must be held.
+func (c *Client) respondAt(to common.Address, callData []byte, block uint64) ([]byte, bool) {
+	var out []byte
+	found, from := false, uint64(0)
+	for at, value := range c.history[callKey{to, string(callData)}] {
+		if at <= block && (!found || at > from) {
+			out, found, from = value, true, at
+		}
+	}
+	return out, found
+}
+
+// respond looks up the programmed response. c.mu must be held.
+func (c *Client) respond(to common.Address, callData []byte) ([]byte, error) {
+	resp, ok := c.responses[callKey{to, string(callData)}]
+	if !ok {
+		return nil, ErrReverted
+	}
+	return resp.out, resp.err
+}
+
+// aggregate3 answers each batched call from the programmed responses. c.mu must be held.
+func (c *Client) aggregate3(data []byte) ([]byte, error) {
+	method := multicallABI.Methods["aggregate3"]
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("contractstest: decoding aggregate3: %w", err)
+	}
+	calls := *abi.ConvertType(values[0], new([]gen.Multicall3Call3)).(*[]gen.Multicall3Call3)
+
+	results := make([]gen.Multicall3Result, len(calls))
+	for i, call := range calls {
+		out, err := c.respond(call.Target, call.CallData)
+		if err != nil {
+			if !call.AllowFailure {
+				return nil, ErrReverted
+			}
+			results[i] = gen.Multicall3Result{ReturnData: []byte{}}
+			continue
+		}
+		results[i] = gen.Multicall3Result{Success: true, ReturnData: out}
+	}
+	return method.Outputs.Pack(results)
+}
+
+// EstimateGas implements ethereum.GasEstimator. A call programmed with Fail fails to
+// estimate with the same error, as it would revert; every other call costs the gas set
+// with SetGas.
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if err := c.begin(ctx); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if call.To != nil {
+		if resp, ok := c.responses[callKey{*call.To, string(call.Data)}]; ok && resp.err != nil {
+			return 0, resp.err
+		}
+	}
+	return c.gas, nil
+}
+
+// SuggestGasPrice implements ethereum.GasPricer
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+// SuggestGasTipCap implements contracts.GasReader
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.gasTipCap), nil
+}
+
+// CodeAt implements bind.ContractCaller
+func (c *Client) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.eoas[contract] {
+		return nil, nil
+	}
+	deployedAt, ok := c.deployed[contract]
+	if ok && blockNumber != nil && blockNumber.Uint64() < deployedAt {
+		return nil, nil
+	}
+	return []byte{0x60}, nil
+}
+
+// FilterLogs implements ethereum.LogFilterer
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var logs []types.Log
+	for _, vLog := range c.logs {
+		if matches(q, vLog) {
+			logs = append(logs, vLog)
+		}
+	}
+	return logs, nil
+}
+
+func matches(q ethereum.FilterQuery, vLog types.Log) bool {
+	if q.FromBlock != nil && vLog.BlockNumber < q.FromBlock.Uint64() {
+		return false
+	}
+	if q.ToBlock != nil && vLog.BlockNumber > q.ToBlock.Uint64() {
+		return false
+	}
+	if len(q.Addresses) > 0 && !contains(q.Addresses, vLog.Address) {
+		return false
+	}
+	if len(vLog.Topics) < len(q.Topics) {
+		return false
+	}
+	for i, accepted := range q.Topics {
+		if len(accepted) > 0 && !contains(accepted, vLog.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscribeFilterLogs implements ethereum.LogFilterer. The fake has no subscriptions, so
+// a LogStream over it polls.
+func (c *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("contractstest: subscriptions are not supported")
+}
+
+// TransactionReceipt implements contracts.TxReader
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// TransactionByHash implements contracts.TxReader
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, false, nil
+}
+
+// BlockNumber implements contracts.TxReader
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := c.begin(ctx); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.block, nil
+}
+
+// HeaderByNumber implements contracts.HeaderReader. Blocks above the latest block are
+// not found; a nil number is the latest block.
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number == nil {
+		return c.header(c.block), nil
+	}
+	if number.Uint64() > c.block {
+		return nil, ethereum.NotFound
+	}
+	return c.header(number.Uint64()), nil
+}
+
+// PendingNonceAt implements contracts.TxSender
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if err := c.begin(ctx); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonces[account], nil
+}
+
+// NonceAt implements contracts.TxSender. Only the latest block is known: it returns the
+// nonce after the sender's transactions mined by AddTransaction.
+func (c *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if err := c.begin(ctx); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mined[account], nil
+}
+
+// SendTransaction implements contracts.TxSender. A signed transaction is accepted when its
+// nonce is the sender's pending nonce, or when it replaces an unmined transaction sent
+// with the same nonce with both fees at least 10% higher, as nodes require. It is not
+// mined until AddTransaction says so.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.begin(ctx); err != nil {
+		return err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(c.chainID), tx)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch nonce := c.nonces[from]; {
+	case tx.Nonce() < c.mined[from]:
+		return errors.New("nonce too low")
+	case tx.Nonce() < nonce:
+		return c.replace(from, tx)
+	case tx.Nonce() > nonce:
+		return errors.New("nonce too high")
+	}
+	c.nonces[from]++
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+// replace accepts tx in place of the pending transaction with its sender and nonce. c.mu
+// must be held.
+func (c *Client) replace(from common.Address, tx *types.Transaction) error {
+	signer := types.LatestSignerForChainID(c.chainID)
+	for i := len(c.sent) - 1; i >= 0; i-- {
+		pending := c.sent[i]
+		if sender, _ := types.Sender(signer, pending); sender != from || pending.Nonce() != tx.Nonce() {
+			continue
+		}
+		if !bumped(tx.GasTipCap(), pending.GasTipCap()) || !bumped(tx.GasFeeCap(), pending.GasFeeCap()) {
+			return errors.New("replacement transaction underpriced")
+		}
+		c.sent = append(c.sent, tx)
+		return nil
+	}
+	return errors.New("nonce too low")
+}
+
+// bumped reports whether fee is at least 10% above previous
+func bumped(fee, previous *big.Int) bool {
+	threshold := new(big.Int).Mul(previous, big.NewInt(110))
+	return new(big.Int).Mul(fee, big.NewInt(100)).Cmp(threshold) >= 0
+}
+
+// ChainID implements contracts.Caller
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := c.begin(ctx); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(c.chainID), nil
+}
+
+// revertError is a node's revert with its data, like the rpc.DataError ethclient returns
+type revertError struct {
+	reason string
+	data   string
+}
+
+func (e *revertError) Error() string          { return "execution reverted: " + e.reason }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+// Revert returns the error a node reports for a call reverting with require(false, reason),
+// for use with Fail
+func Revert(reason string) error {
+	method := abi.NewMethod("Error", "Error", abi.Function, "", false, false,
+		abi.Arguments{{Type: mustNewType("string")}}, nil)
+	data, err := method.Inputs.Pack(reason)
+	if err != nil {
+		panic(fmt.Sprintf("contractstest: packing revert reason: %v", err))
+	}
+	return &revertError{reason: reason, data: hexutil.Encode(append(method.ID, data...))}
+}
+
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
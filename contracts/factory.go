@@ -0,0 +1,126 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"atfi-backend/contracts/gen"
+)
+
+// EventCreatedEventTopic is the topic of the factory log emitted for every new event vault
+var EventCreatedEventTopic = crypto.Keccak256Hash([]byte("EventCreated(uint256,address,address,uint256,uint256,uint256,uint256)"))
+
+// factoryLogs decodes factory logs with the generated binding; it never talks to a node
+var factoryLogs = mustFactoryFilterer()
+
+func mustFactoryFilterer() *gen.FactoryFilterer {
+	filterer, err := gen.NewFactoryFilterer(common.Address{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return filterer
+}
+
+// EventCreatedEvent is emitted by the factory when an organizer deploys an event vault
+type EventCreatedEvent struct {
+	LogMeta
+	EventID              *big.Int
+	Vault                common.Address
+	Organizer            common.Address
+	StakeAmount          *big.Int
+	MaxParticipants      *big.Int
+	RegistrationDeadline *big.Int
+	EventDate            *big.Int
+}
+
+// ParseEventCreated decodes an EventCreated log..
+func ParseEventCreated(vLog types.Log) (EventCreatedEvent, error) {
+	event, err := factoryLogs.ParseEventCreated(vLog)
+	if err != nil {
+		return EventCreatedEvent{}, fmt.Errorf("failed to parse EventCreated log: %w", err)
+	}
+	return EventCreatedEvent{
+		LogMeta:              logMeta(vLog),
+		EventID:              event.EventId,
+		Vault:                event.Vault,
+		Organizer:            event.Organizer,
+		StakeAmount:          event.StakeAmount,
+		MaxParticipants:      event.MaxParticipant,
+		RegistrationDeadline: event.RegistrationDeadline,
+		EventDate:            event.EventDate,
+	}, nil
+}
+
+// FactoryAddressFromEnv returns FACTORY_ADDRESS, the factory deployed on the RPC node's
+// chain, or the factory of the CHAIN_ID preset. ok is false when neither is set.
+func FactoryAddressFromEnv() (address common.Address, ok bool, err error) {
+	raw := strings.TrimSpace(os.Getenv("FACTORY_ADDRESS"))
+	if raw == "" {
+		chain, err := ChainFromEnv()
+		if err != nil {
+			return common.Address{}, false, err
+		}
+		raw = chain.FactoryAddress
+	}
+	if raw == "" {
+		return common.Address{}, false, nil
+	}
+	if !common.IsHexAddress(raw) {
+		return common.Address{}, false, fmt.Errorf("invalid FACTORY_ADDRESS %q", raw)
+	}
+	return common.HexToAddress(raw), true, nil
+}
+
+// FactoryContract reads the event factory, the registry of every vault ATFi deployed
+type FactoryContract struct {
+	address common.Address
+	caller  *gen.FactoryCaller
+}
+
+// NewFactoryContract binds the factory at address. Transient failures are retried under
+// DefaultRetryPolicy.
+func NewFactoryContract(client bind.ContractCaller, address common.Address) (*FactoryContract, error) {
+	caller, err := gen.NewFactoryCaller(address, retryingCaller{client})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind factory contract: %w", err)
+	}
+	return &FactoryContract{address: address, caller: caller}, nil
+}
+
+// Address is the factory's contract address
+func (f *FactoryContract) Address() common.Address {
+	return f.address
+}
+
+// IsVaultDeployed reports whether the factory deployed vault
+func (f *FactoryContract) IsVaultDeployed(ctx context.Context, vault common.Address) (bool, error) {
+	return vaultCall(ctx, "isVault", func(opts *bind.CallOpts) (bool, error) {
+		return f.caller.IsVault(opts, vault)
+	})
+}
+
+// GetVault returns the vault the factory deployed for eventID, or the zero address when
+// there is no such event
+func (f *FactoryContract) GetVault(ctx context.Context, eventID int64) (common.Address, error) {
+	return vaultCall(ctx, "getVault", func(opts *bind.CallOpts) (common.Address, error) {
+		return f.caller.GetVault(opts, big.NewInt(eventID))
+	})
+}
+
+// VerifyVault reports whether vault is the one the factory deployed for eventID, so that
+// neither a foreign contract nor another event's vault passes
+func (f *FactoryContract) VerifyVault(ctx context.Context, eventID int64, vault common.Address) (bool, error) {
+	deployed, err := f.GetVault(ctx, eventID)
+	if err != nil {
+		return false, err
+	}
+	return deployed != (common.Address{}) && deployed == vault, nil
+}
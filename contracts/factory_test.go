@@ -0,0 +1,52 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestFactoryVerifyVault(t *testing.T) {
+	factoryAddress := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	client := contractstest.NewClient(84532)
+	client.SetFactoryVault(factoryAddress, 7, vault)
+	client.SetFactoryVault(factoryAddress, 8, common.Address{})
+
+	factory, err := contracts.NewFactoryContract(client, factoryAddress)
+	if err != nil {
+		t.Fatalf("NewFactoryContract: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		eventID int64
+		vault   common.Address
+		want    bool
+	}{
+		{"vault deployed for the event", 7, vault, true},
+		{"foreign contract", 7, otherVault, false},
+		{"event without a vault", 8, common.Address{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := factory.VerifyVault(context.Background(), tt.eventID, tt.vault)
+			if err != nil || got != tt.want {
+				t.Errorf("VerifyVault = %t, %v; want %t", got, err, tt.want)
+			}
+		})
+	}
+
+	if deployed, err := factory.IsVaultDeployed(context.Background(), vault); err != nil || !deployed {
+		t.Errorf("IsVaultDeployed = %t, %v; want true", deployed, err)
+	}
+
+	// Unknown events revert in the fake; the error must not read as a verdict
+	if _, err := factory.VerifyVault(context.Background(), 9, vault); !errors.Is(err, contractstest.ErrReverted) {
+		t.Errorf("VerifyVault of an unprogrammed event = %v, want the revert", err)
+	}
+}
@@ -0,0 +1,290 @@
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Defaults for a Failover
+const (
+	DefaultProviderCooldown = 30 * time.Second
+	DefaultProbeInterval    = 15 * time.Second
+)
+
+// probeBody is the request used to check whether an unhealthy provider is back
+const probeBody = `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`
+
+// ProviderHealth is one RPC provider's state as reported by the readiness endpoint. Name
+// is the provider's scheme and host only, since URLs often embed API keys.
+type ProviderHealth struct {
+	Name           string     `json:"name"`
+	Healthy        bool       `json:"healthy"`
+	Requests       uint64     `json:"requests"`
+	Failures       uint64     `json:"failures"`
+	LastError      string     `json:"last_error,omitempty"`
+	UnhealthyUntil *time.Time `json:"unhealthy_until,omitempty"`
+}
+
+type provider struct {
+	url     *url.URL
+	name    string
+	healthy bool
+
+	requests       uint64
+	failures       uint64
+	lastErr        string
+	unhealthyUntil time.Time
+}
+
+// Failover is an http.RoundTripper that sends each JSON-RPC request to the first healthy
+// provider, in configured order, and moves on to the next when a provider fails at the
+// transport level or answers 429 or 5xx. A failing provider is skipped for Cooldown and
+// then probed back to health by Run. JSON-RPC errors such as reverts come back as normal
+// responses and never trigger a failover.
+type Failover struct {
+	providers []*provider
+	transport http.RoundTripper
+	failovers atomic.Uint64
+
+	Cooldown time.Duration
+
+	mu sync.Mutex
+}
+
+// NewFailover returns a Failover over urls, tried in the given order
+func NewFailover(urls []string) (*Failover, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no RPC URLs configured")
+	}
+
+	f := &Failover{transport: http.DefaultTransport, Cooldown: DefaultProviderCooldown}
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("invalid RPC URL %q: must be http or https", redactURL(raw))
+		}
+		f.providers = append(f.providers, &provider{url: parsed, name: parsed.Scheme + "://" + parsed.Host, healthy: true})
+	}
+	return f, nil
+}
+
+// DialFailover returns an ethclient whose requests go through a Failover over urls
+func DialFailover(ctx context.Context, urls []string) (*ethclient.Client, *Failover, error) {
+	failover, err := NewFailover(urls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, urls[0], rpc.WithHTTPClient(&http.Client{Transport: failover}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create RPC client: %w", err)
+	}
+	return ethclient.NewClient(rpcClient), failover, nil
+}
+
+// ParseRPCURLs splits a comma-separated RPC_URLS value, dropping empty entries
+func ParseRPCURLs(raw string) []string {
+	var urls []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			urls = append(urls, part)
+		}
+	}
+	return urls
+}
+
+// RoundTrip implements http.RoundTripper
+func (f *Failover) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for attempt, p := range f.candidates() {
+		resp, err := f.send(req, p, body)
+		if err == nil {
+			f.markHealthy(p)
+			if attempt > 0 {
+				f.failovers.Add(1)
+			}
+			return resp, nil
+		}
+
+		// A cancelled request says nothing about the provider
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		f.markUnhealthy(p, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// send forwards req to p. 429 and 5xx answers count as failures.
+func (f *Failover) send(req *http.Request, p *provider, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL = p.url
+	out.Host = p.url.Host
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := f.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s answered %s", p.name, resp.Status)
+	}
+	return resp, nil
+}
+
+// candidates lists the providers not cooling down, in order, or every provider when all
+// are, so an outage of all of them still gets a try
+func (f *Failover) candidates() []*provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	var healthy []*provider
+	for _, p := range f.providers {
+		if !now.Before(p.unhealthyUntil) {
+			healthy = append(healthy, p)
+		}
+	}
+	if len(healthy) == 0 {
+		return f.providers
+	}
+	return healthy
+}
+
+func (f *Failover) markHealthy(p *provider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.requests++
+	p.healthy = true
+	p.unhealthyUntil = time.Time{}
+}
+
+func (f *Failover) markUnhealthy(p *provider, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.requests++
+	p.failures++
+	p.healthy = false
+	p.lastErr = redactURL(err.Error())
+	p.unhealthyUntil = time.Now().Add(f.Cooldown)
+}
+
+// Probe sends eth_blockNumber to every unhealthy provider whose cooldown has passed, and
+// marks the ones that answer healthy again
+func (f *Failover) Probe(ctx context.Context) {
+	f.mu.Lock()
+	var due []*provider
+	for _, p := range f.providers {
+		if !p.healthy && !time.Now().Before(p.unhealthyUntil) {
+			due = append(due, p)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, p := range due {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url.String(), nil)
+		if err != nil {
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := f.send(req, p, []byte(probeBody))
+		if err != nil {
+			f.markUnhealthy(p, err)
+			continue
+		}
+		resp.Body.Close()
+		f.markHealthy(p)
+	}
+}
+
+// Run probes unhealthy providers every interval until ctx is cancelled
+func (f *Failover) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.Probe(ctx)
+		}
+	}
+}
+
+// Health reports every provider in configured order
+func (f *Failover) Health() []ProviderHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	health := make([]ProviderHealth, len(f.providers))
+	for i, p := range f.providers {
+		health[i] = ProviderHealth{
+			Name:      p.name,
+			Healthy:   p.healthy,
+			Requests:  p.requests,
+			Failures:  p.failures,
+			LastError: p.lastErr,
+		}
+		if !health[i].Healthy {
+			until := p.unhealthyUntil
+			health[i].UnhealthyUntil = &until
+		}
+	}
+	return health
+}
+
+// AnyHealthy reports whether at least one provider is healthy
+func AnyHealthy(providers []ProviderHealth) bool {
+	for _, p := range providers {
+		if p.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// Failovers counts requests that succeeded only after an earlier provider failed
+func (f *Failover) Failovers() uint64 {
+	return f.failovers.Load()
+}
+
+// redactURL strips paths and queries, where providers put API keys, from URLs in s
+func redactURL(s string) string {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		trimmed := strings.Trim(field, `"':`)
+		parsed, err := url.Parse(trimmed)
+		if err != nil || parsed.Host == "" || (parsed.Path == "" && parsed.RawQuery == "") {
+			continue
+		}
+		fields[i] = strings.Replace(field, trimmed, parsed.Scheme+"://"+parsed.Host+"/…", 1)
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,106 @@
+package contracts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// rpcServer answers eth_blockNumber with 0x10, or with status while failing is set
+func rpcServer(t *testing.T, status int) (*httptest.Server, *atomic.Bool, *atomic.Int32) {
+	t.Helper()
+	failing := &atomic.Bool{}
+	failing.Store(true)
+	hits := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, failing, hits
+}
+
+func TestFailoverSkipsUnhealthyProviders(t *testing.T) {
+	primary, primaryFailing, primaryHits := rpcServer(t, http.StatusTooManyRequests)
+	backup, backupFailing, _ := rpcServer(t, http.StatusBadGateway)
+	backupFailing.Store(false)
+
+	client, failover, err := DialFailover(context.Background(), []string{primary.URL + "/v2/secret-key", backup.URL})
+	if err != nil {
+		t.Fatalf("DialFailover: %v", err)
+	}
+	defer client.Close()
+
+	block, err := client.BlockNumber(context.Background())
+	if err != nil || block != 16 {
+		t.Fatalf("BlockNumber = %d, %v; want 16 from the backup", block, err)
+	}
+	if failover.Failovers() != 1 {
+		t.Errorf("failovers = %d, want 1", failover.Failovers())
+	}
+
+	health := failover.Health()
+	if health[0].Healthy || !health[1].Healthy {
+		t.Errorf("health = %+v, want the primary unhealthy", health)
+	}
+	if strings.Contains(health[0].Name, "secret-key") || strings.Contains(health[0].LastError, "secret-key") {
+		t.Errorf("health leaks the provider URL: %+v", health[0])
+	}
+
+	// While cooling down the primary is not tried
+	if _, err := client.BlockNumber(context.Background()); err != nil {
+		t.Fatalf("BlockNumber: %v", err)
+	}
+	if primaryHits.Load() != 1 {
+		t.Errorf("primary got %d requests during its cooldown, want 1", primaryHits.Load())
+	}
+
+	// Once the cooldown has passed a probe brings it back
+	primaryFailing.Store(false)
+	failover.Cooldown = 0
+	failover.markUnhealthy(failover.providers[0], context.DeadlineExceeded)
+	failover.Probe(context.Background())
+	if health := failover.Health(); !health[0].Healthy {
+		t.Errorf("primary still unhealthy after a successful probe: %+v", health[0])
+	}
+}
+
+func TestFailoverTriesEveryProviderWhenAllAreDown(t *testing.T) {
+	primary, _, primaryHits := rpcServer(t, http.StatusServiceUnavailable)
+	backup, _, backupHits := rpcServer(t, http.StatusServiceUnavailable)
+
+	client, failover, err := DialFailover(context.Background(), []string{primary.URL, backup.URL})
+	if err != nil {
+		t.Fatalf("DialFailover: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.BlockNumber(context.Background()); err == nil {
+			t.Fatal("expected an error with every provider down")
+		}
+	}
+	if primaryHits.Load() != 2 || backupHits.Load() != 2 {
+		t.Errorf("hits = %d/%d, want every provider tried each time", primaryHits.Load(), backupHits.Load())
+	}
+	if AnyHealthy(failover.Health()) {
+		t.Error("AnyHealthy = true with every provider down")
+	}
+}
+
+func TestNewFailoverRejectsNonHTTPURLs(t *testing.T) {
+	if _, err := NewFailover([]string{"wss://example.com"}); err == nil {
+		t.Error("expected an error for a WebSocket URL")
+	}
+	if _, err := NewFailover(nil); err == nil {
+		t.Error("expected an error without URLs")
+	}
+}
@@ -0,0 +1,108 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultConfirmationDepth is how many blocks, counting its own, a log needs before data
+// copied from it is treated as final
+const DefaultConfirmationDepth = 5
+
+// HeaderReader reads the canonical chain's headers
+type HeaderReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+var _ HeaderReader = (*ethclient.Client)(nil)
+
+// BlockRef names a block by number and hash, as a log records it
+type BlockRef struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// Finality is where a block stands against the canonical chain
+type Finality string
+
+const (
+	// FinalityPending is still canonical but not yet deep enough, or not visible to the
+	// node yet
+	FinalityPending Finality = "pending"
+	// FinalityFinal is canonical and at least the confirmation depth deep
+	FinalityFinal Finality = "final"
+	// FinalityReorged was replaced: the canonical block at its height has another hash
+	FinalityReorged Finality = "reorged"
+)
+
+// CheckFinality reports the finality of each ref at the given depth, reading the head
+// once and each distinct height's header once. A block whose hash no longer matches is
+// reorged however deep it is; results line up with refs.
+func CheckFinality(ctx context.Context, client HeaderReader, depth uint64, refs []BlockRef) ([]Finality, error) {
+	results := make([]Finality, len(refs))
+	if len(refs) == 0 {
+		return results, nil
+	}
+
+	var latest uint64
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		latest, err = Retry(ctx, DefaultRetryPolicy, client.BlockNumber)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	canonical := map[uint64]*common.Hash{}
+	for i, ref := range refs {
+		hash, ok := canonical[ref.Number]
+		if !ok {
+			hash, err = canonicalHash(ctx, client, ref.Number)
+			if err != nil {
+				return nil, err
+			}
+			canonical[ref.Number] = hash
+		}
+
+		switch {
+		case hash == nil:
+			// The node has not seen the height yet, or the chain got shorter
+			results[i] = FinalityPending
+		case *hash != ref.Hash:
+			results[i] = FinalityReorged
+		case ref.Number <= latest && latest-ref.Number+1 >= depth:
+			results[i] = FinalityFinal
+		default:
+			results[i] = FinalityPending
+		}
+	}
+	return results, nil
+}
+
+// canonicalHash returns the hash of the canonical block at number, or nil when the node
+// has no block there
+func canonicalHash(ctx context.Context, client HeaderReader, number uint64) (*common.Hash, error) {
+	var header *types.Header
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		header, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (*types.Header, error) {
+			return client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		})
+		return err
+	})
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %d: %w", number, err)
+	}
+	hash := header.Hash()
+	return &hash, nil
+}
@@ -0,0 +1,82 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestCheckFinality(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+
+	refs := []contracts.BlockRef{
+		{Number: 90, Hash: client.BlockHash(90)},
+		{Number: 96, Hash: client.BlockHash(96)},
+		{Number: 97, Hash: client.BlockHash(97)},
+		{Number: 100, Hash: client.BlockHash(100)},
+	}
+	got, err := contracts.CheckFinality(context.Background(), client, 5, refs)
+	if err != nil {
+		t.Fatalf("CheckFinality: %v", err)
+	}
+	// Block 96 has 5 confirmations at head 100, block 97 only 4
+	want := []contracts.Finality{contracts.FinalityFinal, contracts.FinalityFinal, contracts.FinalityPending, contracts.FinalityPending}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: %s, want %s", refs[i].Number, got[i], want[i])
+		}
+	}
+
+	// Blocks above the head are not known yet
+	ahead := []contracts.BlockRef{{Number: 101, Hash: client.BlockHash(101)}}
+	if got, err := contracts.CheckFinality(context.Background(), client, 5, ahead); err != nil || got[0] != contracts.FinalityPending {
+		t.Errorf("block above the head: %v, %v; want pending", got, err)
+	}
+}
+
+func TestCheckFinalityDetectsReorgs(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+
+	refs := []contracts.BlockRef{
+		{Number: 94, Hash: client.BlockHash(94)},
+		{Number: 95, Hash: client.BlockHash(95)},
+		{Number: 99, Hash: client.BlockHash(99)},
+	}
+
+	// The chain forks before block 95 and grows past the depth of every ref
+	client.Reorg(95)
+	client.SetBlockNumber(110)
+
+	got, err := contracts.CheckFinality(context.Background(), client, 5, refs)
+	if err != nil {
+		t.Fatalf("CheckFinality: %v", err)
+	}
+	want := []contracts.Finality{contracts.FinalityFinal, contracts.FinalityReorged, contracts.FinalityReorged}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d: %s, want %s", refs[i].Number, got[i], want[i])
+		}
+	}
+
+	// A ref taken from the new fork is canonical
+	refs[1].Hash = client.BlockHash(95)
+	got, err = contracts.CheckFinality(context.Background(), client, 5, refs[1:2])
+	if err != nil || got[0] != contracts.FinalityFinal {
+		t.Errorf("block 95 on the new fork: %v, %v; want final", got, err)
+	}
+}
+
+func TestCheckFinalityFailsWhenTheNodeIsDown(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+	client.Down(errors.New("401 Unauthorized"))
+
+	if _, err := contracts.CheckFinality(context.Background(), client, 5, []contracts.BlockRef{{Number: 90}}); err == nil {
+		t.Error("CheckFinality succeeded with the node down")
+	}
+}
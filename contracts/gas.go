@@ -0,0 +1,107 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// DefaultGasPriceTTL is how long a GasOracle reuses fetched fee suggestions
+const DefaultGasPriceTTL = 5 * time.Second
+
+// GasReader is the part of an Ethereum client gas estimation needs
+type GasReader interface {
+	ethereum.GasEstimator
+	ethereum.GasPricer
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// GasPrice is a fee suggestion in wei per gas. MaxFeePerGas leaves room for the base fee
+// to double before the transaction is mined, the way wallets price EIP-1559 transactions.
+type GasPrice struct {
+	GasPrice             *big.Int
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+}
+
+// GasOracle fetches fee suggestions from the node and reuses them for TTL, so a burst of
+// estimates costs two RPC requests rather than two each
+type GasOracle struct {
+	client GasReader
+
+	// TTL is how long fetched suggestions are reused
+	TTL time.Duration
+
+	mu      sync.Mutex
+	price   GasPrice
+	fetched time.Time
+}
+
+// NewGasOracle returns a GasOracle caching for DefaultGasPriceTTL
+func NewGasOracle(client GasReader) *GasOracle {
+	return &GasOracle{client: client, TTL: DefaultGasPriceTTL}
+}
+
+// Price returns the current fee suggestion. Failures are not cached.
+func (o *GasOracle) Price(ctx context.Context) (GasPrice, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.fetched.IsZero() && time.Since(o.fetched) < o.TTL {
+		return o.price, nil
+	}
+
+	var gasPrice, tip *big.Int
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		gasPrice, err = Retry(ctx, DefaultRetryPolicy, o.client.SuggestGasPrice)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		tip, err = Retry(ctx, DefaultRetryPolicy, o.client.SuggestGasTipCap)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return GasPrice{}, err
+	}
+
+	o.price = newGasPrice(gasPrice, tip)
+	o.fetched = time.Now()
+	return o.price, nil
+}
+
+// newGasPrice derives the max fee from the node's gas price, which is the base fee plus
+// the tip
+func newGasPrice(gasPrice, tip *big.Int) GasPrice {
+	if tip.Cmp(gasPrice) > 0 {
+		tip = gasPrice
+	}
+	baseFee := new(big.Int).Sub(gasPrice, tip)
+	maxFee := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	return GasPrice{
+		GasPrice:             new(big.Int).Set(gasPrice),
+		MaxPriorityFeePerGas: new(big.Int).Set(tip),
+		MaxFeePerGas:         maxFee,
+	}
+}
+
+// EstimateGas estimates the gas msg would use under CallTimeout. A call that would revert
+// fails with an error RevertReason can explain.
+func EstimateGas(ctx context.Context, client GasReader, msg ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		gas, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (uint64, error) {
+			return client.EstimateGas(ctx, msg)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	return gas, nil
+}
@@ -0,0 +1,72 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestGasOracleCachesPrices(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetGasPrice(big.NewInt(1100), big.NewInt(100))
+	oracle := contracts.NewGasOracle(client)
+
+	price, err := oracle.Price(context.Background())
+	if err != nil {
+		t.Fatalf("Price: %v", err)
+	}
+	// Base fee 1000 doubled, plus the tip
+	if price.GasPrice.Int64() != 1100 || price.MaxPriorityFeePerGas.Int64() != 100 || price.MaxFeePerGas.Int64() != 2100 {
+		t.Errorf("price = %+v, want 1100, tip 100, max 2100", price)
+	}
+
+	// Within the TTL the node is not asked again
+	client.Down(errors.New("503 Service Unavailable"))
+	if _, err := oracle.Price(context.Background()); err != nil {
+		t.Errorf("cached Price: %v", err)
+	}
+
+	oracle.TTL = 0
+	if _, err := oracle.Price(context.Background()); err == nil {
+		t.Error("Price succeeded with the node down and the cache expired")
+	}
+}
+
+func TestEstimateGasReportsRevertReason(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetGas(52000)
+	claim, err := contracts.PackVaultCall(contracts.ClaimFunction)
+	if err != nil {
+		t.Fatalf("PackVaultCall: %v", err)
+	}
+
+	gas, err := contracts.EstimateGas(context.Background(), client, ethereum.CallMsg{From: participant, To: &vault, Data: claim})
+	if err != nil || gas != 52000 {
+		t.Errorf("EstimateGas = %d, %v; want 52000", gas, err)
+	}
+
+	client.Fail(vault, claim, contractstest.Revert("Not an attendee"))
+	_, err = contracts.EstimateGas(context.Background(), client, ethereum.CallMsg{From: participant, To: &vault, Data: claim})
+	if reason, ok := contracts.RevertReason(err); !ok || reason != "Not an attendee" {
+		t.Errorf("RevertReason = %q, %t; want the decoded reason", reason, ok)
+	}
+
+	client.Down(errors.New("connection refused"))
+	_, err = contracts.EstimateGas(context.Background(), client, ethereum.CallMsg{From: participant, To: &vault, Data: claim})
+	if _, ok := contracts.RevertReason(err); ok || err == nil {
+		t.Errorf("unreachable node reported as a revert: %v", err)
+	}
+}
+
+func TestRevertReasonFromMessage(t *testing.T) {
+	err := errors.New("failed to estimate gas: insufficient funds for gas * price + value")
+	if reason, ok := contracts.RevertReason(err); !ok || reason != "insufficient funds for gas * price + value" {
+		t.Errorf("RevertReason = %q, %t", reason, ok)
+	}
+}
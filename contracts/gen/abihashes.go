@@ -0,0 +1,12 @@
+// Code generated by bindgen. DO NOT EDIT.
+
+package gen
+
+// ABIHashes is the SHA-256 of each ABI in contracts/abi the bindings were generated from
+var ABIHashes = map[string]string{
+	"ERC1271":    "c4576a4b00ef6c8c9ef9ad2305eaf340a0441698a67119804866d9e3b5293989",
+	"ERC20":      "8797bbcc247e8350c9085fdbde5c3c62c10769082ecdad85fa248594660fbfde",
+	"Factory":    "7c6cf0abdba5227293a7609afc4fff26063257191123b1d178588548d91db7d1",
+	"Multicall3": "617db5aca38a010f84e6c7d3045aae137361b979e25b7f1de978f931e97a9773",
+	"Vault":      "7a29d49a2f40ac23bf3d4cc4bfd87c705ee2a28cd50960c01f1b9aa54563cb73",
+}
@@ -0,0 +1,12 @@
+// Package gen holds abigen bindings for the ATFi contracts, generated from the ABIs
+// checked in under contracts/abi. Do not edit the bindings by hand: change the ABI, then
+// run
+//
+//	go generate ./contracts/gen
+//
+// and commit the result. TestBindingsAreFresh fails while an ABI and its binding
+// disagree. Handlers never use these types directly; the contracts package wraps them so
+// callers can be given fakes.
+package gen
+
+//go:generate go run ./internal/bindgen -abi ../abi -out .
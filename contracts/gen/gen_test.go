@@ -0,0 +1,40 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBindingsAreFresh fails when an ABI in contracts/abi was added, removed or changed
+// without regenerating the bindings
+func TestBindingsAreFresh(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "abi", "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list ABIs: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		seen[name] = true
+
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); ABIHashes[name] != got {
+			t.Errorf("%s binding is stale, run go generate ./contracts/gen", name)
+		}
+	}
+
+	for name := range ABIHashes {
+		if !seen[name] {
+			t.Errorf("%s binding has no ABI any more, run go generate ./contracts/gen", name)
+		}
+	}
+}
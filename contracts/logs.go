@@ -0,0 +1,76 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultLogChunkSize keeps each eth_getLogs request under the 10k-block limit many providers enforce
+const DefaultLogChunkSize uint64 = 9999
+
+// FilterLogsChunked runs query over [fromBlock, toBlock] in windows of at most chunkSize blocks
+func FilterLogsChunked(ctx context.Context, client ethereum.LogFilterer, query ethereum.FilterQuery, fromBlock, toBlock, chunkSize uint64) ([]types.Log, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultLogChunkSize
+	}
+
+	var logs []types.Log
+	for start := fromBlock; start <= toBlock; start += chunkSize + 1 {
+		end := start + chunkSize
+		if end > toBlock {
+			end = toBlock
+		}
+
+		query.FromBlock = new(big.Int).SetUint64(start)
+		query.ToBlock = new(big.Int).SetUint64(end)
+
+		chunk, err := Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) ([]types.Log, error) {
+			return client.FilterLogs(ctx, query)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter logs for blocks %d-%d: %w", start, end, err)
+		}
+		logs = append(logs, chunk...)
+	}
+
+	return logs, nil
+}
+
+// FindDeployBlock binary-searches for the first block at which address has contract code.
+// Requires a node that serves historical state.
+func FindDeployBlock(ctx context.Context, client Caller, address common.Address) (uint64, error) {
+	caller := retryingCaller{client}
+	latest, err := Retry(ctx, DefaultRetryPolicy, client.BlockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	code, err := caller.CodeAt(ctx, address, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get code at latest block: %w", err)
+	}
+	if len(code) == 0 {
+		return 0, fmt.Errorf("no contract deployed at %s", address.Hex())
+	}
+
+	low, high := uint64(0), latest
+	for low < high {
+		mid := low + (high-low)/2
+		code, err := caller.CodeAt(ctx, address, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, fmt.Errorf("failed to get code at block %d: %w", mid, err)
+		}
+		if len(code) > 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	return low, nil
+}
@@ -0,0 +1,310 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts/gen"
+)
+
+// Multicall3Address is where Multicall3 is deployed on Base, Base Sepolia and most other
+// EVM chains
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// DefaultMaxCalldata keeps each aggregate3 request well under common RPC body limits
+const DefaultMaxCalldata = 64 * 1024
+
+// DefaultMaxCalls caps the calls in one aggregate3 request, so a request also stays within
+// the gas providers allow an eth_call
+const DefaultMaxCalls = 500
+
+var (
+	multicallABI = mustParseABI(gen.Multicall3MetaData)
+	vaultABI     = mustParseABI(gen.VaultMetaData)
+)
+
+// Call is one view call to batch through Multicall3
+type Call struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// CallResult is the raw return data of one batched call, or the reason it failed
+type CallResult struct {
+	ReturnData []byte
+	Err        error
+}
+
+// Multicaller batches view calls into Multicall3 aggregate3 requests. Every call is sent
+// with allowFailure set, so one reverting target does not fail the others.
+type Multicaller struct {
+	client  bind.ContractCaller
+	address common.Address
+
+	// MaxCalldata caps the size of a single aggregate3 request; larger batches are split
+	MaxCalldata int
+	// MaxCalls caps the number of calls in a single aggregate3 request
+	MaxCalls int
+}
+
+// NewMulticaller returns a Multicaller for the canonical Multicall3 deployment
+func NewMulticaller(client bind.ContractCaller) *Multicaller {
+	return &Multicaller{
+		client:      client,
+		address:     common.HexToAddress(Multicall3Address),
+		MaxCalldata: DefaultMaxCalldata,
+		MaxCalls:    DefaultMaxCalls,
+	}
+}
+
+// Aggregate runs calls through Multicall3, one RPC request per chunk. Results line up with
+// calls. A reverted call is reported in its own result; the returned error is only set
+// when a whole request fails.
+func (m *Multicaller) Aggregate(ctx context.Context, calls []Call) ([]CallResult, error) {
+	results := make([]CallResult, 0, len(calls))
+	for _, chunk := range chunkCalls(calls, m.MaxCalldata, m.MaxCalls) {
+		chunkResults, err := m.aggregate(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunkResults...)
+	}
+	return results, nil
+}
+
+func (m *Multicaller) aggregate(ctx context.Context, calls []Call) ([]CallResult, error) {
+	callData, err := packAggregate3(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	err = withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		out, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) ([]byte, error) {
+			return m.client.CallContract(ctx, ethereum.CallMsg{To: &m.address, Data: callData}, nil)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call aggregate3: %w", err)
+	}
+
+	returned, err := unpackAggregate3(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(returned) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 returned %d results for %d calls", len(returned), len(calls))
+	}
+
+	results := make([]CallResult, len(returned))
+	for i, result := range returned {
+		if !result.Success {
+			results[i].Err = errors.New("call reverted")
+			continue
+		}
+		results[i].ReturnData = result.ReturnData
+	}
+	return results, nil
+}
+
+// packAggregate3 encodes an aggregate3 call that lets every call fail on its own
+func packAggregate3(calls []Call) ([]byte, error) {
+	call3s := make([]gen.Multicall3Call3, len(calls))
+	for i, call := range calls {
+		call3s[i] = gen.Multicall3Call3{Target: call.Target, AllowFailure: true, CallData: call.CallData}
+	}
+
+	callData, err := multicallABI.Pack("aggregate3", call3s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack aggregate3 call data: %w", err)
+	}
+	return callData, nil
+}
+
+// unpackAggregate3 decodes aggregate3's (bool success, bytes returnData)[] result
+func unpackAggregate3(out []byte) ([]gen.Multicall3Result, error) {
+	values, err := multicallABI.Unpack("aggregate3", out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack aggregate3 result: %w", err)
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("aggregate3 returned %d values", len(values))
+	}
+	return *abi.ConvertType(values[0], new([]gen.Multicall3Result)).(*[]gen.Multicall3Result), nil
+}
+
+// chunkCalls splits calls so each chunk's encoded aggregate3 calldata stays within
+// maxCalldata and it holds at most maxCalls calls; zero means no limit. A call that is too
+// large on its own still gets a chunk of its own.
+func chunkCalls(calls []Call, maxCalldata, maxCalls int) [][]Call {
+	// selector, array offset and array length
+	const header = 4 + 32 + 32
+	var chunks [][]Call
+	start, size := 0, header
+	for i, call := range calls {
+		// tuple offset, target, allowFailure, bytes offset, bytes length, padded bytes
+		callSize := 5*32 + (len(call.CallData)+31)/32*32
+		tooLarge := maxCalldata > 0 && size+callSize > maxCalldata
+		tooMany := maxCalls > 0 && i-start >= maxCalls
+		if i > start && (tooLarge || tooMany) {
+			chunks = append(chunks, calls[start:i])
+			start, size = i, header
+		}
+		size += callSize
+	}
+	if start < len(calls) {
+		chunks = append(chunks, calls[start:])
+	}
+	return chunks
+}
+
+// ParticipantCount is one vault's on-chain participant count, or the reason it could not
+// be read
+type ParticipantCount struct {
+	Vault common.Address
+	Count *big.Int
+	Err   error
+}
+
+// ParticipantCounts reads getParticipantCount from every vault through Multicall3. Failed
+// vaults are reported individually; the returned error is only set when a whole request
+// fails.
+func (m *Multicaller) ParticipantCounts(ctx context.Context, vaults []common.Address) ([]ParticipantCount, error) {
+	callData, err := vaultABI.Pack("getParticipantCount")
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack getParticipantCount call data: %w", err)
+	}
+
+	calls := make([]Call, len(vaults))
+	for i, vault := range vaults {
+		calls[i] = Call{Target: vault, CallData: callData}
+	}
+
+	results, err := m.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]ParticipantCount, len(vaults))
+	for i, result := range results {
+		counts[i].Vault = vaults[i]
+		if result.Err != nil {
+			counts[i].Err = result.Err
+			continue
+		}
+		values, err := vaultABI.Unpack("getParticipantCount", result.ReturnData)
+		if err != nil {
+			counts[i].Err = fmt.Errorf("failed to unpack getParticipantCount: %w", err)
+			continue
+		}
+		counts[i].Count = values[0].(*big.Int)
+	}
+	return counts, nil
+}
+
+// ClaimableAmount is what a wallet can claim from one vault, or the reason it could not be
+// read
+type ClaimableAmount struct {
+	Vault  common.Address
+	Amount *big.Int
+	Err    error
+}
+
+// ClaimableAmounts reads claimableAmount(wallet) from every vault through Multicall3.
+// Failed vaults are reported individually; the returned error is only set when a whole
+// request fails.
+func (m *Multicaller) ClaimableAmounts(ctx context.Context, wallet common.Address, vaults []common.Address) ([]ClaimableAmount, error) {
+	callData, err := vaultABI.Pack("claimableAmount", wallet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack claimableAmount call data: %w", err)
+	}
+
+	calls := make([]Call, len(vaults))
+	for i, vault := range vaults {
+		calls[i] = Call{Target: vault, CallData: callData}
+	}
+
+	results, err := m.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make([]ClaimableAmount, len(vaults))
+	for i, result := range results {
+		amounts[i].Vault = vaults[i]
+		if result.Err != nil {
+			amounts[i].Err = result.Err
+			continue
+		}
+		values, err := vaultABI.Unpack("claimableAmount", result.ReturnData)
+		if err != nil {
+			amounts[i].Err = fmt.Errorf("failed to unpack claimableAmount: %w", err)
+			continue
+		}
+		amounts[i].Amount = values[0].(*big.Int)
+	}
+	return amounts, nil
+}
+
+// WalletBalance is one wallet's balance of a token, or the reason it could not be read
+type WalletBalance struct {
+	Wallet  common.Address
+	Balance *big.Int
+	Err     error
+}
+
+// BatchBalances reads token's balanceOf for every wallet through Multicall3. Chunks are
+// sent one after the other rather than all at once, so a long list does not burst past
+// the provider's rate limit, and a chunk that fails only fails its own wallets. The
+// returned error is only set when every chunk failed.
+func (m *Multicaller) BatchBalances(ctx context.Context, token common.Address, wallets []common.Address) ([]WalletBalance, error) {
+	balances := make([]WalletBalance, len(wallets))
+	calls := make([]Call, len(wallets))
+	for i, wallet := range wallets {
+		balances[i].Wallet = wallet
+		callData, err := erc20ABI.Pack("balanceOf", wallet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack balanceOf call data: %w", err)
+		}
+		calls[i] = Call{Target: token, CallData: callData}
+	}
+
+	offset, failed := 0, 0
+	var lastErr error
+	chunks := chunkCalls(calls, m.MaxCalldata, m.MaxCalls)
+	for _, chunk := range chunks {
+		results, err := m.aggregate(ctx, chunk)
+		for j := range chunk {
+			balance := &balances[offset+j]
+			switch {
+			case err != nil:
+				balance.Err = err
+			case results[j].Err != nil:
+				balance.Err = fmt.Errorf("balanceOf failed: %w", results[j].Err)
+			case len(results[j].ReturnData) == 0:
+				balance.Err = errors.New("no ERC-20 contract at address")
+			default:
+				if unpackErr := erc20ABI.UnpackIntoInterface(&balance.Balance, "balanceOf", results[j].ReturnData); unpackErr != nil {
+					balance.Err = fmt.Errorf("failed to unpack balanceOf result: %w", unpackErr)
+				}
+			}
+		}
+		if err != nil {
+			failed++
+			lastErr = err
+		}
+		offset += len(chunk)
+	}
+	if len(chunks) > 0 && failed == len(chunks) {
+		return nil, lastErr
+	}
+	return balances, nil
+}
@@ -0,0 +1,189 @@
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"atfi-backend/contracts/gen"
+)
+
+// readFixture loads a hex-encoded payload from testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	decoded, err := hexutil.Decode(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("Failed to decode fixture %s: %v", name, err)
+	}
+	return decoded
+}
+
+func TestParticipantCountsMatchesRecordedPayload(t *testing.T) {
+	caller := &fakeCaller{result: readFixture(t, "aggregate3_participant_counts.response.hex")}
+	vaults := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+	}
+
+	counts, err := NewMulticaller(caller).ParticipantCounts(context.Background(), vaults)
+	if err != nil {
+		t.Fatalf("ParticipantCounts: %v", err)
+	}
+
+	if *caller.call.To != common.HexToAddress(Multicall3Address) {
+		t.Errorf("called %s, want Multicall3", caller.call.To.Hex())
+	}
+	if want := readFixture(t, "aggregate3_participant_counts.request.hex"); !bytes.Equal(caller.call.Data, want) {
+		t.Errorf("call data = %x, want %x", caller.call.Data, want)
+	}
+
+	if len(counts) != 2 {
+		t.Fatalf("got %d counts, want 2", len(counts))
+	}
+	if counts[0].Err != nil || counts[0].Count.Int64() != 3 || counts[0].Vault != vaults[0] {
+		t.Errorf("first vault = %+v, want 3 participants", counts[0])
+	}
+	// The reverted vault fails on its own without poisoning the batch
+	if counts[1].Err == nil || counts[1].Count != nil {
+		t.Errorf("second vault = %+v, want an error", counts[1])
+	}
+}
+
+// echoCaller answers every aggregate3 request with one successful uint256 per call, the
+// call's index in the request. Requests numbered in fail, counting from 1, fail instead.
+type echoCaller struct {
+	fakeCaller
+	requests int
+	fail     map[int]error
+}
+
+func (e *echoCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	e.requests++
+	if err := e.fail[e.requests]; err != nil {
+		return nil, err
+	}
+	method := multicallABI.Methods["aggregate3"]
+	values, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := *abi.ConvertType(values[0], new([]gen.Multicall3Call3)).(*[]gen.Multicall3Call3)
+
+	results := make([]gen.Multicall3Result, len(calls))
+	for i := range calls {
+		results[i] = gen.Multicall3Result{Success: true, ReturnData: word(int64(i))}
+	}
+	return method.Outputs.Pack(results)
+}
+
+func TestAggregateChunksLargeBatches(t *testing.T) {
+	calls := make([]Call, 10)
+	for i := range calls {
+		calls[i] = Call{Target: common.BigToAddress(big.NewInt(int64(i + 1))), CallData: []byte{0xad, 0x60, 0x57, 0x29}}
+	}
+
+	caller := &echoCaller{}
+	multicaller := NewMulticaller(caller)
+	// Room for the header plus three calls of 6 words each
+	multicaller.MaxCalldata = 4 + 2*32 + 3*6*32
+
+	results, err := multicaller.Aggregate(context.Background(), calls)
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if caller.requests != 4 {
+		t.Errorf("sent %d requests, want 4", caller.requests)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("got %d results, want %d", len(results), len(calls))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("result %d failed: %v", i, result.Err)
+		}
+	}
+}
+
+func TestChunkCallsKeepsOversizedCallAlone(t *testing.T) {
+	calls := []Call{{CallData: make([]byte, 1024)}, {CallData: make([]byte, 4)}}
+	chunks := chunkCalls(calls, 256, 0)
+	if len(chunks) != 2 || len(chunks[0]) != 1 || len(chunks[1]) != 1 {
+		t.Errorf("got %d chunks, want each call on its own", len(chunks))
+	}
+}
+
+func TestBatchBalancesChunksAtMaxCalls(t *testing.T) {
+	token := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	const maxCalls = 3
+
+	// Exactly one chunk's worth, one over, exactly two and one over two
+	for wallets, wantRequests := range map[int]int{1: 1, maxCalls: 1, maxCalls + 1: 2, 2 * maxCalls: 2, 2*maxCalls + 1: 3} {
+		addresses := make([]common.Address, wallets)
+		for i := range addresses {
+			addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+		}
+
+		caller := &echoCaller{}
+		multicaller := NewMulticaller(caller)
+		multicaller.MaxCalls = maxCalls
+		balances, err := multicaller.BatchBalances(context.Background(), token, addresses)
+		if err != nil {
+			t.Fatalf("BatchBalances for %d wallets: %v", wallets, err)
+		}
+		if caller.requests != wantRequests {
+			t.Errorf("%d wallets: sent %d requests, want %d", wallets, caller.requests, wantRequests)
+		}
+		if len(balances) != wallets {
+			t.Fatalf("%d wallets: got %d balances", wallets, len(balances))
+		}
+		for i, balance := range balances {
+			// echoCaller answers with the call's index within its chunk
+			if balance.Err != nil || balance.Wallet != addresses[i] || balance.Balance.Int64() != int64(i%maxCalls) {
+				t.Errorf("%d wallets: balance %d = %+v, want %d", wallets, i, balance, i%maxCalls)
+			}
+		}
+	}
+}
+
+func TestBatchBalancesIsolatesFailedChunks(t *testing.T) {
+	token := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	addresses := make([]common.Address, 7)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	caller := &echoCaller{fail: map[int]error{2: errors.New("401 Unauthorized")}}
+	multicaller := NewMulticaller(caller)
+	multicaller.MaxCalls = 3
+	balances, err := multicaller.BatchBalances(context.Background(), token, addresses)
+	if err != nil {
+		t.Fatalf("BatchBalances: %v", err)
+	}
+	for i, balance := range balances {
+		inFailedChunk := i >= 3 && i < 6
+		if (balance.Err != nil) != inFailedChunk {
+			t.Errorf("balance %d = %+v, want failed %t", i, balance, inFailedChunk)
+		}
+	}
+
+	// Nothing read at all is an error
+	caller = &echoCaller{fail: map[int]error{1: errors.New("401 Unauthorized"), 2: errors.New("401 Unauthorized")}}
+	multicaller = NewMulticaller(caller)
+	multicaller.MaxCalls = 5
+	if _, err := multicaller.BatchBalances(context.Background(), token, addresses); err == nil {
+		t.Error("BatchBalances succeeded with every chunk failing")
+	}
+}
@@ -0,0 +1,256 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Name cache tuning: how long a resolved (or missing) name is trusted, how long a single
+// lookup may take and how many background refreshes may queue up
+const (
+	nameCacheTTL       = 6 * time.Hour
+	nameLookupTimeout  = 2 * time.Second
+	nameRefreshBacklog = 256
+)
+
+const nameRegistryABI = `[
+	{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"name","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"addr","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+type nameEntry struct {
+	name       string // empty when the wallet has no primary name
+	resolvedAt time.Time
+}
+
+// NameResolver reverse-resolves wallets to their ENS or Basename primary name. Results,
+// including misses, are cached in process and refreshed in the background once stale.
+type NameResolver struct {
+	client        Caller
+	registry      common.Address
+	reverseSuffix string
+	abi           abi.ABI
+
+	mu      sync.RWMutex
+	cache   map[common.Address]nameEntry
+	pending map[common.Address]bool
+	refresh chan common.Address
+}
+
+// NewNameResolver creates a resolver using the registry at registry. reverseSuffix is the
+// reverse namespace, "addr.reverse" on Ethereum or "<coinType hex>.reverse" on L2s.
+func NewNameResolver(client Caller, registry common.Address, reverseSuffix string) (*NameResolver, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(nameRegistryABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse name registry ABI: %w", err)
+	}
+
+	return &NameResolver{
+		client:        client,
+		registry:      registry,
+		reverseSuffix: reverseSuffix,
+		abi:           parsedABI,
+		cache:         map[common.Address]nameEntry{},
+		pending:       map[common.Address]bool{},
+		refresh:       make(chan common.Address, nameRefreshBacklog),
+	}, nil
+}
+
+// NewNameResolverFromEnv picks the registry of the RPC node's Chain preset (ENS on
+// Ethereum, Basenames on Base), or ENS_REGISTRY_ADDRESS when set. It returns nil when the chain has no known registry.
+func NewNameResolverFromEnv(ctx context.Context, client Caller) (*NameResolver, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	registry := strings.TrimSpace(os.Getenv("ENS_REGISTRY_ADDRESS"))
+	if registry == "" {
+		chain, _ := LookupChain(chainID.Int64())
+		registry = chain.NameRegistry
+	}
+	if registry == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(registry) {
+		return nil, fmt.Errorf("invalid ENS_REGISTRY_ADDRESS: %s", registry)
+	}
+
+	return NewNameResolver(client, common.HexToAddress(registry), reverseSuffix(chainID))
+}
+
+// reverseSuffix is the ENSIP-11 reverse namespace for a chain
+func reverseSuffix(chainID *big.Int) string {
+	switch chainID.Int64() {
+	case 1, 11155111:
+		return "addr.reverse"
+	}
+	coinType := new(big.Int).Or(big.NewInt(0x80000000), chainID)
+	return fmt.Sprintf("%x.reverse", coinType)
+}
+
+// Namehash implements the ENS namehash of a dot-separated name
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// Cached returns the cached name for wallet without blocking. Missing or stale entries are
+// queued for a background refresh, and the stale name, if any, is returned meanwhile.
+func (r *NameResolver) Cached(wallet common.Address) string {
+	r.mu.RLock()
+	entry, ok := r.cache[wallet]
+	r.mu.RUnlock()
+
+	if !ok || time.Since(entry.resolvedAt) > nameCacheTTL {
+		r.queueRefresh(wallet)
+	}
+	return entry.name
+}
+
+// Name returns wallet's primary name, resolving it now when the cache has nothing fresh.
+// Failures and timeouts return the stale name, or "" when there is none.
+func (r *NameResolver) Name(ctx context.Context, wallet common.Address) string {
+	r.mu.RLock()
+	entry, ok := r.cache[wallet]
+	r.mu.RUnlock()
+	if ok && time.Since(entry.resolvedAt) <= nameCacheTTL {
+		return entry.name
+	}
+
+	name, err := r.Refresh(ctx, wallet)
+	if err != nil {
+		slog.WarnContext(ctx, "Name lookup failed", "wallet", wallet.Hex(), "error", err)
+		return entry.name
+	}
+	return name
+}
+
+// Refresh resolves wallet now, bypassing the cache, and caches the result
+func (r *NameResolver) Refresh(ctx context.Context, wallet common.Address) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, nameLookupTimeout)
+	defer cancel()
+
+	name, err := r.lookup(ctx, wallet)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.cache[wallet] = nameEntry{name: name, resolvedAt: time.Now()}
+	r.mu.Unlock()
+
+	return name, nil
+}
+
+// Run resolves queued wallets until ctx is cancelled
+func (r *NameResolver) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case wallet := <-r.refresh:
+			if _, err := r.Refresh(ctx, wallet); err != nil {
+				slog.WarnContext(ctx, "Background name refresh failed", "wallet", wallet.Hex(), "error", err)
+			}
+			r.mu.Lock()
+			delete(r.pending, wallet)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// queueRefresh schedules a background lookup unless one is already pending. When the
+// backlog is full the request is dropped; the next access queues it again.
+func (r *NameResolver) queueRefresh(wallet common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending[wallet] {
+		return
+	}
+	select {
+	case r.refresh <- wallet:
+		r.pending[wallet] = true
+	default:
+	}
+}
+
+// lookup reverse-resolves wallet and confirms the name resolves forward to the same
+// wallet, so nobody can claim a name that points elsewhere. No primary name yields "".
+func (r *NameResolver) lookup(ctx context.Context, wallet common.Address) (string, error) {
+	reverseNode := Namehash(strings.ToLower(wallet.Hex()[2:]) + "." + r.reverseSuffix)
+	resolver, err := r.resolverFor(ctx, reverseNode)
+	if err != nil || resolver == (common.Address{}) {
+		return "", err
+	}
+
+	var name string
+	if err := r.call(ctx, resolver, "name", reverseNode, &name); err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	forwardNode := Namehash(name)
+	forwardResolver, err := r.resolverFor(ctx, forwardNode)
+	if err != nil || forwardResolver == (common.Address{}) {
+		return "", err
+	}
+	var resolved common.Address
+	if err := r.call(ctx, forwardResolver, "addr", forwardNode, &resolved); err != nil {
+		return "", err
+	}
+	if resolved != wallet {
+		return "", nil
+	}
+
+	return name, nil
+}
+
+func (r *NameResolver) resolverFor(ctx context.Context, node common.Hash) (common.Address, error) {
+	var resolver common.Address
+	err := r.call(ctx, r.registry, "resolver", node, &resolver)
+	return resolver, err
+}
+
+// call invokes a single-argument view function taking a node and unpacks its one result
+func (r *NameResolver) call(ctx context.Context, to common.Address, method string, node common.Hash, out interface{}) error {
+	callData, err := r.abi.Pack(method, node)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s call data: %w", method, err)
+	}
+
+	result, err := r.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: callData}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if len(result) == 0 {
+		// Not a contract, or the resolver does not implement the method
+		return nil
+	}
+
+	if err := r.abi.UnpackIntoInterface(out, method, result); err != nil {
+		return fmt.Errorf("failed to unpack %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNotStuck is returned by NonceManager.Replace for a transaction that is not due for
+// replacement: it was mined, is unknown, or has not been pending long enough
+var ErrNotStuck = errors.New("transaction is not stuck")
+
+// NonceSource is the part of an Ethereum client a NonceManager needs
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// BuildFunc builds and signs a transaction with the given nonce
+type BuildFunc func(ctx context.Context, nonce uint64) (*types.Transaction, error)
+
+// NonceManager hands out the nonces of backend-signed transactions. Sends from one sender
+// are serialized, so concurrent callers get consecutive nonces instead of colliding on
+// the node's pending nonce. The next nonce is tracked locally and read from the node again
+// on first use and after a failed send. Sent transactions are remembered until mined, so a
+// stuck one can be replaced with higher fees.
+type NonceManager struct {
+	client NonceSource
+
+	mu      sync.Mutex
+	senders map[common.Address]*senderNonces
+}
+
+// senderNonces is one sender's state; its mutex is held for the whole of a send
+type senderNonces struct {
+	mu       sync.Mutex
+	next     uint64
+	known    bool
+	inflight map[uint64]inflightTx
+}
+
+type inflightTx struct {
+	tx     *types.Transaction
+	sentAt time.Time
+}
+
+// NewNonceManager returns a manager sending through client
+func NewNonceManager(client NonceSource) *NonceManager {
+	return &NonceManager{client: client, senders: map[common.Address]*senderNonces{}}
+}
+
+func (m *NonceManager) sender(address common.Address) *senderNonces {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.senders[address]
+	if !ok {
+		s = &senderNonces{inflight: map[uint64]inflightTx{}}
+		m.senders[address] = s
+	}
+	return s
+}
+
+// Refresh forgets sender's local nonce, so the next send reads it from the node
+func (m *NonceManager) Refresh(sender common.Address) {
+	s := m.sender(sender)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.known = false
+}
+
+// Next returns the nonce sender's next transaction would be sent with, without using it
+func (m *NonceManager) Next(ctx context.Context, sender common.Address) (uint64, error) {
+	s := m.sender(sender)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return m.nextNonce(ctx, sender, s)
+}
+
+// Send builds a transaction with sender's next nonce and broadcasts it. The nonce is only
+// used up when the node accepts the transaction. When the node rejects the nonce, as after
+// another process sent from the same key, the nonce is read again and the transaction
+// built once more with it.
+func (m *NonceManager) Send(ctx context.Context, sender common.Address, build BuildFunc) (*types.Transaction, error) {
+	s := m.sender(sender)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		nonce, err := m.nextNonce(ctx, sender, s)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := build(ctx, nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		// Sends are not retried on errors: a request that timed out may still have been
+		// accepted. A rejected nonce means it was not.
+		err = withCallTimeout(ctx, func(ctx context.Context) error {
+			return m.client.SendTransaction(ctx, tx)
+		})
+		if err == nil {
+			s.next = nonce + 1
+			s.inflight[nonce] = inflightTx{tx: tx, sentAt: time.Now()}
+			return tx, nil
+		}
+
+		s.known = false
+		if !IsNonceError(err) || attempt >= 2 {
+			return nil, fmt.Errorf("failed to send transaction: %w", err)
+		}
+	}
+}
+
+// Replace re-sends sender's transaction hash with the same nonce once it has been pending
+// for longer than after, as built by rebuild, which should raise its fees. It returns
+// ErrNotStuck when the transaction is not due, including when its nonce was mined.
+func (m *NonceManager) Replace(ctx context.Context, sender common.Address, hash common.Hash, after time.Duration, rebuild func(ctx context.Context, stuck *types.Transaction) (*types.Transaction, error)) (*types.Transaction, error) {
+	s := m.sender(sender)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stuck *inflightTx
+	for _, inflight := range s.inflight {
+		if inflight.tx.Hash() == hash {
+			inflight := inflight
+			stuck = &inflight
+			break
+		}
+	}
+	if stuck == nil || time.Since(stuck.sentAt) < after {
+		return nil, ErrNotStuck
+	}
+
+	if err := m.forgetMined(ctx, sender, s); err != nil {
+		return nil, err
+	}
+	if _, ok := s.inflight[stuck.tx.Nonce()]; !ok {
+		return nil, ErrNotStuck
+	}
+
+	tx, err := rebuild(ctx, stuck.tx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Nonce() != stuck.tx.Nonce() {
+		return nil, fmt.Errorf("replacement has nonce %d, want %d", tx.Nonce(), stuck.tx.Nonce())
+	}
+	err = withCallTimeout(ctx, func(ctx context.Context) error {
+		return m.client.SendTransaction(ctx, tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send replacement transaction: %w", err)
+	}
+	s.inflight[tx.Nonce()] = inflightTx{tx: tx, sentAt: time.Now()}
+	return tx, nil
+}
+
+// Forget stops tracking sender's transaction hash for replacement, once it was mined or
+// given up on
+func (m *NonceManager) Forget(sender common.Address, hash common.Hash) {
+	s := m.sender(sender)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nonce, inflight := range s.inflight {
+		if inflight.tx.Hash() == hash {
+			delete(s.inflight, nonce)
+		}
+	}
+}
+
+// nextNonce returns the nonce of the next transaction, asking the node when it is not
+// known. s.mu must be held.
+func (m *NonceManager) nextNonce(ctx context.Context, sender common.Address, s *senderNonces) (uint64, error) {
+	if s.known {
+		return s.next, nil
+	}
+
+	var nonce uint64
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		nonce, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (uint64, error) {
+			return m.client.PendingNonceAt(ctx, sender)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+
+	s.next = nonce
+	s.known = true
+	return nonce, nil
+}
+
+// forgetMined drops the in-flight transactions whose nonce the latest block has used.
+// s.mu must be held.
+func (m *NonceManager) forgetMined(ctx context.Context, sender common.Address, s *senderNonces) error {
+	var mined uint64
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		mined, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (uint64, error) {
+			return m.client.NonceAt(ctx, sender, nil)
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	for nonce := range s.inflight {
+		if nonce < mined {
+			delete(s.inflight, nonce)
+		}
+	}
+	return nil
+}
+
+// IsNonceError reports whether a node rejected a transaction for its nonce
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "nonce too low") || strings.Contains(message, "nonce too high")
+}
@@ -0,0 +1,131 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+// newSigner returns a fresh sender and a BuildFunc signing an empty call to vault from it
+// with whatever nonce it is given
+func newSigner(t *testing.T) (common.Address, contracts.BuildFunc) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(84532))
+	build := func(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+		return types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(84532),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21_000,
+			To:        &vault,
+		}), signer, key)
+	}
+	return crypto.PubkeyToAddress(key.PublicKey), build
+}
+
+func TestNonceManagerSerializesConcurrentSends(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	manager := contracts.NewNonceManager(client)
+	sender, build := newSigner(t)
+	client.SetNonce(sender, 3)
+
+	const senders = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var nonces []int
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := manager.Send(context.Background(), sender, build)
+			if err != nil {
+				t.Errorf("Send: %v", err)
+				return
+			}
+			mu.Lock()
+			nonces = append(nonces, int(tx.Nonce()))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(nonces)
+	for i, nonce := range nonces {
+		if nonce != 3+i {
+			t.Fatalf("nonces = %v, want %d consecutive nonces from 3", nonces, senders)
+		}
+	}
+	if sent := client.Sent(); len(sent) != senders {
+		t.Errorf("node accepted %d transactions, want %d", len(sent), senders)
+	}
+}
+
+func TestNonceManagerRecoversFromNonceTooLow(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	manager := contracts.NewNonceManager(client)
+	sender, build := newSigner(t)
+
+	if tx, err := manager.Send(context.Background(), sender, build); err != nil || tx.Nonce() != 0 {
+		t.Fatalf("first Send: %v, %v; want nonce 0", tx, err)
+	}
+
+	// Another process used nonces 1 to 4 from the same key
+	client.SetNonce(sender, 5)
+	tx, err := manager.Send(context.Background(), sender, build)
+	if err != nil {
+		t.Fatalf("Send after nonce too low: %v", err)
+	}
+	if tx.Nonce() != 5 {
+		t.Errorf("nonce = %d, want 5 read from the node", tx.Nonce())
+	}
+	if tx, err := manager.Send(context.Background(), sender, build); err != nil || tx.Nonce() != 6 {
+		t.Errorf("next Send: %v, %v; want nonce 6", tx, err)
+	}
+}
+
+func TestNonceManagerRefreshesAfterFailedSends(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	manager := contracts.NewNonceManager(client)
+	sender, build := newSigner(t)
+	client.SetNonce(sender, 2)
+
+	failing := func(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+		return nil, errors.New("could not build")
+	}
+	if _, err := manager.Send(context.Background(), sender, failing); err == nil {
+		t.Fatal("Send with a failing build succeeded")
+	}
+
+	// A failed build does not use the nonce up
+	if next, err := manager.Next(context.Background(), sender); err != nil || next != 2 {
+		t.Errorf("Next = %d, %v; want 2", next, err)
+	}
+
+	client.Down(errors.New("401 Unauthorized"))
+	if _, err := manager.Send(context.Background(), sender, build); err == nil {
+		t.Fatal("Send with the node down succeeded")
+	}
+	client.Down(nil)
+
+	// The node moved on while it was unreachable; the nonce is read again
+	client.SetNonce(sender, 4)
+	tx, err := manager.Send(context.Background(), sender, build)
+	if err != nil || tx.Nonce() != 4 {
+		t.Errorf("Send after the outage: %v, %v; want nonce 4", tx, err)
+	}
+}
@@ -0,0 +1,194 @@
+package contracts
+
+import (
+	"container/list"
+	"context"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Defaults for a ReadCache
+const (
+	DefaultReadCacheSize     = 1024
+	DefaultReadCacheBlockTTL = time.Second
+)
+
+// ReadCacheStats reports how well a ReadCache is doing
+type ReadCacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+	Block   uint64 `json:"block"`
+}
+
+type readKey struct {
+	to    common.Address
+	data  string
+	block uint64
+}
+
+type readEntry struct {
+	key readKey
+	out []byte
+}
+
+// ReadCache is a Caller that serves repeated view calls for the same block from memory.
+// Calls at the latest block are pinned to the latest block number, fetched at most once
+// per BlockTTL, so entries stop matching as soon as the chain advances. The least recently
+// used entries are evicted beyond Size. Failed calls and everything but eth_call pass
+// straight through.
+type ReadCache struct {
+	Caller
+
+	// Size caps the number of cached results
+	Size int
+	// BlockTTL is how long a fetched latest block number is trusted
+	BlockTTL time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
+	blockMu   sync.Mutex
+	block     uint64
+	blockTime time.Time
+
+	mu      sync.Mutex
+	entries map[readKey]*list.Element
+	order   *list.List // most recently used first
+}
+
+// NewReadCache wraps client in a ReadCache with the default size and block TTL
+func NewReadCache(client Caller) *ReadCache {
+	return &ReadCache{
+		Caller:   client,
+		Size:     DefaultReadCacheSize,
+		BlockTTL: DefaultReadCacheBlockTTL,
+		entries:  map[readKey]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// NewReadCacheFromEnv wraps client in a ReadCache holding CONTRACT_READ_CACHE_SIZE results,
+// DefaultReadCacheSize when unset. It returns nil when the size is 0, so reads bypass the
+// cache.
+func NewReadCacheFromEnv(client Caller) *ReadCache {
+	cache := NewReadCache(client)
+	if raw := os.Getenv("CONTRACT_READ_CACHE_SIZE"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 0 {
+			size = DefaultReadCacheSize
+		}
+		if size == 0 {
+			return nil
+		}
+		cache.Size = size
+	}
+	return cache
+}
+
+// Unwrap returns the client the cache reads through
+func (c *ReadCache) Unwrap() Caller {
+	return c.Caller
+}
+
+// CallContract implements bind.ContractCaller
+func (c *ReadCache) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if call.To == nil {
+		return c.Caller.CallContract(ctx, call, blockNumber)
+	}
+
+	var block uint64
+	if blockNumber != nil {
+		block = blockNumber.Uint64()
+	} else {
+		latest, err := c.latestBlock(ctx)
+		if err != nil {
+			// Without a block to pin to the call cannot be cached
+			return c.Caller.CallContract(ctx, call, nil)
+		}
+		block = latest
+	}
+
+	key := readKey{to: *call.To, data: string(call.Data), block: block}
+	if out, ok := c.get(key); ok {
+		c.hits.Add(1)
+		return out, nil
+	}
+	c.misses.Add(1)
+
+	out, err := c.Caller.CallContract(ctx, call, new(big.Int).SetUint64(block))
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, out)
+	return out, nil
+}
+
+// latestBlock returns the latest block number, asking the node at most once per BlockTTL
+func (c *ReadCache) latestBlock(ctx context.Context) (uint64, error) {
+	c.blockMu.Lock()
+	defer c.blockMu.Unlock()
+
+	if !c.blockTime.IsZero() && time.Since(c.blockTime) < c.BlockTTL {
+		return c.block, nil
+	}
+	latest, err := c.Caller.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.block, c.blockTime = latest, time.Now()
+	return latest, nil
+}
+
+func (c *ReadCache) get(key readKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*readEntry).out, true
+}
+
+func (c *ReadCache) put(key readKey, out []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&readEntry{key: key, out: out})
+	for c.order.Len() > c.Size && c.Size > 0 {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*readEntry).key)
+	}
+}
+
+// Stats reports the cache's hits, misses and size, and the block it last pinned to
+func (c *ReadCache) Stats() ReadCacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	c.blockMu.Lock()
+	block := c.block
+	c.blockMu.Unlock()
+
+	return ReadCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+		Block:   block,
+	}
+}
@@ -0,0 +1,103 @@
+package contracts_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+// readCount reads vault's participant count through client
+func readCount(t *testing.T, client contracts.Caller, vault common.Address) int64 {
+	t.Helper()
+	vc, err := contracts.NewVaultContract(client, vault.Hex())
+	if err != nil {
+		t.Fatalf("NewVaultContract: %v", err)
+	}
+	count, err := vc.GetParticipantCount(context.Background())
+	if err != nil {
+		t.Fatalf("GetParticipantCount: %v", err)
+	}
+	return count.Int64()
+}
+
+func TestReadCacheServesRepeatedReadsWithinABlock(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+	client.SetParticipantCount(vault, 3)
+
+	cache := contracts.NewReadCache(client)
+	cache.BlockTTL = time.Hour
+	readCount(t, cache, vault)
+	if got := readCount(t, cache, vault); got != 3 {
+		t.Errorf("cached count = %d, want 3", got)
+	}
+	if calls := len(client.Calls()); calls != 1 {
+		t.Errorf("got %d eth_calls, want 1", calls)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.Block != 100 {
+		t.Errorf("stats = %+v, want one hit and one miss at block 100", stats)
+	}
+
+	// Once the block number is refreshed and has moved on, the entry no longer matches
+	cache.BlockTTL = 0
+	client.SetBlockNumber(101)
+	client.SetParticipantCount(vault, 4)
+	if got := readCount(t, cache, vault); got != 4 {
+		t.Errorf("count at the next block = %d, want 4", got)
+	}
+}
+
+func TestReadCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBlockNumber(100)
+	vaults := []common.Address{vault, otherVault, participant}
+	for _, v := range vaults {
+		client.SetParticipantCount(v, 1)
+	}
+
+	cache := contracts.NewReadCache(client)
+	cache.Size = 2
+	cache.BlockTTL = time.Hour
+	for _, v := range vaults {
+		readCount(t, cache, v)
+	}
+	readCount(t, cache, participant)
+	readCount(t, cache, vault)
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 4 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want the first vault evicted", stats)
+	}
+}
+
+func TestReadCacheDoesNotCacheFailures(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	cache := contracts.NewReadCache(client)
+	cache.BlockTTL = time.Hour
+
+	vc, _ := contracts.NewVaultContract(cache, vault.Hex())
+	if _, err := vc.GetParticipantCount(context.Background()); err == nil {
+		t.Fatal("expected the unprogrammed call to revert")
+	}
+	client.SetParticipantCount(vault, 2)
+	if got := readCount(t, cache, vault); got != 2 {
+		t.Errorf("count after a failed read = %d, want 2", got)
+	}
+}
+
+func TestNewReadCacheFromEnv(t *testing.T) {
+	client := contractstest.NewClient(84532)
+
+	t.Setenv("CONTRACT_READ_CACHE_SIZE", "0")
+	if contracts.NewReadCacheFromEnv(client) != nil {
+		t.Error("a size of 0 should disable the cache")
+	}
+	t.Setenv("CONTRACT_READ_CACHE_SIZE", "16")
+	if cache := contracts.NewReadCacheFromEnv(client); cache == nil || cache.Size != 16 {
+		t.Errorf("cache = %+v, want size 16", cache)
+	}
+}
@@ -0,0 +1,176 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxReader is the part of *ethclient.Client VerifyTx needs
+type TxReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// TxState is the outcome of verifying a transaction
+type TxState string
+
+const (
+	// TxPending has no receipt yet (unmined, unknown or reorged out), or too few confirmations
+	TxPending TxState = "pending"
+	// TxFailed was mined but reverted
+	TxFailed TxState = "failed"
+	// TxWrongTarget succeeded but called another contract or did not emit the expected log
+	TxWrongTarget TxState = "wrong_target"
+	// TxConfirmed succeeded, matched and has enough confirmations
+	TxConfirmed TxState = "confirmed"
+)
+
+// LogMatch describes a log the transaction must emit. Topics works like
+// ethereum.FilterQuery's: each position lists the accepted values, and an empty position
+// matches anything.
+type LogMatch struct {
+	Address common.Address
+	Topics  [][]common.Hash
+}
+
+// Matches reports whether vLog was emitted by m.Address with matching topics
+func (m LogMatch) Matches(vLog *types.Log) bool {
+	if vLog.Address != m.Address || len(vLog.Topics) < len(m.Topics) {
+		return false
+	}
+	for i, accepted := range m.Topics {
+		if len(accepted) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range accepted {
+			if vLog.Topics[i] == topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyOpts are the checks VerifyTx applies on top of the receipt status
+type VerifyOpts struct {
+	// To is the contract the transaction must call. Leave nil for flows that smart
+	// wallets route through another contract, and match a log instead.
+	To *common.Address
+	// Log, when set, must be emitted by the transaction
+	Log *LogMatch
+	// Confirmations is how many blocks, counting the one holding the transaction, must
+	// exist before it counts as confirmed. Zero and one both mean mined.
+	Confirmations uint64
+}
+
+// TxVerification is the result of VerifyTx. BlockNumber and Confirmations are set once
+// the transaction is mined; Reason explains every state but TxConfirmed.
+type TxVerification struct {
+	State         TxState
+	BlockNumber   uint64
+	Confirmations uint64
+	Reason        string
+	Receipt       *types.Receipt
+}
+
+// ParticipantTopic is the topic of an indexed address argument
+func ParticipantTopic(participant common.Address) common.Hash {
+	return common.BytesToHash(participant.Bytes())
+}
+
+// VerifyTx checks that hash is a successful, sufficiently confirmed transaction matching
+// opts. Transactions that cannot be accepted are described by the returned state; the
+// error is only set when the node could not be asked.
+func VerifyTx(ctx context.Context, client TxReader, hash common.Hash, opts VerifyOpts) (*TxVerification, error) {
+	var receipt *types.Receipt
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		receipt, err = Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (*types.Receipt, error) {
+			return client.TransactionReceipt(ctx, hash)
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return &TxVerification{State: TxPending, Reason: "transaction has no receipt yet"}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch receipt: %w", err)
+	}
+
+	result := &TxVerification{Receipt: receipt}
+	if receipt.BlockNumber != nil {
+		result.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		result.State = TxFailed
+		result.Reason = "transaction reverted"
+		return result, nil
+	}
+
+	if opts.To != nil {
+		var tx *types.Transaction
+		err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+			tx, _, err = client.TransactionByHash(ctx, hash)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				return &TxVerification{State: TxPending, Reason: "transaction has no receipt yet"}, nil
+			}
+			return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+		}
+		if tx.To() == nil || *tx.To() != *opts.To {
+			result.State = TxWrongTarget
+			result.Reason = fmt.Sprintf("transaction does not call %s", opts.To.Hex())
+			return result, nil
+		}
+	}
+
+	if opts.Log != nil && !emits(receipt, *opts.Log) {
+		result.State = TxWrongTarget
+		result.Reason = fmt.Sprintf("transaction emitted no matching log from %s", opts.Log.Address.Hex())
+		return result, nil
+	}
+
+	var latest uint64
+	err = withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		latest, err = Retry(ctx, DefaultRetryPolicy, client.BlockNumber)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if latest >= result.BlockNumber {
+		result.Confirmations = latest - result.BlockNumber + 1
+	}
+
+	if result.Confirmations < opts.Confirmations {
+		result.State = TxPending
+		result.Reason = fmt.Sprintf("transaction has %d of %d confirmations", result.Confirmations, opts.Confirmations)
+		return result, nil
+	}
+
+	result.State = TxConfirmed
+	return result, nil
+}
+
+// emits reports whether any of the receipt's logs matches m
+func emits(receipt *types.Receipt, m LogMatch) bool {
+	for _, vLog := range receipt.Logs {
+		if m.Matches(vLog) {
+			return true
+		}
+	}
+	return false
+}
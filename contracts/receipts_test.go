@@ -0,0 +1,205 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeTxReader serves one receipt and transaction from a chain at head
+type fakeTxReader struct {
+	receipt    *types.Receipt
+	tx         *types.Transaction
+	head       uint64
+	receiptErr error
+}
+
+func (f *fakeTxReader) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if f.receiptErr != nil {
+		return nil, f.receiptErr
+	}
+	if f.receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return f.receipt, nil
+}
+
+func (f *fakeTxReader) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if f.tx == nil {
+		return nil, false, ethereum.NotFound
+	}
+	return f.tx, false, nil
+}
+
+func (f *fakeTxReader) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+func TestVerifyTx(t *testing.T) {
+	vault := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	participant := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	claimLog := &types.Log{Address: vault, Topics: []common.Hash{ClaimedEventTopic, ParticipantTopic(participant)}}
+	receipt := func(status uint64, logs ...*types.Log) *types.Receipt {
+		return &types.Receipt{Status: status, BlockNumber: big.NewInt(100), Logs: logs}
+	}
+	txTo := func(to common.Address) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{To: &to})
+	}
+	logMatch := &LogMatch{Address: vault, Topics: [][]common.Hash{{ClaimedEventTopic}, {ParticipantTopic(participant)}}}
+
+	tests := []struct {
+		name          string
+		reader        *fakeTxReader
+		opts          VerifyOpts
+		want          TxState
+		confirmations uint64
+	}{
+		{
+			name:   "reorged or unknown receipt is pending",
+			reader: &fakeTxReader{head: 105},
+			opts:   VerifyOpts{Confirmations: 3},
+			want:   TxPending,
+		},
+		{
+			name:   "reverted",
+			reader: &fakeTxReader{receipt: receipt(types.ReceiptStatusFailed), tx: txTo(vault), head: 105},
+			opts:   VerifyOpts{To: &vault},
+			want:   TxFailed,
+		},
+		{
+			name:   "calls another contract",
+			reader: &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful, claimLog), tx: txTo(other), head: 105},
+			opts:   VerifyOpts{To: &vault},
+			want:   TxWrongTarget,
+		},
+		{
+			name:   "missing log",
+			reader: &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful), head: 105},
+			opts:   VerifyOpts{Log: logMatch},
+			want:   TxWrongTarget,
+		},
+		{
+			name: "log for another participant",
+			reader: &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful, &types.Log{
+				Address: vault,
+				Topics:  []common.Hash{ClaimedEventTopic, ParticipantTopic(other)},
+			}), head: 105},
+			opts: VerifyOpts{Log: logMatch},
+			want: TxWrongTarget,
+		},
+		{
+			name:          "too few confirmations",
+			reader:        &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful, claimLog), head: 101},
+			opts:          VerifyOpts{Log: logMatch, Confirmations: 3},
+			want:          TxPending,
+			confirmations: 2,
+		},
+		{
+			name:          "node behind the receipt",
+			reader:        &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful, claimLog), head: 99},
+			opts:          VerifyOpts{Log: logMatch, Confirmations: 1},
+			want:          TxPending,
+			confirmations: 0,
+		},
+		{
+			name:          "confirmed",
+			reader:        &fakeTxReader{receipt: receipt(types.ReceiptStatusSuccessful, claimLog), tx: txTo(vault), head: 102},
+			opts:          VerifyOpts{To: &vault, Log: logMatch, Confirmations: 3},
+			want:          TxConfirmed,
+			confirmations: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyTx(context.Background(), tt.reader, common.Hash{}, tt.opts)
+			if err != nil {
+				t.Fatalf("VerifyTx: %v", err)
+			}
+			if got.State != tt.want {
+				t.Errorf("state = %s (%s), want %s", got.State, got.Reason, tt.want)
+			}
+			if got.Confirmations != tt.confirmations {
+				t.Errorf("confirmations = %d, want %d", got.Confirmations, tt.confirmations)
+			}
+			if tt.want != TxConfirmed && got.Reason == "" {
+				t.Error("expected a reason")
+			}
+		})
+	}
+}
+
+func TestVerifyTxReportsRPCErrors(t *testing.T) {
+	rpcErr := errors.New("connection refused")
+	_, err := VerifyTx(context.Background(), &fakeTxReader{receiptErr: rpcErr}, common.Hash{}, VerifyOpts{})
+	if !errors.Is(err, rpcErr) {
+		t.Errorf("err = %v, want %v", err, rpcErr)
+	}
+}
+
+func TestVerifyRegistrationTxAcceptsDeposited(t *testing.T) {
+	vault := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	participant := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	reader := &fakeTxReader{
+		receipt: &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(100),
+			Logs:        []*types.Log{{Address: vault, Topics: []common.Hash{DepositedEventTopic, ParticipantTopic(participant)}}},
+		},
+		head: 100,
+	}
+
+	got, err := VerifyRegistrationTx(context.Background(), reader, "0x01", vault, participant, 1)
+	if err != nil {
+		t.Fatalf("VerifyRegistrationTx: %v", err)
+	}
+	if got.State != TxConfirmed {
+		t.Errorf("state = %s (%s), want confirmed", got.State, got.Reason)
+	}
+}
+
+func TestVerifySettlementTxDecodesSettled(t *testing.T) {
+	vault := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	settled := &types.Log{
+		Address: vault,
+		Topics:  []common.Hash{SettledEventTopic},
+		Data:    append(append(word(3), word(2)...), word(5000000)...),
+	}
+	reader := &fakeTxReader{
+		receipt: &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(100),
+			Logs:        []*types.Log{{Address: other, Topics: []common.Hash{SettledEventTopic}}, settled},
+		},
+		head: 100,
+	}
+
+	got, err := VerifySettlementTx(context.Background(), reader, "0x01", vault, 1)
+	if err != nil {
+		t.Fatalf("VerifySettlementTx: %v", err)
+	}
+	if got.State != TxConfirmed {
+		t.Fatalf("state = %s (%s), want confirmed", got.State, got.Reason)
+	}
+
+	event, err := SettledInReceipt(got.Receipt, vault)
+	if err != nil {
+		t.Fatalf("SettledInReceipt: %v", err)
+	}
+	if event.AttendedCount.Int64() != 3 || event.NoShowCount.Int64() != 2 || event.RewardPerAttendee.Int64() != 5000000 {
+		t.Errorf("settled = %+v, want 3 attended, 2 no-shows, 5000000 each", event)
+	}
+
+	if _, err := SettledInReceipt(got.Receipt, common.HexToAddress("0x4444444444444444444444444444444444444444")); err == nil {
+		t.Error("found a Settled log from a vault that emitted none")
+	}
+}
@@ -0,0 +1,65 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Topics of the vault events emitted when a participant stakes, checks in, claims or
+// withdraws, and when the organizer settles the event
+var (
+	RegisteredEventTopic = crypto.Keccak256Hash([]byte("Registered(address,uint256)"))
+	DepositedEventTopic  = crypto.Keccak256Hash([]byte("Deposited(address,uint256)"))
+	CheckedInEventTopic  = crypto.Keccak256Hash([]byte("CheckedIn(address)"))
+	SettledEventTopic    = crypto.Keccak256Hash([]byte("Settled(uint256,uint256,uint256)"))
+	ClaimedEventTopic    = crypto.Keccak256Hash([]byte("Claimed(address,uint256)"))
+	WithdrawnEventTopic  = crypto.Keccak256Hash([]byte("Withdrawn(address,uint256)"))
+)
+
+// FetchDepositors returns the lowercase addresses of every wallet that staked into the vault
+// between fromBlock and the latest block
+func FetchDepositors(ctx context.Context, client Caller, vault common.Address, fromBlock uint64) (map[string]bool, error) {
+	return fetchParticipants(ctx, client, vault, fromBlock, RegisteredEventTopic, DepositedEventTopic)
+}
+
+// FetchWithdrawers returns the lowercase addresses of every wallet that withdrew its stake
+// from the vault between fromBlock and the latest block
+func FetchWithdrawers(ctx context.Context, client Caller, vault common.Address, fromBlock uint64) (map[string]bool, error) {
+	return fetchParticipants(ctx, client, vault, fromBlock, WithdrawnEventTopic)
+}
+
+// fetchParticipants collects the participant (first indexed argument) of every vault log
+// matching one of the topics
+func fetchParticipants(ctx context.Context, client Caller, vault common.Address, fromBlock uint64, topics ...common.Hash) (map[string]bool, error) {
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{vault},
+		Topics:    [][]common.Hash{topics},
+	}
+
+	logs, err := FilterLogsChunked(ctx, client, query, fromBlock, latest, DefaultLogChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := map[string]bool{}
+	for _, vLog := range logs {
+		// The participant is the first indexed argument
+		if len(vLog.Topics) < 2 || vLog.Removed {
+			continue
+		}
+		participant := common.BytesToAddress(vLog.Topics[1].Bytes())
+		participants[strings.ToLower(participant.Hex())] = true
+	}
+
+	return participants, nil
+}
@@ -0,0 +1,175 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RetryPolicy controls how transient RPC failures are retried. Attempts counts the first
+// try. Each delay doubles from BaseDelay up to MaxDelay and is then shortened by a random
+// fraction of up to Jitter, so callers that failed together do not retry together.
+type RetryPolicy struct {
+	Attempts   int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	MaxElapsed time.Duration
+	Jitter     float64
+}
+
+// DefaultRetryPolicy is used by the read paths in this package
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	BaseDelay:  200 * time.Millisecond,
+	MaxDelay:   2 * time.Second,
+	MaxElapsed: 5 * time.Second,
+	Jitter:     0.5,
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not transient, or the
+// policy runs out. It never sleeps past ctx's deadline: when the next delay would end
+// after it, the last error is returned straight away.
+func Retry[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	delay := policy.BaseDelay
+
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil || !IsTransient(err) || attempt >= policy.Attempts || ctx.Err() != nil {
+			return value, err
+		}
+
+		wait := delay
+		if policy.Jitter > 0 {
+			wait -= time.Duration(rand.Float64() * policy.Jitter * float64(delay))
+		}
+		if policy.MaxElapsed > 0 && time.Since(start)+wait > policy.MaxElapsed {
+			return value, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return value, err
+		}
+		if !sleepCtx(ctx, wait) {
+			return value, err
+		}
+
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// retryCall retries fn under DefaultRetryPolicy when it only returns an error
+func retryCall(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Messages of transient failures that reach us as plain strings, lowercased
+var transientMessages = []string{
+	"too many requests",
+	"rate limit",
+	"compute units",
+	"limit exceeded",
+	"request timed out",
+	"timeout",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"header not found",
+}
+
+// Messages that mean the call itself failed and will fail again, lowercased
+var permanentMessages = []string{
+	"execution reverted",
+	"revert",
+	"invalid opcode",
+	"out of gas",
+	"insufficient funds",
+	"nonce too low",
+}
+
+// isExecutionError reports whether err is the call itself failing, such as a revert,
+// rather than the request
+func isExecutionError(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, permanent := range permanentMessages {
+		if strings.Contains(message, permanent) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTransient reports whether err is worth retrying: rate limits, timeouts, dropped
+// connections and gateway errors. Reverts and other execution errors never are, and
+// neither is a cancelled context. Retry also stops once the caller's context is done.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Revert data is an rpc.DataError, which HTTP errors never are
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	if isExecutionError(err) {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryingCaller retries a bind.ContractCaller's calls under DefaultRetryPolicy
+type retryingCaller struct {
+	bind.ContractCaller
+}
+
+func (r retryingCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) ([]byte, error) {
+		return r.ContractCaller.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (r retryingCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) ([]byte, error) {
+		return r.ContractCaller.CodeAt(ctx, contract, blockNumber)
+	})
+}
@@ -0,0 +1,125 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// revertError mimics the rpc.DataError go-ethereum returns for a reverted eth_call
+type revertError struct{}
+
+func (revertError) Error() string          { return "execution reverted: not a participant" }
+func (revertError) ErrorData() interface{} { return "0x08c379a0" }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"revert data error", revertError{}, false},
+		{"revert message", errors.New("execution reverted"), false},
+		{"custom revert", errors.New("execution reverted: AlreadyClaimed()"), false},
+		{"out of gas", errors.New("out of gas"), false},
+		{"invalid opcode", errors.New("invalid opcode: INVALID"), false},
+		{"insufficient funds", errors.New("insufficient funds for gas * price + value"), false},
+		{"http 429", rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{"http 503", rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{"http 401", rpc.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}, false},
+		{"rate limit message", errors.New("429 Too Many Requests: {\"code\":-32005,\"message\":\"rate limit exceeded\"}"), true},
+		{"compute units", errors.New("Your app has exceeded its compute units per second capacity"), true},
+		{"daily limit", errors.New("daily request count exceeded, request rate limited"), true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"connection reset message", errors.New("Post \"https://sepolia.base.org\": read tcp 10.0.0.2:443: read: connection reset by peer"), true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"i/o timeout", errors.New("Post \"https://sepolia.base.org\": dial tcp: i/o timeout"), true},
+		{"request timed out", errors.New("request timed out"), true},
+		{"header not found", errors.New("header not found"), true},
+		{"deadline exceeded", fmt.Errorf("eth_call: %w", context.DeadlineExceeded), true},
+		{"cancelled", context.Canceled, false},
+		{"not found", errors.New("not found"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+var fastRetry = RetryPolicy{Attempts: 4, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, Jitter: 0.5}
+
+func TestRetryStopsOnPermanentErrors(t *testing.T) {
+	calls := 0
+	_, err := Retry(context.Background(), fastRetry, func(ctx context.Context) (int, error) {
+		calls++
+		return 0, revertError{}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("calls = %d, err = %v; want one call and the revert", calls, err)
+	}
+}
+
+func TestRetryRecoversFromTransientErrors(t *testing.T) {
+	calls := 0
+	value, err := Retry(context.Background(), fastRetry, func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, rpc.HTTPError{StatusCode: 429}
+		}
+		return 42, nil
+	})
+	if err != nil || value != 42 || calls != 3 {
+		t.Errorf("Retry = %d, %v after %d calls; want 42 after 3", value, err, calls)
+	}
+
+	calls = 0
+	if _, err := Retry(context.Background(), fastRetry, func(ctx context.Context) (int, error) {
+		calls++
+		return 0, rpc.HTTPError{StatusCode: 503}
+	}); err == nil || calls != fastRetry.Attempts {
+		t.Errorf("calls = %d, err = %v; want %d calls and the last error", calls, err, fastRetry.Attempts)
+	}
+}
+
+func TestRetryRespectsContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	policy := RetryPolicy{Attempts: 10, BaseDelay: time.Second}
+	calls := 0
+	start := time.Now()
+	_, err := Retry(ctx, policy, func(ctx context.Context) (int, error) {
+		calls++
+		return 0, errors.New("connection reset by peer")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("calls = %d, err = %v; want one call when the delay outlasts the deadline", calls, err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Retry took %s, want it to give up without sleeping", elapsed)
+	}
+}
+
+func TestRetryRespectsMaxElapsed(t *testing.T) {
+	policy := RetryPolicy{Attempts: 10, BaseDelay: 20 * time.Millisecond, MaxElapsed: 30 * time.Millisecond}
+	calls := 0
+	_, err := Retry(context.Background(), policy, func(ctx context.Context) (int, error) {
+		calls++
+		return 0, errors.New("i/o timeout")
+	})
+	if err == nil || calls != 2 {
+		t.Errorf("calls = %d, err = %v; want 2 calls within MaxElapsed", calls, err)
+	}
+}
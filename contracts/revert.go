@@ -0,0 +1,156 @@
+package contracts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"atfi-backend/contracts/gen"
+)
+
+// Selectors of the revert payloads Solidity itself emits
+var (
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0} // Error(string)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71} // Panic(uint256)
+)
+
+// revertABIs are the contracts whose custom errors DecodeRevert knows
+var revertABIs = []abi.ABI{vaultABI, mustParseABI(gen.FactoryMetaData)}
+
+// panicReasons names the Panic(uint256) codes the Solidity compiler uses
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to an uninitialized function",
+}
+
+// Revert is a decoded revert payload
+type Revert struct {
+	// Name identifies the revert for clients: the custom error's name, the message of an
+	// Error(string), "Panic(0x11)" for panics, or the raw payload for unknown selectors
+	Name string
+	// Reason explains the revert in words, with a custom error's arguments
+	Reason string
+	// Data is the raw payload, or empty when the node returned none
+	Data string
+}
+
+// DecodeRevert decodes revert data: Error(string), Panic(uint256) and the custom errors
+// of the vault and factory ABIs. Anything else is reported as its raw hex.
+func DecodeRevert(data []byte) Revert {
+	return decodeRevert(data, revertABIs)
+}
+
+func decodeRevert(data []byte, abis []abi.ABI) Revert {
+	raw := hexutil.Encode(data)
+	if len(data) < 4 {
+		return Revert{Name: raw, Reason: "execution reverted without a reason", Data: raw}
+	}
+
+	selector := data[:4]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			return Revert{Name: reason, Reason: reason, Data: raw}
+		}
+	case bytes.Equal(selector, panicSelector):
+		if code, err := unpackPanic(data[4:]); err == nil {
+			name := fmt.Sprintf("Panic(0x%x)", code)
+			reason, known := panicReasons[code.Uint64()]
+			if !code.IsUint64() || !known {
+				reason = "unknown panic code"
+			}
+			return Revert{Name: name, Reason: fmt.Sprintf("panic: %s (0x%x)", reason, code), Data: raw}
+		}
+	}
+
+	for _, contractABI := range abis {
+		for _, customErr := range contractABI.Errors {
+			if !bytes.Equal(customErr.ID[:4], selector) {
+				continue
+			}
+			args, err := customErr.Inputs.Unpack(data[4:])
+			if err != nil {
+				continue
+			}
+			return Revert{Name: customErr.Name, Reason: formatCustomError(customErr, args), Data: raw}
+		}
+	}
+	return Revert{Name: raw, Reason: "execution reverted with unknown error " + hexutil.Encode(selector), Data: raw}
+}
+
+func unpackPanic(data []byte) (*big.Int, error) {
+	if len(data) != 32 {
+		return nil, errors.New("invalid panic payload")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// formatCustomError renders a custom error like a call: NotOrganizer(0x1234..., 5)
+func formatCustomError(customErr abi.Error, args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprint(arg)
+		if stringer, ok := arg.(fmt.Stringer); ok {
+			parts[i] = stringer.String()
+		}
+	}
+	return customErr.Name + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// RevertOf explains an error from a call or gas estimate that failed because the
+// transaction itself would fail. The revert data is decoded when the node returned it;
+// otherwise the reason is taken from the error message and Data is empty. ok is false for
+// every other error, such as the node being unreachable.
+func RevertOf(err error) (revert Revert, ok bool) {
+	if err == nil || !isExecutionError(err) {
+		return Revert{}, false
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, isString := dataErr.ErrorData().(string); isString {
+			if raw, decodeErr := hexutil.Decode(data); decodeErr == nil && len(raw) > 0 {
+				return DecodeRevert(raw), true
+			}
+		}
+	}
+
+	reason := revertMessage(err)
+	return Revert{Name: reason, Reason: reason}, true
+}
+
+// RevertReason is RevertOf's reason alone
+func RevertReason(err error) (reason string, ok bool) {
+	revert, ok := RevertOf(err)
+	return revert.Reason, ok
+}
+
+// revertMessage takes the reason from an execution error's message
+func revertMessage(err error) string {
+	// Drop the context our own wrapping added in front of the node's message
+	message := err.Error()
+	if i := strings.Index(message, "execution reverted: "); i >= 0 {
+		return message[i+len("execution reverted: "):]
+	}
+	lower := strings.ToLower(message)
+	for _, permanent := range permanentMessages {
+		if i := strings.Index(lower, permanent); i >= 0 {
+			return message[i:]
+		}
+	}
+	return message
+}
@@ -0,0 +1,101 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Custom errors of the kind a vault reverts with; the vault ABI in contracts/abi does not
+// declare any yet
+const customErrorsABI = `[
+	{"type":"error","name":"AlreadySettled","inputs":[]},
+	{"type":"error","name":"NotOrganizer","inputs":[{"name":"caller","type":"address"}]},
+	{"type":"error","name":"RegistrationClosed","inputs":[{"name":"deadline","type":"uint256"}]}
+]`
+
+// dataError is a node's revert carrying its payload, like the rpc.DataError ethclient returns
+type dataError struct {
+	message string
+	data    string
+}
+
+func (e *dataError) Error() string          { return e.message }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevert(t *testing.T) {
+	customErrors, err := abi.JSON(strings.NewReader(customErrorsABI))
+	if err != nil {
+		t.Fatalf("parsing the custom errors: %v", err)
+	}
+
+	// Payloads as nodes return them
+	tests := []struct {
+		payload    string
+		wantName   string
+		wantReason string
+	}{
+		{
+			"0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000f416c726561647920736574746c65640000000000000000000000000000000000",
+			"Already settled", "Already settled",
+		},
+		{
+			"0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+			"Panic(0x11)", "panic: arithmetic underflow or overflow (0x11)",
+		},
+		{
+			"0x4e487b7100000000000000000000000000000000000000000000000000000000000000ff",
+			"Panic(0xff)", "panic: unknown panic code (0xff)",
+		},
+		{"0x560ff900", "AlreadySettled", "AlreadySettled()"},
+		{
+			"0xd1c56a7a0000000000000000000000002222222222222222222222222222222222222222",
+			"NotOrganizer", "NotOrganizer(0x2222222222222222222222222222222222222222)",
+		},
+		{
+			"0x383b6ce30000000000000000000000000000000000000000000000000000000067748580",
+			"RegistrationClosed", "RegistrationClosed(1735689600)",
+		},
+		// Unknown selectors and malformed payloads fall back to the raw hex
+		{"0xdeadbeef00", "0xdeadbeef00", "execution reverted with unknown error 0xdeadbeef"},
+		{"0x4e487b7100", "0x4e487b7100", "execution reverted with unknown error 0x4e487b71"},
+		{"0x", "0x", "execution reverted without a reason"},
+	}
+	for _, tt := range tests {
+		got := decodeRevert(hexutil.MustDecode(tt.payload), []abi.ABI{customErrors})
+		if got.Name != tt.wantName || got.Reason != tt.wantReason || got.Data != tt.payload {
+			t.Errorf("decodeRevert(%s) = %+v; want name %q, reason %q", tt.payload, got, tt.wantName, tt.wantReason)
+		}
+	}
+}
+
+func TestRevertOf(t *testing.T) {
+	// A custom error the known ABIs do not declare keeps its raw payload
+	err := fmt.Errorf("failed to estimate gas: %w", &dataError{message: "execution reverted", data: "0x560ff900"})
+	revert, ok := RevertOf(err)
+	if !ok || revert.Name != "0x560ff900" || revert.Data != "0x560ff900" {
+		t.Errorf("RevertOf(custom error) = %+v, %t", revert, ok)
+	}
+
+	err = &dataError{
+		message: "execution reverted: Already settled",
+		data:    "0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000f416c726561647920736574746c65640000000000000000000000000000000000",
+	}
+	if reason, ok := RevertReason(err); !ok || reason != "Already settled" {
+		t.Errorf("RevertReason(Error(string)) = %q, %t", reason, ok)
+	}
+
+	// Without data the node's message is all there is
+	revert, ok = RevertOf(errors.New("failed to call claim: execution reverted: Not an attendee"))
+	if !ok || revert.Name != "Not an attendee" || revert.Data != "" {
+		t.Errorf("RevertOf(message only) = %+v, %t", revert, ok)
+	}
+
+	if _, ok := RevertOf(errors.New("connection refused")); ok {
+		t.Error("RevertOf reported a connection failure as a revert")
+	}
+}
@@ -0,0 +1,34 @@
+package contracts
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SettleFunction is the vault function the organizer calls with the attended wallets to
+// settle the event
+const SettleFunction = "settleEvent"
+
+// PackSettleEvent ABI-encodes a settleEvent call. Attendees are deduplicated and sorted so
+// the same attendance always yields the same call data.
+func PackSettleEvent(attendees []common.Address) ([]byte, error) {
+	return PackVaultCall(SettleFunction, SortAddresses(attendees))
+}
+
+// SortAddresses returns a sorted copy of addresses without duplicates
+func SortAddresses(addresses []common.Address) []common.Address {
+	sorted := append([]common.Address{}, addresses...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0
+	})
+
+	unique := make([]common.Address, 0, len(sorted))
+	for i, addr := range sorted {
+		if i == 0 || addr != sorted[i-1] {
+			unique = append(unique, addr)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,34 @@
+package contracts
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPackSettleEventMatchesKnownEncoding(t *testing.T) {
+	first := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	second := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	// Out of order and repeated: the call data must not depend on either
+	data, err := PackSettleEvent([]common.Address{second, first, second})
+	if err != nil {
+		t.Fatalf("PackSettleEvent: %v", err)
+	}
+
+	if want := readFixture(t, "settle_event.calldata.hex"); !bytes.Equal(data, want) {
+		t.Errorf("call data = %x, want %x", data, want)
+	}
+}
+
+func TestPackSettleEventEncodesEmptyList(t *testing.T) {
+	// An empty list still encodes; refusing to settle without attendees is the caller's call
+	data, err := PackSettleEvent(nil)
+	if err != nil {
+		t.Fatalf("PackSettleEvent: %v", err)
+	}
+	if len(data) != 4+2*32 {
+		t.Errorf("empty settle call data is %d bytes, want %d", len(data), 4+2*32)
+	}
+}
@@ -0,0 +1,140 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"atfi-backend/contracts/gen"
+)
+
+// EIP1271MagicValue is what a contract wallet's isValidSignature returns for a signature
+// it accepts: the function's own selector
+var EIP1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// SignatureVerifier checks wallet signatures. Externally owned accounts are verified by
+// recovering the signer. Addresses with code, such as Coinbase Smart Wallet accounts, are
+// asked through their EIP-1271 isValidSignature instead.
+type SignatureVerifier struct {
+	client bind.ContractCaller // nil verifies externally owned accounts only
+}
+
+// NewSignatureVerifier returns a verifier reading contract wallets through client, which
+// may be nil to accept externally owned accounts only
+func NewSignatureVerifier(client bind.ContractCaller) *SignatureVerifier {
+	v := &SignatureVerifier{}
+	if client != nil {
+		v.client = retryingCaller{client}
+	}
+	return v
+}
+
+// VerifyPersonal checks that signature is address's personal_sign (EIP-191) signature of
+// message
+func (v *SignatureVerifier) VerifyPersonal(ctx context.Context, address, message, signature string) error {
+	return v.VerifyHash(ctx, address, accounts.TextHash([]byte(message)), signature)
+}
+
+// VerifyHash checks that signature is address's signature of the 32-byte digest, such as
+// the hash of an EIP-712 typed payload. A signature that does not recover to address is
+// passed to the address's isValidSignature when it has code; an address without code only
+// accepts its own signature.
+func (v *SignatureVerifier) VerifyHash(ctx context.Context, address string, digest []byte, signature string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid wallet address: %s", address)
+	}
+	if len(digest) != common.HashLength {
+		return fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	wallet := common.HexToAddress(address)
+	signer, recoverErr := recoverSigner(digest, sig)
+	if recoverErr == nil && signer == wallet {
+		return nil
+	}
+
+	isContract, err := v.isContract(ctx, wallet)
+	if err != nil {
+		return err
+	}
+	if !isContract {
+		if recoverErr != nil {
+			return recoverErr
+		}
+		return fmt.Errorf("signature was produced by %s, not %s", signer.Hex(), address)
+	}
+	return v.verifyContractSignature(ctx, wallet, common.BytesToHash(digest), sig)
+}
+
+// isContract reports whether wallet has code, and so may be a smart contract wallet
+func (v *SignatureVerifier) isContract(ctx context.Context, wallet common.Address) (bool, error) {
+	if v == nil || v.client == nil {
+		return false, nil
+	}
+	var code []byte
+	err := withCallTimeout(ctx, func(ctx context.Context) (err error) {
+		code, err = v.client.CodeAt(ctx, wallet, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read code of %s: %w", wallet.Hex(), err)
+	}
+	return len(code) > 0, nil
+}
+
+// verifyContractSignature asks the contract wallet whether it accepts signature over hash.
+// A wallet that reverts, as one without isValidSignature does, is taken as refusing it.
+func (v *SignatureVerifier) verifyContractSignature(ctx context.Context, wallet common.Address, hash common.Hash, sig []byte) error {
+	caller, err := gen.NewERC1271Caller(wallet, v.client)
+	if err != nil {
+		return fmt.Errorf("failed to bind contract wallet: %w", err)
+	}
+	magic, err := vaultCall(ctx, "isValidSignature", func(opts *bind.CallOpts) ([4]byte, error) {
+		return caller.IsValidSignature(opts, hash, sig)
+	})
+	if err != nil {
+		if reason, reverted := RevertReason(err); reverted {
+			return fmt.Errorf("contract wallet %s rejected the signature: %s", wallet.Hex(), reason)
+		}
+		return err
+	}
+	if magic != EIP1271MagicValue {
+		return fmt.Errorf("contract wallet %s rejected the signature", wallet.Hex())
+	}
+	return nil
+}
+
+// recoverSigner returns the account whose 65-byte signature sig is, leaving sig as it was
+func recoverSigner(digest, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+
+	// Wallets produce v as 27/28, crypto expects 0/1
+	sig = append([]byte(nil), sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// VerifyPersonalSignature checks that signature is a personal_sign (EIP-191) signature
+// of message produced by address. Only externally owned accounts are accepted; use a
+// SignatureVerifier for contract wallets.
+func VerifyPersonalSignature(address, message, signature string) error {
+	return NewSignatureVerifier(nil).VerifyPersonal(context.Background(), address, message, signature)
+}
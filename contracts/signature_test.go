@@ -0,0 +1,103 @@
+package contracts_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+var smartWallet = common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+// personalSign signs message the way wallets answer personal_sign, with v as 27/28
+func personalSign(t *testing.T, message string) (common.Address, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return crypto.PubkeyToAddress(key.PublicKey), hexutil.Encode(sig)
+}
+
+func TestVerifyPersonalFromExternallyOwnedAccount(t *testing.T) {
+	signer, signature := personalSign(t, "ATFi authentication")
+	client := contractstest.NewClient(84532)
+	client.SetEOA(signer)
+	client.SetEOA(participant)
+	verifier := contracts.NewSignatureVerifier(client)
+
+	if err := verifier.VerifyPersonal(context.Background(), signer.Hex(), "ATFi authentication", signature); err != nil {
+		t.Errorf("VerifyPersonal: %v", err)
+	}
+	if err := verifier.VerifyPersonal(context.Background(), signer.Hex(), "something else", signature); err == nil {
+		t.Error("VerifyPersonal accepted a signature of another message")
+	}
+	if err := verifier.VerifyPersonal(context.Background(), participant.Hex(), "ATFi authentication", signature); err == nil {
+		t.Error("VerifyPersonal accepted another account's signature")
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("made %d contract calls for an externally owned account, want none", len(calls))
+	}
+
+	// Without a client only externally owned accounts are verified
+	if err := contracts.VerifyPersonalSignature(signer.Hex(), "ATFi authentication", signature); err != nil {
+		t.Errorf("VerifyPersonalSignature: %v", err)
+	}
+}
+
+func TestVerifyPersonalFromContractWallet(t *testing.T) {
+	message := "ATFi authentication"
+	hash := common.BytesToHash(accounts.TextHash([]byte(message)))
+	// Smart wallet signatures are longer than 65 bytes and cannot be recovered
+	signature := append([]byte{0x01}, make([]byte, 96)...)
+
+	client := contractstest.NewClient(84532)
+	client.SetSignatureResult(smartWallet, hash, signature, contracts.EIP1271MagicValue)
+	verifier := contracts.NewSignatureVerifier(client)
+	if err := verifier.VerifyPersonal(context.Background(), smartWallet.Hex(), message, hexutil.Encode(signature)); err != nil {
+		t.Errorf("VerifyPersonal with the magic value: %v", err)
+	}
+
+	client.SetSignatureResult(smartWallet, hash, signature, [4]byte{0xff, 0xff, 0xff, 0xff})
+	if err := verifier.VerifyPersonal(context.Background(), smartWallet.Hex(), message, hexutil.Encode(signature)); err == nil {
+		t.Error("VerifyPersonal accepted a signature the wallet did not return the magic value for")
+	}
+
+	// A contract that does not implement EIP-1271 reverts, which refuses the signature
+	other := append([]byte{0x02}, make([]byte, 96)...)
+	if err := verifier.VerifyPersonal(context.Background(), smartWallet.Hex(), message, hexutil.Encode(other)); err == nil {
+		t.Error("VerifyPersonal accepted a signature the wallet reverted on")
+	}
+
+	// Without a client there is no way to ask the wallet
+	if err := contracts.VerifyPersonalSignature(smartWallet.Hex(), message, hexutil.Encode(signature)); err == nil {
+		t.Error("VerifyPersonalSignature accepted a contract wallet signature")
+	}
+}
+
+func TestVerifyHashFromContractWallet(t *testing.T) {
+	// An EIP-712 digest is passed to the wallet as is
+	digest := crypto.Keccak256Hash([]byte("\x19\x01"), make([]byte, 64))
+	signature := make([]byte, 65)
+
+	client := contractstest.NewClient(84532)
+	client.SetSignatureResult(smartWallet, digest, signature, contracts.EIP1271MagicValue)
+	verifier := contracts.NewSignatureVerifier(client)
+	if err := verifier.VerifyHash(context.Background(), smartWallet.Hex(), digest.Bytes(), hexutil.Encode(signature)); err != nil {
+		t.Errorf("VerifyHash: %v", err)
+	}
+	if err := verifier.VerifyHash(context.Background(), smartWallet.Hex(), digest.Bytes()[:31], hexutil.Encode(signature)); err == nil {
+		t.Error("VerifyHash accepted a short digest")
+	}
+}
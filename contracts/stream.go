@@ -0,0 +1,306 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// StreamState is how a LogStream is currently receiving logs
+type StreamState string
+
+const (
+	// StreamStarting has not finished its first backfill
+	StreamStarting StreamState = "starting"
+	// StreamConnected follows a live WebSocket subscription
+	StreamConnected StreamState = "connected"
+	// StreamPolling has no WebSocket endpoint and polls over HTTP
+	StreamPolling StreamState = "polling"
+	// StreamDegraded lost its subscription or failed to poll, and is retrying
+	StreamDegraded StreamState = "degraded"
+)
+
+// Defaults for a LogStream
+const (
+	DefaultStreamPollInterval = 15 * time.Second
+	DefaultStreamMinBackoff   = time.Second
+	DefaultStreamMaxBackoff   = time.Minute
+
+	// How many blocks behind the stream delivered logs are remembered for deduplication
+	streamDedupeDepth = 256
+)
+
+// LogBackend is the HTTP side of a LogStream, usually an *ethclient.Client
+type LogBackend interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// LogHandler receives logs in chain order. through is the block up to which every log has
+// now been delivered, the checkpoint to resume from. A handler error stops delivery until
+// the stream retries, which re-delivers the same logs.
+type LogHandler func(ctx context.Context, logs []types.Log, through uint64) error
+
+// logKey identifies a log for deduplication
+type logKey struct {
+	txHash common.Hash
+	index  uint
+}
+
+// LogStream delivers the logs matching a query, exactly once each, across restarts of
+// its subscription. With a WebSocket URL it follows eth_subscribe and, after every
+// reconnect, backfills the blocks it may have missed over HTTP before resuming. Without
+// one it polls over HTTP.
+type LogStream struct {
+	backend LogBackend
+	query   ethereum.FilterQuery
+	dial    func(ctx context.Context) (ethereum.LogFilterer, func(), error)
+
+	PollInterval time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	// OnStateChange, when set, is called from the stream's goroutine on every transition
+	OnStateChange func(state StreamState, err error)
+
+	mu    sync.Mutex
+	state StreamState
+	err   error
+
+	next uint64
+	seen map[logKey]uint64
+}
+
+// NewLogStream returns a stream of the logs matching query (its block range is ignored).
+// wsURL may be empty to poll over HTTP only.
+func NewLogStream(backend LogBackend, wsURL string, query ethereum.FilterQuery) *LogStream {
+	s := &LogStream{
+		backend:      backend,
+		query:        query,
+		PollInterval: DefaultStreamPollInterval,
+		MinBackoff:   DefaultStreamMinBackoff,
+		MaxBackoff:   DefaultStreamMaxBackoff,
+		state:        StreamStarting,
+		seen:         map[logKey]uint64{},
+	}
+	if wsURL != "" {
+		s.dial = func(ctx context.Context) (ethereum.LogFilterer, func(), error) {
+			client, err := ethclient.DialContext(ctx, wsURL)
+			if err != nil {
+				return nil, nil, err
+			}
+			return client, client.Close, nil
+		}
+	}
+	return s
+}
+
+// State returns the current state and, when degraded, the error that caused it
+func (s *LogStream) State() (StreamState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.err
+}
+
+func (s *LogStream) setState(state StreamState, err error) {
+	s.mu.Lock()
+	changed := s.state != state
+	s.state, s.err = state, err
+	s.mu.Unlock()
+
+	if changed && s.OnStateChange != nil {
+		s.OnStateChange(state, err)
+	}
+}
+
+// Run delivers logs from fromBlock on until ctx is cancelled, which is the only error it
+// returns. Failures are retried with exponential backoff and reported through State.
+func (s *LogStream) Run(ctx context.Context, fromBlock uint64, handle LogHandler) error {
+	s.next = fromBlock
+	backoff := s.MinBackoff
+
+	retry := func(err error) error {
+		s.setState(StreamDegraded, err)
+		if !sleepCtx(ctx, backoff) {
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+		return nil
+	}
+
+	for {
+		if s.dial == nil {
+			if err := s.backfill(ctx, handle); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err := retry(err); err != nil {
+					return err
+				}
+				continue
+			}
+			s.setState(StreamPolling, nil)
+			backoff = s.MinBackoff
+			if !sleepCtx(ctx, s.PollInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		err := s.follow(ctx, handle, func() { backoff = s.MinBackoff })
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := retry(err); err != nil {
+			return err
+		}
+	}
+}
+
+// follow subscribes, backfills what was missed while not subscribed and then delivers
+// live logs until the subscription fails. Subscribing first means no block falls between
+// the backfill and the subscription; duplicates are dropped.
+func (s *LogStream) follow(ctx context.Context, handle LogHandler, connected func()) error {
+	filterer, closeConn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to dial WebSocket endpoint: %w", err)
+	}
+	defer closeConn()
+
+	logs := make(chan types.Log, 128)
+	sub, err := filterer.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: s.query.Addresses, Topics: s.query.Topics}, logs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	if err := s.backfill(ctx, handle); err != nil {
+		return err
+	}
+	s.setState(StreamConnected, nil)
+	connected()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = fmt.Errorf("subscription closed")
+			}
+			return fmt.Errorf("log subscription dropped: %w", err)
+		case vLog := <-logs:
+			if err := s.deliverLive(ctx, vLog, handle); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deliverLive hands one subscribed log to handle. The stream resumes from the log's own
+// block, since more logs of that block may still follow.
+func (s *LogStream) deliverLive(ctx context.Context, vLog types.Log, handle LogHandler) error {
+	// Reorged-out logs are left to the consumer's confirmation handling
+	if vLog.Removed || s.isSeen(vLog) {
+		return nil
+	}
+
+	next := s.next
+	if vLog.BlockNumber > next {
+		next = vLog.BlockNumber
+	}
+	through := uint64(0)
+	if next > 0 {
+		through = next - 1
+	}
+
+	if err := handle(ctx, []types.Log{vLog}, through); err != nil {
+		return err
+	}
+	s.markSeen(vLog)
+	s.next = next
+	return nil
+}
+
+// backfill delivers every log from the next undelivered block up to the latest block,
+// one DefaultLogChunkSize window at a time
+func (s *LogStream) backfill(ctx context.Context, handle LogHandler) error {
+	latest, err := s.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	for s.next <= latest {
+		to := s.next + DefaultLogChunkSize
+		if to > latest {
+			to = latest
+		}
+
+		query := s.query
+		query.FromBlock = new(big.Int).SetUint64(s.next)
+		query.ToBlock = new(big.Int).SetUint64(to)
+		logs, err := s.backend.FilterLogs(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to filter logs for blocks %d-%d: %w", s.next, to, err)
+		}
+
+		fresh := logs[:0]
+		for _, vLog := range logs {
+			if !vLog.Removed && !s.isSeen(vLog) {
+				fresh = append(fresh, vLog)
+			}
+		}
+		if err := handle(ctx, fresh, to); err != nil {
+			return err
+		}
+		for _, vLog := range fresh {
+			s.markSeen(vLog)
+		}
+		s.next = to + 1
+		s.prune()
+	}
+	return nil
+}
+
+func (s *LogStream) isSeen(vLog types.Log) bool {
+	_, ok := s.seen[logKey{vLog.TxHash, vLog.Index}]
+	return ok
+}
+
+func (s *LogStream) markSeen(vLog types.Log) {
+	s.seen[logKey{vLog.TxHash, vLog.Index}] = vLog.BlockNumber
+}
+
+// prune forgets logs too far behind the stream to be delivered again
+func (s *LogStream) prune() {
+	if s.next < streamDedupeDepth {
+		return
+	}
+	horizon := s.next - streamDedupeDepth
+	for key, block := range s.seen {
+		if block < horizon {
+			delete(s.seen, key)
+		}
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is cancelled first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,195 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// fakeChain serves logs over "HTTP" from a chain whose head can move
+type fakeChain struct {
+	fakeLogFilterer
+	mu   sync.Mutex
+	head uint64
+}
+
+func (f *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.head, nil
+}
+
+func (f *fakeChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fakeLogFilterer.FilterLogs(ctx, q)
+}
+
+// add mines vLog, moving the head to its block
+func (f *fakeChain) add(vLog types.Log) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs = append(f.logs, vLog)
+	if vLog.BlockNumber > f.head {
+		f.head = vLog.BlockNumber
+	}
+}
+
+// scriptedSubscriber runs one script per connection, pushing live logs before dropping
+type scriptedSubscriber struct {
+	scripts []func(ch chan<- types.Log) error
+	dials   int
+}
+
+func (s *scriptedSubscriber) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	panic("not used")
+}
+
+func (s *scriptedSubscriber) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	script := s.scripts[s.dials-1]
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		if err := script(ch); err != nil {
+			return err
+		}
+		<-quit
+		return nil
+	}), nil
+}
+
+func testLog(block uint64, index uint) types.Log {
+	return types.Log{BlockNumber: block, Index: index, TxHash: common.BigToHash(big.NewInt(int64(block*100) + int64(index)))}
+}
+
+// collector records delivered logs and stops the stream once it has want of them
+type collector struct {
+	mu      sync.Mutex
+	logs    []types.Log
+	through []uint64
+	want    int
+	cancel  context.CancelFunc
+}
+
+func (c *collector) handle(ctx context.Context, logs []types.Log, through uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, logs...)
+	c.through = append(c.through, through)
+	if len(c.logs) >= c.want {
+		c.cancel()
+	}
+	return nil
+}
+
+func runStream(t *testing.T, s *LogStream, from uint64, c *collector) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.cancel = cancel
+
+	if err := s.Run(ctx, from, c.handle); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v, want context.Canceled", err)
+	}
+}
+
+func TestLogStreamPollsWithoutWebSocket(t *testing.T) {
+	chain := &fakeChain{head: 20}
+	chain.add(testLog(5, 0))
+	chain.add(testLog(12, 1))
+
+	s := NewLogStream(chain, "", ethereum.FilterQuery{})
+	s.PollInterval = time.Millisecond
+	c := &collector{want: 3}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		chain.add(testLog(25, 0))
+	}()
+	runStream(t, s, 0, c)
+
+	if len(c.logs) != 3 {
+		t.Fatalf("got %d logs, want 3", len(c.logs))
+	}
+	if state, _ := s.State(); state != StreamPolling {
+		t.Errorf("state = %s, want polling", state)
+	}
+	last := c.through[len(c.through)-1]
+	if last != 25 {
+		t.Errorf("through = %d, want 25", last)
+	}
+}
+
+func TestLogStreamBackfillsAfterReconnect(t *testing.T) {
+	chain := &fakeChain{head: 10}
+	chain.add(testLog(3, 0))
+
+	live := testLog(11, 0)
+	missed := testLog(12, 0)
+	after := testLog(14, 2)
+
+	subscriber := &scriptedSubscriber{scripts: []func(ch chan<- types.Log) error{
+		// The first connection sees one live log, then drops while block 12 is mined
+		func(ch chan<- types.Log) error {
+			chain.add(live)
+			ch <- live
+			chain.add(missed)
+			return errors.New("websocket: close 1006")
+		},
+		// After reconnecting the node replays the live log before a new one
+		func(ch chan<- types.Log) error {
+			ch <- live
+			chain.add(after)
+			ch <- after
+			return nil
+		},
+	}}
+
+	var states []StreamState
+	s := NewLogStream(chain, "", ethereum.FilterQuery{})
+	s.MinBackoff = time.Millisecond
+	s.OnStateChange = func(state StreamState, err error) { states = append(states, state) }
+	s.dial = func(ctx context.Context) (ethereum.LogFilterer, func(), error) {
+		subscriber.dials++
+		return subscriber, func() {}, nil
+	}
+
+	c := &collector{want: 4}
+	runStream(t, s, 0, c)
+
+	want := []types.Log{testLog(3, 0), live, missed, after}
+	if len(c.logs) != len(want) {
+		t.Fatalf("got %d logs, want %d: %+v", len(c.logs), len(want), c.logs)
+	}
+	for i := range want {
+		if c.logs[i].TxHash != want[i].TxHash || c.logs[i].Index != want[i].Index {
+			t.Errorf("log %d = block %d, want block %d", i, c.logs[i].BlockNumber, want[i].BlockNumber)
+		}
+	}
+	if subscriber.dials != 2 {
+		t.Errorf("dialled %d times, want 2", subscriber.dials)
+	}
+
+	wantStates := []StreamState{StreamConnected, StreamDegraded, StreamConnected}
+	if len(states) != len(wantStates) {
+		t.Fatalf("states = %v, want %v", states, wantStates)
+	}
+	for i := range wantStates {
+		if states[i] != wantStates[i] {
+			t.Errorf("states = %v, want %v", states, wantStates)
+			break
+		}
+	}
+
+	for i := 1; i < len(c.through); i++ {
+		if c.through[i] < c.through[i-1] {
+			t.Errorf("checkpoint moved backwards: %v", c.through)
+		}
+	}
+}
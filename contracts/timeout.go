@@ -0,0 +1,61 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultCallTimeout bounds a single contract read, retries included, when
+// CONTRACT_CALL_TIMEOUT is unset or invalid
+const DefaultCallTimeout = 3 * time.Second
+
+// CallTimeout is CONTRACT_CALL_TIMEOUT, a Go duration such as "5s", or DefaultCallTimeout
+func CallTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("CONTRACT_CALL_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return DefaultCallTimeout
+	}
+	return timeout
+}
+
+// CallTimeoutError is returned when a contract read gets no answer within its timeout, or
+// within what was left of the caller's deadline if that was shorter
+type CallTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *CallTimeoutError) Error() string {
+	return fmt.Sprintf("no answer within %s", e.Timeout)
+}
+
+// Unwrap lets errors.Is match context.DeadlineExceeded
+func (e *CallTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// IsCallTimeout reports whether err comes from a contract read that timed out
+func IsCallTimeout(err error) bool {
+	var timeoutErr *CallTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// withCallTimeout runs fn under CallTimeout and turns its deadline into a CallTimeoutError.
+// A cancelled ctx is returned as is.
+func withCallTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	budget := CallTimeout()
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < budget {
+		budget = time.Until(deadline)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	err := fn(callCtx)
+	if err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return &CallTimeoutError{Timeout: budget.Round(time.Millisecond)}
+	}
+	return err
+}
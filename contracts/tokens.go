@@ -0,0 +1,234 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts/gen"
+)
+
+// Token is an ERC-20 the API reports balances for
+type Token struct {
+	Symbol   string `json:"symbol"`
+	Address  string `json:"address"`
+	Decimals int    `json:"decimals"`
+}
+
+// erc20ABI is the generated ERC-20 binding's ABI. The balance helpers pack calls with it
+// themselves because they batch them into one RPC request, which the generated callers
+// cannot do.
+var erc20ABI = mustParseABI(gen.ERC20MetaData)
+
+func mustParseABI(meta *bind.MetaData) abi.ABI {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return *parsed
+}
+
+// Validate reports why a configured token cannot be queried
+func (t Token) Validate() error {
+	switch {
+	case t.Symbol == "":
+		return errors.New("token has no symbol")
+	case !common.IsHexAddress(t.Address):
+		return fmt.Errorf("invalid token address %q", t.Address)
+	case t.Decimals < 0 || t.Decimals > 36:
+		return fmt.Errorf("invalid decimals %d", t.Decimals)
+	}
+	return nil
+}
+
+// TokensFromEnv returns the token list for the RPC node's chain, from its Chain preset.
+// The first token of a chain is its primary token, the one profiles report as their
+// balance. TOKEN_LIST replaces the built-in lists with a JSON object keyed by chain ID, e.g.
+// {"84532":[{"symbol":"USDC","address":"0x...","decimals":6}]}. Entries are not validated
+// here; BalancesOf reports bad ones individually.
+func TokensFromEnv(ctx context.Context, client Caller) ([]Token, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	raw := strings.TrimSpace(os.Getenv("TOKEN_LIST"))
+	if raw == "" {
+		chain, _ := LookupChain(chainID.Int64())
+		return chain.Tokens, nil
+	}
+
+	var lists map[string][]Token
+	if err := json.Unmarshal([]byte(raw), &lists); err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_LIST: %w", err)
+	}
+	return lists[strconv.FormatInt(chainID.Int64(), 10)], nil
+}
+
+// TokenBalance is one token's balance for a wallet, or the reason it could not be read
+type TokenBalance struct {
+	Token Token
+	Raw   *big.Int
+	Err   error
+}
+
+// BalancesOf reads wallet's balance of every token in a single batched RPC request.
+// Invalid tokens and failed calls are reported per token; the returned error is only set
+// when the batch as a whole fails.
+func BalancesOf(ctx context.Context, client Caller, wallet common.Address, tokens []Token) ([]TokenBalance, error) {
+	balances := make([]TokenBalance, len(tokens))
+	var calls []Call
+	var batched []int
+
+	callData, err := erc20ABI.Pack("balanceOf", wallet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack balanceOf call data: %w", err)
+	}
+
+	for i, token := range tokens {
+		balances[i].Token = token
+		if err := token.Validate(); err != nil {
+			balances[i].Err = err
+			continue
+		}
+		calls = append(calls, Call{Target: common.HexToAddress(token.Address), CallData: callData})
+		batched = append(batched, i)
+	}
+
+	results, err := callBatch(ctx, client, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call balanceOf: %w", err)
+	}
+
+	for j, i := range batched {
+		if results[j].Err != nil {
+			balances[i].Err = fmt.Errorf("balanceOf failed: %w", results[j].Err)
+			continue
+		}
+		if len(results[j].ReturnData) == 0 {
+			balances[i].Err = errors.New("no ERC-20 contract at address")
+			continue
+		}
+		var raw *big.Int
+		if err := erc20ABI.UnpackIntoInterface(&raw, "balanceOf", results[j].ReturnData); err != nil {
+			balances[i].Err = fmt.Errorf("failed to unpack balanceOf result: %w", err)
+			continue
+		}
+		balances[i].Raw = raw
+	}
+
+	return balances, nil
+}
+
+// TokenMetadata reads the symbol and decimals of the ERC-20 at address through the
+// generated binding
+func TokenMetadata(ctx context.Context, client bind.ContractCaller, address common.Address) (Token, error) {
+	caller, err := gen.NewERC20Caller(address, retryingCaller{client})
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to bind token contract: %w", err)
+	}
+	symbol, err := vaultCall(ctx, "symbol", caller.Symbol)
+	if err != nil {
+		return Token{}, err
+	}
+	decimals, err := vaultCall(ctx, "decimals", caller.Decimals)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := Token{Symbol: symbol, Address: address.Hex(), Decimals: int(decimals)}
+	if err := token.Validate(); err != nil {
+		return Token{}, err
+	}
+	return token, nil
+}
+
+// StakeTokens reads and remembers the token each vault takes stakes in. A vault's token
+// is fixed when it is deployed, so entries never expire; failed reads are not remembered.
+type StakeTokens struct {
+	client bind.ContractCaller
+
+	mu      sync.Mutex
+	byVault map[common.Address]Token
+}
+
+// NewStakeTokens returns an empty cache reading through client
+func NewStakeTokens(client bind.ContractCaller) *StakeTokens {
+	return &StakeTokens{client: client, byVault: map[common.Address]Token{}}
+}
+
+// Of returns the stake token of the vault at address
+func (st *StakeTokens) Of(ctx context.Context, vault common.Address) (Token, error) {
+	st.mu.Lock()
+	token, ok := st.byVault[vault]
+	st.mu.Unlock()
+	if ok {
+		return token, nil
+	}
+
+	contract, err := NewVaultContract(st.client, vault.Hex())
+	if err != nil {
+		return Token{}, err
+	}
+	token, err = contract.StakeToken(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	st.mu.Lock()
+	st.byVault[vault] = token
+	st.mu.Unlock()
+	return token, nil
+}
+
+// AllowanceAndBalance reads how much of token spender may pull from owner and owner's
+// balance of token, in a single batched RPC request
+func AllowanceAndBalance(ctx context.Context, client Caller, token Token, owner, spender common.Address) (allowance, balance *big.Int, err error) {
+	if err := token.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	allowanceData, err := erc20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pack allowance call data: %w", err)
+	}
+	balanceData, err := erc20ABI.Pack("balanceOf", owner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pack balanceOf call data: %w", err)
+	}
+
+	to := common.HexToAddress(token.Address)
+	results, err := callBatch(ctx, client, []Call{
+		{Target: to, CallData: allowanceData},
+		{Target: to, CallData: balanceData},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to call allowance and balanceOf: %w", err)
+	}
+
+	methods := [2]string{"allowance", "balanceOf"}
+	var values [2]*big.Int
+	for i, method := range methods {
+		if results[i].Err != nil {
+			return nil, nil, fmt.Errorf("%s failed: %w", method, results[i].Err)
+		}
+		if len(results[i].ReturnData) == 0 {
+			return nil, nil, errors.New("no ERC-20 contract at address")
+		}
+		if err := erc20ABI.UnpackIntoInterface(&values[i], method, results[i].ReturnData); err != nil {
+			return nil, nil, fmt.Errorf("failed to unpack %s result: %w", method, err)
+		}
+	}
+
+	return values[0], values[1], nil
+}
@@ -0,0 +1,314 @@
+package contracts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"atfi-backend/units"
+)
+
+// Caps a Transactor prices its transactions within unless configured otherwise
+var DefaultGasCaps = GasCaps{
+	MaxFeePerGas:         big.NewInt(50_000_000_000), // 50 gwei
+	MaxPriorityFeePerGas: big.NewInt(2_000_000_000),  // 2 gwei
+	GasLimit:             5_000_000,
+	GasMarginPercent:     20,
+}
+
+// ErrGasCapExceeded is returned when a transaction cannot be priced within its caps
+var ErrGasCapExceeded = errors.New("gas cap exceeded")
+
+// GasCaps bound what a Transactor is willing to pay. Fee suggestions above a cap are
+// lowered to it; a transaction that would still not be mined, or would need more than
+// GasLimit gas, is refused with ErrGasCapExceeded.
+type GasCaps struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	GasLimit             uint64
+	// GasMarginPercent is added to the estimate, so state changing between the estimate
+	// and inclusion does not run the transaction out of gas
+	GasMarginPercent uint64
+}
+
+// GasCapsFromEnv reads prefix_MAX_FEE_GWEI, prefix_MAX_PRIORITY_FEE_GWEI and
+// prefix_GAS_LIMIT, keeping DefaultGasCaps for the ones that are unset
+func GasCapsFromEnv(prefix string) (GasCaps, error) {
+	caps := DefaultGasCaps
+
+	for _, fee := range []struct {
+		name   string
+		target **big.Int
+	}{
+		{prefix + "_MAX_FEE_GWEI", &caps.MaxFeePerGas},
+		{prefix + "_MAX_PRIORITY_FEE_GWEI", &caps.MaxPriorityFeePerGas},
+	} {
+		raw := strings.TrimSpace(os.Getenv(fee.name))
+		if raw == "" {
+			continue
+		}
+		wei, err := parseGwei(raw)
+		if err != nil {
+			return GasCaps{}, fmt.Errorf("invalid %s %q: %w", fee.name, raw, err)
+		}
+		*fee.target = wei
+	}
+
+	if raw := strings.TrimSpace(os.Getenv(prefix + "_GAS_LIMIT")); raw != "" {
+		limit, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || limit == 0 {
+			return GasCaps{}, fmt.Errorf("invalid %s_GAS_LIMIT %q", prefix, raw)
+		}
+		caps.GasLimit = limit
+	}
+
+	if caps.MaxPriorityFeePerGas.Cmp(caps.MaxFeePerGas) > 0 {
+		return GasCaps{}, fmt.Errorf("%s_MAX_PRIORITY_FEE_GWEI is above %s_MAX_FEE_GWEI", prefix, prefix)
+	}
+	return caps, nil
+}
+
+// parseGwei converts a positive decimal amount of gwei to wei, refusing fractions of a wei
+func parseGwei(raw string) (*big.Int, error) {
+	wei, err := units.ParseUnits(raw, 9)
+	if err != nil {
+		return nil, err
+	}
+	if wei.Sign() <= 0 {
+		return nil, errors.New("not a positive number")
+	}
+	return wei, nil
+}
+
+// Percentage by which a replacement raises both fees; nodes refuse replacements that
+// raise them by less than 10%
+const replacementFeeBumpPercent = 12
+
+// TxSender is the part of an Ethereum client a Transactor needs
+type TxSender interface {
+	GasReader
+	NonceSource
+}
+
+var _ TxSender = (*ethclient.Client)(nil)
+
+// Transactor signs and sends EIP-1559 transactions from one key. Its nonces come from a
+// NonceManager, so transactions sent in quick succession do not reuse the node's pending
+// nonce.
+type Transactor struct {
+	client  TxSender
+	key     *ecdsa.PrivateKey
+	address common.Address
+	chainID *big.Int
+	gas     *GasOracle
+
+	// Caps bound the fees and gas of every transaction
+	Caps GasCaps
+	// Nonces hands out the nonces of the transactor's address. Transactors sharing a key
+	// must share it.
+	Nonces *NonceManager
+}
+
+// NewTransactor creates a transactor sending from key on chainID within DefaultGasCaps,
+// with a NonceManager of its own
+func NewTransactor(client TxSender, key *ecdsa.PrivateKey, chainID *big.Int) *Transactor {
+	return &Transactor{
+		client:  client,
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		chainID: chainID,
+		gas:     NewGasOracle(client),
+		Caps:    DefaultGasCaps,
+		Nonces:  NewNonceManager(client),
+	}
+}
+
+// NewSettlementTransactorFromEnv loads the settlement key from SETTLEMENT_PRIVATE_KEY, its
+// caps from the SETTLEMENT_ gas variables and the chain ID and pending nonce from the RPC
+// node. It returns nil when no key is configured.
+func NewSettlementTransactorFromEnv(ctx context.Context, client interface {
+	TxSender
+	ChainID(ctx context.Context) (*big.Int, error)
+}) (*Transactor, error) {
+	hexKey := strings.TrimPrefix(strings.TrimSpace(os.Getenv("SETTLEMENT_PRIVATE_KEY")), "0x")
+	if hexKey == "" {
+		return nil, nil
+	}
+
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SETTLEMENT_PRIVATE_KEY: %w", err)
+	}
+
+	caps, err := GasCapsFromEnv("SETTLEMENT")
+	if err != nil {
+		return nil, err
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	t := NewTransactor(client, key, chainID)
+	t.Caps = caps
+	if _, err := t.Nonces.Next(ctx, t.address); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Address is the account the transactor sends from
+func (t *Transactor) Address() common.Address {
+	return t.address
+}
+
+// ChainID is the chain the transactor signs for
+func (t *Transactor) ChainID() *big.Int {
+	return new(big.Int).Set(t.chainID)
+}
+
+// Build prices a call of data on to and returns it unsigned, with the nonce it would be
+// sent with now. A call that would revert fails with an error RevertReason can explain.
+func (t *Transactor) Build(ctx context.Context, to common.Address, data []byte) (*types.Transaction, error) {
+	nonce, err := t.Nonces.Next(ctx, t.address)
+	if err != nil {
+		return nil, err
+	}
+	return t.build(ctx, to, data, nonce)
+}
+
+// Send builds, signs and broadcasts a call of data on to through the NonceManager
+func (t *Transactor) Send(ctx context.Context, to common.Address, data []byte) (*types.Transaction, error) {
+	return t.Nonces.Send(ctx, t.address, func(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+		tx, err := t.build(ctx, to, data, nonce)
+		if err != nil {
+			return nil, err
+		}
+		return t.sign(tx)
+	})
+}
+
+// ReplaceStuck re-sends the transaction hash with the same nonce and fees raised by
+// replacementFeeBumpPercent, or to the current suggestion when that is higher, once it
+// has been pending for longer than after. It returns ErrNotStuck when the transaction is
+// not due, and ErrGasCapExceeded when the raised fees would be above the caps.
+func (t *Transactor) ReplaceStuck(ctx context.Context, hash common.Hash, after time.Duration) (*types.Transaction, error) {
+	return t.Nonces.Replace(ctx, t.address, hash, after, func(ctx context.Context, stuck *types.Transaction) (*types.Transaction, error) {
+		price, err := t.gas.Price(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tip := bumpFee(stuck.GasTipCap(), price.MaxPriorityFeePerGas)
+		maxFee := bumpFee(stuck.GasFeeCap(), price.MaxFeePerGas)
+		if t.Caps.MaxPriorityFeePerGas != nil && tip.Cmp(t.Caps.MaxPriorityFeePerGas) > 0 ||
+			t.Caps.MaxFeePerGas != nil && maxFee.Cmp(t.Caps.MaxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w: replacing needs %s wei max fee and %s wei tip", ErrGasCapExceeded, maxFee, tip)
+		}
+
+		return t.sign(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     stuck.Nonce(),
+			GasTipCap: tip,
+			GasFeeCap: maxFee,
+			Gas:       stuck.Gas(),
+			To:        stuck.To(),
+			Value:     stuck.Value(),
+			Data:      stuck.Data(),
+		}))
+	})
+}
+
+// Forget stops tracking the transaction hash for replacement, once it was mined or given
+// up on
+func (t *Transactor) Forget(hash common.Hash) {
+	t.Nonces.Forget(t.address, hash)
+}
+
+// bumpFee raises fee by replacementFeeBumpPercent, rounding up, or to suggested when
+// that is higher
+func bumpFee(fee, suggested *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementFeeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if suggested != nil && suggested.Cmp(bumped) > 0 {
+		bumped.Set(suggested)
+	}
+	return bumped
+}
+
+func (t *Transactor) sign(tx *types.Transaction) (*types.Transaction, error) {
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(t.chainID), t.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// build prices the transaction within the caps
+func (t *Transactor) build(ctx context.Context, to common.Address, data []byte, nonce uint64) (*types.Transaction, error) {
+	estimate, err := EstimateGas(ctx, t.client, ethereum.CallMsg{From: t.address, To: &to, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	gas := estimate + estimate*t.Caps.GasMarginPercent/100
+	if t.Caps.GasLimit > 0 && gas > t.Caps.GasLimit {
+		if estimate > t.Caps.GasLimit {
+			return nil, fmt.Errorf("%w: needs %d gas, the limit is %d", ErrGasCapExceeded, estimate, t.Caps.GasLimit)
+		}
+		gas = t.Caps.GasLimit
+	}
+
+	price, err := t.gas.Price(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tip, maxFee, err := t.capFees(price)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   t.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: maxFee,
+		Gas:       gas,
+		To:        &to,
+		Data:      data,
+	}), nil
+}
+
+// capFees lowers the suggested fees to the caps, refusing when the current base fee alone
+// is above the max fee cap
+func (t *Transactor) capFees(price GasPrice) (tip, maxFee *big.Int, err error) {
+	tip = new(big.Int).Set(price.MaxPriorityFeePerGas)
+	maxFee = new(big.Int).Set(price.MaxFeePerGas)
+	if t.Caps.MaxPriorityFeePerGas != nil && tip.Cmp(t.Caps.MaxPriorityFeePerGas) > 0 {
+		tip.Set(t.Caps.MaxPriorityFeePerGas)
+	}
+	if t.Caps.MaxFeePerGas != nil && maxFee.Cmp(t.Caps.MaxFeePerGas) > 0 {
+		maxFee.Set(t.Caps.MaxFeePerGas)
+	}
+
+	baseFee := new(big.Int).Sub(price.GasPrice, price.MaxPriorityFeePerGas)
+	if maxFee.Cmp(baseFee) < 0 {
+		return nil, nil, fmt.Errorf("%w: base fee %s wei is above the max fee of %s wei", ErrGasCapExceeded, baseFee, maxFee)
+	}
+	if tip.Cmp(maxFee) > 0 {
+		tip.Set(maxFee)
+	}
+	return tip, maxFee, nil
+}
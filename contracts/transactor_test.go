@@ -0,0 +1,186 @@
+package contracts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func newTestTransactor(t *testing.T, client *contractstest.Client) *contracts.Transactor {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return contracts.NewTransactor(client, key, big.NewInt(84532))
+}
+
+func TestTransactorCapsFees(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetGas(100_000)
+	// Base fee 1000 and tip 500: the suggested max fee is 2500
+	client.SetGasPrice(big.NewInt(1500), big.NewInt(500))
+	transactor := newTestTransactor(t, client)
+	transactor.Caps = contracts.GasCaps{
+		MaxFeePerGas:         big.NewInt(2000),
+		MaxPriorityFeePerGas: big.NewInt(200),
+		GasLimit:             115_000,
+		GasMarginPercent:     20,
+	}
+
+	tx, err := transactor.Build(context.Background(), vault, []byte{0x12})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if tx.GasFeeCap().Int64() != 2000 || tx.GasTipCap().Int64() != 200 {
+		t.Errorf("fees = %s max, %s tip; want the caps 2000 and 200", tx.GasFeeCap(), tx.GasTipCap())
+	}
+	// The 20% margin is cut back to the limit
+	if tx.Gas() != 115_000 {
+		t.Errorf("gas = %d, want the 115000 limit", tx.Gas())
+	}
+
+	// A base fee above the max fee cap would never be mined
+	client.SetGasPrice(big.NewInt(2600), big.NewInt(500))
+	transactor = newTestTransactor(t, client)
+	transactor.Caps.MaxFeePerGas = big.NewInt(2000)
+	if _, err := transactor.Build(context.Background(), vault, []byte{0x12}); !errors.Is(err, contracts.ErrGasCapExceeded) {
+		t.Errorf("Build with the base fee over the cap: %v, want ErrGasCapExceeded", err)
+	}
+
+	client.SetGas(6_000_000)
+	transactor = newTestTransactor(t, client)
+	if _, err := transactor.Build(context.Background(), vault, []byte{0x12}); !errors.Is(err, contracts.ErrGasCapExceeded) {
+		t.Errorf("Build over the gas limit: %v, want ErrGasCapExceeded", err)
+	}
+}
+
+func TestTransactorManagesNonces(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	transactor := newTestTransactor(t, client)
+	client.SetNonce(transactor.Address(), 7)
+
+	for want := uint64(7); want < 9; want++ {
+		tx, err := transactor.Send(context.Background(), vault, []byte{0x12})
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		if tx.Nonce() != want {
+			t.Errorf("nonce = %d, want %d", tx.Nonce(), want)
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(84532)), tx)
+		if err != nil || from != transactor.Address() {
+			t.Errorf("signed by %s (%v), want %s", from.Hex(), err, transactor.Address().Hex())
+		}
+	}
+
+	// Another sender using the key makes the local nonce stale: the node rejects it, and
+	// the send is built again with the nonce fetched afresh
+	client.SetNonce(transactor.Address(), 12)
+	tx, err := transactor.Send(context.Background(), vault, []byte{0x12})
+	if err != nil {
+		t.Fatalf("Send with a stale nonce: %v", err)
+	}
+	if tx.Nonce() != 12 {
+		t.Errorf("nonce = %d, want 12", tx.Nonce())
+	}
+
+	if sent := client.Sent(); len(sent) != 3 {
+		t.Errorf("sent %d transactions, want 3", len(sent))
+	}
+}
+
+func TestTransactorReplacesStuckTransactions(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetGasPrice(big.NewInt(1500), big.NewInt(500))
+	transactor := newTestTransactor(t, client)
+
+	stuck, err := transactor.Send(context.Background(), vault, []byte{0x12})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if _, err := transactor.ReplaceStuck(context.Background(), stuck.Hash(), time.Hour); !errors.Is(err, contracts.ErrNotStuck) {
+		t.Errorf("ReplaceStuck before the timeout: %v, want ErrNotStuck", err)
+	}
+
+	replacement, err := transactor.ReplaceStuck(context.Background(), stuck.Hash(), 0)
+	if err != nil {
+		t.Fatalf("ReplaceStuck: %v", err)
+	}
+	if replacement.Nonce() != stuck.Nonce() || replacement.Hash() == stuck.Hash() {
+		t.Errorf("replacement has nonce %d and hash %s; want nonce %d and a new hash", replacement.Nonce(), replacement.Hash().Hex(), stuck.Nonce())
+	}
+	// 2500 and 500 raised by 12%
+	if replacement.GasFeeCap().Int64() != 2800 || replacement.GasTipCap().Int64() != 560 {
+		t.Errorf("replacement fees = %s max, %s tip; want 2800 and 560", replacement.GasFeeCap(), replacement.GasTipCap())
+	}
+
+	// The next send is unaffected by the replacement
+	next, err := transactor.Send(context.Background(), vault, []byte{0x12})
+	if err != nil || next.Nonce() != stuck.Nonce()+1 {
+		t.Errorf("Send after the replacement: nonce %v, %v; want %d", next, err, stuck.Nonce()+1)
+	}
+
+	// Once its nonce is mined the replacement is no longer stuck
+	client.AddTransaction(replacement, &types.Receipt{Status: types.ReceiptStatusSuccessful})
+	if _, err := transactor.ReplaceStuck(context.Background(), replacement.Hash(), 0); !errors.Is(err, contracts.ErrNotStuck) {
+		t.Errorf("ReplaceStuck of a mined transaction: %v, want ErrNotStuck", err)
+	}
+
+	// Fees cannot be raised past the caps
+	transactor.Caps.MaxFeePerGas = big.NewInt(2600)
+	if _, err := transactor.ReplaceStuck(context.Background(), next.Hash(), 0); !errors.Is(err, contracts.ErrGasCapExceeded) {
+		t.Errorf("ReplaceStuck over the caps: %v, want ErrGasCapExceeded", err)
+	}
+}
+
+func TestTransactorDoesNotSendReverts(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	transactor := newTestTransactor(t, client)
+	client.Fail(vault, []byte{0x12}, contractstest.Revert("Already settled"))
+
+	_, err := transactor.Send(context.Background(), vault, []byte{0x12})
+	if reason, ok := contracts.RevertReason(err); !ok || reason != "Already settled" {
+		t.Errorf("RevertReason = %q, %t; want the decoded reason", reason, ok)
+	}
+	if sent := client.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d transactions, want none", len(sent))
+	}
+}
+
+func TestGasCapsFromEnv(t *testing.T) {
+	t.Setenv("TEST_MAX_FEE_GWEI", "0.5")
+	t.Setenv("TEST_MAX_PRIORITY_FEE_GWEI", "0.01")
+	t.Setenv("TEST_GAS_LIMIT", "")
+
+	caps, err := contracts.GasCapsFromEnv("TEST")
+	if err != nil {
+		t.Fatalf("GasCapsFromEnv: %v", err)
+	}
+	if caps.MaxFeePerGas.Int64() != 500_000_000 || caps.MaxPriorityFeePerGas.Int64() != 10_000_000 {
+		t.Errorf("caps = %s max, %s tip; want 0.5 and 0.01 gwei", caps.MaxFeePerGas, caps.MaxPriorityFeePerGas)
+	}
+	if caps.GasLimit != contracts.DefaultGasCaps.GasLimit {
+		t.Errorf("gas limit = %d, want the default", caps.GasLimit)
+	}
+
+	// The default priority fee cap is above this max fee cap
+	t.Setenv("TEST_MAX_PRIORITY_FEE_GWEI", "")
+	if _, err := contracts.GasCapsFromEnv("TEST"); err == nil {
+		t.Error("GasCapsFromEnv accepted a priority fee cap above the max fee cap")
+	}
+
+	t.Setenv("TEST_MAX_PRIORITY_FEE_GWEI", "0.01")
+	t.Setenv("TEST_GAS_LIMIT", "lots")
+	if _, err := contracts.GasCapsFromEnv("TEST"); err == nil {
+		t.Error("GasCapsFromEnv accepted an invalid gas limit")
+	}
+}
@@ -4,71 +4,195 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"strings"
 
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+
+	"atfi-backend/contracts/gen"
 )
 
-// VaultContract wraps the VaultATFi smart contract interactions
+// WithdrawFunction is the vault function a participant calls to pull their stake
+// back out before the registration deadline
+const WithdrawFunction = "withdraw"
+
+// DepositFunction is the vault function a participant calls to stake and register, once
+// the vault may pull the stake token
+const DepositFunction = "deposit"
+
+// ClaimFunction is the vault function an attendee calls to collect their reward
+const ClaimFunction = "claim"
+
+// PackVaultCall ABI-encodes a call to the vault function method
+func PackVaultCall(method string, args ...interface{}) ([]byte, error) {
+	data, err := vaultABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s call data: %w", method, err)
+	}
+	return data, nil
+}
+
+// VaultContract wraps the VaultATFi smart contract interactions. It reads through the
+// generated binding in contracts/gen.
 type VaultContract struct {
-	client   *ethclient.Client
-	address  common.Address
-	abi      abi.ABI
+	address common.Address
+	client  bind.ContractCaller
+	caller  *gen.VaultCaller
 }
 
-// NewVaultContract creates a new VaultContract instance
-func NewVaultContract(client *ethclient.Client, address string) (*VaultContract, error) {
-	// VaultATFi ABI - only the functions we need
-	vaultABI := `[{"inputs":[],"name":"getParticipantCount","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+// NewVaultContract creates a new VaultContract instance. client is usually an
+// *ethclient.Client; transient failures are retried under DefaultRetryPolicy.
+func NewVaultContract(client bind.ContractCaller, address string) (*VaultContract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid vault address %q", address)
+	}
 
-	parsedABI, err := abi.JSON(strings.NewReader(vaultABI))
+	vaultAddress := common.HexToAddress(address)
+	caller, err := gen.NewVaultCaller(vaultAddress, retryingCaller{client})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse vault ABI: %w", err)
+		return nil, fmt.Errorf("failed to bind vault contract: %w", err)
 	}
 
 	return &VaultContract{
+		address: vaultAddress,
 		client:  client,
-		address: common.HexToAddress(address),
-		abi:     parsedABI,
+		caller:  caller,
 	}, nil
 }
 
+// Address is the vault's contract address
+func (vc *VaultContract) Address() common.Address {
+	return vc.address
+}
+
+// callOpts reads at the latest block under ctx
+func callOpts(ctx context.Context) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx}
+}
+
+// vaultCall runs one contract read under CallTimeout, naming the function in its error
+func vaultCall[T any](ctx context.Context, method string, call func(opts *bind.CallOpts) (T, error)) (T, error) {
+	var value T
+	err := withCallTimeout(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = call(callOpts(ctx))
+		return err
+	})
+	if err != nil {
+		return value, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	return value, nil
+}
+
 // GetParticipantCount calls the getParticipantCount() function on the vault contract
 func (vc *VaultContract) GetParticipantCount(ctx context.Context) (*big.Int, error) {
-	callData, err := vc.abi.Pack("getParticipantCount")
-	if err != nil {
-		return nil, fmt.Errorf("failed to pack call data: %w", err)
+	return vc.ParticipantCountAt(ctx, nil)
+}
+
+// ParticipantCountAt reads getParticipantCount() at block, or at the latest block when
+// block is nil. Past blocks need a node that kept their state: a pruned one answers
+// ErrArchiveUnavailable.
+func (vc *VaultContract) ParticipantCountAt(ctx context.Context, block *big.Int) (*big.Int, error) {
+	count, err := vaultCall(ctx, "getParticipantCount", func(opts *bind.CallOpts) (*big.Int, error) {
+		opts.BlockNumber = block
+		return vc.caller.GetParticipantCount(opts)
+	})
+	if err != nil && block != nil && isMissingState(err) {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveUnavailable, err)
 	}
+	return count, err
+}
+
+// Organizer returns the address that created the event
+func (vc *VaultContract) Organizer(ctx context.Context) (common.Address, error) {
+	return vaultCall(ctx, "organizer", vc.caller.Organizer)
+}
+
+// StakeAmount returns the stake each participant deposits, in token base units
+func (vc *VaultContract) StakeAmount(ctx context.Context) (*big.Int, error) {
+	return vaultCall(ctx, "stakeAmount", vc.caller.StakeAmount)
+}
+
+// EventDate returns the event start as a Unix timestamp
+func (vc *VaultContract) EventDate(ctx context.Context) (*big.Int, error) {
+	return vaultCall(ctx, "eventDate", vc.caller.EventDate)
+}
+
+// RegistrationDeadline returns the last moment to register as a Unix timestamp
+func (vc *VaultContract) RegistrationDeadline(ctx context.Context) (*big.Int, error) {
+	return vaultCall(ctx, "registrationDeadline", vc.caller.RegistrationDeadline)
+}
+
+// MaxParticipants returns the participant cap
+func (vc *VaultContract) MaxParticipants(ctx context.Context) (*big.Int, error) {
+	return vaultCall(ctx, "maxParticipants", vc.caller.MaxParticipants)
+}
+
+// IsSettled reports whether the organizer has settled the event
+func (vc *VaultContract) IsSettled(ctx context.Context) (bool, error) {
+	return vaultCall(ctx, "isSettled", vc.caller.IsSettled)
+}
+
+// IsParticipant reports whether participant has staked in the vault
+func (vc *VaultContract) IsParticipant(ctx context.Context, participant common.Address) (bool, error) {
+	return vaultCall(ctx, "isParticipant", func(opts *bind.CallOpts) (bool, error) {
+		return vc.caller.IsParticipant(opts, participant)
+	})
+}
+
+// HasCheckedIn reports whether participant was marked as attended
+func (vc *VaultContract) HasCheckedIn(ctx context.Context, participant common.Address) (bool, error) {
+	return vaultCall(ctx, "hasCheckedIn", func(opts *bind.CallOpts) (bool, error) {
+		return vc.caller.HasCheckedIn(opts, participant)
+	})
+}
+
+// Token returns the ERC-20 the vault takes stakes in
+func (vc *VaultContract) Token(ctx context.Context) (common.Address, error) {
+	return vaultCall(ctx, "token", vc.caller.Token)
+}
 
-	result, err := vc.client.CallContract(ctx, ethereum.CallMsg{
-		To:   &vc.address,
-		Data: callData,
-	}, nil)
+// StakeToken reads the vault's token and that token's symbol and decimals. Use a
+// StakeTokens to avoid reading them again for every request.
+func (vc *VaultContract) StakeToken(ctx context.Context) (Token, error) {
+	address, err := vc.Token(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call getParticipantCount: %w", err)
+		return Token{}, err
 	}
-
-	var participantCount *big.Int
-	err = vc.abi.UnpackIntoInterface(&participantCount, "getParticipantCount", result)
+	token, err := TokenMetadata(ctx, vc.client, address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unpack result: %w", err)
+		return Token{}, fmt.Errorf("failed to read stake token %s: %w", address.Hex(), err)
 	}
+	return token, nil
+}
+
+// TotalAssets returns what the vault holds in stake token base units, including the
+// yield its deposits have earned
+func (vc *VaultContract) TotalAssets(ctx context.Context) (*big.Int, error) {
+	return vaultCall(ctx, "totalAssets", vc.caller.TotalAssets)
+}
+
+// HasClaimed calls the hasClaimed(address) function on the vault contract
+func (vc *VaultContract) HasClaimed(ctx context.Context, participant common.Address) (bool, error) {
+	return vaultCall(ctx, "hasClaimed", func(opts *bind.CallOpts) (bool, error) {
+		return vc.caller.HasClaimed(opts, participant)
+	})
+}
 
-	return participantCount, nil
+// ClaimableAmount returns what participant would receive by claiming now, in stake token
+// base units. It is zero before settlement, for no-shows and once claimed.
+func (vc *VaultContract) ClaimableAmount(ctx context.Context, participant common.Address) (*big.Int, error) {
+	return vaultCall(ctx, "claimableAmount", func(opts *bind.CallOpts) (*big.Int, error) {
+		return vc.caller.ClaimableAmount(opts, participant)
+	})
 }
 
-// GetEventDetails calls multiple view functions to get event details
-func (vc *VaultContract) GetEventDetails(ctx context.Context) (map[string]interface{}, error) {
-	// This can be extended to call other view functions like eventId, organizer, etc.
-	participantCount, err := vc.GetParticipantCount(ctx)
+// State reads the vault's full on-chain snapshot in one Multicall3 request. Fields that
+// fail to load are listed in VaultState.Failed; the error is only set when the request as
+// a whole fails.
+func (vc *VaultContract) State(ctx context.Context) (*VaultState, error) {
+	states, err := NewMulticaller(vc.client).VaultStates(ctx, []common.Address{vc.address})
 	if err != nil {
 		return nil, err
 	}
-
-	return map[string]interface{}{
-		"participant_count": participantCount,
-	}, nil
-}
\ No newline at end of file
+	return states[0], nil
+}
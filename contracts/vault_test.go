@@ -0,0 +1,152 @@
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeCaller records the last call and answers with a fixed result. Every address has code.
+type fakeCaller struct {
+	call   ethereum.CallMsg
+	result []byte
+	err    error
+}
+
+func (f *fakeCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (f *fakeCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.call = call
+	return f.result, f.err
+}
+
+// word is a 32-byte ABI word holding v
+func word(v int64) []byte {
+	return common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+func TestVaultAddressMethodsEncodeCallData(t *testing.T) {
+	vaultAddress := "0x1111111111111111111111111111111111111111"
+	participant := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	tests := []struct {
+		signature string
+		call      func(*VaultContract) (bool, error)
+	}{
+		{"isParticipant(address)", func(vc *VaultContract) (bool, error) { return vc.IsParticipant(context.Background(), participant) }},
+		{"hasCheckedIn(address)", func(vc *VaultContract) (bool, error) { return vc.HasCheckedIn(context.Background(), participant) }},
+		{"hasClaimed(address)", func(vc *VaultContract) (bool, error) { return vc.HasClaimed(context.Background(), participant) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			caller := &fakeCaller{result: word(1)}
+			vc, err := NewVaultContract(caller, vaultAddress)
+			if err != nil {
+				t.Fatalf("NewVaultContract: %v", err)
+			}
+
+			got, err := tt.call(vc)
+			if err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			if !got {
+				t.Errorf("got false, want true")
+			}
+
+			want := append(crypto.Keccak256([]byte(tt.signature))[:4], common.LeftPadBytes(participant.Bytes(), 32)...)
+			if !bytes.Equal(caller.call.Data, want) {
+				t.Errorf("call data = %s, want %s", hexutil.Encode(caller.call.Data), hexutil.Encode(want))
+			}
+			if caller.call.To == nil || *caller.call.To != common.HexToAddress(vaultAddress) {
+				t.Errorf("call sent to %v, want %s", caller.call.To, vaultAddress)
+			}
+		})
+	}
+}
+
+func TestVaultViewsUnpackResults(t *testing.T) {
+	organizer := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	caller := &fakeCaller{}
+	vc, err := NewVaultContract(caller, "0x1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatalf("NewVaultContract: %v", err)
+	}
+
+	caller.result = common.LeftPadBytes(organizer.Bytes(), 32)
+	if got, err := vc.Organizer(context.Background()); err != nil || got != organizer {
+		t.Errorf("Organizer() = %s, %v, want %s", got.Hex(), err, organizer.Hex())
+	}
+	if want := crypto.Keccak256([]byte("organizer()"))[:4]; !bytes.Equal(caller.call.Data, want) {
+		t.Errorf("organizer call data = %s, want %s", hexutil.Encode(caller.call.Data), hexutil.Encode(want))
+	}
+
+	caller.result = word(10_000_000)
+	if got, err := vc.StakeAmount(context.Background()); err != nil || got.Int64() != 10_000_000 {
+		t.Errorf("StakeAmount() = %v, %v, want 10000000", got, err)
+	}
+
+	caller.result = word(0)
+	if got, err := vc.IsSettled(context.Background()); err != nil || got {
+		t.Errorf("IsSettled() = %v, %v, want false", got, err)
+	}
+}
+
+func TestVaultCallErrors(t *testing.T) {
+	vc, err := NewVaultContract(&fakeCaller{}, "0x1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatalf("NewVaultContract: %v", err)
+	}
+	if _, err := vc.MaxParticipants(context.Background()); err == nil {
+		t.Error("MaxParticipants() succeeded against an address without code")
+	}
+
+	rpcErr := errors.New("rpc down")
+	vc, _ = NewVaultContract(&fakeCaller{err: rpcErr}, "0x1111111111111111111111111111111111111111")
+	if _, err := vc.EventDate(context.Background()); !errors.Is(err, rpcErr) {
+		t.Errorf("EventDate() error = %v, want wrapped %v", err, rpcErr)
+	}
+
+	if _, err := NewVaultContract(&fakeCaller{}, "not-an-address"); err == nil {
+		t.Error("NewVaultContract accepted an invalid address")
+	}
+}
+
+// hangingCaller blocks every call until ctx is done
+type hangingCaller struct{ fakeCaller }
+
+func (h *hangingCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestVaultCallTimeout(t *testing.T) {
+	t.Setenv("CONTRACT_CALL_TIMEOUT", "50ms")
+	vc, _ := NewVaultContract(&hangingCaller{}, "0x1111111111111111111111111111111111111111")
+
+	start := time.Now()
+	_, err := vc.GetParticipantCount(context.Background())
+	if !IsCallTimeout(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetParticipantCount() error = %v, want a CallTimeoutError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetParticipantCount() took %s with a 50ms timeout", elapsed)
+	}
+
+	// A cancelled request is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := vc.GetParticipantCount(ctx); IsCallTimeout(err) || !errors.Is(err, context.Canceled) {
+		t.Errorf("GetParticipantCount() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,193 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"atfi-backend/contracts/gen"
+)
+
+// vaultLogs decodes vault logs with the generated binding; it never talks to a node
+var vaultLogs = mustVaultFilterer()
+
+func mustVaultFilterer() *gen.VaultFilterer {
+	filterer, err := gen.NewVaultFilterer(common.Address{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return filterer
+}
+
+// LogMeta locates a decoded log on-chain
+type LogMeta struct {
+	Address     common.Address
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	Index       uint
+}
+
+// Meta returns where the event was emitted
+func (m LogMeta) Meta() LogMeta {
+	return m
+}
+
+func logMeta(vLog types.Log) LogMeta {
+	return LogMeta{Address: vLog.Address, BlockNumber: vLog.BlockNumber, BlockHash: vLog.BlockHash, TxHash: vLog.TxHash, Index: vLog.Index}
+}
+
+// VaultEvent is any decoded vault event. Switch on the concrete type to handle one.
+type VaultEvent interface {
+	Meta() LogMeta
+}
+
+// RegisteredEvent is emitted when a participant stakes into the vault
+type RegisteredEvent struct {
+	LogMeta
+	Participant common.Address
+	Amount      *big.Int
+}
+
+// CheckedInEvent is emitted when a participant is marked as attended
+type CheckedInEvent struct {
+	LogMeta
+	Participant common.Address
+}
+
+// SettledEvent is emitted once when the organizer settles the event
+type SettledEvent struct {
+	LogMeta
+	AttendedCount     *big.Int
+	NoShowCount       *big.Int
+	RewardPerAttendee *big.Int
+}
+
+// ClaimedEvent is emitted when an attendee claims their stake and reward
+type ClaimedEvent struct {
+	LogMeta
+	Participant common.Address
+	Amount      *big.Int
+}
+
+// WithdrawnEvent is emitted when a participant pulls their stake back out
+type WithdrawnEvent struct {
+	LogMeta
+	Participant common.Address
+	Amount      *big.Int
+}
+
+// ParseRegistered decodes a Registered log
+func ParseRegistered(vLog types.Log) (RegisteredEvent, error) {
+	event, err := vaultLogs.ParseRegistered(vLog)
+	if err != nil {
+		return RegisteredEvent{}, fmt.Errorf("failed to parse Registered log: %w", err)
+	}
+	return RegisteredEvent{LogMeta: logMeta(vLog), Participant: event.Participant, Amount: event.Amount}, nil
+}
+
+// ParseCheckedIn decodes a CheckedIn log
+func ParseCheckedIn(vLog types.Log) (CheckedInEvent, error) {
+	event, err := vaultLogs.ParseCheckedIn(vLog)
+	if err != nil {
+		return CheckedInEvent{}, fmt.Errorf("failed to parse CheckedIn log: %w", err)
+	}
+	return CheckedInEvent{LogMeta: logMeta(vLog), Participant: event.Participant}, nil
+}
+
+// ParseSettled decodes a Settled log
+func ParseSettled(vLog types.Log) (SettledEvent, error) {
+	event, err := vaultLogs.ParseSettled(vLog)
+	if err != nil {
+		return SettledEvent{}, fmt.Errorf("failed to parse Settled log: %w", err)
+	}
+	return SettledEvent{
+		LogMeta:           logMeta(vLog),
+		AttendedCount:     event.AttendedCount,
+		NoShowCount:       event.NoShowCount,
+		RewardPerAttendee: event.RewardPerAttendee,
+	}, nil
+}
+
+// ParseClaimed decodes a Claimed log
+func ParseClaimed(vLog types.Log) (ClaimedEvent, error) {
+	event, err := vaultLogs.ParseClaimed(vLog)
+	if err != nil {
+		return ClaimedEvent{}, fmt.Errorf("failed to parse Claimed log: %w", err)
+	}
+	return ClaimedEvent{LogMeta: logMeta(vLog), Participant: event.Participant, Amount: event.Amount}, nil
+}
+
+// ParseWithdrawn decodes a Withdrawn log
+func ParseWithdrawn(vLog types.Log) (WithdrawnEvent, error) {
+	event, err := vaultLogs.ParseWithdrawn(vLog)
+	if err != nil {
+		return WithdrawnEvent{}, fmt.Errorf("failed to parse Withdrawn log: %w", err)
+	}
+	return WithdrawnEvent{LogMeta: logMeta(vLog), Participant: event.Participant, Amount: event.Amount}, nil
+}
+
+// ParseVaultLog decodes any vault event. ok is false for logs with an unknown topic, which
+// callers should skip.
+func ParseVaultLog(vLog types.Log) (event VaultEvent, ok bool, err error) {
+	if len(vLog.Topics) == 0 {
+		return nil, false, nil
+	}
+
+	switch vLog.Topics[0] {
+	case RegisteredEventTopic:
+		event, err = ParseRegistered(vLog)
+	case CheckedInEventTopic:
+		event, err = ParseCheckedIn(vLog)
+	case SettledEventTopic:
+		event, err = ParseSettled(vLog)
+	case ClaimedEventTopic:
+		event, err = ParseClaimed(vLog)
+	case WithdrawnEventTopic:
+		event, err = ParseWithdrawn(vLog)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return event, true, nil
+}
+
+// FilterVaultEvents returns the decoded events vault emitted in [fromBlock, toBlock], in
+// chain order. Logs are fetched in DefaultLogChunkSize windows; removed logs and unknown
+// topics are skipped.
+func FilterVaultEvents(ctx context.Context, client ethereum.LogFilterer, vault common.Address, fromBlock, toBlock uint64) ([]VaultEvent, error) {
+	query := ethereum.FilterQuery{Addresses: []common.Address{vault}}
+	logs, err := FilterLogsChunked(ctx, client, query, fromBlock, toBlock, DefaultLogChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	var events []VaultEvent
+	for _, vLog := range logs {
+		if vLog.Removed {
+			continue
+		}
+		event, ok, err := ParseVaultLog(vLog)
+		if err != nil {
+			return nil, fmt.Errorf("block %d log %d: %w", vLog.BlockNumber, vLog.Index, err)
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,110 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fixtureLogs loads eth_getLogs output recorded in testdata
+func fixtureLogs(t *testing.T) []types.Log {
+	t.Helper()
+	data, err := os.ReadFile("testdata/vault_logs.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var logs []types.Log
+	if err := json.Unmarshal(data, &logs); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+	return logs
+}
+
+// fakeLogFilterer answers each FilterLogs call with the logs inside its block range
+type fakeLogFilterer struct {
+	logs    []types.Log
+	queries int
+}
+
+func (f *fakeLogFilterer) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.queries++
+	var logs []types.Log
+	for _, vLog := range f.logs {
+		if vLog.BlockNumber >= q.FromBlock.Uint64() && vLog.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, vLog)
+		}
+	}
+	return logs, nil
+}
+
+func (f *fakeLogFilterer) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	panic("not used")
+}
+
+func TestFilterVaultEventsDecodesFixture(t *testing.T) {
+	logs := fixtureLogs(t)
+	removed := logs[0]
+	removed.Removed = true
+	removed.BlockNumber = 18200200
+	filterer := &fakeLogFilterer{logs: append(logs, removed)}
+	vault := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	attendee := common.HexToAddress("0x70997970C51812dc3A65C118b4E6F5E4d2C9f4A6")
+
+	events, err := FilterVaultEvents(context.Background(), filterer, vault, 18200000, 18270000)
+	if err != nil {
+		t.Fatalf("FilterVaultEvents: %v", err)
+	}
+	if filterer.queries != 8 {
+		t.Errorf("made %d FilterLogs calls, want 8 chunks", filterer.queries)
+	}
+
+	// The unknown topic and the removed log are skipped
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+
+	registered, ok := events[0].(RegisteredEvent)
+	if !ok || registered.Participant != attendee || registered.Amount.Cmp(big.NewInt(10_000_000)) != 0 {
+		t.Errorf("events[0] = %+v, want Registered by %s for 10 USDC", events[0], attendee.Hex())
+	}
+	if registered.Address != vault || registered.BlockNumber != 18200100 || registered.Index != 3 {
+		t.Errorf("events[0] meta = %+v", registered.LogMeta)
+	}
+
+	if _, ok := events[1].(RegisteredEvent); !ok {
+		t.Errorf("events[1] = %T, want RegisteredEvent", events[1])
+	}
+	if checkedIn, ok := events[2].(CheckedInEvent); !ok || checkedIn.Participant != attendee {
+		t.Errorf("events[2] = %+v, want CheckedIn by %s", events[2], attendee.Hex())
+	}
+
+	settled, ok := events[3].(SettledEvent)
+	if !ok {
+		t.Fatalf("events[3] = %T, want SettledEvent", events[3])
+	}
+	if settled.AttendedCount.Int64() != 1 || settled.NoShowCount.Int64() != 1 || settled.RewardPerAttendee.Int64() != 20_000_000 {
+		t.Errorf("settled = %+v", settled)
+	}
+
+	if claimed, ok := events[4].(ClaimedEvent); !ok || claimed.Amount.Int64() != 20_000_000 {
+		t.Errorf("events[4] = %+v, want Claimed of 20 USDC", events[4])
+	}
+}
+
+func TestParseRejectsOtherEvents(t *testing.T) {
+	logs := fixtureLogs(t)
+	settledLog := logs[4]
+
+	if _, err := ParseRegistered(settledLog); err == nil {
+		t.Error("ParseRegistered accepted a Settled log")
+	}
+	if _, ok, err := ParseVaultLog(logs[1]); ok || err != nil {
+		t.Errorf("unknown topic: ok = %v, err = %v, want skipped", ok, err)
+	}
+}
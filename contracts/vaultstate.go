@@ -0,0 +1,197 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VaultState is a vault's on-chain snapshot. A field is nil when it could not be read, and
+// Failed then names it, by its JSON name, with the reason. EventDate and
+// RegistrationDeadline are Unix timestamps.
+type VaultState struct {
+	Vault                common.Address
+	ParticipantCount     *big.Int
+	MaxParticipants      *big.Int
+	Organizer            *common.Address
+	StakeAmount          *big.Int
+	EventDate            *big.Int
+	RegistrationDeadline *big.Int
+	Settled              *bool
+	Token                *common.Address
+	Failed               map[string]string
+}
+
+// Complete reports whether every field was read
+func (s *VaultState) Complete() bool {
+	return len(s.Failed) == 0
+}
+
+// vaultStateFields are the view functions a VaultState is read from, by JSON field name
+var vaultStateFields = []struct{ field, method string }{
+	{"participant_count", "getParticipantCount"},
+	{"max_participants", "maxParticipants"},
+	{"organizer_address", "organizer"},
+	{"stake_amount", "stakeAmount"},
+	{"event_date", "eventDate"},
+	{"registration_deadline", "registrationDeadline"},
+	{"settled", "isSettled"},
+	{"token_address", "token"},
+}
+
+// VaultStates reads the snapshot of every vault through Multicall3. Results line up with
+// vaults. Fields that fail to load are flagged in each state's Failed; the returned error
+// is only set when a whole request fails.
+func (m *Multicaller) VaultStates(ctx context.Context, vaults []common.Address) ([]*VaultState, error) {
+	calls := make([]Call, 0, len(vaults)*len(vaultStateFields))
+	for _, vault := range vaults {
+		for _, f := range vaultStateFields {
+			callData, err := vaultABI.Pack(f.method)
+			if err != nil {
+				return nil, fmt.Errorf("failed to pack %s call data: %w", f.method, err)
+			}
+			calls = append(calls, Call{Target: vault, CallData: callData})
+		}
+	}
+
+	results, err := m.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]*VaultState, len(vaults))
+	for i, vault := range vaults {
+		state := &VaultState{Vault: vault}
+		for j, f := range vaultStateFields {
+			if err := state.set(f.method, results[i*len(vaultStateFields)+j]); err != nil {
+				if state.Failed == nil {
+					state.Failed = map[string]string{}
+				}
+				state.Failed[f.field] = err.Error()
+			}
+		}
+		states[i] = state
+	}
+	return states, nil
+}
+
+// set decodes one view call's result into the matching field
+func (s *VaultState) set(method string, result CallResult) error {
+	if result.Err != nil {
+		return result.Err
+	}
+	if len(result.ReturnData) == 0 {
+		return fmt.Errorf("%s returned no data", method)
+	}
+	values, err := vaultABI.Unpack(method, result.ReturnData)
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", method, err)
+	}
+
+	switch value := values[0].(type) {
+	case *big.Int:
+		switch method {
+		case "getParticipantCount":
+			s.ParticipantCount = value
+		case "maxParticipants":
+			s.MaxParticipants = value
+		case "stakeAmount":
+			s.StakeAmount = value
+		case "eventDate":
+			s.EventDate = value
+		case "registrationDeadline":
+			s.RegistrationDeadline = value
+		}
+	case common.Address:
+		switch method {
+		case "organizer":
+			s.Organizer = &value
+		case "token":
+			s.Token = &value
+		}
+	case bool:
+		s.Settled = &value
+	}
+	return nil
+}
+
+// vaultStateJSON is VaultState on the wire. Integers are decimal strings so they survive
+// JSON clients without precision loss; fields that failed to load are null.
+type vaultStateJSON struct {
+	Vault                common.Address    `json:"vault_address"`
+	ParticipantCount     *string           `json:"participant_count"`
+	MaxParticipants      *string           `json:"max_participants"`
+	Organizer            *common.Address   `json:"organizer_address"`
+	StakeAmount          *string           `json:"stake_amount"`
+	EventDate            *string           `json:"event_date"`
+	RegistrationDeadline *string           `json:"registration_deadline"`
+	Settled              *bool             `json:"settled"`
+	Token                *common.Address   `json:"token_address"`
+	Failed               map[string]string `json:"failed,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s VaultState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(vaultStateJSON{
+		Vault:                s.Vault,
+		ParticipantCount:     decimalString(s.ParticipantCount),
+		MaxParticipants:      decimalString(s.MaxParticipants),
+		Organizer:            s.Organizer,
+		StakeAmount:          decimalString(s.StakeAmount),
+		EventDate:            decimalString(s.EventDate),
+		RegistrationDeadline: decimalString(s.RegistrationDeadline),
+		Settled:              s.Settled,
+		Token:                s.Token,
+		Failed:               s.Failed,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *VaultState) UnmarshalJSON(data []byte) error {
+	var wire vaultStateJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	state := VaultState{
+		Vault:     wire.Vault,
+		Organizer: wire.Organizer,
+		Settled:   wire.Settled,
+		Token:     wire.Token,
+		Failed:    wire.Failed,
+	}
+	for _, field := range []struct {
+		name string
+		raw  *string
+		dst  **big.Int
+	}{
+		{"participant_count", wire.ParticipantCount, &state.ParticipantCount},
+		{"max_participants", wire.MaxParticipants, &state.MaxParticipants},
+		{"stake_amount", wire.StakeAmount, &state.StakeAmount},
+		{"event_date", wire.EventDate, &state.EventDate},
+		{"registration_deadline", wire.RegistrationDeadline, &state.RegistrationDeadline},
+	} {
+		if field.raw == nil {
+			continue
+		}
+		value, ok := new(big.Int).SetString(*field.raw, 10)
+		if !ok {
+			return fmt.Errorf("invalid %s %q", field.name, *field.raw)
+		}
+		*field.dst = value
+	}
+
+	*s = state
+	return nil
+}
+
+func decimalString(value *big.Int) *string {
+	if value == nil {
+		return nil
+	}
+	s := value.String()
+	return &s
+}
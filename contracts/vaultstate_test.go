@@ -0,0 +1,106 @@
+package contracts_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+// vaultWithState programs every VaultState view of vault but the ones in skip
+func vaultWithState(skip ...string) *contractstest.Client {
+	client := contractstest.NewClient(84532)
+	views := map[string]interface{}{
+		"getParticipantCount":  big.NewInt(12),
+		"maxParticipants":      big.NewInt(50),
+		"organizer":            participant,
+		"stakeAmount":          new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil),
+		"eventDate":            big.NewInt(1767225600),
+		"registrationDeadline": big.NewInt(1767139200),
+		"isSettled":            false,
+		"token":                usdc,
+	}
+	for _, method := range skip {
+		delete(views, method)
+	}
+	for method, output := range views {
+		client.SetVaultView(vault, method, output)
+	}
+	return client
+}
+
+func TestVaultState(t *testing.T) {
+	vc, _ := contracts.NewVaultContract(vaultWithState(), vault.Hex())
+	state, err := vc.State(context.Background())
+	if err != nil {
+		t.Fatalf("State: %v", err)
+	}
+	if !state.Complete() {
+		t.Fatalf("failed fields: %v", state.Failed)
+	}
+	if state.ParticipantCount.Int64() != 12 || state.MaxParticipants.Int64() != 50 ||
+		*state.Organizer != participant || *state.Token != usdc || *state.Settled ||
+		state.RegistrationDeadline.Int64() != 1767139200 || state.EventDate.Int64() != 1767225600 {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestVaultStateFlagsFailedFields(t *testing.T) {
+	vc, _ := contracts.NewVaultContract(vaultWithState("token", "isSettled"), vault.Hex())
+	state, err := vc.State(context.Background())
+	if err != nil {
+		t.Fatalf("State: %v", err)
+	}
+	if state.Complete() || len(state.Failed) != 2 || state.Failed["token_address"] == "" || state.Failed["settled"] == "" {
+		t.Errorf("failed = %v, want token_address and settled", state.Failed)
+	}
+	if state.Token != nil || state.Settled != nil || state.ParticipantCount.Int64() != 12 {
+		t.Errorf("state = %+v, want only the failed fields unset", state)
+	}
+
+	encoded, _ := json.Marshal(state)
+	if !strings.Contains(string(encoded), `"settled":null`) || !strings.Contains(string(encoded), `"token_address":null`) {
+		t.Errorf("JSON = %s, want failed fields as null", encoded)
+	}
+}
+
+func TestVaultStateJSONRoundTrip(t *testing.T) {
+	for _, skip := range [][]string{nil, {"organizer", "stakeAmount"}} {
+		vc, _ := contracts.NewVaultContract(vaultWithState(skip...), vault.Hex())
+		state, err := vc.State(context.Background())
+		if err != nil {
+			t.Fatalf("State: %v", err)
+		}
+
+		encoded, err := json.Marshal(state)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var decoded contracts.VaultState
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("Unmarshal %s: %v", encoded, err)
+		}
+		again, _ := json.Marshal(decoded)
+		if string(again) != string(encoded) {
+			t.Errorf("round trip changed the JSON:\n%s\n%s", encoded, again)
+		}
+
+		if state.StakeAmount != nil && decoded.StakeAmount.Cmp(state.StakeAmount) != 0 {
+			t.Errorf("stake amount = %v, want %v without precision loss", decoded.StakeAmount, state.StakeAmount)
+		}
+		if decoded.Vault != vault || len(decoded.Failed) != len(skip) {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	}
+
+	var state contracts.VaultState
+	if err := json.Unmarshal([]byte(`{"vault_address":"`+common.Address{}.Hex()+`","stake_amount":"1.5"}`), &state); err == nil {
+		t.Error("accepted a non-integer stake amount")
+	}
+}
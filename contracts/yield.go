@@ -0,0 +1,61 @@
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// YieldPosition is what a vault's stakes are worth against what was staked, in stake
+// token base units. Principal is the stake of every current participant; TotalAssets
+// adds the yield earned on it.
+type YieldPosition struct {
+	Principal   *big.Int
+	TotalAssets *big.Int
+}
+
+// Accrued is the yield earned so far. It is zero rather than negative while the vault
+// holds less than its principal, e.g. once attendees have started claiming.
+func (p YieldPosition) Accrued() *big.Int {
+	accrued := new(big.Int).Sub(p.TotalAssets, p.Principal)
+	if accrued.Sign() < 0 {
+		return new(big.Int)
+	}
+	return accrued
+}
+
+// Yield reads the vault's yield position in one Multicall3 request. Unlike State it
+// needs every value, so any failed read fails the whole position.
+func (vc *VaultContract) Yield(ctx context.Context) (*YieldPosition, error) {
+	methods := []string{"totalAssets", "stakeAmount", "getParticipantCount"}
+	calls := make([]Call, len(methods))
+	for i, method := range methods {
+		callData, err := vaultABI.Pack(method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack %s call data: %w", method, err)
+		}
+		calls[i] = Call{Target: vc.address, CallData: callData}
+	}
+
+	results, err := NewMulticaller(vc.client).Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*big.Int, len(methods))
+	for i, method := range methods {
+		if results[i].Err != nil {
+			return nil, fmt.Errorf("failed to call %s: %w", method, results[i].Err)
+		}
+		out, err := vaultABI.Unpack(method, results[i].ReturnData)
+		if err != nil || len(out) != 1 {
+			return nil, fmt.Errorf("failed to unpack %s: %v", method, err)
+		}
+		values[i] = out[0].(*big.Int)
+	}
+
+	return &YieldPosition{
+		TotalAssets: values[0],
+		Principal:   new(big.Int).Mul(values[1], values[2]),
+	}, nil
+}
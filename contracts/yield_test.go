@@ -0,0 +1,49 @@
+package contracts_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+)
+
+func TestVaultYield(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetParticipantCount(vault, 4)
+	client.SetVaultView(vault, "stakeAmount", big.NewInt(10_000_000))
+	client.SetVaultView(vault, "totalAssets", big.NewInt(40_125_000))
+	vc, _ := contracts.NewVaultContract(client, vault.Hex())
+
+	position, err := vc.Yield(context.Background())
+	if err != nil {
+		t.Fatalf("Yield: %v", err)
+	}
+	if position.Principal.Int64() != 40_000_000 || position.TotalAssets.Int64() != 40_125_000 || position.Accrued().Int64() != 125_000 {
+		t.Errorf("position = %s principal, %s total, %s accrued; want 40000000, 40125000, 125000",
+			position.Principal, position.TotalAssets, position.Accrued())
+	}
+
+	// Claims leave the vault holding less than the principal
+	client.SetVaultView(vault, "totalAssets", big.NewInt(30_000_000))
+	position, err = vc.Yield(context.Background())
+	if err != nil {
+		t.Fatalf("Yield: %v", err)
+	}
+	if position.Accrued().Sign() != 0 {
+		t.Errorf("accrued = %s, want 0", position.Accrued())
+	}
+}
+
+func TestVaultYieldNeedsEveryRead(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetParticipantCount(vault, 4)
+	client.SetVaultView(vault, "stakeAmount", big.NewInt(10_000_000))
+	vc, _ := contracts.NewVaultContract(client, vault.Hex())
+
+	// Vaults deployed before totalAssets existed revert
+	if _, err := vc.Yield(context.Background()); err == nil {
+		t.Error("Yield succeeded without totalAssets")
+	}
+}
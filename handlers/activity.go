@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Activity tracking tuning: how often a wallet's last_active_at may be written, how many
+// writes may wait for the worker, and how many wallets the throttle remembers
+const (
+	activityThrottle      = 10 * time.Minute
+	activityQueueSize     = 1024
+	activityMaxThrottled  = 10000
+	activityWriteDeadline = 5 * time.Second
+	defaultActiveWindow   = 7 * 24 * time.Hour
+	maxActiveWindow       = 365 * 24 * time.Hour
+)
+
+// activityWrite is one queued write: a login event when login is set, else a
+// last_active_at bump
+type activityWrite struct {
+	walletAddress string
+	login         bool
+	ipAddress     string
+	userAgent     string
+}
+
+// ActivityTracker records profile activity off the request path. Writes are queued and
+// applied by Run; when the queue is full they are dropped rather than delaying requests.
+type ActivityTracker struct {
+	db    *pgxpool.Pool
+	queue chan activityWrite
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+func NewActivityTracker(db *pgxpool.Pool) *ActivityTracker {
+	return &ActivityTracker{
+		db:       db,
+		queue:    make(chan activityWrite, activityQueueSize),
+		lastSeen: map[string]time.Time{},
+	}
+}
+
+// Touch queues a last_active_at update for the wallet's profile, at most once per
+// activityThrottle per wallet
+func (t *ActivityTracker) Touch(walletAddress string) {
+	wallet := strings.ToLower(walletAddress)
+	now := time.Now()
+
+	t.mu.Lock()
+	if last, ok := t.lastSeen[wallet]; ok && now.Sub(last) < activityThrottle {
+		t.mu.Unlock()
+		return
+	}
+	if len(t.lastSeen) >= activityMaxThrottled {
+		for w, last := range t.lastSeen {
+			if now.Sub(last) >= activityThrottle {
+				delete(t.lastSeen, w)
+			}
+		}
+	}
+	t.lastSeen[wallet] = now
+	t.mu.Unlock()
+
+	t.enqueue(activityWrite{walletAddress: wallet})
+}
+
+// RecordLogin queues a login event for the wallet
+func (t *ActivityTracker) RecordLogin(walletAddress, ipAddress, userAgent string) {
+	t.enqueue(activityWrite{walletAddress: strings.ToLower(walletAddress), login: true, ipAddress: ipAddress, userAgent: userAgent})
+}
+
+func (t *ActivityTracker) enqueue(write activityWrite) {
+	select {
+	case t.queue <- write:
+	default:
+		slog.Warn("Activity queue full, dropping activity", "wallet", write.walletAddress)
+	}
+}
+
+// Run applies queued writes until ctx is cancelled
+func (t *ActivityTracker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case write := <-t.queue:
+			writeCtx, cancel := context.WithTimeout(ctx, activityWriteDeadline)
+			if err := t.apply(writeCtx, write); err != nil {
+				slog.ErrorContext(ctx, "Failed to record activity", "wallet", write.walletAddress, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (t *ActivityTracker) apply(ctx context.Context, write activityWrite) error {
+	if write.login {
+		_, err := t.db.Exec(ctx, `
+			INSERT INTO login_events (wallet_address, ip_address, user_agent)
+			VALUES ($1, $2, $3)
+		`, write.walletAddress, nullIfEmpty(write.ipAddress), nullIfEmpty(write.userAgent))
+		return err
+	}
+
+	// Linked wallets count as activity of the profile they belong to
+	_, err := t.db.Exec(ctx, `
+		UPDATE profiles SET last_active_at = now()
+		WHERE id = `+ownerProfileOf("$1"), write.walletAddress)
+	return err
+}
+
+// VerifyAuth confirms the request's wallet signature for a sign-in and records the login.
+// The frontend calls it once after the wallet signs; later requests reuse the headers.
+func (h *UserHandler) VerifyAuth(c *gin.Context) {
+	walletAddress := c.GetString(middleware.UserAddressKey)
+	h.activity.RecordLogin(walletAddress, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address": walletAddress,
+		"is_admin":       middleware.IsAdmin(c),
+	})
+}
+
+// ListProfiles pages through every profile with its last activity, most recently active
+// first (admin only). Deleted profiles are left out.
+func (h *UserHandler) ListProfiles(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxParticipantsPageSize {
+		limit = maxParticipantsPageSize
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT `+profileReturning+`, last_active_at, COUNT(*) OVER()
+		FROM profiles
+		WHERE deleted_at IS NULL
+		ORDER BY last_active_at DESC NULLS LAST, created_at DESC NULLS LAST, id
+		LIMIT $1 OFFSET $2
+	`, limit, (page-1)*limit)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing profiles", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	profiles := []models.Profile{}
+	total := 0
+	for rows.Next() {
+		var lastActiveAt *time.Time
+		profile, err := scanProfile(rows, &lastActiveAt, &total)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning profile row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan profile"})
+			return
+		}
+		profile.LastActiveAt = lastActiveAt
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profiles": profiles,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetActiveUserStats counts the profiles active and the sign-ins within a window such as
+// 7d or 24h (admin only)
+func (h *UserHandler) GetActiveUserStats(c *gin.Context) {
+	window := defaultActiveWindow
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := parseWindow(raw)
+		if err != nil || parsed <= 0 || parsed > maxActiveWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a duration such as 7d or 24h, up to 365d"})
+			return
+		}
+		window = parsed
+	}
+
+	var activeUsers, totalUsers, logins, loginWallets int
+	err := h.db.QueryRow(c, `
+		SELECT COUNT(*) FILTER (WHERE last_active_at >= now() - $1 * interval '1 second'),
+		       COUNT(*),
+		       (SELECT COUNT(*) FROM login_events WHERE created_at >= now() - $1 * interval '1 second'),
+		       (SELECT COUNT(DISTINCT LOWER(wallet_address)) FROM login_events WHERE created_at >= now() - $1 * interval '1 second')
+		FROM profiles
+		WHERE deleted_at IS NULL
+	`, window.Seconds()).Scan(&activeUsers, &totalUsers, &logins, &loginWallets)
+	if err != nil {
+		slog.ErrorContext(c, "Error computing active user stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":         c.DefaultQuery("window", "7d"),
+		"window_seconds": int64(window.Seconds()),
+		"active_users":   activeUsers,
+		"total_users":    totalUsers,
+		"logins":         logins,
+		"login_wallets":  loginWallets,
+	})
+}
+
+// parseWindow parses a Go duration, also accepting a whole number of days such as "7d"
+func parseWindow(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"atfi-backend/contracts"
+	"atfi-backend/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// allowanceReadDeadline bounds the allowance and balance RPC reads
+const allowanceReadDeadline = 10 * time.Second
+
+// GetAllowance checks whether a wallet can register: that it has approved the event's vault
+// to pull the stake and holds enough of the stake token. It is read live rather than
+// cached, since the frontend calls it again right after the wallet approves.
+func (h *EventHandler) GetAllowance(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	walletAddress := c.Query("wallet")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet must be a valid address"})
+		return
+	}
+
+	var vaultAddress *string
+	var stakeAmount string
+	err = h.db.QueryRow(c, `
+		SELECT vault_address, stake_amount::text FROM events_onchain WHERE event_id = $1
+	`, eventID).Scan(&vaultAddress, &stakeAmount)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for allowance check", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+
+	required, ok := new(big.Int).SetString(stakeAmount, 10)
+	if !ok {
+		slog.ErrorContext(c, "Event has a non-integer stake amount", "event_id", eventID, "stake_amount", stakeAmount)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid stake amount"})
+		return
+	}
+
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, allowanceReadDeadline)
+	defer cancel()
+	token, err := h.stakeTokens.Of(ctx, common.HexToAddress(*vaultAddress))
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read the stake token", "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read the stake token", err))
+		return
+	}
+	allowance, balance, err := contracts.AllowanceAndBalance(ctx, h.client, token,
+		common.HexToAddress(walletAddress), common.HexToAddress(*vaultAddress))
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read allowance", "token", token.Symbol, "wallet", walletAddress, "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read token allowance", err))
+		return
+	}
+
+	allowanceShortfall := shortfall(required, allowance)
+	balanceShortfall := shortfall(required, balance)
+	amount := func(value *big.Int) gin.H {
+		return gin.H{"raw": value.String(), "formatted": units.FormatUnits(value, token.Decimals)}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":            eventID,
+		"wallet_address":      walletAddress,
+		"vault_address":       *vaultAddress,
+		"token":               token,
+		"stake_amount":        amount(required),
+		"allowance":           amount(allowance),
+		"balance":             amount(balance),
+		"allowance_ok":        allowanceShortfall.Sign() == 0,
+		"balance_ok":          balanceShortfall.Sign() == 0,
+		"can_register":        allowanceShortfall.Sign() == 0 && balanceShortfall.Sign() == 0,
+		"allowance_shortfall": amount(allowanceShortfall),
+		"balance_shortfall":   amount(balanceShortfall),
+	})
+}
+
+// displayToken returns the token to format an event's amounts with: its vault's stake
+// token when that can be read, otherwise the chain's primary token
+func (h *EventHandler) displayToken(ctx context.Context, vaultAddress *string) contracts.Token {
+	return vaultToken(ctx, h.stakeTokens, h.tokens, vaultAddress)
+}
+
+// shortfall is how far have falls short of need, or zero when it covers it
+func shortfall(need, have *big.Int) *big.Int {
+	missing := new(big.Int).Sub(need, have)
+	if missing.Sign() < 0 {
+		return new(big.Int)
+	}
+	return missing
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"atfi-backend/contracts"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// attendanceProof signs a receipt for a check-in. It returns nil when no attestor key is
+// configured.
+func (h *CheckinHandler) attendanceProof(ctx context.Context, eventID int64, walletAddress string, checkedInAt time.Time) (*contracts.AttendanceProof, error) {
+	if h.attestor == nil {
+		return nil, nil
+	}
+
+	var vaultAddress string
+	err := h.db.QueryRow(ctx, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.attestor.SignAttendance(contracts.Attendance{
+		EventID:     eventID,
+		Vault:       common.HexToAddress(vaultAddress),
+		Wallet:      common.HexToAddress(walletAddress),
+		CheckedInAt: checkedInAt.Unix(),
+	})
+}
+
+// issueAttendanceProof is attendanceProof for check-in responses: failures are logged
+// and leave the proof out rather than failing a check-in that already committed
+func (h *CheckinHandler) issueAttendanceProof(ctx context.Context, eventID int64, walletAddress string, checkedInAt time.Time) *contracts.AttendanceProof {
+	proof, err := h.attendanceProof(ctx, eventID, walletAddress, checkedInAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to sign attendance proof", "event_id", eventID, "wallet", walletAddress, "error", err)
+		return nil
+	}
+	return proof
+}
+
+// GetAttendanceProof re-issues the signed attendance receipt for a checked-in wallet
+func (h *CheckinHandler) GetAttendanceProof(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	walletAddress := c.Query("wallet")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A valid wallet is required"})
+		return
+	}
+
+	if h.attestor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Attendance proofs are not configured"})
+		return
+	}
+
+	// The receipt is pinned to the recorded check-in time so re-fetching yields the same proof
+	var checkedInAt time.Time
+	err = h.db.QueryRow(c, `
+		SELECT ck.checked_in_at
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN checkins ck ON ck.event_id = p.event_id AND LOWER(ck.user_address) = LOWER(pr.wallet_address)
+		WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2) AND p.is_attend = true
+		ORDER BY ck.checked_in_at DESC
+		LIMIT 1
+	`, eventID, walletAddress).Scan(&checkedInAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No check-in found for this wallet"})
+			return
+		}
+		slog.ErrorContext(c, "Error loading check-in for attendance proof", "event_id", eventID, "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	proof, err := h.attendanceProof(c, eventID, walletAddress, checkedInAt)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to sign attendance proof", "event_id", eventID, "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign attendance proof"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attendance_proof": proof})
+}
+
+// VerifyAttendanceProof checks a submitted attendance receipt and reports who signed it.
+// A proof is valid when it was signed by our attestor for the chain we run on.
+func (h *CheckinHandler) VerifyAttendanceProof(c *gin.Context) {
+	var proof contracts.AttendanceProof
+	if err := c.ShouldBindJSON(&proof); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	signer, err := contracts.RecoverAttendanceSigner(proof)
+	if err != nil {
+		if errors.Is(err, contracts.ErrInvalidProof) {
+			c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify proof"})
+		return
+	}
+
+	attendance, err := contracts.ParseAttendance(proof.Message)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"valid":  false,
+		"signer": signer.Hex(),
+		"attendance": gin.H{
+			"event_id":      attendance.EventID,
+			"vault":         attendance.Vault.Hex(),
+			"wallet":        attendance.Wallet.Hex(),
+			"checked_in_at": time.Unix(attendance.CheckedInAt, 0).UTC(),
+		},
+	}
+
+	if h.attestor != nil {
+		response["attestor"] = h.attestor.Address().Hex()
+		response["valid"] = signer == h.attestor.Address() && strings.EqualFold(proof.Domain.ChainID, h.attestor.ChainID().String())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"atfi-backend/models"
+)
+
+// recordAudit writes an entry to the audit_log table
+func recordAudit(ctx context.Context, q querier, actor, action string, eventID *int64, details map[string]interface{}) error {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO audit_log (actor, action, event_id, details)
+		VALUES ($1, $2, $3, $4)
+	`, actor, action, eventID, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// recordCheckinAudit writes a change to a check-in's validation to checkin_audit. prev is
+// the check-in as it was before the change.
+func recordCheckinAudit(ctx context.Context, q querier, prev models.Checkin, action, actor, reason, deviceLabel, ipAddress string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO checkin_audit (checkin_id, event_id, action, actor, previous_validated, previous_validated_at,
+		                           previous_validated_by, reason, device_label, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''))
+	`, prev.ID, prev.EventID, action, actor, prev.IsValidated, prev.ValidatedAt, prev.ValidatedBy, reason, deviceLabel, ipAddress)
+	if err != nil {
+		return fmt.Errorf("failed to write check-in audit entry: %w", err)
+	}
+	return nil
+}
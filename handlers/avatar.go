@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"atfi-backend/identicon"
+	"atfi-backend/middleware"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Largest avatar accepted, and the image types allowed with the extension stored for each
+const maxAvatarBytes = 2 << 20
+
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// identiconURLPrefix is the path GetIdenticon is served under, relative to the API host
+const identiconURLPrefix = "/api/v1/avatars/"
+
+// Identicons never change for a wallet, so clients may keep them for a year
+const (
+	defaultIdenticonSize  = 64
+	identiconCacheControl = "public, max-age=31536000, immutable"
+)
+
+// identiconURL is the generated avatar of a wallet
+func identiconURL(walletAddress string) string {
+	return identiconURLPrefix + strings.ToLower(walletAddress) + ".png"
+}
+
+// avatarOrIdenticon returns avatarURL, or the wallet's identicon when no avatar is set
+func avatarOrIdenticon(avatarURL *string, walletAddress string) *string {
+	if avatarURL != nil && *avatarURL != "" {
+		return avatarURL
+	}
+	url := identiconURL(walletAddress)
+	return &url
+}
+
+// GetIdenticon renders the blockies-style avatar of a wallet as a PNG. The output depends
+// only on the address and size, so it is served with long-lived cache headers.
+func (h *UserHandler) GetIdenticon(c *gin.Context) {
+	walletAddress := strings.TrimSuffix(c.Param("walletAddress"), ".png")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultIdenticonSize)))
+	if err != nil || size < 1 || size > identicon.MaxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 1 and " + strconv.Itoa(identicon.MaxSize)})
+		return
+	}
+
+	data, err := identicon.PNG(walletAddress, size)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to render identicon", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render avatar"})
+		return
+	}
+
+	c.Header("Cache-Control", identiconCacheControl)
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+// UploadAvatar replaces a profile's avatar with the image in the multipart "avatar" field.
+// Only the wallet itself or an admin may change it. The previous uploaded file is deleted.
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarBytes+64<<10)
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxAvatarBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatar must be at most 2 MB"})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAvatarBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read avatar"})
+		return
+	}
+	if len(data) > maxAvatarBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatar must be at most 2 MB"})
+		return
+	}
+
+	// Trust the bytes, not the client's declared content type
+	contentType := http.DetectContentType(data)
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Avatar must be a PNG, JPEG, GIF or WebP image"})
+		return
+	}
+
+	// A fresh name per upload so caches never serve the old picture
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+		return
+	}
+	key := "avatars/" + strings.ToLower(walletAddress) + "-" + hex.EncodeToString(suffix) + ext
+
+	url, err := h.store.Put(c, key, contentType, bytes.NewReader(data))
+	if err != nil {
+		slog.ErrorContext(c, "Failed to store avatar", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+		return
+	}
+
+	var replacedKey *string
+	err = h.db.QueryRow(c, `
+		WITH old AS (SELECT avatar_key FROM profiles WHERE LOWER(wallet_address) = LOWER($1) FOR UPDATE)
+		UPDATE profiles SET avatar_url = $2, avatar_key = $3
+		WHERE LOWER(wallet_address) = LOWER($1)
+		RETURNING (SELECT avatar_key FROM old)
+	`, walletAddress, url, key).Scan(&replacedKey)
+	if err != nil {
+		// Don't leave the new file behind when the profile was not updated
+		h.deleteAvatarFile(c, &key)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	h.deleteAvatarFile(c, replacedKey)
+
+	c.JSON(http.StatusOK, gin.H{"wallet_address": walletAddress, "avatar_url": url})
+}
+
+// DeleteAvatar removes a profile's avatar, deleting the uploaded file if there is one
+func (h *UserHandler) DeleteAvatar(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	var replacedKey *string
+	err := h.db.QueryRow(c, `
+		WITH old AS (SELECT avatar_key FROM profiles WHERE LOWER(wallet_address) = LOWER($1) FOR UPDATE)
+		UPDATE profiles SET avatar_url = NULL, avatar_key = NULL
+		WHERE LOWER(wallet_address) = LOWER($1)
+		RETURNING (SELECT avatar_key FROM old)
+	`, walletAddress).Scan(&replacedKey)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	h.deleteAvatarFile(c, replacedKey)
+
+	c.Status(http.StatusNoContent)
+}
+
+// canEditProfile allows the wallet itself or an admin, writing the error response otherwise
+func (h *UserHandler) canEditProfile(c *gin.Context, walletAddress string) bool {
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return false
+	}
+	if !strings.EqualFold(c.GetString(middleware.UserAddressKey), walletAddress) && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own profile"})
+		return false
+	}
+	return true
+}
+
+// deleteAvatarFile removes a replaced avatar from storage. Failures only leave an orphaned
+// file behind, so they are logged rather than returned.
+func (h *UserHandler) deleteAvatarFile(ctx context.Context, key *string) {
+	if key == nil || *key == "" {
+		return
+	}
+	if err := h.store.Delete(ctx, *key); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete avatar", "key", *key, "error", err)
+	}
+}
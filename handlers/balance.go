@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"atfi-backend/cache"
+	"atfi-backend/contracts"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// How long a cached balance is served as current, and how long a background refresh may run
+const (
+	balanceCacheTTL        = 30 * time.Second
+	balanceRefreshDeadline = 10 * time.Second
+)
+
+// balanceCache serves wallet balances from a store with stale-while-revalidate: fresh
+// entries are returned as is, stale ones are returned immediately while a refresh runs in
+// the background, and misses are read live. Concurrent reads of one wallet share a single
+// RPC call.
+type balanceCache struct {
+	store cache.BalanceStore
+	ttl   time.Duration
+	fetch func(ctx context.Context, wallet string) (string, error)
+	now   func() time.Time
+	group singleflight.Group
+}
+
+func newBalanceCache(store cache.BalanceStore, fetch func(ctx context.Context, wallet string) (string, error)) *balanceCache {
+	return &balanceCache{store: store, ttl: balanceCacheTTL, fetch: fetch, now: time.Now}
+}
+
+// Get returns the balance for wallet. With fresh it always reads the chain.
+func (bc *balanceCache) Get(ctx context.Context, wallet string, fresh bool) (cache.Balance, error) {
+	if !fresh {
+		cached, ok, err := bc.store.Get(ctx, wallet)
+		if err != nil {
+			slog.WarnContext(ctx, "Balance cache read failed", "wallet", wallet, "error", err)
+		}
+		if ok {
+			if bc.now().Sub(cached.AsOf) > bc.ttl {
+				bc.refreshInBackground(wallet)
+			}
+			return cached, nil
+		}
+	}
+	return bc.load(ctx, wallet)
+}
+
+// load reads the balance from the chain and stores it, sharing the call with any
+// concurrent load of the same wallet
+func (bc *balanceCache) load(ctx context.Context, wallet string) (cache.Balance, error) {
+	result, err, _ := bc.group.Do(strings.ToLower(wallet), func() (interface{}, error) {
+		value, err := bc.fetch(ctx, wallet)
+		if err != nil {
+			return cache.Balance{}, err
+		}
+		balance := cache.Balance{Value: value, AsOf: bc.now()}
+		if err := bc.store.Set(ctx, wallet, balance); err != nil {
+			slog.WarnContext(ctx, "Balance cache write failed", "wallet", wallet, "error", err)
+		}
+		return balance, nil
+	})
+	if err != nil {
+		return cache.Balance{}, err
+	}
+	return result.(cache.Balance), nil
+}
+
+// refreshInBackground reloads a stale balance without holding up the request
+func (bc *balanceCache) refreshInBackground(wallet string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), balanceRefreshDeadline)
+		defer cancel()
+		if _, err := bc.load(ctx, wallet); err != nil {
+			slog.Warn("Background balance refresh failed", "wallet", wallet, "error", err)
+		}
+	}()
+}
+
+// GetBalances returns the wallet's balance of every configured token, read in one batched
+// RPC request. Tokens that are misconfigured or fail to read are listed under warnings
+// instead of failing the response; when the read times out every token is, and degraded
+// is set.
+func (h *UserHandler) GetBalances(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+
+	results, err := contracts.BalancesOf(c, h.client, common.HexToAddress(walletAddress), h.tokens)
+	degraded := contracts.IsCallTimeout(err)
+	if degraded {
+		// A slow node degrades to an answer listing every token under warnings
+		slog.WarnContext(c, "Token balances timed out", "wallet", walletAddress, "error", err)
+		results = make([]contracts.TokenBalance, len(h.tokens))
+		for i, token := range h.tokens {
+			results[i] = contracts.TokenBalance{Token: token, Err: err}
+		}
+	} else if err != nil {
+		slog.ErrorContext(c, "Failed to read token balances", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read token balances", "details": err.Error()})
+		return
+	}
+
+	balances := []gin.H{}
+	warnings := []gin.H{}
+	for _, result := range results {
+		if result.Err != nil {
+			slog.WarnContext(c, "Skipping balance", "token", result.Token.Symbol, "wallet", walletAddress, "error", result.Err)
+			warnings = append(warnings, gin.H{
+				"symbol":  result.Token.Symbol,
+				"address": result.Token.Address,
+				"message": result.Err.Error(),
+			})
+			continue
+		}
+		raw := result.Raw.String()
+		balances = append(balances, gin.H{
+			"symbol":    result.Token.Symbol,
+			"address":   result.Token.Address,
+			"decimals":  result.Token.Decimals,
+			"raw":       raw,
+			"formatted": formatTokenAmount(raw, result.Token.Decimals),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address": walletAddress,
+		"balances":       balances,
+		"warnings":       warnings,
+		"degraded":       degraded,
+	})
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"atfi-backend/models"
+)
+
+// Largest batch accepted by BulkImportParticipants
+const maxBulkImportSize = 1000
+
+// Per-row outcomes of a bulk import
+const (
+	bulkStatusCreated   = "created"
+	bulkStatusDuplicate = "duplicate"
+	bulkStatusInvalid   = "invalid"
+)
+
+// BulkImportParticipants inserts indexer-backfilled registrations with set-based statements
+// inside one transaction, creating missing profiles. Invalid rows are skipped unless the
+// request is atomic, in which case any invalid row rejects the whole batch.
+func (h *StakeHandler) BulkImportParticipants(c *gin.Context) {
+	var req models.BulkParticipantImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.Participants) > maxBulkImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Batch exceeds the maximum of %d participants", maxBulkImportSize)})
+		return
+	}
+
+	results := make([]models.BulkParticipantImportResult, len(req.Participants))
+
+	// Resolve which events exist so unknown ones are reported instead of failing the insert
+	eventIDs := []int64{}
+	for _, p := range req.Participants {
+		eventIDs = append(eventIDs, p.EventID)
+	}
+	knownEvents := map[int64]bool{}
+	rows, err := h.db.Query(c, "SELECT event_id FROM events_onchain WHERE event_id = ANY($1)", eventIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	for rows.Next() {
+		var eventID int64
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		knownEvents[eventID] = true
+	}
+	rows.Close()
+
+	// Validate rows and drop in-batch duplicates
+	seen := map[string]bool{}
+	valid := []int{}
+	invalidCount := 0
+	for i, p := range req.Participants {
+		results[i].Index = i
+		p.WalletAddress = strings.ToLower(p.WalletAddress)
+		req.Participants[i].WalletAddress = p.WalletAddress
+
+		var problem string
+		switch {
+		case !knownEvents[p.EventID]:
+			problem = "unknown event_id"
+		case !common.IsHexAddress(p.WalletAddress):
+			problem = "invalid wallet_address"
+		case p.TxHash == "":
+			problem = "tx_hash is required"
+		case !isBaseUnitAmount(p.Amount):
+			problem = "amount must be a non-negative integer in token base units"
+		}
+		if problem != "" {
+			results[i].Status = bulkStatusInvalid
+			results[i].Error = problem
+			invalidCount++
+			continue
+		}
+
+		key := fmt.Sprintf("%d:%s", p.EventID, p.WalletAddress)
+		if seen[key] {
+			results[i].Status = bulkStatusDuplicate
+			continue
+		}
+		seen[key] = true
+		valid = append(valid, i)
+	}
+
+	if req.Atomic && invalidCount > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Batch contains invalid rows; nothing was imported",
+			"results": results,
+		})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var events []int64
+	var wallets, txHashes, amounts []string
+	var blocks []int64
+	var timestamps []time.Time
+	for _, i := range valid {
+		p := req.Participants[i]
+		events = append(events, p.EventID)
+		wallets = append(wallets, p.WalletAddress)
+		txHashes = append(txHashes, p.TxHash)
+		amounts = append(amounts, p.Amount)
+		blocks = append(blocks, p.Block)
+		timestamps = append(timestamps, p.Timestamp)
+	}
+
+	// Create bare profiles for wallets we have never seen
+	_, err = tx.Exec(c, `
+		INSERT INTO profiles (id, wallet_address, created_at, updated_at)
+		SELECT gen_random_uuid(), w, now(), now()
+		FROM (SELECT DISTINCT unnest($1::text[]) AS w) AS wallets
+		ON CONFLICT ((LOWER(wallet_address))) DO NOTHING
+	`, wallets)
+	if err != nil {
+		slog.ErrorContext(c, "Error creating profiles during bulk import", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create profiles"})
+		return
+	}
+
+	createdRows, err := tx.Query(c, `
+		WITH input AS (
+			SELECT * FROM unnest($1::bigint[], $2::text[]) AS t(event_id, wallet_address)
+		)
+		INSERT INTO participant (event_id, user_id, is_attend, is_claim, source, created_at, updated_at)
+		SELECT i.event_id, pr.id, false, false, $3, now(), now()
+		FROM input i
+		JOIN profiles pr ON LOWER(pr.wallet_address) = i.wallet_address
+		ON CONFLICT DO NOTHING
+		RETURNING event_id, user_id
+	`, events, wallets, participantSourceIndexer)
+	if err != nil {
+		slog.ErrorContext(c, "Error inserting participants during bulk import", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert participants"})
+		return
+	}
+	createdUsers := map[string]bool{}
+	for createdRows.Next() {
+		var eventID int64
+		var userID string
+		if err := createdRows.Scan(&eventID, &userID); err != nil {
+			createdRows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		createdUsers[fmt.Sprintf("%d:%s", eventID, userID)] = true
+	}
+	createdRows.Close()
+
+	_, err = tx.Exec(c, `
+		WITH input AS (
+			SELECT * FROM unnest($1::bigint[], $2::text[], $3::text[], $4::numeric[], $5::bigint[], $6::timestamptz[])
+				AS t(event_id, wallet_address, tx_hash, amount, block, ts)
+		)
+		INSERT INTO stakes (event_id, user_id, wallet_address, stake_amount, stake_transaction_hash,
+		                    created_at_block, created_at_timestamp, created_at, updated_at)
+		SELECT i.event_id, pr.id, i.wallet_address, i.amount, i.tx_hash, i.block, i.ts, now(), now()
+		FROM input i
+		JOIN profiles pr ON LOWER(pr.wallet_address) = i.wallet_address
+		ON CONFLICT DO NOTHING
+	`, events, wallets, txHashes, amounts, blocks, timestamps)
+	if err != nil {
+		slog.ErrorContext(c, "Error inserting stakes during bulk import", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert stakes"})
+		return
+	}
+
+	// Map created (event, user) pairs back to request rows
+	userIDs := map[string]string{}
+	profileRows, err := tx.Query(c, "SELECT LOWER(wallet_address), id FROM profiles WHERE LOWER(wallet_address) = ANY($1)", wallets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	for profileRows.Next() {
+		var walletAddress, userID string
+		if err := profileRows.Scan(&walletAddress, &userID); err != nil {
+			profileRows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		userIDs[walletAddress] = userID
+	}
+	profileRows.Close()
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import participants"})
+		return
+	}
+
+	summary := map[string]int{bulkStatusCreated: 0, bulkStatusDuplicate: 0, bulkStatusInvalid: invalidCount}
+	for _, i := range valid {
+		p := req.Participants[i]
+		if createdUsers[fmt.Sprintf("%d:%s", p.EventID, userIDs[p.WalletAddress])] {
+			results[i].Status = bulkStatusCreated
+		} else {
+			results[i].Status = bulkStatusDuplicate
+		}
+	}
+	for _, r := range results {
+		if r.Status == bulkStatusDuplicate {
+			summary[bulkStatusDuplicate]++
+		} else if r.Status == bulkStatusCreated {
+			summary[bulkStatusCreated]++
+		}
+	}
+
+	slog.InfoContext(c, "Bulk imported participants", "summary", summary)
+
+	c.JSON(http.StatusOK, gin.H{
+		"summary": summary,
+		"results": results,
+	})
+}
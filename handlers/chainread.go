@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"atfi-backend/contracts"
+)
+
+// chainReadStatus is the status for a failed on-chain read: 504 when the node did not
+// answer within CONTRACT_CALL_TIMEOUT, so clients know a retry may succeed, and 502 otherwise
+func chainReadStatus(err error) int {
+	if contracts.IsCallTimeout(err) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// chainError is the response body for a failed on-chain call. A call that reverted also
+// gets onchain_revert, naming the custom error or giving the revert message, and reason.
+func chainError(message string, err error) gin.H {
+	body := gin.H{"error": message, "details": err.Error()}
+	if revert, ok := contracts.RevertOf(err); ok {
+		body["onchain_revert"] = revert.Name
+		body["reason"] = revert.Reason
+	}
+	return body
+}
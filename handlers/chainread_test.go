@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	testUSDC   = contracts.Token{Symbol: "USDC", Address: "0x036CbD53842c5426634e7929541eC2318f3dCF7e", Decimals: 6}
+	testWallet = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+)
+
+// getBalances serves GetBalances for testWallet and decodes the response
+func getBalances(t *testing.T, h *UserHandler) (int, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/profiles/:walletAddress/balances", h.GetBalances)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profiles/"+testWallet.Hex()+"/balances", nil))
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	return w.Code, body
+}
+
+// vaultEvents returns n events sharing vault
+func vaultEvents(n int, vault common.Address) []models.EventDetail {
+	events := make([]models.EventDetail, n)
+	for i := range events {
+		events[i].EventID = int64(i + 1)
+		events[i].VaultAddress = vault.Hex()
+	}
+	return events
+}
+
+func TestGetBalances(t *testing.T) {
+	client := contractstest.NewClient(84532)
+	client.SetBalance(common.HexToAddress(testUSDC.Address), testWallet, big.NewInt(12500000))
+	h := &UserHandler{client: client, tokens: []contracts.Token{testUSDC}}
+
+	status, body := getBalances(t, h)
+	if status != http.StatusOK || body["degraded"] != false {
+		t.Fatalf("status = %d, body = %v; want a 200 that is not degraded", status, body)
+	}
+	balances := body["balances"].([]any)
+	if len(balances) != 1 || balances[0].(map[string]any)["formatted"] != "12.5" {
+		t.Errorf("balances = %v, want 12.5 USDC", balances)
+	}
+
+	balance, err := h.getPrimaryTokenBalance(context.Background(), testWallet.Hex())
+	if err != nil || balance != "12.5" {
+		t.Errorf("getPrimaryTokenBalance = %q, %v; want 12.5", balance, err)
+	}
+}
+
+func TestGetBalancesDegradesWhenTheNodeIsSlow(t *testing.T) {
+	t.Setenv("CONTRACT_CALL_TIMEOUT", "100ms")
+	client := contractstest.NewClient(84532)
+	client.Delay = 10 * time.Second
+	h := &UserHandler{client: client, tokens: []contracts.Token{testUSDC}}
+
+	start := time.Now()
+	status, body := getBalances(t, h)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("GetBalances took %s with a 100ms call timeout", elapsed)
+	}
+	if status != http.StatusOK || body["degraded"] != true {
+		t.Fatalf("status = %d, body = %v; want a degraded 200", status, body)
+	}
+	if len(body["balances"].([]any)) != 0 || len(body["warnings"].([]any)) != 1 {
+		t.Errorf("body = %v, want USDC under warnings", body)
+	}
+}
+
+func TestFillParticipantCounts(t *testing.T) {
+	vault := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	client := contractstest.NewClient(84532)
+	client.SetParticipantCount(vault, 9)
+	h := &EventHandler{client: client, vaults: newVaultVerifier(nil)}
+
+	// Per vault below the multicall threshold, one aggregate3 above it
+	for _, tt := range []struct{ vaults, calls int }{{2, 2}, {5, 1}} {
+		before := len(client.Calls())
+		events := vaultEvents(tt.vaults, vault)
+		h.fillParticipantCounts(context.Background(), events)
+		for _, event := range events {
+			if event.CurrentParticipants != 9 {
+				t.Errorf("%d vaults: event %d has %d participants, want 9", tt.vaults, event.EventID, event.CurrentParticipants)
+			}
+		}
+		if calls := len(client.Calls()) - before; calls != tt.calls {
+			t.Errorf("%d vaults took %d eth_calls, want %d", tt.vaults, calls, tt.calls)
+		}
+	}
+}
+
+func TestFillParticipantCountsRespectsTheCallTimeout(t *testing.T) {
+	t.Setenv("CONTRACT_CALL_TIMEOUT", "100ms")
+	client := contractstest.NewClient(84532)
+	client.Delay = 10 * time.Second
+	h := &EventHandler{client: client, vaults: newVaultVerifier(nil)}
+
+	for _, vaults := range []int{2, 5} {
+		events := vaultEvents(vaults, common.HexToAddress("0x00000000000000000000000000000000000000bb"))
+
+		start := time.Now()
+		h.fillParticipantCounts(context.Background(), events)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%d vaults took %s with a 100ms call timeout", vaults, elapsed)
+		}
+		for _, event := range events {
+			if event.CurrentParticipants != 0 {
+				t.Errorf("event %d has %d participants, want the zero fallback", event.EventID, event.CurrentParticipants)
+			}
+		}
+	}
+}
+
+func TestChainReadStatus(t *testing.T) {
+	if status := chainReadStatus(&contracts.CallTimeoutError{Timeout: time.Second}); status != http.StatusGatewayTimeout {
+		t.Errorf("timeout status = %d, want 504", status)
+	}
+	if status := chainReadStatus(context.Canceled); status != http.StatusBadGateway {
+		t.Errorf("other status = %d, want 502", status)
+	}
+}
@@ -1,32 +1,62 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
-	"database/sql"
+	"encoding/csv"
 	"encoding/hex"
-	"log"
+	"fmt"
+	"log/slog"
+	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/google/uuid"
+	"atfi-backend/config"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
 	"atfi-backend/models"
+	"atfi-backend/reputation"
 )
 
 type CheckinHandler struct {
-	db *pgxpool.Pool
+	db          *pgxpool.Pool
+	client      contracts.Caller
+	now         func() time.Time // swapped out to pin the clock
+	broadcaster checkinBroadcaster
+	attestor    *contracts.Attestor     // nil when attendance proofs are not configured
+	names       *contracts.NameResolver // nil when the chain has no name registry
+	tokens      []contracts.Token       // first entry is the chain's primary token
+	stakeTokens *contracts.StakeTokens  // nil without a client
+	balances    tokenBalanceCache
+	cfg         *config.Config
 }
 
-func NewCheckinHandler(db *pgxpool.Pool) *CheckinHandler {
-	return &CheckinHandler{db: db}
+func NewCheckinHandler(db *pgxpool.Pool, client contracts.Caller, attestor *contracts.Attestor, names *contracts.NameResolver, tokens []contracts.Token, cfg *config.Config) *CheckinHandler {
+	h := &CheckinHandler{db: db, client: client, now: time.Now, attestor: attestor, names: names, tokens: tokens, cfg: cfg}
+	if client != nil {
+		h.stakeTokens = contracts.NewStakeTokens(client)
+	}
+	return h
 }
 
+// CheckIn marks a participant as attended on the organizer's behalf. The participant is
+// identified by profile UUID (user_id) or wallet address; when both are given they must
+// refer to the same profile. Only the organizer or an admin may check people in.
 func (h *CheckinHandler) CheckIn(c *gin.Context) {
 	var req struct {
-		EventID int64  `json:"event_id" binding:"required"`
-		UserID  string `json:"user_id" binding:"required"`
+		EventID        int64  `json:"event_id" binding:"required"`
+		UserID         string `json:"user_id"`
+		WalletAddress  string `json:"wallet_address"`
+		DeviceLabel    string `json:"device_label" binding:"max=100"`
+		OverrideReason string `json:"override_reason" binding:"max=500"`
+		ClientUUID     string `json:"client_uuid"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -34,48 +64,151 @@ func (h *CheckinHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Checking in participant: event=%d, user=%s", req.EventID, req.UserID)
-
-	// Validate user ID is a valid UUID
-	if _, err := uuid.Parse(req.UserID); err != nil {
-		log.Printf("Invalid user ID format: %s: %v", req.UserID, err)
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid user ID format"})
+	// Retries carrying the key of a completed check-in get the original response back
+	opKey := idempotencyKey(c, req.ClientUUID)
+	if rejectIdempotencyKey(c, opKey) {
 		return
 	}
 
-	// Check if participant exists for this event
-	var participantExists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM participant WHERE event_id = $1 AND user_id = $2)", req.EventID, req.UserID).Scan(&participantExists)
+	// Checked before replaying so that a stored response is only returned to the organizer
+	organizer, err := getEventOrganizer(c, h.db, req.EventID)
 	if err != nil {
-		log.Printf("Error checking participant existence: %v", err)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error loading event organizer", "event_id", req.EventID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
 		return
 	}
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Only the event organizer can check in participants"})
+		return
+	}
 
-	if !participantExists {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Participant not found for this event. Please ensure the participant has registered."})
+	if replayed, err := replayCheckin(c, h.db, req.EventID, opKey); err != nil || replayed {
+		if err != nil {
+			slog.ErrorContext(c, "Error looking up idempotency key", "event_id", req.EventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		}
+		return
+	}
+
+	if req.UserID == "" && req.WalletAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "user_id or wallet_address is required"})
 		return
 	}
 
-	// Check if already checked in
-	var isAttend bool
-	err = h.db.QueryRow(c, "SELECT is_attend FROM participant WHERE event_id = $1 AND user_id = $2", req.EventID, req.UserID).Scan(&isAttend)
+	slog.DebugContext(c, "Checking in participant", "event_id", req.EventID, "profile_id", req.UserID, "wallet", req.WalletAddress)
+
+	// Validate user ID is a valid UUID
+	if req.UserID != "" {
+		if _, err := uuid.Parse(req.UserID); err != nil {
+			slog.DebugContext(c, "Invalid user ID format", "profile_id", req.UserID, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid user ID format"})
+			return
+		}
+	}
+
+	// Resolve the wallet to its profile so the scanner can send the QR address directly
+	if req.WalletAddress != "" {
+		var profileID string
+		err := h.db.QueryRow(c, "SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", req.WalletAddress).Scan(&profileID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "No profile found for this wallet address"})
+				return
+			}
+			slog.ErrorContext(c, "Error resolving wallet", "wallet", req.WalletAddress, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+			return
+		}
+
+		if req.UserID != "" && !strings.EqualFold(req.UserID, profileID) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "user_id and wallet_address refer to different profiles"})
+			return
+		}
+		req.UserID = profileID
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Read the event status together with the participant, holding a share lock on the
+	// metadata row so a concurrent settlement waits until this check-in commits
+	var status string
+	var eventDate int64
+	var participantID, walletAddress *string
+	var isAttend *bool
+	err = tx.QueryRow(c, `
+		SELECT em.status, eo.event_date, eo.organizer_address, p.id, p.is_attend, pr.wallet_address
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		LEFT JOIN participant p ON p.event_id = eo.event_id AND p.user_id = $2
+		LEFT JOIN profiles pr ON pr.id = p.user_id
+		WHERE eo.event_id = $1
+		FOR SHARE OF em
+	`, req.EventID, req.UserID).Scan(&status, &eventDate, &organizer, &participantID, &isAttend, &walletAddress)
 	if err != nil {
-		log.Printf("Error checking attendance status: %v", err)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error loading event for check-in", "event_id", req.EventID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
 		return
 	}
 
-	if isAttend {
+	// Organizers may check someone in despite the event state by giving a reason;
+	// ?force=true is the older form of the same override. Overrides are audited.
+	override := req.OverrideReason != "" || c.Query("force") == "true"
+
+	now := h.now()
+	if !override {
+		if status != models.StatusLive {
+			c.JSON(http.StatusConflict, gin.H{
+				"success":        false,
+				"message":        "Event is not live",
+				"current_status": status,
+			})
+			return
+		}
+		if !checkInOpen(status, eventDate, now, h.cfg.CheckInWindow) {
+			opens, closes := checkInWindow(eventDate, h.cfg.CheckInWindow)
+			c.JSON(http.StatusConflict, gin.H{
+				"success":        false,
+				"message":        "Check-in is not open for this event",
+				"current_status": status,
+				"window_opens":   opens,
+				"window_closes":  closes,
+			})
+			return
+		}
+	}
+
+	if participantID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Participant not found for this event. Please ensure the participant has registered."})
+		return
+	}
+
+	if *isAttend {
+		if replayed, _ := replayCheckin(c, h.db, req.EventID, opKey); replayed {
+			return
+		}
 		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Participant has already checked in to this event"})
 		return
 	}
 
-	// Update participant status to checked in
+	// Update participant status to checked in. The is_attend guard makes a concurrent
+	// duplicate wait for the first request and then fall through to the conflict path.
 	updateQuery := `
 		UPDATE participant
 		SET is_attend = true, updated_at = $1
-		WHERE event_id = $2 AND user_id = $3
+		WHERE id = $2 AND is_attend = false
 		RETURNING id, event_id, user_id, is_attend, is_claim, created_at, updated_at
 	`
 
@@ -89,8 +222,7 @@ func (h *CheckinHandler) CheckIn(c *gin.Context) {
 		UpdatedAt time.Time `json:"updated_at"`
 	}
 
-	now := time.Now()
-	err = h.db.QueryRow(c, updateQuery, now, req.EventID, req.UserID).Scan(
+	err = tx.QueryRow(c, updateQuery, now, *participantID).Scan(
 		&participant.ID,
 		&participant.EventID,
 		&participant.UserID,
@@ -100,74 +232,194 @@ func (h *CheckinHandler) CheckIn(c *gin.Context) {
 		&participant.UpdatedAt,
 	)
 
+	if err == pgx.ErrNoRows {
+		tx.Rollback(c)
+		if replayed, _ := replayCheckin(c, h.db, req.EventID, opKey); replayed {
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Participant has already checked in to this event"})
+		return
+	}
 	if err != nil {
-		log.Printf("Error updating participant check-in status: %v", err)
+		slog.ErrorContext(c, "Error updating participant check-in status", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
 		return
 	}
 
-	log.Printf("Successfully checked in participant: event=%d, user=%s", req.EventID, req.UserID)
+	if override {
+		err = recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "checkin_forced", &req.EventID, map[string]interface{}{
+			"user_id":         req.UserID,
+			"event_status":    status,
+			"override_reason": req.OverrideReason,
+			"checked_in_at":   now,
+		})
+		if err != nil {
+			slog.ErrorContext(c, "Failed to audit forced check-in", "event_id", req.EventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+			return
+		}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
+	if walletAddress != nil {
+		record := models.CreateCheckinRequest{
+			EventID:       req.EventID,
+			WalletAddress: *walletAddress,
+			CheckedBy:     c.GetString(middleware.UserAddressKey),
+			IPAddress:     c.ClientIP(),
+			DeviceLabel:   req.DeviceLabel,
+		}
+		if err := recordCheckin(c, tx, record, now); err != nil {
+			slog.ErrorContext(c, "Failed to record check-in", "event_id", req.EventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+			return
+		}
+	}
+
+	var proof *contracts.AttendanceProof
+	if walletAddress != nil {
+		proof = h.issueAttendanceProof(c, req.EventID, *walletAddress, now)
+	}
+
+	response := gin.H{
 		"success": true,
 		"message": "Successfully checked in to event",
 		"participant": participant,
-	})
+		"attendance_proof": proof,
+	}
+
+	// Stored in the same transaction so a retry never sees the check-in without its response
+	if err := saveCheckinResponse(c, tx, req.EventID, opKey, http.StatusOK, response); err != nil {
+		if isUniqueViolation(err, "checkin_idempotency_pkey") {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "message": "Idempotency key was already used for a different check-in"})
+			return
+		}
+		slog.ErrorContext(c, "Failed to store idempotency key", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	slog.InfoContext(c, "Successfully checked in participant", "event_id", req.EventID, "profile_id", req.UserID)
+
+	h.recordActivity(c, req.EventID, activityCheckedIn, &participant.ID, walletAddress)
+
+	c.JSON(http.StatusOK, response)
 }
 
+// GetCheckins lists an event's check-ins newest first, a page at a time. Filters cover
+// validation state, a checked_in_at range (since/until) and a wallet address search.
 func (h *CheckinHandler) GetCheckins(c *gin.Context) {
-	eventID := c.Param("id")
-
-	// Verify event exists
-	var exists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM events WHERE id = $1)", eventID).Scan(&exists)
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.GetCheckinsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.EventID = eventID
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > maxParticipantsPageSize {
+		req.Limit = maxParticipantsPageSize
+	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	// Verify event exists; scanner details are only shown to its organizer
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
+	showScanner := isOrganizerOrAdmin(c, organizer)
 
-	// Get check-ins
+	where, args := checkinFilters(req)
+
+	// Get the requested page with the total match count alongside each row
 	query := `
-		SELECT id, event_id, user_address, qr_data, checked_in_at, is_validated, validated_at, validated_by
+		SELECT ` + checkinColumns + `, ` + walletDeleted("user_address") + `, COUNT(*) OVER() AS total
 		FROM checkins
-		WHERE event_id = $1
-		ORDER BY checked_in_at DESC
-	`
+		WHERE ` + where + `
+		ORDER BY checked_in_at DESC, id
+		LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
 
-	rows, err := h.db.Query(c, query, eventID)
+	rows, err := h.db.Query(c, query, args...)
 	if err != nil {
+		slog.ErrorContext(c, "Error listing check-ins", "event_id", eventID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 	defer rows.Close()
 
-	var checkins []models.CheckIn
+	checkins := []models.Checkin{}
+	total := 0
 	for rows.Next() {
-		var checkin models.CheckIn
-		err := rows.Scan(
-			&checkin.ID,
-			&checkin.EventID,
-			&checkin.UserAddress,
-			&checkin.QRData,
-			&checkin.CheckedInAt,
-			&checkin.IsValidated,
-			&checkin.ValidatedAt,
-			&checkin.ValidatedBy,
-		)
+		var deleted bool
+		checkin, err := scanCheckin(rows, &deleted, &total)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan check-in"})
 			return
 		}
+		if deleted {
+			checkin.Redact()
+		}
+		if !showScanner {
+			checkin.HideScanner()
+		}
 
 		checkins = append(checkins, checkin)
 	}
 
-	c.JSON(http.StatusOK, checkins)
+	c.JSON(http.StatusOK, gin.H{
+		"checkins":    checkins,
+		"count":       len(checkins),
+		"total":       total,
+		"page":        req.Page,
+		"limit":       req.Limit,
+		"total_pages": (total + req.Limit - 1) / req.Limit,
+	})
+}
+
+// checkinFilters builds the WHERE clause and arguments for GetCheckins. The event and
+// time-range predicates line up with the (event_id, checked_in_at) index.
+func checkinFilters(req models.GetCheckinsRequest) (string, []interface{}) {
+	where := "event_id = $1"
+	args := []interface{}{req.EventID}
+
+	if req.Validated != nil {
+		args = append(args, *req.Validated)
+		where += " AND is_validated = $" + strconv.Itoa(len(args))
+	}
+
+	if req.Since != nil {
+		args = append(args, *req.Since)
+		where += " AND checked_in_at > $" + strconv.Itoa(len(args))
+	}
+
+	if req.Until != nil {
+		args = append(args, *req.Until)
+		where += " AND checked_in_at <= $" + strconv.Itoa(len(args))
+	}
+
+	if req.Search != "" {
+		args = append(args, "%"+strings.ToLower(req.Search)+"%")
+		where += " AND LOWER(user_address) LIKE $" + strconv.Itoa(len(args))
+	}
+
+	return where, args
 }
 
 func (h *CheckinHandler) ValidateCheckIn(c *gin.Context) {
@@ -176,37 +428,77 @@ func (h *CheckinHandler) ValidateCheckIn(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if (req.CheckInID == "") == (len(req.CheckInIDs) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either checkin_id or checkin_ids"})
+		return
+	}
 
 	// Get organizer address from context (assuming authenticated)
-	organizerAddress := c.GetString("user_address")
+	organizerAddress := c.GetString(middleware.UserAddressKey)
 	if organizerAddress == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Verify check-in exists and get event details
-	var eventID string
-	err := h.db.QueryRow(c, "SELECT event_id FROM checkins WHERE id = $1", req.CheckInID).Scan(&eventID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Check-in not found"})
+	if req.CheckInID != "" {
+		checkin, status, message := h.validateCheckin(c, organizerAddress, req.CheckInID, req)
+		if status != http.StatusOK {
+			c.JSON(status, gin.H{"error": message})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.JSON(http.StatusOK, checkin)
 		return
 	}
 
+	// Bulk approval of the self check-in queue; each ID succeeds or fails on its own
+	results := make([]gin.H, 0, len(req.CheckInIDs))
+	updated := 0
+	for _, id := range req.CheckInIDs {
+		checkin, status, message := h.validateCheckin(c, organizerAddress, id, req)
+		if status != http.StatusOK {
+			results = append(results, gin.H{"checkin_id": id, "status": status, "error": message})
+			continue
+		}
+		updated++
+		results = append(results, gin.H{"checkin_id": id, "status": status, "checkin": checkin})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated": updated,
+		"failed":  len(req.CheckInIDs) - updated,
+		"results": results,
+	})
+}
+
+// validateCheckin sets one check-in's validation on behalf of the organizer and records
+// the change in checkin_audit. On failure it returns the HTTP status and message to report.
+func (h *CheckinHandler) validateCheckin(c *gin.Context, organizerAddress, checkinID string, req models.ValidateCheckInRequest) (models.Checkin, int, string) {
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		return models.Checkin{}, http.StatusInternalServerError, "Database error"
+	}
+	defer tx.Rollback(c)
+
+	// Lock the check-in so the audited previous state is the one actually replaced
+	previous, err := scanCheckin(tx.QueryRow(c, "SELECT "+checkinColumns+" FROM checkins WHERE id::text = $1 FOR UPDATE", checkinID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Checkin{}, http.StatusNotFound, "Check-in not found"
+		}
+		return models.Checkin{}, http.StatusInternalServerError, "Database error"
+	}
+
 	// Verify organizer owns the event
-	var organizer string
-	err = h.db.QueryRow(c, "SELECT organizer_address FROM events WHERE id = $1", eventID).Scan(&organizer)
+	organizer, err := getEventOrganizer(c, tx, previous.EventID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		if err == pgx.ErrNoRows {
+			return models.Checkin{}, http.StatusNotFound, "Event not found"
+		}
+		return models.Checkin{}, http.StatusInternalServerError, "Database error"
 	}
 
-	if organizer != organizerAddress {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to validate this check-in"})
-		return
+	if !isOrganizerOrAdmin(c, organizer) {
+		return models.Checkin{}, http.StatusForbidden, "Not authorized to validate this check-in"
 	}
 
 	// Update check-in validation
@@ -214,81 +506,130 @@ func (h *CheckinHandler) ValidateCheckIn(c *gin.Context) {
 		UPDATE checkins
 		SET is_validated = $1, validated_at = $2, validated_by = $3
 		WHERE id = $4
-		RETURNING id, event_id, user_address, qr_data, checked_in_at, is_validated, validated_at, validated_by
+		RETURNING ` + checkinColumns + `
 	`
 
-	var checkin models.CheckIn
-	var validatedAt time.Time
+	// Rejections clear validated_at
+	var validatedAt *time.Time
 	if req.IsValid {
 		now := time.Now()
-		validatedAt = now
+		validatedAt = &now
 	}
 
-	err = h.db.QueryRow(c, query,
+	checkin, err := scanCheckin(tx.QueryRow(c, query,
 		req.IsValid,
 		validatedAt,
 		organizerAddress,
-		req.CheckInID,
-	).Scan(
-		&checkin.ID,
-		&checkin.EventID,
-		&checkin.UserAddress,
-		&checkin.QRData,
-		&checkin.CheckedInAt,
-		&checkin.IsValidated,
-		&checkin.ValidatedAt,
-		&checkin.ValidatedBy,
-	)
+		previous.ID,
+	))
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update check-in"})
-		return
+		return models.Checkin{}, http.StatusInternalServerError, "Failed to update check-in"
 	}
 
-	// If check-in is validated, also update participant table
-	if req.IsValid {
-		// Get user ID from profiles table using wallet address
+	// Validating marks the participant attended for this event only; rejecting a check-in
+	// that was validated takes the attendance back, so settlement no longer counts it
+	if req.IsValid || previous.IsValidated {
+		// Older rows may predate user_id; fall back to the profile for the wallet
 		var userID uuid.UUID
-		err = h.db.QueryRow(c, "SELECT id FROM profiles WHERE wallet_address = $1", checkin.UserAddress).Scan(&userID)
+		if checkin.UserID != nil {
+			userID = *checkin.UserID
+		} else {
+			err = tx.QueryRow(c, "SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", checkin.WalletAddress).Scan(&userID)
+		}
 		if err != nil {
 			// Log warning but don't fail the check-in validation
-			log.Printf("Warning: Could not find user profile for wallet address %s: %v", checkin.UserAddress, err)
-		} else {
-			// Try to update existing participant record first
-			updateQuery := `
-				UPDATE participant
-				SET is_attend = true
-				WHERE user_id = $1
-			`
-			result, err := h.db.Exec(c, updateQuery, userID)
+			slog.WarnContext(c, "Could not find user profile", "wallet", checkin.WalletAddress, "error", err)
+		} else if req.IsValid {
+			_, err = tx.Exec(c, `
+				INSERT INTO participant (event_id, user_id, is_attend, is_claim, created_at, updated_at)
+				VALUES ($1, $2, true, false, now(), now())
+				ON CONFLICT ON CONSTRAINT participant_event_user_key
+				DO UPDATE SET is_attend = true, updated_at = now()
+			`, checkin.EventID, userID)
 			if err != nil {
-				log.Printf("Warning: Failed to update participant record for user %s: %v", userID, err)
-			} else if result.RowsAffected() == 0 {
-				// No existing record, try to insert (this will fail if either event_id or user_id already exists)
-				insertQuery := `
-					INSERT INTO participant (event_id, user_id, is_attend, is_claim)
-					VALUES ($1, $2, true, false)
-				`
-				_, err = h.db.Exec(c, insertQuery, checkin.EventID, userID)
-				if err != nil {
-					log.Printf("Warning: Failed to insert participant record for event %s, user %s: %v", checkin.EventID, userID, err)
-				} else {
-					log.Printf("Successfully inserted participant record: event %s, user %s marked as attended", checkin.EventID, userID)
-				}
-			} else {
-				log.Printf("Successfully updated participant record: user %s marked as attended", userID)
+				slog.ErrorContext(c, "Failed to mark participant attended", "event_id", checkin.EventID, "profile_id", userID, "error", err)
+				return models.Checkin{}, http.StatusInternalServerError, "Failed to update check-in"
 			}
+			slog.InfoContext(c, "Participant marked as attended", "event_id", checkin.EventID, "profile_id", userID)
+		} else if status, message := revokeAttendance(c, tx, checkin, userID, organizerAddress, req.Reason); status != http.StatusOK {
+			return models.Checkin{}, status, message
 		}
 	}
 
-	c.JSON(http.StatusOK, checkin)
+	action := models.CheckinAuditRejected
+	if req.IsValid {
+		action = models.CheckinAuditValidated
+	}
+	if err := recordCheckinAudit(c, tx, previous, action, organizerAddress, req.Reason, req.DeviceLabel, c.ClientIP()); err != nil {
+		slog.ErrorContext(c, "Error auditing check-in", "checkin_id", previous.ID, "error", err)
+		return models.Checkin{}, http.StatusInternalServerError, "Failed to record audit entry"
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.Checkin{}, http.StatusInternalServerError, "Failed to update check-in"
+	}
+
+	if req.IsValid {
+		h.recordActivity(c, checkin.EventID, activityValidated, nil, &checkin.WalletAddress)
+	}
+
+	return checkin, http.StatusOK, ""
+}
+
+// revokeAttendance takes back the attendance of a participant whose validated check-in was
+// rejected, in the same transaction, and audits it. Participants who already claimed are
+// left alone: the event settled with them. On failure it returns the HTTP status and
+// message to report.
+func revokeAttendance(ctx context.Context, tx pgx.Tx, checkin models.Checkin, userID uuid.UUID, actor, reason string) (int, string) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE participant SET is_attend = false, updated_at = now()
+		WHERE event_id = $1 AND user_id = $2 AND is_attend AND NOT is_claim
+	`, checkin.EventID, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to revoke attendance", "event_id", checkin.EventID, "profile_id", userID, "error", err)
+		return http.StatusInternalServerError, "Failed to update check-in"
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusOK, ""
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE stakes SET is_attended = false, updated_at = now()
+		WHERE event_id = $1 AND user_id = $2
+	`, checkin.EventID, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error syncing stake attendance", "event_id", checkin.EventID, "profile_id", userID, "error", err)
+		return http.StatusInternalServerError, "Failed to update check-in"
+	}
+
+	err = recordAudit(ctx, tx, actor, "attendance_revoked", &checkin.EventID, map[string]interface{}{
+		"checkin_id":     checkin.ID,
+		"wallet_address": checkin.WalletAddress,
+		"reason":         reason,
+	})
+	if err != nil {
+		return http.StatusInternalServerError, "Failed to record audit entry"
+	}
+	slog.InfoContext(ctx, "Attendance revoked", "event_id", checkin.EventID, "profile_id", userID)
+	return http.StatusOK, ""
 }
 
-// ClaimReward handles reward claiming for participants
+// Claim sources recorded in participant.claim_source
+const (
+	claimSourceOnchainTx    = "onchain_tx"
+	claimSourceOnchainState = "onchain_state"
+)
+
+// ClaimReward marks a participant's reward as claimed once the claim is confirmed on-chain,
+// either by a successful claim transaction or by the vault's hasClaimed view. The event
+// comes from the path on /events/:id/claim; user_id defaults to the authenticated wallet,
+// and only admins may claim on behalf of another wallet.
 func (h *CheckinHandler) ClaimReward(c *gin.Context) {
 	var req struct {
-		EventID int64  `json:"event_id" binding:"required"`
-		UserID  string `json:"user_id" binding:"required"`
+		EventID         int64  `json:"event_id"`
+		UserID          string `json:"user_id"`
+		TransactionHash string `json:"transaction_hash"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -296,92 +637,219 @@ func (h *CheckinHandler) ClaimReward(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Claiming reward for participant: event=%d, user=%s", req.EventID, req.UserID)
+	if idParam := c.Param("id"); idParam != "" {
+		eventID, err := strconv.ParseInt(idParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+		if req.EventID != 0 && req.EventID != eventID {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "event_id does not match the event in the URL"})
+			return
+		}
+		req.EventID = eventID
+	}
+	if req.EventID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "event_id is required"})
+		return
+	}
+
+	walletAddress := c.GetString(middleware.UserAddressKey)
+	if req.UserID == "" {
+		req.UserID = walletAddress
+	}
+	if !common.IsHexAddress(req.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "user_id must be a wallet address"})
+		return
+	}
+	if !strings.EqualFold(req.UserID, walletAddress) && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "You can only claim your own reward"})
+		return
+	}
+
+	slog.DebugContext(c, "Claiming reward for participant", "event_id", req.EventID, "profile_id", req.UserID)
 
 	// Get profile UUID using wallet address
 	var profileUUID uuid.UUID
 	var err error
 
 	// Look up profile by wallet address to get the UUID
-	err = h.db.QueryRow(c, "SELECT id FROM profiles WHERE wallet_address = $1", req.UserID).Scan(&profileUUID)
+	err = h.db.QueryRow(c, "SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", req.UserID).Scan(&profileUUID)
 	if err != nil {
-		log.Printf("Profile not found for wallet address %s: %v", req.UserID, err)
+		slog.WarnContext(c, "Profile not found", "profile_id", req.UserID, "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User profile not found. Please ensure you have a profile."})
 		return
 	}
-	log.Printf("Found profile UUID %s for wallet address %s", profileUUID, req.UserID)
+	slog.DebugContext(c, "Found profile UUID", "profile_uuid", profileUUID, "profile_id", req.UserID)
 
-	// Check if participant exists for this event
-	var participantExists bool
-	err = h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM participant WHERE event_id = $1 AND user_id = $2)", req.EventID, profileUUID).Scan(&participantExists)
+	// Get current participant status and the event's claim window
+	var isAttend, isClaim, claimExpired bool
+	var claimDeadline *time.Time
+	err = h.db.QueryRow(c, `
+		SELECT p.is_attend, p.is_claim, p.claim_expired, em.claim_deadline
+		FROM participant p
+		JOIN events_metadata em ON em.event_id = p.event_id
+		WHERE p.event_id = $1 AND p.user_id = $2
+	`, req.EventID, profileUUID).Scan(&isAttend, &isClaim, &claimExpired, &claimDeadline)
 	if err != nil {
-		log.Printf("Error checking participant existence: %v", err)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Participant not found for this event. Please ensure you have registered."})
+			return
+		}
+		slog.ErrorContext(c, "Error checking participant status", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
 		return
 	}
 
-	if !participantExists {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Participant not found for this event. Please ensure you have registered."})
+	// Check if participant has checked in
+	if !isAttend {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "You must check in to the event before claiming rewards"})
 		return
 	}
 
-	// Get current participant status
-	var isAttend, isClaim bool
-	err = h.db.QueryRow(c, "SELECT is_attend, is_claim FROM participant WHERE event_id = $1 AND user_id = $2", req.EventID, profileUUID).Scan(&isAttend, &isClaim)
+	// Check if reward already claimed
+	if isClaim {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Reward has already been claimed for this event"})
+		return
+	}
+
+	if claimExpired || (claimDeadline != nil && time.Now().After(*claimDeadline)) {
+		c.JSON(http.StatusGone, gin.H{"success": false, "message": "The claim window for this event has closed", "claim_deadline": claimDeadline})
+		return
+	}
+
+	var vaultAddress string
+	err = h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", req.EventID).Scan(&vaultAddress)
 	if err != nil {
-		log.Printf("Error checking participant status: %v", err)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Event not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
 		return
 	}
 
-	// Check if participant has checked in
-	if !isAttend {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "You must check in to the event before claiming rewards"})
+	if h.client == nil || !common.IsHexAddress(vaultAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "message": "Cannot verify the claim on-chain right now"})
 		return
 	}
+	vault := common.HexToAddress(vaultAddress)
+	wallet := common.HexToAddress(req.UserID)
+
+	// Prefer the claim transaction when the client has one, otherwise ask the vault
+	var claimSource string
+	var claimTxHash *string
+	if req.TransactionHash != "" {
+		verification, err := contracts.VerifyClaimTx(c, h.client, req.TransactionHash, vault, wallet, h.cfg.TxConfirmations)
+		if err != nil {
+			slog.WarnContext(c, "Claim tx could not be verified", "tx_hash", req.TransactionHash, "event_id", req.EventID, "error", err)
+			c.JSON(chainReadStatus(err), gin.H{"success": false, "message": "Failed to verify the claim transaction"})
+			return
+		}
+		if status, details, ok := txRejection(verification, h.cfg.TxConfirmations); !ok {
+			details["success"] = false
+			if verification.State == contracts.TxPending {
+				details["message"] = "Claim transaction is not confirmed yet. Retry once it has been mined."
+			} else {
+				slog.WarnContext(c, "Claim tx rejected", "tx_hash", req.TransactionHash, "event_id", req.EventID, "reason", verification.Reason)
+				details["message"] = "Transaction is not a successful claim from this event's vault"
+			}
+			c.JSON(status, details)
+			return
+		}
+		claimSource = claimSourceOnchainTx
+		claimTxHash = &req.TransactionHash
+	} else {
+		vaultContract, err := contracts.NewVaultContract(h.client, vaultAddress)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to load vault contract"})
+			return
+		}
+		claimed, err := vaultContract.HasClaimed(c, wallet)
+		if err != nil {
+			slog.ErrorContext(c, "hasClaimed failed", "profile_id", req.UserID, "vault", vaultAddress, "error", err)
+			c.JSON(chainReadStatus(err), gin.H{"success": false, "message": "Failed to read claim status from the vault"})
+			return
+		}
+		if !claimed {
+			c.JSON(http.StatusConflict, gin.H{
+				"success":       false,
+				"message":       "Reward has not been claimed on-chain. Call claim on the event vault, then retry with its transaction_hash.",
+				"vault_address": vaultAddress,
+			})
+			return
+		}
+		claimSource = claimSourceOnchainState
+	}
 
-	// Check if reward already claimed
-	if isClaim {
-		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Reward has already been claimed for this event"})
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
 		return
 	}
+	defer tx.Rollback(c)
 
 	// Update participant status to claimed
 	updateQuery := `
 		UPDATE participant
-		SET is_claim = true, updated_at = $1
-		WHERE event_id = $2 AND user_id = $3
-		RETURNING id, event_id, user_id, is_attend, is_claim, created_at, updated_at
+		SET is_claim = true, claim_source = $4, claim_transaction_hash = $5, updated_at = $1
+		WHERE event_id = $2 AND user_id = $3 AND is_claim = false
+		RETURNING id, event_id, user_id, is_attend, is_claim, claim_source, claim_transaction_hash, created_at, updated_at
 	`
 
 	var participant struct {
-		ID        string    `json:"id"`
-		EventID   int64     `json:"event_id"`
-		UserID    string    `json:"user_id"`
-		IsAttend  bool      `json:"is_attend"`
-		IsClaim   bool      `json:"is_claim"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
+		ID                   string    `json:"id"`
+		EventID              int64     `json:"event_id"`
+		UserID               string    `json:"user_id"`
+		IsAttend             bool      `json:"is_attend"`
+		IsClaim              bool      `json:"is_claim"`
+		ClaimSource          *string   `json:"claim_source"`
+		ClaimTransactionHash *string   `json:"claim_transaction_hash"`
+		CreatedAt            time.Time `json:"created_at"`
+		UpdatedAt            time.Time `json:"updated_at"`
 	}
 
 	now := time.Now()
-	err = h.db.QueryRow(c, updateQuery, now, req.EventID, profileUUID).Scan(
+	err = tx.QueryRow(c, updateQuery, now, req.EventID, profileUUID, claimSource, claimTxHash).Scan(
 		&participant.ID,
 		&participant.EventID,
 		&participant.UserID,
 		&participant.IsAttend,
 		&participant.IsClaim,
+		&participant.ClaimSource,
+		&participant.ClaimTransactionHash,
 		&participant.CreatedAt,
 		&participant.UpdatedAt,
 	)
 
 	if err != nil {
-		log.Printf("Error updating participant claim status: %v", err)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Reward has already been claimed for this event"})
+			return
+		}
+		slog.ErrorContext(c, "Error updating participant claim status", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to claim reward"})
+		return
+	}
+
+	// Keep the stake row in step with the participant
+	_, err = tx.Exec(c, `
+		UPDATE stakes
+		SET claimed = true, claimed_transaction_hash = COALESCE($3, claimed_transaction_hash), claimed_at = $4, updated_at = $4
+		WHERE event_id = $1 AND user_id = $2 AND claimed = false
+	`, req.EventID, profileUUID, claimTxHash, now)
+	if err != nil {
+		slog.ErrorContext(c, "Error syncing stake claim status", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to claim reward"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to claim reward"})
 		return
 	}
 
-	log.Printf("Successfully claimed reward for participant: event=%d, user=%s", req.EventID, req.UserID)
+	slog.InfoContext(c, "Successfully claimed reward for participant", "event_id", req.EventID, "profile_id", req.UserID, "claim_source", claimSource)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -390,7 +858,8 @@ func (h *CheckinHandler) ClaimReward(c *gin.Context) {
 	})
 }
 
-// GetParticipantStatus retrieves participant status for an event
+// GetParticipantStatus retrieves participant status, stake and reward for an event. Only
+// the wallet itself, the event organizer or an admin may read it.
 func (h *CheckinHandler) GetParticipantStatus(c *gin.Context) {
 	eventIDParam := c.Param("id")
 	userAddress := c.Param("userAddress")
@@ -402,34 +871,42 @@ func (h *CheckinHandler) GetParticipantStatus(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Getting participant status: event=%d, user=%s", eventID, userAddress)
-
-	// Get user ID from profiles table using wallet address
-	var userID *string
-	err = h.db.QueryRow(c, "SELECT id FROM profiles WHERE wallet_address = $1", userAddress).Scan(&userID)
-	if err != nil {
-		// Participant not found, return null
-		c.JSON(http.StatusOK, gin.H{"participant": nil})
-		return
+	if !strings.EqualFold(c.GetString(middleware.UserAddressKey), userAddress) {
+		organizer, err := getEventOrganizer(c, h.db, eventID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !isOrganizerOrAdmin(c, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view this participant"})
+			return
+		}
 	}
 
-	// Get participant record
-	var participant struct {
-		ID        string    `json:"id"`
-		EventID   int64     `json:"event_id"`
-		UserID    string    `json:"user_id"`
-		UserAddress string `json:"user_address"`
-		IsAttend  bool      `json:"is_attend"`
-		IsClaim   bool      `json:"is_claim"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-	}
+	slog.DebugContext(c, "Getting participant status", "event_id", eventID, "wallet", userAddress)
+
+	// Get participant record with its stake and the event status; unknown wallets
+	// and unregistered wallets both come back as a null participant. A registration
+	// made with any wallet linked to the same profile counts.
+	var participant models.ParticipantStatus
+	var vaultAddress *string
 
 	query := `
-		SELECT p.id, p.event_id, p.user_id, pr.wallet_address, p.is_attend, p.is_claim, p.created_at, p.updated_at
+		SELECT p.id, p.event_id, p.user_id, pr.wallet_address, p.is_attend, p.is_claim, p.created_at, p.updated_at,
+		       em.status, s.stake_amount::text, s.reward_amount::text, s.claimed_transaction_hash,
+		       em.claim_deadline, p.claim_expired, eo.vault_address
 		FROM participant p
 		JOIN profiles pr ON p.user_id = pr.id
-		WHERE p.event_id = $1 AND pr.wallet_address = $2
+		JOIN events_metadata em ON em.event_id = p.event_id
+		LEFT JOIN events_onchain eo ON eo.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.event_id = $1 AND LOWER(pr.wallet_address) IN ` + linkedWalletsOf("$2") + `
+		ORDER BY LOWER(pr.wallet_address) = LOWER($2) DESC
+		LIMIT 1
 	`
 
 	err = h.db.QueryRow(c, query, eventID, userAddress).Scan(
@@ -441,22 +918,58 @@ func (h *CheckinHandler) GetParticipantStatus(c *gin.Context) {
 		&participant.IsClaim,
 		&participant.CreatedAt,
 		&participant.UpdatedAt,
+		&participant.EventStatus,
+		&participant.StakeAmount,
+		&participant.RewardAmount,
+		&participant.ClaimedTransactionHash,
+		&participant.ClaimDeadline,
+		&participant.ClaimExpired,
+		&vaultAddress,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusOK, gin.H{"participant": nil})
 			return
 		}
-		log.Printf("Error getting participant status: %v", err)
+		slog.ErrorContext(c, "Error getting participant status", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
+	token := vaultToken(c, h.stakeTokens, h.tokens, vaultAddress)
+	participant.StakeAmountFormatted = formatAmount(participant.StakeAmount, token)
+	participant.RewardAmountFormatted = formatAmount(participant.RewardAmount, token)
+
+	// The vault has the final say on what a claim pays; a failed read keeps the recorded reward
+	if participant.EventStatus == models.StatusSettled && participant.IsAttend && h.client != nil &&
+		vaultAddress != nil && common.IsHexAddress(*vaultAddress) && common.IsHexAddress(participant.UserAddress) {
+		amount, err := h.claimableAmount(c, *vaultAddress, participant.UserAddress)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to read the claimable amount", "wallet", participant.UserAddress, "event_id", eventID, "error", err)
+		} else {
+			onchain := amount.String()
+			participant.OnchainClaimable = &onchain
+			// Nothing is left to claim once claimed, whatever the reward was
+			participant.Mismatch = !participant.IsClaim && claimMismatch(participant.RewardAmount, amount)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"participant": participant})
 }
 
-// GetEventParticipants retrieves all participants for an event with profile information
+// claimableAmount reads what the vault at vaultAddress would pay wallet on claiming
+func (h *CheckinHandler) claimableAmount(c *gin.Context, vaultAddress, wallet string) (*big.Int, error) {
+	vault, err := contracts.NewVaultContract(h.client, vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	return vault.ClaimableAmount(c, common.HexToAddress(wallet))
+}
+
+// GetEventParticipants retrieves a page of participants for an event with profile information.
+// Emails are only included for the event organizer or an admin. With include=balances the
+// organizer also gets each wallet's live balance of the stake token.
 func (h *CheckinHandler) GetEventParticipants(c *gin.Context) {
 	eventIDParam := c.Param("id")
 
@@ -467,52 +980,85 @@ func (h *CheckinHandler) GetEventParticipants(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Getting participants for event: %d", eventID)
+	var req models.GetEventParticipantsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.EventID = eventID
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > maxParticipantsPageSize {
+		req.Limit = maxParticipantsPageSize
+	}
+
+	orderBy, ok := participantSortColumns[req.Sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort field"})
+		return
+	}
+	switch req.Order {
+	case "asc":
+		orderBy += " ASC"
+	case "desc":
+		orderBy += " DESC"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort order"})
+		return
+	}
+
+	slog.DebugContext(c, "Getting participants", "event_id", eventID)
 
-	// Get all participants with profile information
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	showEmails := isOrganizerOrAdmin(c, organizer)
+	includeBalances := includes(c.Query("include"), "balances")
+	if includeBalances && !showEmails {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can see participant balances"})
+		return
+	}
+
+	where, args := participantFilters(req)
+
+	// Get the requested page with the total match count alongside each row
 	query := `
 		SELECT p.id, p.event_id, p.user_id, p.is_attend, p.is_claim, p.created_at, p.updated_at,
-		       pr.wallet_address, pr.email, pr.name
+		       pr.wallet_address, pr.email, pr.name, pr.avatar_url,
+		       s.stake_amount::text, s.stake_transaction_hash, p.reward_amount::text,
+		       p.notes, p.custom_fields, pr.deleted_at IS NOT NULL, pr.reputation_score,
+		       COUNT(*) OVER() AS total
 		FROM participant p
 		LEFT JOIN profiles pr ON p.user_id = pr.id
-		WHERE p.event_id = $1
-		ORDER BY p.created_at DESC
-	`
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE ` + where + `
+		ORDER BY ` + orderBy + `, p.id
+		LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
 
-	rows, err := h.db.Query(c, query, eventID)
+	rows, err := h.db.Query(c, query, args...)
 	if err != nil {
-		log.Printf("Error getting event participants: %v", err)
+		slog.ErrorContext(c, "Error getting event participants", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 	defer rows.Close()
 
-	var participants []struct {
-		ID        string     `json:"id"`
-		EventID   int64      `json:"event_id"`
-		UserID    string     `json:"user_id"`
-		IsAttend  bool       `json:"is_attend"`
-		IsClaim   bool       `json:"is_claim"`
-		CreatedAt time.Time  `json:"created_at"`
-		UpdatedAt time.Time  `json:"updated_at"`
-		WalletAddress string    `json:"user_address"`
-		Email     *string    `json:"email"`
-		Name      *string    `json:"name"`
-	}
+	participants := []models.ParticipantListItem{}
+	total := 0
 
 	for rows.Next() {
-		var participant struct {
-			ID        string     `json:"id"`
-			EventID   int64      `json:"event_id"`
-			UserID    string     `json:"user_id"`
-			IsAttend  bool       `json:"is_attend"`
-			IsClaim   bool       `json:"is_claim"`
-			CreatedAt time.Time  `json:"created_at"`
-			UpdatedAt time.Time  `json:"updated_at"`
-			WalletAddress string    `json:"user_address"`
-			Email     *string    `json:"email"`
-			Name      *string    `json:"name"`
-		}
+		var participant models.ParticipantListItem
+		var deleted bool
+		var reputationScore *float64
 
 		err := rows.Scan(
 			&participant.ID,
@@ -525,20 +1071,239 @@ func (h *CheckinHandler) GetEventParticipants(c *gin.Context) {
 			&participant.WalletAddress,
 			&participant.Email,
 			&participant.Name,
+			&participant.AvatarURL,
+			&participant.StakeAmount,
+			&participant.TransactionHash,
+			&participant.RewardAmount,
+			&participant.Notes,
+			&participant.CustomFields,
+			&deleted,
+			&reputationScore,
+			&total,
 		)
 		if err != nil {
-			log.Printf("Error scanning participant row: %v", err)
+			slog.ErrorContext(c, "Error scanning participant row", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan participant data"})
 			return
 		}
 
+		// Contact details and organizer annotations stay private to the organizer
+		if !showEmails {
+			participant.Email = nil
+			participant.Notes = nil
+			participant.CustomFields = nil
+		}
+
+		// Lists never wait on name lookups; uncached wallets show a short address until
+		// the background refresh fills them in
+		participant.DisplayName = displayName(participant.Name, resolveName(c, h.names, participant.WalletAddress, false), participant.WalletAddress)
+		if deleted {
+			participant.WalletAddress = models.RedactedWallet
+			participant.DisplayName = models.RedactedWallet
+		} else {
+			participant.AvatarURL = avatarOrIdenticon(participant.AvatarURL, participant.WalletAddress)
+		}
+		// Organizers see the coarse tier only, never the raw score
+		participant.ReputationTier = reputation.TierFor(reputationScore)
+
 		participants = append(participants, participant)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"participants": participants,
-		"count": len(participants),
-	})
+		"count":        len(participants),
+		"total":        total,
+		"page":         req.Page,
+		"limit":        req.Limit,
+		"total_pages":  (total + req.Limit - 1) / req.Limit,
+	}
+	if includeBalances {
+		token, err := h.addParticipantBalances(c, eventID, participants)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to read participant balances", "event_id", eventID, "error", err)
+			response["balances_error"] = err.Error()
+		} else {
+			response["balances_token"] = token
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportEventParticipantsCSV streams an event's participant list as CSV (organizer only).
+// Rows are written straight to the response so large events are never buffered in memory.
+func (h *CheckinHandler) ExportEventParticipantsCSV(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.GetEventParticipantsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.EventID = eventID
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can export participants"})
+		return
+	}
+
+	where, args := participantFilters(req)
+	query := `
+		SELECT pr.wallet_address, pr.name, pr.email, p.created_at, p.is_attend, p.is_claim,
+		       p.notes, p.custom_fields::text, pr.deleted_at IS NOT NULL
+		FROM participant p
+		LEFT JOIN profiles pr ON p.user_id = pr.id
+		WHERE ` + where + `
+		ORDER BY p.created_at ASC, p.id`
+
+	rows, err := h.db.Query(c, query, args...)
+	if err != nil {
+		slog.ErrorContext(c, "Error exporting participants", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("event-%d-participants-%s.csv", eventID, time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"wallet_address", "name", "email", "registered_at", "attended", "claimed", "notes", "custom_fields"})
+
+	written := 0
+	for rows.Next() {
+		var walletAddress, name, email, notes *string
+		var registeredAt time.Time
+		var attended, claimed, deleted bool
+		var customFields string
+
+		if err := rows.Scan(&walletAddress, &name, &email, &registeredAt, &attended, &claimed, &notes, &customFields, &deleted); err != nil {
+			// Headers are already sent, so the best we can do is stop and log
+			slog.ErrorContext(c, "Error scanning participant export row", "event_id", eventID, "error", err)
+			break
+		}
+
+		// As in the participant list, a deleted profile keeps its row but not its identity
+		if deleted {
+			redacted := models.RedactedWallet
+			walletAddress, name, email = &redacted, nil, nil
+		}
+
+		writer.Write([]string{
+			derefString(walletAddress),
+			derefString(name),
+			derefString(email),
+			registeredAt.UTC().Format(time.RFC3339),
+			strconv.FormatBool(attended),
+			strconv.FormatBool(claimed),
+			derefString(notes),
+			customFields,
+		})
+
+		written++
+		if written%csvFlushEvery == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.ErrorContext(c, "Error writing participant export", "event_id", eventID, "error", err)
+	}
+}
+
+// Rows written between flushes of streamed CSV exports
+const csvFlushEvery = 500
+
+// checkinColumns lists the checkins columns in the order scanCheckin reads them
+const checkinColumns = `id, event_id, user_id, user_address, qr_data, checked_in_at, is_validated, validated_at,
+		validated_by, checked_by, ip_address, device_label, device_id, client_uuid`
+
+// scanCheckin reads a row selected with checkinColumns, followed by any extra columns
+func scanCheckin(row pgx.Row, extra ...interface{}) (models.Checkin, error) {
+	var checkin models.Checkin
+	dest := []interface{}{
+		&checkin.ID,
+		&checkin.EventID,
+		&checkin.UserID,
+		&checkin.WalletAddress,
+		&checkin.QRData,
+		&checkin.CheckedInAt,
+		&checkin.IsValidated,
+		&checkin.ValidatedAt,
+		&checkin.ValidatedBy,
+		&checkin.CheckedBy,
+		&checkin.IPAddress,
+		&checkin.DeviceLabel,
+		&checkin.DeviceID,
+		&checkin.ClientUUID,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return checkin, err
+}
+
+// recordCheckin stores the check-in record with the scanner wallet, client IP and device
+func recordCheckin(ctx context.Context, q querier, req models.CreateCheckinRequest, checkedInAt time.Time) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO checkins (event_id, user_id, user_address, qr_data, checked_in_at, checked_by, ip_address, device_label, device_id, client_uuid)
+		VALUES ($1, (SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($2)), LOWER($2), $3, $4,
+		        NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)
+	`, req.EventID, req.WalletAddress, req.QRData, checkedInAt, req.CheckedBy, req.IPAddress, req.DeviceLabel, req.DeviceID, req.ClientUUID)
+	return err
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Upper bound on participants returned per page
+const maxParticipantsPageSize = 200
+
+// participantSortColumns maps the sort query values to SQL expressions
+var participantSortColumns = map[string]string{
+	"registered_at": "p.created_at",
+	"name":          "LOWER(pr.name)",
+}
+
+// participantFilters builds the WHERE clause shared by the participant list endpoints
+func participantFilters(req models.GetEventParticipantsRequest) (string, []interface{}) {
+	where := "p.event_id = $1"
+	args := []interface{}{req.EventID}
+
+	if req.AttendedOnly {
+		where += " AND p.is_attend = true"
+	}
+
+	if req.ClaimedOnly {
+		where += " AND p.is_claim = true"
+	}
+
+	if req.Search != "" {
+		args = append(args, "%"+strings.ToLower(req.Search)+"%")
+		where += " AND (LOWER(pr.wallet_address) LIKE $" + strconv.Itoa(len(args)) +
+			" OR LOWER(pr.name) LIKE $" + strconv.Itoa(len(args)) + ")"
+	}
+
+	return where, args
 }
 
 // generateQRData generates unique QR data for check-in
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"atfi-backend/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newCheckinTestHandler returns a CheckinHandler on db without a chain client or attestor
+func newCheckinTestHandler(db *pgxpool.Pool) *CheckinHandler {
+	return NewCheckinHandler(db, nil, nil, nil, nil, testConfig())
+}
+
+func TestCheckInRequiresOrganizer(t *testing.T) {
+	db := testDB(t)
+	organizer, attendee := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive, EventDate: time.Now()})
+	seedParticipant(t, db, eventID, seedProfile(t, db, attendee, "Attendee"))
+	h := newCheckinTestHandler(db)
+	body := map[string]any{"event_id": eventID, "wallet_address": attendee.Hex()}
+
+	tests := []struct {
+		name string
+		who  caller
+		want int
+	}{
+		{"attendee checking themselves in", caller{wallet: attendee}, http.StatusForbidden},
+		{"stranger", caller{wallet: newTestWallet()}, http.StatusForbidden},
+		{"organizer", caller{wallet: organizer}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.POST("/checkin", h.CheckIn)
+		if w := serveJSON(router, http.MethodPost, "/checkin", body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	var checkedBy string
+	err := db.QueryRow(context.Background(), `
+		SELECT COALESCE(checked_by, '') FROM checkins WHERE event_id = $1 AND user_address = LOWER($2)
+	`, eventID, attendee.Hex()).Scan(&checkedBy)
+	if err != nil {
+		t.Fatalf("load check-in: %v", err)
+	}
+	if !strings.EqualFold(checkedBy, organizer.Hex()) {
+		t.Errorf("checked_by = %q, want the organizer %s", checkedBy, organizer.Hex())
+	}
+}
+
+func TestCheckInUnknownEvent(t *testing.T) {
+	db := testDB(t)
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.POST("/checkin", newCheckinTestHandler(db).CheckIn)
+
+	w := serveJSON(router, http.MethodPost, "/checkin", map[string]any{"event_id": newTestEventID(), "wallet_address": newTestWallet().Hex()})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestExportEventParticipantsCSVRedactsDeletedProfiles(t *testing.T) {
+	db := testDB(t)
+	organizer, kept, deleted := newTestWallet(), newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	seedParticipant(t, db, eventID, seedProfile(t, db, kept, "Kept"))
+	seedParticipant(t, db, eventID, seedProfile(t, db, deleted, "Deleted"))
+	if _, err := db.Exec(context.Background(), "UPDATE profiles SET deleted_at = now() WHERE wallet_address = $1", deleted.Hex()); err != nil {
+		t.Fatalf("delete profile: %v", err)
+	}
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.GET("/events/:id/participants.csv", newCheckinTestHandler(db).ExportEventParticipantsCSV)
+	w := serveJSON(router, http.MethodGet, "/events/"+strconv.FormatInt(eventID, 10)+"/participants.csv", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+
+	body := w.Body.String()
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("%d CSV records, want a header and two participants", len(records))
+	}
+	got := map[string]string{}
+	for _, record := range records[1:] {
+		got[record[0]] = record[1]
+	}
+	if name, ok := got[kept.Hex()]; !ok || name != "Kept" {
+		t.Errorf("rows = %v, want %s named Kept", got, kept.Hex())
+	}
+	if name, ok := got[models.RedactedWallet]; !ok || name != "" {
+		t.Errorf("rows = %v, want a %s row without a name", got, models.RedactedWallet)
+	}
+	if strings.Contains(strings.ToLower(body), strings.ToLower(deleted.Hex())) {
+		t.Error("export contains the deleted wallet")
+	}
+}
+
+func TestParticipantFilters(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       models.GetEventParticipantsRequest
+		wantWhere string
+		wantArgs  int
+	}{
+		{"event only", models.GetEventParticipantsRequest{EventID: 7}, "p.event_id = $1", 1},
+		{"attended", models.GetEventParticipantsRequest{EventID: 7, AttendedOnly: true}, "p.event_id = $1 AND p.is_attend = true", 1},
+		{"claimed", models.GetEventParticipantsRequest{EventID: 7, ClaimedOnly: true}, "p.event_id = $1 AND p.is_claim = true", 1},
+		{"search", models.GetEventParticipantsRequest{EventID: 7, Search: "Ali"}, "p.event_id = $1 AND (LOWER(pr.wallet_address) LIKE $2 OR LOWER(pr.name) LIKE $2)", 2},
+	}
+	for _, tt := range tests {
+		where, args := participantFilters(tt.req)
+		if where != tt.wantWhere || len(args) != tt.wantArgs {
+			t.Errorf("%s: participantFilters = %q with %d args, want %q with %d", tt.name, where, len(args), tt.wantWhere, tt.wantArgs)
+		}
+	}
+
+	// Search terms are bound, lowercased, never spliced into the SQL
+	_, args := participantFilters(models.GetEventParticipantsRequest{EventID: 7, Search: "Ali'; DROP"})
+	if args[1] != "%ali'; drop%" {
+		t.Errorf("search argument = %v, want the lowercased pattern", args[1])
+	}
+}
+
+func TestExportEventParticipantsCSVRejections(t *testing.T) {
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.GET("/events/:id/participants.csv", newCheckinTestHandler(nil).ExportEventParticipantsCSV)
+	if w := serveJSON(router, http.MethodGet, "/events/abc/participants.csv", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid event ID: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	organizer := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	h := newCheckinTestHandler(db)
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/participants.csv"
+
+	tests := []struct {
+		name string
+		who  caller
+		path string
+		want int
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, path, http.StatusForbidden},
+		{"unknown event", caller{wallet: organizer}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/participants.csv", http.StatusNotFound},
+		{"admin", caller{wallet: newTestWallet(), admin: true}, path, http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.GET("/events/:id/participants.csv", h.ExportEventParticipantsCSV)
+		if w := serveJSON(router, http.MethodGet, tt.path, nil); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestExportEventParticipantsCSVFilters(t *testing.T) {
+	db := testDB(t)
+	organizer, attended, absent := newTestWallet(), newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	seedParticipant(t, db, eventID, seedProfile(t, db, absent, "Absent"))
+	attendedID := seedParticipant(t, db, eventID, seedProfile(t, db, attended, "Attended"))
+	if _, err := db.Exec(context.Background(), "UPDATE participant SET is_attend = true WHERE id = $1", attendedID); err != nil {
+		t.Fatalf("mark attended: %v", err)
+	}
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.GET("/events/:id/participants.csv", newCheckinTestHandler(db).ExportEventParticipantsCSV)
+	w := serveJSON(router, http.MethodGet, "/events/"+strconv.FormatInt(eventID, 10)+"/participants.csv?attended_only=true", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", w.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 2 || records[1][0] != attended.Hex() || records[1][4] != "true" {
+		t.Errorf("records = %v, want the header and the attended participant only", records)
+	}
+}
+
+func TestCheckInIdentifiesParticipant(t *testing.T) {
+	db := testDB(t)
+	organizer, byWallet, byID, other := newTestWallet(), newTestWallet(), newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive, EventDate: time.Now()})
+	seedParticipant(t, db, eventID, seedProfile(t, db, byWallet, ""))
+	byIDProfile := seedProfile(t, db, byID, "")
+	seedParticipant(t, db, eventID, byIDProfile)
+	otherProfile := seedProfile(t, db, other, "")
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.POST("/checkin", newCheckinTestHandler(db).CheckIn)
+
+	tests := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"neither", map[string]any{}, http.StatusBadRequest},
+		{"invalid user_id", map[string]any{"user_id": "not-a-uuid"}, http.StatusBadRequest},
+		{"wallet without a profile", map[string]any{"wallet_address": newTestWallet().Hex()}, http.StatusNotFound},
+		{"user_id and wallet of different profiles", map[string]any{"user_id": otherProfile, "wallet_address": byWallet.Hex()}, http.StatusBadRequest},
+		{"profile not registered", map[string]any{"user_id": otherProfile}, http.StatusNotFound},
+		{"lowercase wallet", map[string]any{"wallet_address": strings.ToLower(byWallet.Hex())}, http.StatusOK},
+		{"user_id", map[string]any{"user_id": byIDProfile}, http.StatusOK},
+		{"matching user_id and wallet already checked in", map[string]any{"user_id": byIDProfile, "wallet_address": byID.Hex()}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt.body["event_id"] = eventID
+		if w := serveJSON(router, http.MethodPost, "/checkin", tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestCheckInWindowAndOverride(t *testing.T) {
+	db := testDB(t)
+	organizer, early, closed := newTestWallet(), newTestWallet(), newTestWallet()
+	notStarted := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive, EventDate: time.Now().Add(24 * time.Hour)})
+	seedParticipant(t, db, notStarted, seedProfile(t, db, early, ""))
+	registrationClosed := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusRegistrationClosed, EventDate: time.Now()})
+	seedParticipant(t, db, registrationClosed, seedProfile(t, db, closed, ""))
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.POST("/checkin", newCheckinTestHandler(db).CheckIn)
+
+	w := serveJSON(router, http.MethodPost, "/checkin", map[string]any{"event_id": notStarted, "wallet_address": early.Hex()})
+	if body := decodeBody(t, w); w.Code != http.StatusConflict || body["window_opens"] == nil || body["window_closes"] == nil {
+		t.Errorf("before the window: status = %d, body = %v; want 409 with the window", w.Code, body)
+	}
+	w = serveJSON(router, http.MethodPost, "/checkin", map[string]any{"event_id": registrationClosed, "wallet_address": closed.Hex()})
+	if body := decodeBody(t, w); w.Code != http.StatusConflict || body["current_status"] != models.StatusRegistrationClosed {
+		t.Errorf("event not live: status = %d, body = %v; want 409 with current_status", w.Code, body)
+	}
+
+	// The organizer may override with a reason, which is audited
+	w = serveJSON(router, http.MethodPost, "/checkin", map[string]any{"event_id": notStarted, "wallet_address": early.Hex(), "override_reason": "Arrived for setup"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("override: status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	var audited int
+	err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM audit_log WHERE event_id = $1 AND action = 'checkin_forced'", notStarted).Scan(&audited)
+	if err != nil {
+		t.Fatalf("count audit entries: %v", err)
+	}
+	if audited != 1 {
+		t.Errorf("%d checkin_forced audit entries, want 1", audited)
+	}
+}
+
+func TestGetCheckinsChecksEventExists(t *testing.T) {
+	router := newTestRouter(caller{})
+	router.GET("/events/:id/checkins", newCheckinTestHandler(nil).GetCheckins)
+	if w := serveJSON(router, http.MethodGet, "/events/abc/checkins", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid event ID: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	eventID := seedEvent(t, db, testEvent{})
+	// Indexed on-chain but without metadata yet
+	onchainOnly := newTestEventID()
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO events_onchain (event_id, vault_address, organizer_address, stake_amount,
+		                            max_participant, registration_deadline, event_date)
+		VALUES ($1, $2, $3, 1, 10, 0, 0)
+	`, onchainOnly, newTestWallet().Hex(), newTestWallet().Hex())
+	if err != nil {
+		t.Fatalf("seed events_onchain: %v", err)
+	}
+
+	router = newTestRouter(caller{})
+	router.GET("/events/:id/checkins", newCheckinTestHandler(db).GetCheckins)
+	tests := []struct {
+		name    string
+		eventID int64
+		want    int
+	}{
+		{"event", eventID, http.StatusOK},
+		{"event without metadata", onchainOnly, http.StatusOK},
+		{"unknown event", newTestEventID(), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := serveJSON(router, http.MethodGet, "/events/"+strconv.FormatInt(tt.eventID, 10)+"/checkins", nil)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+			continue
+		}
+		if body := decodeBody(t, w); tt.want == http.StatusOK && body["total"] != float64(0) {
+			t.Errorf("%s: body = %v, want no check-ins", tt.name, body)
+		}
+	}
+}
+
+func TestValidateCheckInOnlyMarksItsEvent(t *testing.T) {
+	db := testDB(t)
+	organizer, attendee := newTestWallet(), newTestWallet()
+	profileID := seedProfile(t, db, attendee, "")
+	validated := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	other := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	seedParticipant(t, db, validated, profileID)
+	seedParticipant(t, db, other, profileID)
+	checkinID := seedCheckin(t, db, validated, attendee)
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.POST("/checkin/validate", newCheckinTestHandler(db).ValidateCheckIn)
+	if w := serveJSON(router, http.MethodPost, "/checkin/validate", map[string]any{"checkin_id": checkinID, "is_valid": true}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+
+	attended := map[int64]bool{}
+	rows, err := db.Query(context.Background(), "SELECT event_id, is_attend FROM participant WHERE user_id = $1", profileID)
+	if err != nil {
+		t.Fatalf("load participants: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventID int64
+		var isAttend bool
+		if err := rows.Scan(&eventID, &isAttend); err != nil {
+			t.Fatalf("scan participant: %v", err)
+		}
+		attended[eventID] = isAttend
+	}
+	if !attended[validated] || attended[other] {
+		t.Errorf("attendance = %v, want only event %d marked", attended, validated)
+	}
+}
+
+func TestValidateCheckInRejections(t *testing.T) {
+	h := newCheckinTestHandler(nil)
+	for _, tt := range []struct {
+		name string
+		who  caller
+		body map[string]any
+		want int
+	}{
+		{"neither ID", caller{wallet: newTestWallet()}, map[string]any{"is_valid": true}, http.StatusBadRequest},
+		{"both IDs", caller{wallet: newTestWallet()}, map[string]any{"checkin_id": "a", "checkin_ids": []string{"b"}}, http.StatusBadRequest},
+		{"anonymous", caller{}, map[string]any{"checkin_id": "a"}, http.StatusUnauthorized},
+	} {
+		router := newTestRouter(tt.who)
+		router.POST("/checkin/validate", h.ValidateCheckIn)
+		if w := serveJSON(router, http.MethodPost, "/checkin/validate", tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	db := testDB(t)
+	organizer, attendee := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+	checkinID := seedCheckin(t, db, eventID, attendee)
+
+	h = newCheckinTestHandler(db)
+	for _, tt := range []struct {
+		name string
+		who  caller
+		id   string
+		want int
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, checkinID, http.StatusForbidden},
+		{"attendee", caller{wallet: attendee}, checkinID, http.StatusForbidden},
+		{"unknown check-in", caller{wallet: organizer}, "00000000-0000-0000-0000-000000000000", http.StatusNotFound},
+		{"organizer", caller{wallet: organizer}, checkinID, http.StatusOK},
+	} {
+		router := newTestRouter(tt.who)
+		router.POST("/checkin/validate", h.ValidateCheckIn)
+		if w := serveJSON(router, http.MethodPost, "/checkin/validate", map[string]any{"checkin_id": tt.id, "is_valid": true}); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestGetCheckinsShowsScannerToOrganizerOnly(t *testing.T) {
+	db := testDB(t)
+	organizer, attendee := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+	err := recordCheckin(context.Background(), db, models.CreateCheckinRequest{
+		EventID:       eventID,
+		WalletAddress: attendee.Hex(),
+		CheckedBy:     strings.ToLower(organizer.Hex()),
+		IPAddress:     "203.0.113.7",
+		DeviceLabel:   "Door 1",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	h := newCheckinTestHandler(db)
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/checkins"
+
+	for _, tt := range []struct {
+		name        string
+		who         caller
+		wantScanner bool
+	}{
+		{"organizer", caller{wallet: organizer}, true},
+		{"attendee", caller{wallet: attendee}, false},
+		{"anonymous", caller{}, false},
+	} {
+		router := newTestRouter(tt.who)
+		router.GET("/events/:id/checkins", h.GetCheckins)
+		w := serveJSON(router, http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tt.name, w.Code)
+		}
+		checkins := decodeBody(t, w)["checkins"].([]any)
+		if len(checkins) != 1 {
+			t.Fatalf("%s: %d check-ins, want 1", tt.name, len(checkins))
+		}
+		checkin := checkins[0].(map[string]any)
+		if checkin["wallet_address"] != strings.ToLower(attendee.Hex()) {
+			t.Errorf("%s: wallet_address = %v, want the attendee", tt.name, checkin["wallet_address"])
+		}
+		if _, shown := checkin["device_label"]; shown != tt.wantScanner {
+			t.Errorf("%s: scanner details shown = %t, want %t", tt.name, shown, tt.wantScanner)
+		}
+	}
+}
+
+func TestClaimRewardRejections(t *testing.T) {
+	wallet := newTestWallet()
+	h := newCheckinTestHandler(nil)
+
+	tests := []struct {
+		name string
+		who  caller
+		path string
+		body map[string]any
+		want int
+	}{
+		{"invalid event ID", caller{wallet: wallet}, "/events/abc/claim", map[string]any{}, http.StatusBadRequest},
+		{"body disagrees with the path", caller{wallet: wallet}, "/events/1/claim", map[string]any{"event_id": 2}, http.StatusBadRequest},
+		{"no event on /claim", caller{wallet: wallet}, "/claim", map[string]any{"user_id": wallet.Hex()}, http.StatusBadRequest},
+		{"user_id not a wallet", caller{wallet: wallet}, "/events/1/claim", map[string]any{"user_id": "alice"}, http.StatusBadRequest},
+		{"another wallet", caller{wallet: wallet}, "/events/1/claim", map[string]any{"user_id": newTestWallet().Hex()}, http.StatusForbidden},
+		{"another wallet on /claim", caller{wallet: wallet}, "/claim", map[string]any{"event_id": 1, "user_id": newTestWallet().Hex()}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.POST("/events/:id/claim", h.ClaimReward)
+		router.POST("/claim", h.ClaimReward)
+		if w := serveJSON(router, http.MethodPost, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestClaimReward(t *testing.T) {
+	db := testDB(t)
+	attendee, absent := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Status: models.StatusSettled})
+	attendeeID := seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+	seedParticipant(t, db, eventID, seedProfile(t, db, absent, ""))
+	if _, err := db.Exec(context.Background(), "UPDATE participant SET is_attend = true WHERE id = $1", attendeeID); err != nil {
+		t.Fatalf("mark attended: %v", err)
+	}
+	h := newCheckinTestHandler(db)
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/claim"
+
+	tests := []struct {
+		name string
+		who  caller
+		path string
+		body map[string]any
+		want int
+	}{
+		{"no profile", caller{wallet: newTestWallet()}, path, map[string]any{}, http.StatusNotFound},
+		{"not registered", caller{wallet: attendee}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/claim", map[string]any{}, http.StatusNotFound},
+		{"did not attend", caller{wallet: absent}, path, map[string]any{}, http.StatusBadRequest},
+		// Past the rejections the claim has to be checked on-chain, and there is no chain client
+		{"own reward", caller{wallet: attendee}, path, map[string]any{}, http.StatusServiceUnavailable},
+		{"own reward on /claim", caller{wallet: attendee}, "/claim", map[string]any{"event_id": eventID}, http.StatusServiceUnavailable},
+		{"admin on behalf of a wallet", caller{wallet: newTestWallet(), admin: true}, path, map[string]any{"user_id": attendee.Hex()}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.POST("/events/:id/claim", h.ClaimReward)
+		router.POST("/claim", h.ClaimReward)
+		if w := serveJSON(router, http.MethodPost, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestGetParticipantStatusAuthorization(t *testing.T) {
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.GET("/events/:id/participants/:userAddress", newCheckinTestHandler(nil).GetParticipantStatus)
+	if w := serveJSON(router, http.MethodGet, "/events/abc/participants/"+newTestWallet().Hex(), nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid event ID: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	organizer, attendee := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+	h := newCheckinTestHandler(db)
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/participants/" + attendee.Hex()
+
+	tests := []struct {
+		name            string
+		who             caller
+		path            string
+		want            int
+		wantParticipant bool
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, path, http.StatusForbidden, false},
+		{"stranger, unknown event", caller{wallet: newTestWallet()}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/participants/" + attendee.Hex(), http.StatusNotFound, false},
+		{"the wallet itself", caller{wallet: attendee}, path, http.StatusOK, true},
+		{"the wallet itself, unknown event", caller{wallet: attendee}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/participants/" + attendee.Hex(), http.StatusOK, false},
+		{"organizer", caller{wallet: organizer}, path, http.StatusOK, true},
+		{"admin", caller{wallet: newTestWallet(), admin: true}, path, http.StatusOK, true},
+		{"older singular path", caller{wallet: attendee}, "/events/" + strconv.FormatInt(eventID, 10) + "/participant/" + attendee.Hex(), http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.GET("/events/:id/participants/:userAddress", h.GetParticipantStatus)
+		router.GET("/events/:id/participant/:userAddress", h.GetParticipantStatus)
+		w := serveJSON(router, http.MethodGet, tt.path, nil)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+			continue
+		}
+		if w.Code == http.StatusOK {
+			if got := decodeBody(t, w)["participant"] != nil; got != tt.wantParticipant {
+				t.Errorf("%s: participant returned = %v, want %v", tt.name, got, tt.wantParticipant)
+			}
+		}
+	}
+}
+
+func TestValidateCheckInRejectionRevokesAttendance(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	organizer, attendee := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	participantID := seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+	checkinID := seedCheckin(t, db, eventID, attendee)
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.POST("/checkin/validate", newCheckinTestHandler(db).ValidateCheckIn)
+	validate := func(valid bool) {
+		t.Helper()
+		if w := serveJSON(router, http.MethodPost, "/checkin/validate", map[string]any{"checkin_id": checkinID, "is_valid": valid}); w.Code != http.StatusOK {
+			t.Fatalf("is_valid=%v: status = %d, want 200 (%s)", valid, w.Code, w.Body)
+		}
+	}
+	state := func() (validatedAt *time.Time, attended bool, revocations int) {
+		t.Helper()
+		err := db.QueryRow(ctx, `
+			SELECT ck.validated_at, p.is_attend,
+			       (SELECT COUNT(*) FROM audit_log WHERE event_id = $1 AND action = 'attendance_revoked')
+			FROM checkins ck, participant p
+			WHERE ck.id::text = $2 AND p.id::text = $3
+		`, eventID, checkinID, participantID).Scan(&validatedAt, &attended, &revocations)
+		if err != nil {
+			t.Fatalf("load state: %v", err)
+		}
+		return
+	}
+
+	validate(true)
+	if validatedAt, attended, _ := state(); validatedAt == nil || !attended {
+		t.Fatalf("after validating: validated_at = %v, attended = %v; want both set", validatedAt, attended)
+	}
+
+	validate(false)
+	if validatedAt, attended, revocations := state(); validatedAt != nil || attended || revocations != 1 {
+		t.Errorf("after rejecting: validated_at = %v, attended = %v, revocations = %d; want NULL, false and one audit entry", validatedAt, attended, revocations)
+	}
+
+	// Rejecting again has no attendance left to take back
+	validate(false)
+	if _, _, revocations := state(); revocations != 1 {
+		t.Errorf("second rejection: %d revocations, want still 1", revocations)
+	}
+}
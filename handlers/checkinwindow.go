@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"time"
+
+	"atfi-backend/config"
+	"atfi-backend/models"
+)
+
+// How far ahead of the server clock an offline scanner's timestamp may be
+const maxClientClockSkew = 5 * time.Minute
+
+// checkInWindow returns when check-in opens and closes for an event starting at eventDate
+func checkInWindow(eventDate int64, window config.CheckInWindow) (time.Time, time.Time) {
+	start := time.Unix(eventDate, 0).UTC()
+	return start.Add(-window.OpensBefore), start.Add(window.ClosesAfter)
+}
+
+// checkInOpen reports whether participants may check in: the event must be LIVE and
+// now must fall inside the window around event_date
+func checkInOpen(status string, eventDate int64, now time.Time, window config.CheckInWindow) bool {
+	if status != models.StatusLive {
+		return false
+	}
+	opens, closes := checkInWindow(eventDate, window)
+	return !now.Before(opens) && !now.After(closes)
+}
+
+// offlineScanAccepted reports whether a check-in scanned offline at scannedAt can still be
+// synced. The event must still be LIVE, so entries arriving after settlement are rejected,
+// and scannedAt must fall inside the check-in window and not be ahead of the server clock
+// by more than the allowed skew.
+func offlineScanAccepted(status string, eventDate int64, scannedAt, now time.Time, window config.CheckInWindow) bool {
+	if scannedAt.After(now.Add(maxClientClockSkew)) {
+		return false
+	}
+	return checkInOpen(status, eventDate, scannedAt, window)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"atfi-backend/config"
+	"atfi-backend/models"
+)
+
+func TestCheckInOpen(t *testing.T) {
+	window := config.CheckInWindow{OpensBefore: 2 * time.Hour, ClosesAfter: 6 * time.Hour}
+	eventDate := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		status string
+		now    time.Time
+		want   bool
+	}{
+		{"before the window", models.StatusLive, eventDate.Add(-2*time.Hour - time.Second), false},
+		{"as the window opens", models.StatusLive, eventDate.Add(-2 * time.Hour), true},
+		{"at the event date", models.StatusLive, eventDate, true},
+		{"as the window closes", models.StatusLive, eventDate.Add(6 * time.Hour), true},
+		{"after the window", models.StatusLive, eventDate.Add(6*time.Hour + time.Second), false},
+		{"registration still open", models.StatusRegistrationOpen, eventDate, false},
+		{"registration closed", models.StatusRegistrationClosed, eventDate, false},
+		{"settled", models.StatusSettled, eventDate, false},
+		{"voided", models.StatusVoided, eventDate, false},
+	}
+	for _, tt := range tests {
+		if got := checkInOpen(tt.status, eventDate.Unix(), tt.now, window); got != tt.want {
+			t.Errorf("%s: checkInOpen = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	opens, closes := checkInWindow(eventDate.Unix(), window)
+	if !opens.Equal(eventDate.Add(-2*time.Hour)) || !closes.Equal(eventDate.Add(6*time.Hour)) {
+		t.Errorf("checkInWindow = %s to %s, want 16:00 to 00:00", opens, closes)
+	}
+}
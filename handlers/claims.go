@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/contracts"
+	"atfi-backend/health"
+	"atfi-backend/models"
+	"atfi-backend/rewards"
+)
+
+// claimDeadlineFor returns the requested deadline, or the end of the claim window from
+// settledAt
+func claimDeadlineFor(settledAt time.Time, requested *time.Time, window time.Duration) time.Time {
+	if requested != nil {
+		return *requested
+	}
+	return settledAt.Add(window)
+}
+
+// expireClaims flags attended, unclaimed participants of settled events whose claim window has closed
+func expireClaims(ctx context.Context, q querier) (int64, error) {
+	result, err := q.Exec(ctx, `
+		UPDATE participant p
+		SET claim_expired = true, updated_at = now()
+		FROM events_metadata em
+		WHERE em.event_id = p.event_id
+		  AND em.status = 'SETTLED'
+		  AND em.claim_deadline < now()
+		  AND p.is_attend AND NOT p.is_claim AND NOT p.claim_expired
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// RunClaimExpiryWorker expires closed claim windows every interval until ctx is cancelled,
+// beating heartbeat after each run
+func RunClaimExpiryWorker(ctx context.Context, db *pgxpool.Pool, interval time.Duration, heartbeat *health.Heartbeat) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := expireClaims(ctx, db)
+		if err != nil {
+			slog.ErrorContext(ctx, "Claim expiry run failed", "error", err)
+		} else if expired > 0 {
+			slog.InfoContext(ctx, "Marked participants as claim_expired", "expired", expired)
+		}
+		heartbeat.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimMismatch reports whether the vault's claimable amount disagrees with the recorded
+// reward by more than rounding dust
+func claimMismatch(recorded *string, onchain *big.Int) bool {
+	reward := new(big.Int)
+	if recorded != nil {
+		if _, ok := reward.SetString(*recorded, 10); !ok {
+			return true
+		}
+	}
+	return !rewards.WithinDust(reward, onchain, rewards.DustTolerance)
+}
+
+// GetUserClaims lists the settled events where a wallet attended but has not yet claimed,
+// soonest deadline first, with the total claimable amount
+func (h *StakeHandler) GetUserClaims(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+
+	rows, err := h.db.Query(c, `
+		SELECT eo.event_id, em.title, em.image_url, eo.event_date, eo.vault_address,
+		       COALESCE(s.reward_amount, p.reward_amount)::text, em.claim_deadline
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		JOIN events_metadata em ON em.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE LOWER(pr.wallet_address) = LOWER($1)
+		  AND em.status = 'SETTLED'
+		  AND p.is_attend AND NOT p.is_claim AND NOT p.claim_expired
+		  AND (em.claim_deadline IS NULL OR em.claim_deadline > now())
+		ORDER BY em.claim_deadline ASC NULLS LAST, eo.event_id
+	`, walletAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing claimable rewards", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	claims := []models.ClaimableReward{}
+	total := new(big.Int)
+	for rows.Next() {
+		var claim models.ClaimableReward
+		err := rows.Scan(
+			&claim.EventID,
+			&claim.Title,
+			&claim.ImageURL,
+			&claim.EventDate,
+			&claim.VaultAddress,
+			&claim.RewardAmount,
+			&claim.ClaimDeadline,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning claimable reward", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan claimable reward"})
+			return
+		}
+
+		if claim.RewardAmount != nil {
+			if amount, ok := new(big.Int).SetString(*claim.RewardAmount, 10); ok {
+				total.Add(total, amount)
+			}
+		}
+		claim.RewardAmountFormatted = formatAmount(claim.RewardAmount, vaultToken(c, h.stakeTokens, h.tokens, &claim.VaultAddress))
+
+		claims = append(claims, claim)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	h.addOnchainClaimable(c, walletAddress, claims)
+
+	totalAmount := total.String()
+	c.JSON(http.StatusOK, gin.H{
+		"claims": claims,
+		"totals": gin.H{
+			"count":                   len(claims),
+			"reward_amount":           totalAmount,
+			"reward_amount_formatted": formatTokenAmount(totalAmount, primaryToken(h.tokens).Decimals),
+		},
+	})
+}
+
+// addOnchainClaimable reads what each vault would pay the wallet, in one Multicall3
+// request, and flags claims whose recorded reward disagrees. A failed read leaves
+// onchain_claimable null and the recorded reward as it is.
+func (h *StakeHandler) addOnchainClaimable(c *gin.Context, walletAddress string, claims []models.ClaimableReward) {
+	if h.client == nil || !common.IsHexAddress(walletAddress) {
+		return
+	}
+
+	var vaults []common.Address
+	var indexes []int
+	for i, claim := range claims {
+		if common.IsHexAddress(claim.VaultAddress) {
+			vaults = append(vaults, common.HexToAddress(claim.VaultAddress))
+			indexes = append(indexes, i)
+		}
+	}
+	if len(vaults) == 0 {
+		return
+	}
+
+	amounts, err := contracts.NewMulticaller(h.client).ClaimableAmounts(c, common.HexToAddress(walletAddress), vaults)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read claimable amounts", "wallet", walletAddress, "error", err)
+		return
+	}
+	for i, amount := range amounts {
+		claim := &claims[indexes[i]]
+		if amount.Err != nil {
+			slog.ErrorContext(c, "Failed to read the claimable amount", "wallet", walletAddress, "vault", amount.Vault.Hex(), "error", amount.Err)
+			continue
+		}
+		onchain := amount.Amount.String()
+		claim.OnchainClaimable = &onchain
+		claim.Mismatch = claimMismatch(claim.RewardAmount, amount.Amount)
+	}
+}
+
+// GetUserLockedStakes lists the stakes a wallet still has locked in events that are not
+// settled or voided, soonest event first, with their total and the next event to unlock
+func (h *StakeHandler) GetUserLockedStakes(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+
+	rows, err := h.db.Query(c, `
+		SELECT eo.event_id, em.title, eo.event_date, em.status,
+		       COALESCE(s.stake_amount, eo.stake_amount)::text, eo.vault_address
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		JOIN events_metadata em ON em.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE LOWER(pr.wallet_address) = LOWER($1)
+		  AND em.status NOT IN ('SETTLED', 'VOIDED')
+		ORDER BY eo.event_date ASC, eo.event_id
+	`, walletAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing locked stakes", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	locked := []models.LockedStake{}
+	total := new(big.Int)
+	for rows.Next() {
+		var stake models.LockedStake
+		var vaultAddress *string
+		if err := rows.Scan(&stake.EventID, &stake.Title, &stake.EventDate, &stake.Status, &stake.StakeAmount, &vaultAddress); err != nil {
+			slog.ErrorContext(c, "Error scanning locked stake", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan locked stake"})
+			return
+		}
+
+		if amount, ok := new(big.Int).SetString(stake.StakeAmount, 10); ok {
+			total.Add(total, amount)
+		}
+		stake.StakeAmountFormatted = formatTokenAmount(stake.StakeAmount, vaultToken(c, h.stakeTokens, h.tokens, vaultAddress).Decimals)
+
+		locked = append(locked, stake)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Rows are ordered by event date, so the first one unlocks next
+	var nextUnlock gin.H
+	if len(locked) > 0 {
+		nextUnlock = gin.H{"event_id": locked[0].EventID, "event_date": locked[0].EventDate}
+	}
+
+	totalAmount := total.String()
+	c.JSON(http.StatusOK, gin.H{
+		"locked": locked,
+		"totals": gin.H{
+			"count":                  len(locked),
+			"stake_amount":           totalAmount,
+			"stake_amount_formatted": formatTokenAmount(totalAmount, primaryToken(h.tokens).Decimals),
+		},
+		"next_unlock": nextUnlock,
+	})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"atfi-backend/config"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
+	"atfi-backend/migrations"
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	testDBOnce sync.Once
+	testPool   *pgxpool.Pool
+	testDBErr  error
+)
+
+// testDB returns a pool on TEST_DATABASE_URL with the base schema and every migration
+// applied, skipping the test when it is unset. Point it at a disposable database: tests
+// leave their rows behind, each using its own events and wallets so they never collide.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	testDBOnce.Do(func() {
+		ctx := context.Background()
+		testPool, testDBErr = pgxpool.New(ctx, dbURL)
+		if testDBErr != nil {
+			return
+		}
+		var schema []byte
+		if schema, testDBErr = os.ReadFile("testdata/base_schema.sql"); testDBErr != nil {
+			return
+		}
+		if _, testDBErr = testPool.Exec(ctx, string(schema)); testDBErr != nil {
+			return
+		}
+		testDBErr = migrations.Apply(ctx, testPool)
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+	return testPool
+}
+
+// testConfig is the configuration handlers are built with in tests
+func testConfig() *config.Config {
+	return &config.Config{
+		Chain:           contracts.Chain{ID: 84532, Name: "Base Sepolia"},
+		TxConfirmations: 1,
+		ClaimWindow:     config.DefaultClaimWindow,
+		CheckInWindow:   config.CheckInWindow{OpensBefore: config.DefaultCheckInOpensBefore, ClosesAfter: config.DefaultCheckInClosesAfter},
+		Auth:            config.Auth{QRSigningSecret: testQRSecret},
+	}
+}
+
+// newTestWallet returns a random wallet address
+func newTestWallet() common.Address {
+	var address common.Address
+	rand.Read(address[:])
+	return address
+}
+
+// newTestEventID returns a random event ID unlikely to be used by another test
+func newTestEventID() int64 {
+	id, _ := rand.Int(rand.Reader, big.NewInt(1<<50))
+	return id.Int64() + 1
+}
+
+// testEvent describes an event for seedEvent; zero fields get workable defaults
+type testEvent struct {
+	Organizer       common.Address
+	Vault           common.Address
+	Status          string
+	MaxParticipants int64
+	Deadline        time.Time
+	EventDate       time.Time
+	StakeAmount     string
+	VaultUnverified bool // the factory watcher has not vouched for the vault yet
+}
+
+// seedEvent inserts an event's on-chain row and metadata and returns its ID
+func seedEvent(t *testing.T, db *pgxpool.Pool, event testEvent) int64 {
+	t.Helper()
+	if event.Organizer == (common.Address{}) {
+		event.Organizer = newTestWallet()
+	}
+	if event.Vault == (common.Address{}) {
+		event.Vault = newTestWallet()
+	}
+	if event.Status == "" {
+		event.Status = models.StatusRegistrationOpen
+	}
+	if event.MaxParticipants == 0 {
+		event.MaxParticipants = 100
+	}
+	if event.Deadline.IsZero() {
+		event.Deadline = time.Now().Add(24 * time.Hour)
+	}
+	if event.EventDate.IsZero() {
+		event.EventDate = event.Deadline.Add(24 * time.Hour)
+	}
+	if event.StakeAmount == "" {
+		event.StakeAmount = "10000000"
+	}
+
+	eventID := newTestEventID()
+	ctx := context.Background()
+	_, err := db.Exec(ctx, `
+		INSERT INTO events_onchain (event_id, vault_address, organizer_address, stake_amount,
+		                            max_participant, registration_deadline, event_date, vault_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, eventID, event.Vault.Hex(), event.Organizer.Hex(), event.StakeAmount, event.MaxParticipants,
+		event.Deadline.Unix(), event.EventDate.Unix(), !event.VaultUnverified)
+	if err != nil {
+		t.Fatalf("seed events_onchain: %v", err)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO events_metadata (event_id, title, status) VALUES ($1, 'Test event', $2)
+	`, eventID, event.Status)
+	if err != nil {
+		t.Fatalf("seed events_metadata: %v", err)
+	}
+	return eventID
+}
+
+// seedProfile creates a profile for wallet and returns its ID
+func seedProfile(t *testing.T, db *pgxpool.Pool, wallet common.Address, name string) string {
+	t.Helper()
+	var id string
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO profiles (id, wallet_address, name, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, NULLIF($2, ''), now(), now())
+		RETURNING id
+	`, wallet.Hex(), name).Scan(&id)
+	if err != nil {
+		t.Fatalf("seed profile: %v", err)
+	}
+	return id
+}
+
+// seedParticipant registers profileID for eventID and returns the participant ID
+func seedParticipant(t *testing.T, db *pgxpool.Pool, eventID int64, profileID string) string {
+	t.Helper()
+	var id string
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO participant (event_id, user_id) VALUES ($1, $2) RETURNING id
+	`, eventID, profileID).Scan(&id)
+	if err != nil {
+		t.Fatalf("seed participant: %v", err)
+	}
+	return id
+}
+
+// caller is who a test request is authenticated as; the zero caller is anonymous
+type caller struct {
+	wallet  common.Address
+	admin   bool
+	indexer bool
+}
+
+// newTestRouter returns a router that authenticates every request as who, standing in
+// for middleware.Authenticate, with the gin context carrying the request's context as in
+// main
+func newTestRouter(who caller) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(func(c *gin.Context) {
+		if who.wallet != (common.Address{}) {
+			c.Set(middleware.UserAddressKey, strings.ToLower(who.wallet.Hex()))
+			c.Set(middleware.IsAdminKey, who.admin)
+		}
+		if who.indexer {
+			c.Set(middleware.IsIndexerKey, true)
+		}
+		c.Next()
+	})
+	return router
+}
+
+// serveJSON sends body, encoded as JSON unless nil, and returns the recorded response
+func serveJSON(router http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, _ := json.Marshal(body)
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeBody decodes a JSON response body
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not JSON: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+// seedCheckin records an unvalidated check-in of wallet for eventID and returns its ID
+func seedCheckin(t *testing.T, db *pgxpool.Pool, eventID int64, wallet common.Address) string {
+	t.Helper()
+	ctx := context.Background()
+	if err := recordCheckin(ctx, db, models.CreateCheckinRequest{EventID: eventID, WalletAddress: wallet.Hex()}, time.Now()); err != nil {
+		t.Fatalf("seed check-in: %v", err)
+	}
+	var id string
+	err := db.QueryRow(ctx, `
+		SELECT id::text FROM checkins WHERE event_id = $1 AND user_address = LOWER($2)
+		ORDER BY checked_in_at DESC LIMIT 1
+	`, eventID, wallet.Hex()).Scan(&id)
+	if err != nil {
+		t.Fatalf("load seeded check-in: %v", err)
+	}
+	return id
+}
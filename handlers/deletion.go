@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"atfi-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// walletDeleted is true when the wallet in walletExpr belongs to a deleted profile
+func walletDeleted(walletExpr string) string {
+	return `EXISTS (
+		SELECT 1 FROM profiles dpr
+		WHERE LOWER(dpr.wallet_address) = LOWER(` + walletExpr + `) AND dpr.deleted_at IS NOT NULL
+	)`
+}
+
+// DeleteProfile honors a data-deletion request: name, email and avatar are cleared, linked
+// wallets are released and the wallet is redacted from check-in lists and exports.
+// Participant and stake rows are kept for settlement. Deleting again is a no-op.
+func (h *UserHandler) DeleteProfile(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var profileID uuid.UUID
+	var email, avatarKey *string
+	var deletedAt *time.Time
+	err = tx.QueryRow(c, `
+		SELECT id, email, avatar_key, deleted_at
+		FROM profiles
+		WHERE LOWER(wallet_address) = LOWER($1)
+		FOR UPDATE
+	`, walletAddress).Scan(&profileID, &email, &avatarKey, &deletedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading profile for deletion", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if deletedAt != nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	_, err = tx.Exec(c, `
+		UPDATE profiles
+		SET name = NULL, email = NULL, email_verified_at = NULL, avatar_url = NULL, avatar_key = NULL,
+		    deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, profileID)
+	if err != nil {
+		slog.ErrorContext(c, "Database error deleting profile", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	// Release wallets linked to the profile, and the profile's wallet from any other profile
+	_, err = tx.Exec(c, "DELETE FROM profile_wallets WHERE profile_id = $1 OR wallet_address = LOWER($2)", profileID, walletAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	// Mail still queued for the address would otherwise go out after deletion
+	if email != nil {
+		_, err = tx.Exec(c, "DELETE FROM email_outbox WHERE recipient = $1 AND sent_at IS NULL", *email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+			return
+		}
+	}
+	if _, err = tx.Exec(c, "DELETE FROM email_verifications WHERE profile_id = $1", profileID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	// The audit entry records who asked, not what was removed
+	details := map[string]interface{}{"profile_id": profileID}
+	if err := recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "profile_deleted", nil, details); err != nil {
+		slog.ErrorContext(c, "Error recording profile deletion", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	h.deleteAvatarFile(c, avatarKey)
+	slog.InfoContext(c, "Deleted profile", "profile_id", profileID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/contracts"
+	"atfi-backend/health"
+	"atfi-backend/mailer"
+)
+
+// maxEmailAttempts is how many times a queued email is tried before it is abandoned
+const maxEmailAttempts = 8
+
+// emailBackoff returns the delay before retrying after the given number of failed attempts
+func emailBackoff(attempts int) time.Duration {
+	delay := time.Minute << uint(attempts-1)
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
+// enqueueEmail adds a message to the outbox. Call it inside the transaction that
+// triggers the email so it is only sent if that work commits.
+func enqueueEmail(ctx context.Context, q querier, msg mailer.Message) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO email_outbox (recipient, subject, text_body, html_body)
+		VALUES ($1, $2, $3, $4)
+	`, msg.To, msg.Subject, msg.Text, msg.HTML)
+	return err
+}
+
+// eventLocation is the event's timezone, falling back to UTC when unset or unknown
+func eventLocation(timezone *string) *time.Location {
+	if timezone != nil {
+		if loc, err := time.LoadLocation(*timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// ticketURL links to the participant's ticket page in the frontend at appBaseURL
+func ticketURL(appBaseURL string, eventID int64) string {
+	return strings.TrimRight(appBaseURL, "/") + fmt.Sprintf("/events/%d/ticket", eventID)
+}
+
+// enqueueRegistrationConfirmation queues the confirmation email for a new registration, with
+// the stake shown in token and a ticket link into the frontend at appBaseURL. Nothing is
+// queued when the profile has no verified email address.
+func enqueueRegistrationConfirmation(ctx context.Context, q querier, appBaseURL string, eventID int64, userID, stakeAmount string, token contracts.Token) error {
+	var email *string
+	var title string
+	var eventDate int64
+	var timezone *string
+	var verified bool
+	err := q.QueryRow(ctx, `
+		SELECT pr.email, pr.email_verified_at IS NOT NULL, em.title, eo.event_date, em.timezone
+		FROM profiles pr, events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE pr.id = $1 AND eo.event_id = $2
+	`, userID, eventID).Scan(&email, &verified, &title, &eventDate, &timezone)
+	if err != nil {
+		return err
+	}
+	if email == nil || *email == "" || !verified {
+		return nil
+	}
+
+	date := time.Unix(eventDate, 0).In(eventLocation(timezone)).Format("Monday, January 2, 2006 at 3:04 PM MST")
+	stake := formatTokenAmount(stakeAmount, token.Decimals) + " " + token.Symbol
+	link := ticketURL(appBaseURL, eventID)
+
+	text := fmt.Sprintf("You're registered for %s.\n\nDate: %s\nStake: %s\n\nView your ticket: %s\n", title, date, stake, link)
+	htmlBody := fmt.Sprintf(
+		"<p>You're registered for <strong>%s</strong>.</p><p>Date: %s<br>Stake: %s</p><p><a href=\"%s\">View your ticket</a></p>",
+		html.EscapeString(title), html.EscapeString(date), html.EscapeString(stake), html.EscapeString(link),
+	)
+
+	return enqueueEmail(ctx, q, mailer.Message{
+		To:      *email,
+		Subject: "You're registered for " + title,
+		Text:    text,
+		HTML:    htmlBody,
+	})
+}
+
+// RunEmailOutboxWorker sends due outbox emails every interval until ctx is cancelled.
+// Failed sends are retried with exponential backoff up to maxEmailAttempts. heartbeat is
+// beaten after each run.
+func RunEmailOutboxWorker(ctx context.Context, db *pgxpool.Pool, sender mailer.Sender, interval time.Duration, heartbeat *health.Heartbeat) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var runErr error
+		for {
+			sent, err := sendNextEmail(ctx, db, sender)
+			if err != nil {
+				slog.ErrorContext(ctx, "Email outbox run failed", "error", err)
+				runErr = err
+			}
+			if !sent {
+				break
+			}
+		}
+		heartbeat.Beat(runErr)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendNextEmail locks and sends one due email, reporting whether one was found
+func sendNextEmail(ctx context.Context, db *pgxpool.Pool, sender mailer.Sender) (bool, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	var attempts int
+	var msg mailer.Message
+	err = tx.QueryRow(ctx, `
+		SELECT id, recipient, subject, text_body, html_body, attempts
+		FROM email_outbox
+		WHERE sent_at IS NULL AND attempts < $1 AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, maxEmailAttempts).Scan(&id, &msg.To, &msg.Subject, &msg.Text, &msg.HTML, &attempts)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if sendErr := sender.Send(ctx, msg); sendErr != nil {
+		attempts++
+		slog.WarnContext(ctx, "Email send failed", "email_id", id, "attempt", attempts, "max_attempts", maxEmailAttempts, "error", sendErr)
+		slog.DebugContext(ctx, "Email send failed", "email_id", id, "to", msg.To)
+		_, err = tx.Exec(ctx, `
+			UPDATE email_outbox SET attempts = $2, last_error = $3, next_attempt_at = $4 WHERE id = $1
+		`, id, attempts, sendErr.Error(), time.Now().Add(emailBackoff(attempts)))
+	} else {
+		_, err = tx.Exec(ctx, "UPDATE email_outbox SET attempts = attempts + 1, sent_at = now() WHERE id = $1", id)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
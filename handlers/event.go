@@ -2,34 +2,68 @@ package handlers
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/config"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
 	"atfi-backend/models"
+	"atfi-backend/pricing"
 )
 
 type EventHandler struct {
-	db     *pgxpool.Pool
-	client *ethclient.Client
+	db          *pgxpool.Pool
+	client      contracts.Caller
+	names       *contracts.NameResolver // nil when the chain has no name registry
+	tokens      []contracts.Token       // first entry is the chain's primary token
+	stakeTokens *contracts.StakeTokens  // each vault's stake token; nil without a client
+	blockTimes  *contracts.BlockTimes   // nil without a client
+	gas         *contracts.GasOracle
+	prices      pricing.Source // nil when no price source is configured
+	vaults      *vaultVerifier
+	signer      *contracts.Transactor // nil when server-side settlement is disabled
+	signatures  *contracts.SignatureVerifier
+	cfg         *config.Config
+
+	participantSync    eventLocks
+	settlements        eventLocks
+	settlementMonitors sync.Map // settlement tx hashes being monitored
+	organizers         organizerCache
+	yields             yieldCache
 }
 
-func NewEventHandler(db *pgxpool.Pool, client *ethclient.Client) *EventHandler {
-	return &EventHandler{
-		db:     db,
-		client: client,
+// NewEventHandler creates the event handlers. factory may be nil, in which case vault
+// addresses are trusted as stored, and so may signer, which disables server-side settlement.
+func NewEventHandler(db *pgxpool.Pool, client contracts.Caller, names *contracts.NameResolver, tokens []contracts.Token, prices pricing.Source, factory *contracts.FactoryContract, signer *contracts.Transactor, cfg *config.Config) *EventHandler {
+	h := &EventHandler{
+		db:         db,
+		client:     client,
+		names:      names,
+		tokens:     tokens,
+		prices:     prices,
+		vaults:     newVaultVerifier(factory),
+		signer:     signer,
+		signatures: contracts.NewSignatureVerifier(client),
+		cfg:        cfg,
 	}
+	if client != nil {
+		h.gas = contracts.NewGasOracle(client)
+		h.stakeTokens = contracts.NewStakeTokens(client)
+		h.blockTimes = contracts.NewBlockTimes(client)
+	}
+	return h
 }
 
 func (h *EventHandler) CreateEvent(c *gin.Context) {
@@ -41,18 +75,24 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		Description     string `json:"description"`
 		ImageURL        string `json:"image_url"`
 		OrganizerAddress string `json:"organizer_address"`
+		RotatingQR      bool   `json:"rotating_qr"` // require rotating QR codes at check-in
+		CheckinMode     string `json:"checkin_mode" binding:"omitempty,oneof=staff_scan self_service"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.CheckinMode == "" {
+		req.CheckinMode = models.CheckinModeStaffScan
+	}
 
-	log.Printf("Creating event metadata for EventID: %d, Title: %s, Organizer: %s", req.EventID, req.Title, req.OrganizerAddress)
+	slog.InfoContext(c, "Creating event metadata", "event_id", req.EventID, "title", req.Title, "organizer", req.OrganizerAddress)
 
-	// Verify that on-chain data exists in events_onchain table (should be inserted by indexer)
+	// Verify that on-chain data exists in events_onchain table (should be inserted by indexer).
+	// Rows the factory watcher has not finalized yet could still be reorged away.
 	var onchainExists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM events_onchain WHERE event_id = $1)", req.EventID + 1).Scan(&onchainExists)
+	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM events_onchain WHERE event_id = $1 AND finalized)", req.EventID + 1).Scan(&onchainExists)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify on-chain event data", "details": err.Error()})
 		return
@@ -63,16 +103,30 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	// Insert event metadata into database
+	organizer, err := getEventOrganizer(c, h.db, req.EventID + 1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can edit this event"})
+		return
+	}
+	isOrganizer := strings.EqualFold(c.GetString(middleware.UserAddressKey), organizer)
+
+	// Insert event metadata into database. Re-posting an existing event only edits its
+	// details: the status moves through UpdateEventStatus, and the check-in settings
+	// are the organizer's to change.
 	metadataQuery := `
-		INSERT INTO events_metadata (event_id, title, description, image_url, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO events_metadata (event_id, title, description, image_url, status, rotating_qr, checkin_mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (event_id) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
 			image_url = EXCLUDED.image_url,
-			status = EXCLUDED.status
-		RETURNING event_id, title, description, image_url, status
+			rotating_qr = CASE WHEN $8 THEN EXCLUDED.rotating_qr ELSE events_metadata.rotating_qr END,
+			checkin_mode = CASE WHEN $8 THEN EXCLUDED.checkin_mode ELSE events_metadata.checkin_mode END
+		RETURNING event_id, title, description, image_url, status, rotating_qr, checkin_mode
 	`
 
 	var metadata models.EventMetadata
@@ -82,16 +136,21 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		req.Description,
 		req.ImageURL,
 		"REGISTRATION_OPEN", // Initial status
+		req.RotatingQR,
+		req.CheckinMode,
+		isOrganizer,
 	).Scan(
 		&metadata.EventID,
 		&metadata.Title,
 		&metadata.Description,
 		&metadata.ImageURL,
 		&metadata.Status,
+		&metadata.RotatingQR,
+		&metadata.CheckinMode,
 	)
 
 	if err != nil {
-		log.Printf("Failed to create event metadata: %v", err)
+		slog.ErrorContext(c, "Failed to create event metadata", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event metadata", "details": err.Error()})
 		return
 	}
@@ -126,7 +185,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 	)
 
 	if err != nil {
-		log.Printf("Failed to retrieve complete event details: %v", err)
+		slog.ErrorContext(c, "Failed to retrieve complete event details", "error", err)
 		// Still return the metadata even if we can't get the full details
 		c.JSON(http.StatusCreated, metadata)
 		return
@@ -138,7 +197,11 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 	eventDetail.ImageURL = imageURL
 	eventDetail.OrganizerName = "" // Default empty organizer name
 
-	log.Printf("Successfully created complete event for EventID: %d", req.EventID)
+	// Check the indexer's vault address against the factory while the event is new
+	vaultVerified := eventDetail.VaultAddress != "" && h.vaultTrusted(c, eventDetail.EventID, eventDetail.VaultAddress)
+	eventDetail.VaultVerified = &vaultVerified
+
+	slog.InfoContext(c, "Successfully created complete event", "event_id", req.EventID)
 	c.JSON(http.StatusCreated, eventDetail)
 }
 
@@ -156,9 +219,11 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 		SELECT
 			eo.event_id, eo.vault_address, eo.organizer_address, eo.stake_amount,
 			eo.max_participant, eo.registration_deadline, eo.event_date,
-			em.title, em.description, em.image_url, em.status
+			em.title, em.description, em.image_url, em.status, op.name, op.avatar_url,
+			em.settlement_block IS NOT NULL
 		FROM events_onchain eo
 		JOIN events_metadata em ON eo.event_id = em.event_id
+		LEFT JOIN profiles op ON LOWER(op.wallet_address) = LOWER(eo.organizer_address)
 		WHERE 1=1
 	`
 	args := []interface{}{}
@@ -179,20 +244,22 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	query += " ORDER BY eo.event_id DESC LIMIT $" + strconv.Itoa(argIndex) + " OFFSET $" + strconv.Itoa(argIndex+1)
 	args = append(args, limit, offset)
 
-	log.Printf("Executing query: %s with args: %v", query, args)
+	slog.DebugContext(c, "Executing query", "query", query, "args", args)
 	rows, err := h.db.Query(c, query, args...)
 	if err != nil {
-		log.Printf("Database query error in GetEvents: %v", err)
+		slog.ErrorContext(c, "Database query error in GetEvents", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
 		return
 	}
 	defer rows.Close()
 
 	var events []models.EventDetail
+	now := time.Now()
 	for rows.Next() {
 		var event models.EventDetail
 		var stakeAmountStr string
-		var description, imageURL *string
+		var description, imageURL, organizerName *string
+		var settledOnchain bool
 
 		err := rows.Scan(
 			&event.EventID,
@@ -206,9 +273,12 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 			&description,
 			&imageURL,
 			&event.Status,
+			&organizerName,
+			&event.OrganizerAvatarURL,
+			&settledOnchain,
 		)
 		if err != nil {
-			log.Printf("Error scanning event row: %v", err)
+			slog.ErrorContext(c, "Error scanning event row", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan event", "details": err.Error()})
 			return
 		}
@@ -217,24 +287,14 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 		event.StakeAmount = stakeAmountStr
 		event.Description = description
 		event.ImageURL = imageURL
-		event.OrganizerName = "" // Default empty organizer name
-
-		// Get current participants from smart contract if vault address exists
-		var currentParticipants int64 = 0
-		if event.VaultAddress != "" {
-			if participantCount, err := h.getParticipantCountFromContract(event.VaultAddress); err == nil {
-				currentParticipants = participantCount.Int64()
-				log.Printf("Event %d has %d participants from contract", event.EventID, currentParticipants)
-			} else {
-				log.Printf("Failed to get participant count for event %d: %v", event.EventID, err)
-			}
-		}
-
-		// Add current participants to the event response
-		event.CurrentParticipants = int(currentParticipants)
+		event.OrganizerName = displayName(organizerName, resolveName(c, h.names, event.OrganizerAddress, false), event.OrganizerAddress)
+		setDerivedPhase(&event, settledOnchain, now)
 		events = append(events, event)
 	}
 
+	// Get current participants from the smart contracts of events with a vault
+	h.fillParticipantCounts(c, events)
+
 	// Get total count
 	countQuery := `
 		SELECT COUNT(*)
@@ -258,10 +318,10 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	}
 
 	var total int
-	log.Printf("Executing count query: %s with args: %v", countQuery, countArgs)
+	slog.DebugContext(c, "Executing count query", "query", countQuery, "args", countArgs)
 	err = h.db.QueryRow(c, countQuery, countArgs...).Scan(&total)
 	if err != nil {
-		log.Printf("Failed to get total count - Query: %s, Args: %v, Error: %v", countQuery, countArgs, err)
+		slog.ErrorContext(c, "Failed to get total count", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total count", "details": err.Error()})
 		return
 	}
@@ -284,20 +344,40 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
-	// Query joining events_onchain and events_metadata
+	// Query joining events_onchain and events_metadata, with participant and
+	// check-in counts aggregated for this single event only
 	query := `
 		SELECT
 			eo.event_id, eo.vault_address, eo.organizer_address, eo.stake_amount,
 			eo.max_participant, eo.registration_deadline, eo.event_date,
-			em.title, em.description, em.image_url, em.status
+			em.title, em.description, em.image_url, em.status, em.claim_deadline,
+			COALESCE(p.registered, 0), COALESCE(p.attended, 0),
+			ck.validated, ck.pending, op.name, op.avatar_url, em.settlement_block IS NOT NULL
 		FROM events_onchain eo
 		JOIN events_metadata em ON eo.event_id = em.event_id
+		LEFT JOIN profiles op ON LOWER(op.wallet_address) = LOWER(eo.organizer_address)
+		LEFT JOIN (
+			SELECT event_id,
+			       COUNT(*) AS registered,
+			       COUNT(*) FILTER (WHERE is_attend) AS attended
+			FROM participant
+			WHERE event_id = $1
+			GROUP BY event_id
+		) p ON p.event_id = eo.event_id
+		CROSS JOIN (
+			SELECT COUNT(*) FILTER (WHERE is_validated) AS validated,
+			       COUNT(*) FILTER (WHERE NOT is_validated) AS pending
+			FROM checkins
+			WHERE event_id = $1
+		) ck
 		WHERE eo.event_id = $1
 	`
 
 	var event models.EventDetail
+	var counts models.EventCounts
 	var stakeAmountStr string
-	var description, imageURL *string
+	var description, imageURL, organizerName *string
+	var settledOnchain bool
 
 	err = h.db.QueryRow(c, query, eventID).Scan(
 		&event.EventID,
@@ -311,14 +391,22 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		&description,
 		&imageURL,
 		&event.Status,
+		&event.ClaimDeadline,
+		&counts.Registered,
+		&counts.Attended,
+		&counts.Validated,
+		&counts.Pending,
+		&organizerName,
+		&event.OrganizerAvatarURL,
+		&settledOnchain,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
-		log.Printf("Database query error in GetEvent: %v", err)
+		slog.ErrorContext(c, "Database query error in GetEvent", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
@@ -327,11 +415,19 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 	event.StakeAmount = stakeAmountStr
 	event.Description = description
 	event.ImageURL = imageURL
-	event.OrganizerName = "" // Default empty organizer name
+	event.OrganizerName = displayName(organizerName, resolveName(c, h.names, event.OrganizerAddress, true), event.OrganizerAddress)
+	event.Counts = &counts
+	setDerivedPhase(&event, settledOnchain, time.Now())
+	canRegister := registrationBlocked(event.Status, event.RegistrationDeadline, event.MaxParticipants, counts.Registered, time.Now()) == ""
+	event.CanRegister = &canRegister
+
+	// Only a vault the factory vouches for is read
+	vaultVerified := event.VaultAddress != "" && h.vaultTrusted(c, event.EventID, event.VaultAddress)
+	event.VaultVerified = &vaultVerified
 
 	// Get participant count from smart contractFailed to get total count
-	if event.VaultAddress != "" {
-		if participantCount, err := h.getParticipantCountFromContract(event.VaultAddress); err == nil {
+	if vaultVerified {
+		if participantCount, err := h.getParticipantCountFromContract(c, event.VaultAddress); err == nil {
 			// Add participant count to response
 			c.JSON(http.StatusOK, gin.H{
 				"event":            event,
@@ -339,92 +435,170 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 			})
 			return
 		} else {
-			log.Printf("Failed to get participant count for event %d: %v", event.EventID, err)
+			slog.WarnContext(c, "Failed to get participant count", "event_id", event.EventID, "error", err)
 		}
 	}
 
 	c.JSON(http.StatusOK, event)
 }
 
-func (h *EventHandler) SettleEvent(c *gin.Context) {
-	eventID := c.Param("id")
+// ConfirmSettlement handles confirmation from frontend after successful blockchain settlement
+// (organizer or admin only)
+func (h *EventHandler) ConfirmSettlement(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
 
-	// Check if event exists and get current status
-	var status string
-	query := `
-		SELECT status
-		FROM events_metadata
-		WHERE event_id = $1
-	`
+	var req struct {
+		TransactionHash string        `json:"transaction_hash" binding:"required"`
+		AttendedParticipants []string `json:"attended_participants" binding:"required"`
+		ClaimDeadline   *time.Time    `json:"claim_deadline"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	err := h.db.QueryRow(c, query, eventID).Scan(&status)
+	now := time.Now()
+	if req.ClaimDeadline != nil && !req.ClaimDeadline.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "claim_deadline must be in the future"})
+		return
+	}
+	claimDeadline := claimDeadlineFor(now, req.ClaimDeadline, h.cfg.ClaimWindow)
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
-
-	if status != "LIVE" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Event is not live"})
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can confirm settlement"})
 		return
 	}
 
-	// Update event status
-	updateQuery := `
-		UPDATE events_metadata
-		SET status = 'SETTLED', updated_at = $1
-		WHERE event_id = $2
-	`
+	slog.InfoContext(c, "Confirming settlement", "event_id", eventID, "tx_hash", req.TransactionHash, "attended_participants", len(req.AttendedParticipants))
 
-	_, err = h.db.Exec(c, updateQuery, time.Now(), eventID)
+	// The settlement must be on-chain before the event is marked settled
+	var vaultAddress *string
+	err = h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Event settled successfully"})
-}
-
-// ConfirmSettlement handles confirmation from frontend after successful blockchain settlement
-func (h *EventHandler) ConfirmSettlement(c *gin.Context) {
-	eventID := c.Param("id")
-
-	var req struct {
-		TransactionHash string        `json:"transaction_hash" binding:"required"`
-		AttendedParticipants []string `json:"attended_participants" binding:"required"`
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
 	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cannot verify the settlement on-chain right now"})
 		return
 	}
+	if !h.requireTrustedVault(c, eventID, *vaultAddress) {
+		return
+	}
+	vault := common.HexToAddress(*vaultAddress)
 
-	log.Printf("Confirming settlement for event %s: tx=%s, participants=%d",
-		eventID, req.TransactionHash, len(req.AttendedParticipants))
-
-	// Update event status to SETTLED in events_metadata table
-	updateQuery := `
-		UPDATE events_metadata
-		SET status = 'SETTLED', updated_at = $1
-		WHERE event_id = $2
-	`
-
-	_, err := h.db.Exec(c, updateQuery, time.Now(), eventID)
+	verification, err := contracts.VerifySettlementTx(c, h.client, req.TransactionHash, vault, h.cfg.TxConfirmations)
 	if err != nil {
-		log.Printf("Database error updating event %s: %v", eventID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status", "details": err.Error()})
+		slog.WarnContext(c, "Settlement tx could not be verified", "tx_hash", req.TransactionHash, "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), gin.H{"error": "Failed to verify the settlement transaction"})
+		return
+	}
+	if status, details, ok := txRejection(verification, h.cfg.TxConfirmations); !ok {
+		if verification.State == contracts.TxPending {
+			details["error"] = "pending"
+			details["message"] = "Settlement transaction is not confirmed yet. Retry once it has been mined."
+		} else {
+			slog.WarnContext(c, "Settlement tx rejected", "tx_hash", req.TransactionHash, "event_id", eventID, "reason", verification.Reason)
+			details["error"] = "Transaction did not settle this event's vault"
+		}
+		c.JSON(status, details)
+		return
+	}
+	settled, err := contracts.SettledInReceipt(verification.Receipt, vault)
+	if err != nil {
+		slog.ErrorContext(c, "Settlement tx has an unreadable Settled log", "tx_hash", req.TransactionHash, "event_id", eventID, "error", err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Transaction did not settle this event's vault", "details": err.Error()})
 		return
 	}
 
-	log.Printf("Successfully updated event %s status to SETTLED", eventID)
+	outcome, err := h.recordSettlement(c, c.GetString(middleware.UserAddressKey), eventID, req.TransactionHash,
+		verification, settled, req.AttendedParticipants, claimDeadline, now)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to record the settlement", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm settlement", "details": err.Error()})
+		return
+	}
+	if outcome.Review != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":               "attendee_count_mismatch",
+			"message":             "The on-chain settlement does not match the submitted attendees. The event has been flagged for admin review.",
+			"onchain_attendees":   settled.AttendedCount.String(),
+			"submitted_attendees": outcome.Submitted,
+		})
+		return
+	}
+	result := outcome.Rewards
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withTxLink(gin.H{
 		"message": "Event settlement confirmed successfully",
 		"transaction_hash": req.TransactionHash,
-	})
+		"block_number": verification.BlockNumber,
+		"claim_deadline": claimDeadline,
+		"onchain": gin.H{
+			"attended":            settled.AttendedCount.String(),
+			"no_shows":            settled.NoShowCount.String(),
+			"reward_per_attendee": settled.RewardPerAttendee.String(),
+		},
+		"rewards": gin.H{
+			"attendees": len(result.Rewards),
+			"forfeited": result.Forfeited.String(),
+			"yield":     result.Yield.String(),
+		},
+	}, req.TransactionHash, h.cfg.Chain))
+}
+
+// Reasons registrationBlocked gives for refusing a registration
+const (
+	registrationReasonClosed   = "registration_closed"
+	registrationReasonStarted  = "already_started"
+	registrationReasonSettled  = "settled"
+	registrationReasonCanceled = "cancelled"
+	registrationReasonDeadline = "deadline_passed"
+	registrationReasonFull     = "event_full"
+)
+
+// registrationBlocked returns why a new registration is refused, or "" when it is allowed
+func registrationBlocked(status string, deadline, maxParticipants int64, registered int, now time.Time) string {
+	switch status {
+	case models.StatusRegistrationOpen:
+	case models.StatusLive:
+		return registrationReasonStarted
+	case models.StatusSettled:
+		return registrationReasonSettled
+	case models.StatusVoided:
+		return registrationReasonCanceled
+	default:
+		return registrationReasonClosed
+	}
+
+	if deadline > 0 && now.Unix() > deadline {
+		return registrationReasonDeadline
+	}
+	if maxParticipants > 0 && int64(registered) >= maxParticipants {
+		return registrationReasonFull
+	}
+	return ""
 }
 
 func (h *EventHandler) RegisterUser(c *gin.Context) {
@@ -440,68 +614,167 @@ func (h *EventHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Registering user for event %d: address=%s, tx=%s, amount=%s",
-		req.EventID, req.UserAddress, req.TransactionHash, req.DepositAmount)
+	slog.InfoContext(c, "Registering user", "event_id", req.EventID, "wallet", req.UserAddress, "tx_hash", req.TransactionHash, "deposit_amount", req.DepositAmount)
 
-	// Get user ID from profiles table using wallet address
-	var userID *string
-	err := h.db.QueryRow(c, "SELECT id FROM profiles WHERE wallet_address = $1", req.UserAddress).Scan(&userID)
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Error querying user profile: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error when checking user profile"})
+	if !isBaseUnitAmount(req.DepositAmount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deposit_amount must be a non-negative integer in token base units"})
 		return
 	}
 
-	// If user doesn't exist in profiles, create a basic profile
-	if userID == nil {
-		userID = new(string)
-		insertProfileQuery := `
-			INSERT INTO profiles (wallet_address, created_at, updated_at)
-			VALUES ($1, $2, $3)
-			RETURNING id
-		`
-		err = h.db.QueryRow(c, insertProfileQuery, req.UserAddress, time.Now(), time.Now()).Scan(userID)
-		if err != nil {
-			log.Printf("Error creating user profile: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user profile"})
+	// Status, deadline and capacity in one round trip
+	var status string
+	var deadline, maxParticipants int64
+	var registered int
+	var vaultAddress string
+	err := h.db.QueryRow(c, `
+		SELECT em.status, eo.registration_deadline, eo.max_participant,
+		       (SELECT COUNT(*) FROM participant p WHERE p.event_id = eo.event_id),
+		       COALESCE(eo.vault_address, '')
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE eo.event_id = $1
+	`, req.EventID).Scan(&status, &deadline, &maxParticipants, &registered, &vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
-		log.Printf("Created new profile for user %s with ID %s", req.UserAddress, *userID)
+		slog.ErrorContext(c, "Error loading event for registration", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if reason := registrationBlocked(status, deadline, maxParticipants, registered, time.Now()); reason != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "Registration is not open for this event",
+			"reason":         reason,
+			"current_status": status,
+		})
+		return
 	}
 
-	// Check if participant already exists
-	var existingParticipant int
-	err = h.db.QueryRow(c, "SELECT COUNT(*) FROM participant WHERE event_id = $1 AND user_id = $2", req.EventID, *userID).Scan(&existingParticipant)
+	// The stake must be on-chain before the registration is recorded
+	if h.client == nil || !common.IsHexAddress(vaultAddress) || !common.IsHexAddress(req.UserAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cannot verify the registration on-chain right now"})
+		return
+	}
+	verification, err := contracts.VerifyRegistrationTx(c, h.client, req.TransactionHash, common.HexToAddress(vaultAddress), common.HexToAddress(req.UserAddress), h.cfg.TxConfirmations)
 	if err != nil {
-		log.Printf("Error checking existing participant: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		slog.WarnContext(c, "Registration tx could not be verified", "tx_hash", req.TransactionHash, "event_id", req.EventID, "error", err)
+		c.JSON(chainReadStatus(err), gin.H{"error": "Failed to verify the registration transaction"})
+		return
+	}
+	if status, details, ok := txRejection(verification, h.cfg.TxConfirmations); !ok {
+		if verification.State == contracts.TxPending {
+			details["error"] = "Registration transaction is not confirmed yet. Retry once it has been mined."
+		} else {
+			slog.WarnContext(c, "Registration tx rejected", "tx_hash", req.TransactionHash, "event_id", req.EventID, "reason", verification.Reason)
+			details["error"] = "Transaction is not a deposit into this event's vault"
+		}
+		c.JSON(status, details)
 		return
 	}
 
-	if existingParticipant > 0 {
+	// Get user ID from profiles table using wallet address, creating a basic profile if needed.
+	// This is the staking wallet's own row even when it is linked to another profile, so
+	// settlement and claims keep matching the wallet that staked on-chain.
+	profileID, err := ensureProfile(c, h.db, req.UserAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error resolving user profile", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve user profile"})
+		return
+	}
+	userID := &profileID
+
+	// One registration per person: another wallet linked to the same profile may already hold it
+	var linkedRegistered bool
+	err = h.db.QueryRow(c, `
+		SELECT EXISTS(
+			SELECT 1 FROM participant p
+			JOIN profiles pr ON pr.id = p.user_id
+			WHERE p.event_id = $1 AND LOWER(pr.wallet_address) IN `+linkedWalletsOf("$2")+`
+		)
+	`, req.EventID, req.UserAddress).Scan(&linkedRegistered)
+	if err != nil {
+		slog.ErrorContext(c, "Error checking linked wallet registrations", "wallet", req.UserAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if linkedRegistered {
 		c.JSON(http.StatusConflict, gin.H{"error": "Already registered for this event"})
 		return
 	}
 
-	// Create participant record
+	// While people are queued, only the wallet holding the current promotion slot may register
+	allowed, err := checkWaitlistSlot(c, h.db, req.EventID, req.UserAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error checking waitlist", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !allowed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event has a waitlist. Join the waitlist to be offered a spot."})
+		return
+	}
+
+	// Create participant record and the stake backing it in one transaction. The unique
+	// (event_id, user_id) constraint settles concurrent duplicate registrations, and the
+	// lock on the event row below concurrent ones for the last seats.
 	insertQuery := `
 		INSERT INTO participant (event_id, user_id, is_attend, is_claim, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT ON CONSTRAINT participant_event_user_key DO NOTHING
 		RETURNING id, event_id, user_id, is_attend, is_claim, created_at, updated_at
 	`
 
 	var participant struct {
-		ID        string    `json:"id"`
-		EventID   int64     `json:"event_id"`
-		UserID    string    `json:"user_id"`
-		IsAttend  bool      `json:"is_attend"`
-		IsClaim   bool      `json:"is_claim"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
+		ID              string    `json:"id"`
+		EventID         int64     `json:"event_id"`
+		UserID          string    `json:"user_id"`
+		IsAttend        bool      `json:"is_attend"`
+		IsClaim         bool      `json:"is_claim"`
+		CreatedAt       time.Time `json:"created_at"`
+		UpdatedAt       time.Time `json:"updated_at"`
+		DepositAmount   string    `json:"deposit_amount"`
+		TransactionHash string    `json:"transaction_hash"`
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// The check above ran without a lock; repeat it holding the event row so registrations
+	// for the same event are counted one at a time
+	err = tx.QueryRow(c, `
+		SELECT em.status, eo.registration_deadline, eo.max_participant
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE eo.event_id = $1
+		FOR UPDATE OF eo
+	`, req.EventID).Scan(&status, &deadline, &maxParticipants)
+	if err == nil {
+		err = tx.QueryRow(c, "SELECT COUNT(*) FROM participant WHERE event_id = $1", req.EventID).Scan(&registered)
+	}
+	if err != nil {
+		slog.ErrorContext(c, "Error locking event for registration", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if reason := registrationBlocked(status, deadline, maxParticipants, registered, time.Now()); reason != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "Registration is not open for this event",
+			"reason":         reason,
+			"current_status": status,
+		})
+		return
 	}
 
 	now := time.Now()
-	err = h.db.QueryRow(c, insertQuery, req.EventID, *userID, false, false, now, now).Scan(
+	err = tx.QueryRow(c, insertQuery, req.EventID, *userID, false, false, now, now).Scan(
 		&participant.ID,
 		&participant.EventID,
 		&participant.UserID,
@@ -511,73 +784,284 @@ func (h *EventHandler) RegisterUser(c *gin.Context) {
 		&participant.UpdatedAt,
 	)
 
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already registered for this event"})
+		return
+	}
+
 	if err != nil {
-		log.Printf("Error creating participant record: %v", err)
+		slog.ErrorContext(c, "Error creating participant record", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register participant"})
+		return
+	}
+
+	stakeQuery := `
+		INSERT INTO stakes (event_id, user_id, wallet_address, stake_amount, stake_transaction_hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING stake_amount::text, stake_transaction_hash
+	`
+	err = tx.QueryRow(c, stakeQuery, req.EventID, *userID, req.UserAddress, req.DepositAmount, req.TransactionHash, now).Scan(
+		&participant.DepositAmount,
+		&participant.TransactionHash,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "stakes_stake_transaction_hash_key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "This transaction has already been used for a registration"})
+			return
+		}
+		slog.ErrorContext(c, "Error recording stake", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register participant"})
+		return
+	}
+
+	if err := markWaitlistRegistered(c, tx, req.EventID, req.UserAddress); err != nil {
+		slog.WarnContext(c, "Failed to close waitlist entry", "wallet", req.UserAddress, "event_id", req.EventID, "error", err)
+	}
+
+	if err := enqueueRegistrationConfirmation(c, tx, h.cfg.AppBaseURL, req.EventID, *userID, participant.DepositAmount, h.displayToken(c, &vaultAddress)); err != nil {
+		slog.ErrorContext(c, "Error queueing confirmation email", "wallet", req.UserAddress, "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register participant"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		slog.ErrorContext(c, "Error committing registration", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register participant"})
 		return
 	}
 
 	// Log the transaction for record keeping
-	log.Printf("Participant registered: event=%d, user=%s, tx=%s", req.EventID, req.UserAddress, req.TransactionHash)
+	slog.InfoContext(c, "Participant registered", "event_id", req.EventID, "wallet", req.UserAddress, "tx_hash", req.TransactionHash)
 
-	c.JSON(http.StatusCreated, gin.H{
+	c.JSON(http.StatusCreated, withTxLink(gin.H{
 		"success": true,
 		"message": "Successfully registered for event",
 		"participant": participant,
-	})
+	}, req.TransactionHash, h.cfg.Chain))
 }
 
+// GetUserRegistration returns everything the ticket screen needs for the authenticated
+// wallet: registration, stake, check-in record, the signed QR payload (created on first
+// request) and whether check-in is currently open
 func (h *EventHandler) GetUserRegistration(c *gin.Context) {
-	eventID := c.Param("id")
-	userAddress := c.Query("user")
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
 
+	userAddress := c.Query("user")
 	if userAddress == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User address is required"})
 		return
 	}
 
+	// The QR payload is a bearer credential, so only its owner may fetch it
+	if !strings.EqualFold(c.GetString(middleware.UserAddressKey), userAddress) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own registration"})
+		return
+	}
+
 	var registration struct {
-		ID              string    `json:"id"`
-		EventID         string    `json:"event_id"`
-		UserAddress     string    `json:"user_address"`
-		QRData          string    `json:"qr_data"`
-		CheckedInAt     time.Time `json:"checked_in_at"`
-		IsValidated     bool      `json:"is_validated"`
-		ValidatedAt     *time.Time `json:"validated_at"`
-		ValidatedBy     *string   `json:"validated_by"`
+		Registered    bool            `json:"registered"`
+		ParticipantID string          `json:"participant_id"`
+		EventID       int64           `json:"event_id"`
+		UserID        string          `json:"user_id"`
+		UserAddress   string          `json:"user_address"`
+		IsAttend      bool            `json:"is_attend"`
+		IsClaim       bool            `json:"is_claim"`
+		RegisteredAt  *time.Time      `json:"registered_at"`
+		DepositAmount *string         `json:"deposit_amount"`
+		TransactionHash *string       `json:"transaction_hash"`
+		CheckIn       *models.Checkin `json:"checkin"`
+		QRPayload     *string         `json:"qr_payload"`
+		CanCheckIn    bool            `json:"can_check_in"`
 	}
+	var eventStatus string
+	var eventDate int64
 
 	query := `
-		SELECT id, event_id, user_address, qr_data, checked_in_at, is_validated, validated_at, validated_by
-		FROM checkins
-		WHERE event_id = $1 AND user_address = $2
+		SELECT p.id, p.event_id, p.user_id, pr.wallet_address, p.is_attend, p.is_claim, p.created_at,
+		       s.stake_amount::text, s.stake_transaction_hash, em.status, eo.event_date
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		JOIN events_metadata em ON em.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)
 	`
 
-	err := h.db.QueryRow(c, query, eventID, userAddress).Scan(
-		&registration.ID,
+	err = h.db.QueryRow(c, query, eventID, userAddress).Scan(
+		&registration.ParticipantID,
 		&registration.EventID,
+		&registration.UserID,
 		&registration.UserAddress,
-		&registration.QRData,
-		&registration.CheckedInAt,
-		&registration.IsValidated,
-		&registration.ValidatedAt,
-		&registration.ValidatedBy,
+		&registration.IsAttend,
+		&registration.IsClaim,
+		&registration.RegisteredAt,
+		&registration.DepositAmount,
+		&registration.TransactionHash,
+		&eventStatus,
+		&eventDate,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
 			return
 		}
+		slog.ErrorContext(c, "Database error getting registration", "event_id", eventID, "wallet", userAddress, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
+	registration.Registered = true
+
+	// Attach the check-in record if the participant has scanned in
+	checkinQuery := `
+		SELECT ` + checkinColumns + `
+		FROM checkins
+		WHERE event_id = $1 AND LOWER(user_address) = LOWER($2)
+		ORDER BY checked_in_at DESC
+		LIMIT 1
+	`
+
+	checkin, err := scanCheckin(h.db.QueryRow(c, checkinQuery, eventID, registration.UserAddress))
+	if err == nil {
+		checkin.HideScanner()
+		registration.CheckIn = &checkin
+	} else if err != pgx.ErrNoRows {
+		slog.ErrorContext(c, "Failed to load check-in", "event_id", eventID, "wallet", userAddress, "error", err)
+	}
+
+	qrPayload, err := ensureQRPayload(c, h.db, h.cfg.Auth.QRSigningSecret, registration.ParticipantID, eventID, registration.UserAddress)
+	if err == nil {
+		registration.QRPayload = &qrPayload
+	} else {
+		slog.ErrorContext(c, "Failed to load QR payload", "event_id", eventID, "wallet", userAddress, "error", err)
+	}
+
+	registration.CanCheckIn = !registration.IsAttend && checkInOpen(eventStatus, eventDate, time.Now(), h.cfg.CheckInWindow)
 
 	c.JSON(http.StatusOK, registration)
 }
 
+// Unregister withdraws the authenticated wallet's registration while the event is still
+// open, returning the on-chain call the frontend must make to recover the stake
+func (h *EventHandler) Unregister(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	userAddress := c.GetString(middleware.UserAddressKey)
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Lock the same events_onchain row RegisterUser does, so a withdrawal and a
+	// registration for the event are serialized
+	var status string
+	var vaultAddress *string
+	var registrationDeadline int64
+	err = tx.QueryRow(c, `
+		SELECT em.status, eo.registration_deadline, eo.vault_address
+		FROM events_onchain eo
+		JOIN events_metadata em ON eo.event_id = em.event_id
+		WHERE eo.event_id = $1
+		FOR UPDATE OF eo
+	`, eventID).Scan(&status, &registrationDeadline, &vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for withdrawal", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if status != models.StatusRegistrationOpen || time.Now().Unix() > registrationDeadline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                 "too_late_to_withdraw",
+			"message":               "Registrations can only be withdrawn while registration is open",
+			"status":                status,
+			"registration_deadline": registrationDeadline,
+		})
+		return
+	}
+
+	var participantID string
+	err = tx.QueryRow(c, `
+		DELETE FROM participant p
+		USING profiles pr
+		WHERE p.user_id = pr.id AND p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)
+		RETURNING p.id
+	`, eventID, userAddress).Scan(&participantID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error withdrawing registration", "event_id", eventID, "wallet", userAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw registration"})
+		return
+	}
+
+	// The stake goes back to the wallet, so its record goes with the registration
+	_, err = tx.Exec(c, `
+		DELETE FROM stakes
+		WHERE event_id = $1 AND LOWER(wallet_address) = LOWER($2)
+	`, eventID, userAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error removing stake", "event_id", eventID, "wallet", userAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw registration"})
+		return
+	}
+
+	err = recordAudit(c, tx, userAddress, "participant_withdrawn", &eventID, map[string]interface{}{
+		"participant_id": participantID,
+	})
+	if err != nil {
+		slog.ErrorContext(c, "Failed to audit withdrawal", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+		return
+	}
+
+	// The freed spot goes to the earliest waitlisted wallet
+	if _, err := promoteNextWaitlisted(c, tx, eventID); err != nil {
+		slog.ErrorContext(c, "Failed to promote waitlist", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw registration"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw registration"})
+		return
+	}
+
+	slog.InfoContext(c, "Participant withdrew", "event_id", eventID, "wallet", userAddress)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Registration withdrawn. Submit the withdrawal transaction to recover your stake.",
+		"withdrawal": gin.H{
+			"vault_address": vaultAddress,
+			"function":      contracts.WithdrawFunction,
+		},
+	})
+}
+
 func (h *EventHandler) NotifySettlement(c *gin.Context) {
-	eventID := c.Param("id")
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
 
 	var req struct {
 		Message   string `json:"message"`
@@ -590,10 +1074,9 @@ func (h *EventHandler) NotifySettlement(c *gin.Context) {
 	}
 
 	// Get event organizer
-	var organizerAddress string
-	err := h.db.QueryRow(c, "SELECT organizer_address FROM events WHERE id = $1", eventID).Scan(&organizerAddress)
+	organizerAddress, err := getEventOrganizer(c, h.db, eventID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
@@ -603,14 +1086,18 @@ func (h *EventHandler) NotifySettlement(c *gin.Context) {
 
 	// TODO: Send notification to organizer (email, push notification, etc.)
 	// For now, just log the notification
-	log.Printf("Settlement notification for event %s to organizer %s: %s", eventID, organizerAddress, req.Message)
+	slog.InfoContext(c, "Settlement notification", "event_id", eventID, "organizer", organizerAddress, "message", req.Message)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Organizer notified about settlement"})
 }
 
-// PRD 2.5: Update event status
+// PRD 2.5: Update event status (organizer or admin only)
 func (h *EventHandler) UpdateEventStatus(c *gin.Context) {
-	eventID := c.Param("id")
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
 
 	var req struct {
 		Status string `json:"status" binding:"required"`
@@ -622,113 +1109,303 @@ func (h *EventHandler) UpdateEventStatus(c *gin.Context) {
 	}
 
 	// Validate status
-	validStatuses := []string{"REGISTRATION_OPEN", "REGISTRATION_CLOSED", "LIVE", "SETTLED", "VOIDED"}
-	isValid := false
-	for _, status := range validStatuses {
-		if req.Status == status {
-			isValid = true
-			break
+	if !models.IsValidStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can change the event status"})
+		return
 	}
 
-	if !isValid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+	// Admins may force an otherwise illegal transition; it is recorded in the audit log
+	force := c.Query("force") == "true"
+	if force && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins may force a status change"})
 		return
 	}
 
-	// Update event status in events_metadata table
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Lock the row so concurrent transitions are evaluated against the latest status
+	var currentStatus string
+	err = tx.QueryRow(c, "SELECT status FROM events_metadata WHERE event_id = $1 FOR UPDATE", eventID).Scan(&currentStatus)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !force && !models.CanTransition(currentStatus, req.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":               fmt.Sprintf("Cannot change status from %s to %s", currentStatus, req.Status),
+			"current_status":      currentStatus,
+			"allowed_transitions": models.AllowedTransitions(currentStatus),
+		})
+		return
+	}
+
+	// Update event status in events_metadata table; settling opens the default claim window
 	updateQuery := `
 		UPDATE events_metadata
-		SET status = $1, updated_at = $2
+		SET status = $1, updated_at = $2,
+		    claim_deadline = CASE WHEN $1 = 'SETTLED' THEN COALESCE(claim_deadline, $4) ELSE claim_deadline END,
+		    settled_at = CASE WHEN $1 = 'SETTLED' THEN COALESCE(settled_at, $2) ELSE settled_at END
 		WHERE event_id = $3
 	`
 
-	result, err := h.db.Exec(c, updateQuery, req.Status, time.Now(), eventID)
+	now := time.Now()
+	_, err = tx.Exec(c, updateQuery, req.Status, now, eventID, claimDeadlineFor(now, nil, h.cfg.ClaimWindow))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status"})
 		return
 	}
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	// Voiding an event makes every stake refundable
+	if req.Status == models.StatusVoided {
+		created, err := createRefunds(c, tx, eventID)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to create refunds", "event_id", eventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create refunds"})
+			return
+		}
+		slog.InfoContext(c, "Created pending refunds for voided event", "created", created, "event_id", eventID)
+	}
+
+	if force {
+		err = recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "event_status_forced", &eventID, map[string]interface{}{
+			"from": currentStatus,
+			"to":   req.Status,
+		})
+		if err != nil {
+			slog.ErrorContext(c, "Failed to audit forced status change", "event_id", eventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status"})
 		return
 	}
 
-	log.Printf("Event %s status updated to %s", eventID, req.Status)
+	slog.InfoContext(c, "Event status updated", "event_id", eventID, "from", currentStatus, "to", req.Status, "force", force)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Event status updated successfully"})
 }
 
-// PRD 2.5: Get attended participants for event settlement
+// PRD 2.5: Get attended participants for event settlement. Self-service events only list
+// participants whose check-in the organizer has validated.
 func (h *EventHandler) GetAttendedParticipants(c *gin.Context) {
-	eventID := c.Param("id")
+	participants, err := attendedWallets(c, h.db, c.Param("id"))
+	if err != nil {
+		slog.ErrorContext(c, "Database query error in GetAttendedParticipants", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
-	query := `
-		SELECT pr.wallet_address
+	c.JSON(http.StatusOK, participants)
+}
+
+// GetNoShows lists registered participants who did not check in, with the stake each
+// forfeits (organizer only). With onchain=true, vault depositors missing from our
+// participant table are listed separately when the chain can be read.
+func (h *EventHandler) GetNoShows(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can view no-shows"})
+		return
+	}
+
+	// Participants without a stake row staked the event's fixed amount. Attendance the
+	// vault would not settle with, such as an unvalidated self check-in, is a no-show.
+	rows, err := h.db.Query(c, `
+		SELECT pr.wallet_address, pr.name, COALESCE(s.stake_amount, eo.stake_amount)::text
 		FROM participant p
 		JOIN profiles pr ON p.user_id = pr.id
-		WHERE p.event_id = $1 AND p.is_attend = true
-	`
-
-	rows, err := h.db.Query(c, query, eventID)
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.event_id = $1 AND NOT (p.is_attend AND `+selfServiceCountable+`)
+		ORDER BY LOWER(pr.wallet_address)
+	`, eventID)
 	if err != nil {
-		log.Printf("Database query error in GetAttendedParticipants: %v", err)
+		slog.ErrorContext(c, "Database query error in GetNoShows", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 	defer rows.Close()
 
-	var participants []string
+	noShows := []models.NoShowParticipant{}
+	forfeited := new(big.Int)
 	for rows.Next() {
-		var walletAddress string
-		err := rows.Scan(&walletAddress)
-		if err != nil {
-			log.Printf("Error scanning participant row: %v", err)
-			continue
+		var noShow models.NoShowParticipant
+		if err := rows.Scan(&noShow.WalletAddress, &noShow.Name, &noShow.StakeAmount); err != nil {
+			slog.ErrorContext(c, "Error scanning no-show row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan participant data"})
+			return
+		}
+		if amount, ok := new(big.Int).SetString(noShow.StakeAmount, 10); ok {
+			forfeited.Add(forfeited, amount)
 		}
-		participants = append(participants, walletAddress)
+		noShows = append(noShows, noShow)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
 	}
 
-	c.JSON(http.StatusOK, participants)
-}
+	response := gin.H{
+		"event_id":        eventID,
+		"no_shows":        noShows,
+		"count":           len(noShows),
+		"total_forfeited": forfeited.String(),
+		"onchain_checked": false,
+	}
 
-// Helper function to get participant count from smart contract
-func (h *EventHandler) getParticipantCountFromContract(vaultAddress string) (*big.Int, error) {
-	if h.client == nil {
-		return nil, fmt.Errorf("ethereum client not initialized")
+	if c.Query("onchain") == "true" {
+		if unregistered, err := h.unregisteredDepositors(c, eventID); err != nil {
+			slog.WarnContext(c, "Skipping on-chain no-show check", "event_id", eventID, "error", err)
+		} else {
+			response["onchain_checked"] = true
+			response["unregistered_onchain"] = unregistered
+		}
 	}
 
-	// Simple ABI for getParticipantCount function
-	vaultABI := `[{"inputs":[],"name":"getParticipantCount","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	c.JSON(http.StatusOK, response)
+}
 
-	parsedABI, err := abi.JSON(strings.NewReader(vaultABI))
+// unregisteredDepositors returns vault depositors with no participant row for the event
+func (h *EventHandler) unregisteredDepositors(ctx context.Context, eventID int64) ([]string, error) {
+	var vaultAddress string
+	err := h.db.QueryRow(ctx, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse vault ABI: %w", err)
+		return nil, err
+	}
+	if h.client == nil || !common.IsHexAddress(vaultAddress) {
+		return nil, fmt.Errorf("on-chain data unavailable for vault %q", vaultAddress)
 	}
+	if !h.vaults.trusted(ctx, h.db, eventID, vaultAddress) {
+		return nil, fmt.Errorf("vault %s is not verified against the factory", vaultAddress)
+	}
+	vault := common.HexToAddress(vaultAddress)
 
-	// Pack the function call
-	callData, err := parsedABI.Pack("getParticipantCount")
+	fromBlock, err := contracts.FindDeployBlock(ctx, h.client, vault)
+	if err != nil {
+		fromBlock = 0
+	}
+	depositors, err := contracts.FetchDepositors(ctx, h.client, vault, fromBlock)
 	if err != nil {
-		return nil, fmt.Errorf("failed to pack call data: %w", err)
+		return nil, err
 	}
 
-	// Call the smart contract
-	toAddress := common.HexToAddress(vaultAddress)
-	result, err := h.client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   &toAddress,
-		Data: callData,
-	}, nil)
+	registered, err := registeredWallets(ctx, h.db, eventID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call getParticipantCount: %w", err)
+		return nil, err
+	}
+
+	unregistered := []string{}
+	for walletAddress := range depositors {
+		if !registered[walletAddress] {
+			unregistered = append(unregistered, walletAddress)
+		}
+	}
+	sort.Strings(unregistered)
+	return unregistered, nil
+}
+
+// Listings with more vaults than this read their participant counts in one Multicall3
+// request instead of one eth_call per vault
+const multicallMinVaults = 3
+
+// fillParticipantCounts sets CurrentParticipants and VaultVerified from each event's vault.
+// As in the detail view, only vaults the factory vouches for are read; events without a
+// vault, with an unverified one, or whose vault could not be read keep zero.
+func (h *EventHandler) fillParticipantCounts(ctx context.Context, events []models.EventDetail) {
+	var withVault []int
+	for i := range events {
+		if events[i].VaultAddress == "" {
+			continue
+		}
+		verified := h.vaults.trusted(ctx, h.db, events[i].EventID, events[i].VaultAddress)
+		events[i].VaultVerified = &verified
+		if verified {
+			withVault = append(withVault, i)
+		}
+	}
+
+	if len(withVault) <= multicallMinVaults || h.client == nil {
+		for _, i := range withVault {
+			participantCount, err := h.getParticipantCountFromContract(ctx, events[i].VaultAddress)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to get participant count", "event_id", events[i].EventID, "error", err)
+				continue
+			}
+			events[i].CurrentParticipants = int(participantCount.Int64())
+		}
+		return
+	}
+
+	vaults := make([]common.Address, len(withVault))
+	for j, i := range withVault {
+		vaults[j] = common.HexToAddress(events[i].VaultAddress)
 	}
 
-	// Unpack the result
-	var participantCount *big.Int
-	err = parsedABI.UnpackIntoInterface(&participantCount, "getParticipantCount", result)
+	counts, err := contracts.NewMulticaller(h.client).ParticipantCounts(ctx, vaults)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unpack result: %w", err)
+		slog.WarnContext(ctx, "Failed to batch participant counts", "vaults", len(vaults), "error", err)
+		return
+	}
+	for j, i := range withVault {
+		if counts[j].Err != nil {
+			slog.WarnContext(ctx, "Failed to get participant count", "event_id", events[i].EventID, "error", counts[j].Err)
+			continue
+		}
+		events[i].CurrentParticipants = int(counts[j].Count.Int64())
 	}
+}
 
-	return participantCount, nil
+// getParticipantCountFromContract reads the vault's on-chain participant count
+func (h *EventHandler) getParticipantCountFromContract(ctx context.Context, vaultAddress string) (*big.Int, error) {
+	if h.client == nil {
+		return nil, fmt.Errorf("ethereum client not initialized")
+	}
+
+	vault, err := contracts.NewVaultContract(h.client, vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	return vault.GetParticipantCount(ctx)
 }
\ No newline at end of file
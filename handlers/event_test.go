@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestUpdateEventStatusAuthorization(t *testing.T) {
+	db := testDB(t)
+	organizer := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/status"
+
+	tests := []struct {
+		name   string
+		who    caller
+		query  string
+		status string
+		want   int
+	}{
+		{"stranger voiding", caller{wallet: newTestWallet()}, "", models.StatusVoided, http.StatusForbidden},
+		{"stranger forcing", caller{wallet: newTestWallet()}, "?force=true", models.StatusSettled, http.StatusForbidden},
+		{"organizer forcing", caller{wallet: organizer}, "?force=true", models.StatusSettled, http.StatusForbidden},
+		{"organizer skipping a status", caller{wallet: organizer}, "", models.StatusSettled, http.StatusConflict},
+		{"organizer closing registration", caller{wallet: organizer}, "", models.StatusRegistrationClosed, http.StatusOK},
+		{"admin forcing back open", caller{wallet: newTestWallet(), admin: true}, "?force=true", models.StatusRegistrationOpen, http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.PUT("/events/:id/status", h.UpdateEventStatus)
+		if w := serveJSON(router, http.MethodPut, path+tt.query, map[string]any{"status": tt.status}); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	var status string
+	if err := db.QueryRow(context.Background(), "SELECT status FROM events_metadata WHERE event_id = $1", eventID).Scan(&status); err != nil {
+		t.Fatalf("load status: %v", err)
+	}
+	if status != models.StatusRegistrationOpen {
+		t.Errorf("status = %s, want the admin's REGISTRATION_OPEN", status)
+	}
+}
+
+func TestFillParticipantCountsSkipsUnverifiedVaults(t *testing.T) {
+	db := testDB(t)
+	factory := newTestWallet()
+	trusted, spoofed := newTestWallet(), newTestWallet()
+	trustedID := seedEvent(t, db, testEvent{Vault: trusted, VaultUnverified: true})
+	spoofedID := seedEvent(t, db, testEvent{Vault: spoofed, VaultUnverified: true})
+
+	client := contractstest.NewClient(84532)
+	client.SetFactoryVault(factory, trustedID, trusted)
+	client.SetParticipantCount(trusted, 7)
+	client.SetParticipantCount(spoofed, 99)
+	factoryContract, err := contracts.NewFactoryContract(client, factory)
+	if err != nil {
+		t.Fatalf("NewFactoryContract: %v", err)
+	}
+	h := NewEventHandler(db, client, nil, nil, nil, factoryContract, nil, testConfig())
+
+	events := []models.EventDetail{
+		{EventID: trustedID, VaultAddress: trusted.Hex()},
+		{EventID: spoofedID, VaultAddress: spoofed.Hex()},
+		{EventID: newTestEventID()},
+	}
+	h.fillParticipantCounts(context.Background(), events)
+
+	if events[0].CurrentParticipants != 7 || events[0].VaultVerified == nil || !*events[0].VaultVerified {
+		t.Errorf("factory vault: count = %d, verified = %v; want 7 and verified", events[0].CurrentParticipants, events[0].VaultVerified)
+	}
+	if events[1].CurrentParticipants != 0 || events[1].VaultVerified == nil || *events[1].VaultVerified {
+		t.Errorf("spoofed vault: count = %d, verified = %v; want 0 and unverified", events[1].CurrentParticipants, events[1].VaultVerified)
+	}
+	if events[2].VaultVerified != nil {
+		t.Error("event without a vault reports vault_verified")
+	}
+}
+
+func TestNotifySettlementLooksUpTheOrganizer(t *testing.T) {
+	router := newTestRouter(caller{})
+	router.POST("/events/:id/notify-settlement", NewEventHandler(nil, nil, nil, nil, nil, nil, nil, testConfig()).NotifySettlement)
+	if w := serveJSON(router, http.MethodPost, "/events/abc/notify-settlement", map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid event ID: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	eventID := seedEvent(t, db, testEvent{})
+	router = newTestRouter(caller{})
+	router.POST("/events/:id/notify-settlement", NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig()).NotifySettlement)
+	for _, tt := range []struct {
+		name    string
+		eventID int64
+		want    int
+	}{
+		{"event", eventID, http.StatusOK},
+		{"unknown event", newTestEventID(), http.StatusNotFound},
+	} {
+		path := "/events/" + strconv.FormatInt(tt.eventID, 10) + "/notify-settlement"
+		if w := serveJSON(router, http.MethodPost, path, map[string]any{"message": "Ready"}); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestCreateEventAuthorization(t *testing.T) {
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.POST("/events", NewEventHandler(nil, nil, nil, nil, nil, nil, nil, testConfig()).CreateEvent)
+	if w := serveJSON(router, http.MethodPost, "/events", map[string]any{"event_id": 1}); w.Code != http.StatusBadRequest {
+		t.Errorf("no title: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	organizer := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusSettled})
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	// CreateEvent writes the metadata of event_id + 1
+	post := func(who caller, eventID int64, title string, rotating bool) int {
+		router := newTestRouter(who)
+		router.POST("/events", h.CreateEvent)
+		return serveJSON(router, http.MethodPost, "/events", map[string]any{
+			"event_id": eventID - 1, "title": title, "rotating_qr": rotating, "checkin_mode": models.CheckinModeSelfService,
+		}).Code
+	}
+	load := func() (title, status string, rotating bool, mode string) {
+		err := db.QueryRow(context.Background(), `
+			SELECT title, status, rotating_qr, checkin_mode FROM events_metadata WHERE event_id = $1
+		`, eventID).Scan(&title, &status, &rotating, &mode)
+		if err != nil {
+			t.Fatalf("load metadata: %v", err)
+		}
+		return
+	}
+
+	if got := post(caller{wallet: organizer}, newTestEventID(), "Unknown", false); got != http.StatusBadRequest {
+		t.Errorf("unknown event: status = %d, want 400", got)
+	}
+	if got := post(caller{wallet: newTestWallet()}, eventID, "Hijacked", true); got != http.StatusForbidden {
+		t.Errorf("stranger: status = %d, want 403", got)
+	}
+	if title, _, _, _ := load(); title != "Test event" {
+		t.Errorf("title = %q after a stranger's post, want it unchanged", title)
+	}
+
+	if got := post(caller{wallet: newTestWallet(), admin: true}, eventID, "Admin title", true); got != http.StatusCreated {
+		t.Errorf("admin: status = %d, want 201", got)
+	}
+	if title, status, rotating, mode := load(); title != "Admin title" || status != models.StatusSettled || rotating || mode != models.CheckinModeStaffScan {
+		t.Errorf("after the admin's post: %q %s rotating=%v %s; want the new title and the status and check-in settings kept", title, status, rotating, mode)
+	}
+
+	if got := post(caller{wallet: organizer}, eventID, "Organizer title", true); got != http.StatusCreated {
+		t.Errorf("organizer: status = %d, want 201", got)
+	}
+	if title, status, rotating, mode := load(); title != "Organizer title" || status != models.StatusSettled || !rotating || mode != models.CheckinModeSelfService {
+		t.Errorf("after the organizer's post: %q %s rotating=%v %s; want the new title and check-in settings and the status kept", title, status, rotating, mode)
+	}
+}
+
+func TestNoShowsCountUnvalidatedSelfCheckins(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	organizer, validated, unvalidated, absent := newTestWallet(), newTestWallet(), newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	if _, err := db.Exec(ctx, "UPDATE events_metadata SET checkin_mode = 'self_service' WHERE event_id = $1", eventID); err != nil {
+		t.Fatalf("enable self check-in: %v", err)
+	}
+	for _, wallet := range []common.Address{validated, unvalidated, absent} {
+		seedParticipant(t, db, eventID, seedProfile(t, db, wallet, ""))
+	}
+	for _, wallet := range []common.Address{validated, unvalidated} {
+		checkinID := seedCheckin(t, db, eventID, wallet)
+		_, err := db.Exec(ctx, `
+			UPDATE participant SET is_attend = true
+			WHERE event_id = $1 AND user_id = (SELECT id FROM profiles WHERE wallet_address = $2)
+		`, eventID, wallet.Hex())
+		if err != nil {
+			t.Fatalf("mark attended: %v", err)
+		}
+		if wallet == validated {
+			if _, err := db.Exec(ctx, "UPDATE checkins SET is_validated = true WHERE id::text = $1", checkinID); err != nil {
+				t.Fatalf("validate check-in: %v", err)
+			}
+		}
+	}
+	path := "/events/" + strconv.FormatInt(eventID, 10)
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.GET("/events/:id/no-shows", NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig()).GetNoShows)
+	w := serveJSON(router, http.MethodGet, path+"/no-shows", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("no-shows: status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	body := decodeBody(t, w)
+	noShows := map[string]bool{}
+	for _, noShow := range body["no_shows"].([]any) {
+		noShows[strings.ToLower(noShow.(map[string]any)["wallet_address"].(string))] = true
+	}
+	if len(noShows) != 2 || !noShows[strings.ToLower(unvalidated.Hex())] || !noShows[strings.ToLower(absent.Hex())] {
+		t.Errorf("no-shows = %v, want the unvalidated and the absent wallet", noShows)
+	}
+	if body["total_forfeited"] != "20000000" {
+		t.Errorf("total_forfeited = %v, want both stakes", body["total_forfeited"])
+	}
+
+	router = newTestRouter(caller{})
+	router.GET("/events/:id/stakes/stats", NewStakeHandler(db, nil, nil).GetEventStakesStats)
+	w = serveJSON(router, http.MethodGet, path+"/stakes/stats", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("stats: status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	if stats := decodeBody(t, w); stats["attended_participants"] != 1.0 || stats["no_show_participants"] != 2.0 {
+		t.Errorf("stats = %v, want 1 attended and 2 no-shows", stats)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	db := testDB(t)
+	wallet := newTestWallet()
+	open := seedEvent(t, db, testEvent{})
+	closed := seedEvent(t, db, testEvent{Status: models.StatusLive})
+	profileID := seedProfile(t, db, wallet, "")
+	seedParticipant(t, db, open, profileID)
+	seedParticipant(t, db, closed, profileID)
+
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	unregister := func(eventID int64) int {
+		router := newTestRouter(caller{wallet: wallet})
+		router.DELETE("/events/:id/registration", h.Unregister)
+		return serveJSON(router, http.MethodDelete, "/events/"+strconv.FormatInt(eventID, 10)+"/registration", nil).Code
+	}
+
+	tests := []struct {
+		name    string
+		eventID int64
+		want    int
+	}{
+		{"unknown event", newTestEventID(), http.StatusNotFound},
+		{"registration closed", closed, http.StatusConflict},
+		{"registered", open, http.StatusOK},
+		{"already withdrawn", open, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if got := unregister(tt.eventID); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// checkinCSVHeader names the columns of the check-in export, with the timezone the
+// timestamps are rendered in
+func checkinCSVHeader(loc *time.Location) []string {
+	return []string{
+		fmt.Sprintf("checked_in_at (%s)", loc),
+		"wallet_address",
+		"name",
+		"validated",
+		fmt.Sprintf("validated_at (%s)", loc),
+		"validated_by",
+		"checked_by",
+		"device",
+	}
+}
+
+// checkinCSVRecord renders one check-in for the export. The device is the scanner's
+// label, or its device ID for offline-synced check-ins without one.
+func checkinCSVRecord(checkin models.Checkin, name *string, loc *time.Location) []string {
+	validatedAt := ""
+	if checkin.ValidatedAt != nil {
+		validatedAt = checkin.ValidatedAt.In(loc).Format(time.RFC3339)
+	}
+	device := derefString(checkin.DeviceLabel)
+	if device == "" {
+		device = derefString(checkin.DeviceID)
+	}
+
+	return []string{
+		checkin.CheckedInAt.In(loc).Format(time.RFC3339),
+		checkin.WalletAddress,
+		derefString(name),
+		strconv.FormatBool(checkin.IsValidated),
+		validatedAt,
+		derefString(checkin.ValidatedBy),
+		derefString(checkin.CheckedBy),
+		device,
+	}
+}
+
+// ExportCheckinsCSV streams an event's door log as CSV (organizer only). It accepts the
+// same filters as GetCheckins and writes oldest first.
+func (h *CheckinHandler) ExportCheckinsCSV(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.GetCheckinsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.EventID = eventID
+
+	var organizer string
+	var timezone *string
+	err = h.db.QueryRow(c, `
+		SELECT eo.organizer_address, em.timezone
+		FROM events_onchain eo
+		LEFT JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE eo.event_id = $1
+	`, eventID).Scan(&organizer, &timezone)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can export check-ins"})
+		return
+	}
+
+	where, args := checkinFilters(req)
+	query := `
+		SELECT ` + checkinColumns + `, name, deleted
+		FROM (
+			SELECT ck.*, pr.name, pr.deleted_at IS NOT NULL AS deleted
+			FROM (SELECT * FROM checkins WHERE ` + where + `) ck
+			LEFT JOIN profiles pr ON LOWER(pr.wallet_address) = LOWER(ck.user_address)
+		) door_log
+		ORDER BY checked_in_at ASC, id`
+
+	rows, err := h.db.Query(c, query, args...)
+	if err != nil {
+		slog.ErrorContext(c, "Error exporting check-ins", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	loc := eventLocation(timezone)
+	filename := fmt.Sprintf("event-%d-checkins-%s.csv", eventID, time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(checkinCSVHeader(loc))
+
+	written := 0
+	for rows.Next() {
+		var name *string
+		var deleted bool
+		checkin, err := scanCheckin(rows, &name, &deleted)
+		if err != nil {
+			// Headers are already sent, so the best we can do is stop and log
+			slog.ErrorContext(c, "Error scanning check-in export row", "event_id", eventID, "error", err)
+			break
+		}
+
+		if deleted {
+			checkin.Redact()
+		}
+		writer.Write(checkinCSVRecord(checkin, name, loc))
+
+		written++
+		if written%csvFlushEvery == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.ErrorContext(c, "Error writing check-in export", "event_id", eventID, "error", err)
+	}
+}
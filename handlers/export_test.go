@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"atfi-backend/models"
+)
+
+func TestEventLocation(t *testing.T) {
+	tokyo, unknown, empty := "Asia/Tokyo", "Mars/Olympus_Mons", ""
+	tests := []struct {
+		name     string
+		timezone *string
+		want     string
+	}{
+		{"unset", nil, "UTC"},
+		{"known", &tokyo, "Asia/Tokyo"},
+		{"unknown", &unknown, "UTC"},
+		{"empty", &empty, "UTC"},
+	}
+	for _, tt := range tests {
+		if got := eventLocation(tt.timezone).String(); got != tt.want {
+			t.Errorf("%s: eventLocation = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckinCSVRecord(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no timezone data: %v", err)
+	}
+	checkedIn := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	validated := checkedIn.Add(time.Hour)
+	name, validator, scanner, label, device := "Ada", "0xorganizer", "0xscanner", "Door 1", "device-1"
+
+	header := checkinCSVHeader(tokyo)
+	if header[0] != "checked_in_at (Asia/Tokyo)" || header[4] != "validated_at (Asia/Tokyo)" {
+		t.Errorf("header = %v, want the timestamp columns labelled with the event timezone", header)
+	}
+
+	tests := []struct {
+		name    string
+		checkin models.Checkin
+		who     *string
+		want    []string
+	}{
+		{
+			"validated with a device label",
+			models.Checkin{WalletAddress: "0xabc", CheckedInAt: checkedIn, IsValidated: true, ValidatedAt: &validated,
+				ValidatedBy: &validator, CheckedBy: &scanner, DeviceLabel: &label, DeviceID: &device},
+			&name,
+			[]string{"2026-03-01T18:30:00+09:00", "0xabc", "Ada", "true", "2026-03-01T19:30:00+09:00", "0xorganizer", "0xscanner", "Door 1"},
+		},
+		{
+			"offline sync without a label",
+			models.Checkin{WalletAddress: "0xabc", CheckedInAt: checkedIn, DeviceID: &device},
+			nil,
+			[]string{"2026-03-01T18:30:00+09:00", "0xabc", "", "false", "", "", "", "device-1"},
+		},
+	}
+	for _, tt := range tests {
+		got := checkinCSVRecord(tt.checkin, tt.who, tokyo)
+		if len(got) != len(header) || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: record = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExportCheckinsCSVRejections(t *testing.T) {
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.GET("/events/:id/checkins.csv", newCheckinTestHandler(nil).ExportCheckinsCSV)
+	if w := serveJSON(router, http.MethodGet, "/events/abc/checkins.csv", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid event ID: status = %d, want 400", w.Code)
+	}
+
+	db := testDB(t)
+	organizer := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	h := newCheckinTestHandler(db)
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/checkins.csv"
+
+	tests := []struct {
+		name string
+		who  caller
+		path string
+		want int
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, path, http.StatusForbidden},
+		{"unknown event", caller{wallet: organizer}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/checkins.csv", http.StatusNotFound},
+		{"admin", caller{wallet: newTestWallet(), admin: true}, path, http.StatusOK},
+		{"organizer", caller{wallet: organizer}, path, http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.GET("/events/:id/checkins.csv", h.ExportCheckinsCSV)
+		if w := serveJSON(router, http.MethodGet, tt.path, nil); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestExportCheckinsCSV(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	organizer, attendee, deleted := newTestWallet(), newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer})
+	if _, err := db.Exec(ctx, "UPDATE events_metadata SET timezone = 'Asia/Tokyo' WHERE event_id = $1", eventID); err != nil {
+		t.Fatalf("set timezone: %v", err)
+	}
+	seedProfile(t, db, attendee, "Attendee")
+	seedProfile(t, db, deleted, "")
+	if _, err := db.Exec(ctx, "UPDATE profiles SET deleted_at = NOW() WHERE LOWER(wallet_address) = LOWER($1)", deleted.Hex()); err != nil {
+		t.Fatalf("delete profile: %v", err)
+	}
+	seedCheckin(t, db, eventID, attendee)
+	seedCheckin(t, db, eventID, deleted)
+	// Another event's check-ins stay out of the export
+	seedCheckin(t, db, seedEvent(t, db, testEvent{Organizer: organizer}), attendee)
+
+	router := newTestRouter(caller{wallet: organizer})
+	router.GET("/events/:id/checkins.csv", newCheckinTestHandler(db).ExportCheckinsCSV)
+	w := serveJSON(router, http.MethodGet, "/events/"+strconv.FormatInt(eventID, 10)+"/checkins.csv", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "event-"+strconv.FormatInt(eventID, 10)+"-checkins-") {
+		t.Errorf("Content-Disposition = %q, want the event's check-ins filename", w.Header().Get("Content-Disposition"))
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records = %v, want the header and two check-ins", records)
+	}
+	if records[0][0] != "checked_in_at (Asia/Tokyo)" {
+		t.Errorf("header = %v, want timestamps in the event timezone", records[0])
+	}
+	// Oldest first
+	if !strings.EqualFold(records[1][1], attendee.Hex()) || records[1][2] != "Attendee" {
+		t.Errorf("first row = %v, want the attendee with their name", records[1])
+	}
+	if records[2][1] != models.RedactedWallet || records[2][2] != "" {
+		t.Errorf("second row = %v, want the deleted profile redacted", records[2])
+	}
+}
@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/config"
+	"atfi-backend/contracts"
+)
+
+// How often the factory watcher looks for new blocks
+const factoryPollInterval = 15 * time.Second
+
+// factoryCheckpoint names the factory watcher's row in chain_checkpoints
+const factoryCheckpoint = "factory_event_created"
+
+// Actor recorded in the audit log for rows the factory watcher rolls back
+const factoryWatcherActor = "system:factory-watcher"
+
+// factoryClient is what the factory watcher reads: logs, and headers to check their finality
+type factoryClient interface {
+	contracts.Caller
+	contracts.HeaderReader
+}
+
+// FactoryWatcher copies the factory's EventCreated logs into events_onchain, so events
+// exist as soon as they are deployed even when the external indexer lags. Rows are
+// inserted with ON CONFLICT DO NOTHING, so the indexer can keep writing the same events.
+// The factory's own log vouches for the vault, so the watcher also marks it verified.
+//
+// Rows land unfinalized with the log's block number and hash. A second pass finalizes them
+// once the block is confirmations deep, and deletes them, with an audit entry, when the
+// block was reorged away; the reorged range is then scanned again so an event mined in the
+// replacing blocks is picked up.
+type FactoryWatcher struct {
+	db            *pgxpool.Pool
+	client        factoryClient
+	stream        *contracts.LogStream
+	factory       common.Address
+	startBlock    uint64
+	confirmations uint64
+}
+
+// NewFactoryWatcher returns a watcher for the configured factory, or nil when there is
+// none. Without a checkpoint the watcher starts at the configured start block, or at the
+// factory's deploy block when that is unset. It follows the RPC WebSocket URL when set and
+// polls client otherwise.
+func NewFactoryWatcher(ctx context.Context, db *pgxpool.Pool, client factoryClient, cfg *config.Config) (*FactoryWatcher, error) {
+	if !cfg.Factory.Enabled {
+		return nil, nil
+	}
+	factory := cfg.Factory.Address
+
+	var startBlock uint64
+	if cfg.Factory.StartBlock != nil {
+		startBlock = *cfg.Factory.StartBlock
+	} else {
+		block, err := contracts.FindDeployBlock(ctx, client, factory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find factory deploy block, set FACTORY_START_BLOCK: %w", err)
+		}
+		startBlock = block
+	}
+
+	stream := contracts.NewLogStream(client, cfg.RPC.WebSocketURL, ethereum.FilterQuery{
+		Addresses: []common.Address{factory},
+		Topics:    [][]common.Hash{{contracts.EventCreatedEventTopic}},
+	})
+	stream.PollInterval = factoryPollInterval
+	stream.OnStateChange = func(state contracts.StreamState, err error) {
+		if err != nil {
+			slog.WarnContext(ctx, "Factory watcher state changed", "state", state, "error", err)
+		} else {
+			slog.InfoContext(ctx, "Factory watcher state changed", "state", state)
+		}
+	}
+
+	return &FactoryWatcher{
+		db:            db,
+		client:        client,
+		stream:        stream,
+		factory:       factory,
+		startBlock:    startBlock,
+		confirmations: cfg.Factory.Confirmations,
+	}, nil
+}
+
+// Factory is the watched factory address
+func (w *FactoryWatcher) Factory() common.Address {
+	return w.factory
+}
+
+// State reports how the watcher is receiving logs; StreamDegraded means it is falling behind
+func (w *FactoryWatcher) State() contracts.StreamState {
+	state, _ := w.stream.State()
+	return state
+}
+
+// Run resumes from the checkpoint and follows the factory until ctx is cancelled
+func (w *FactoryWatcher) Run(ctx context.Context) {
+	from := w.startBlock
+	for {
+		checkpoint, err := loadCheckpoint(ctx, w.db, factoryCheckpoint)
+		if err == nil {
+			if checkpoint != nil {
+				from = *checkpoint + 1
+			}
+			break
+		}
+		slog.ErrorContext(ctx, "Factory watcher failed", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(factoryPollInterval):
+		}
+	}
+
+	go w.runFinalizer(ctx)
+	w.stream.Run(ctx, from, w.handle)
+}
+
+// runFinalizer settles pending rows every poll interval until ctx is cancelled
+func (w *FactoryWatcher) runFinalizer(ctx context.Context) {
+	ticker := time.NewTicker(factoryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		finalized, reorged, err := w.finalize(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Factory watcher failed to finalize events", "error", err)
+			continue
+		}
+		if finalized > 0 || reorged > 0 {
+			slog.InfoContext(ctx, "Factory watcher finalized events and rolled back reorged ones", "finalized", finalized, "reorged", reorged)
+		}
+	}
+}
+
+// pendingFactoryRow is an events_onchain row the watcher has not finalized
+type pendingFactoryRow struct {
+	eventID int64
+	vault   string
+	block   contracts.BlockRef
+}
+
+// finalize checks every pending row against the canonical chain. Rows deep enough are
+// finalized; reorged ones are deleted and their blocks scanned again.
+func (w *FactoryWatcher) finalize(ctx context.Context) (finalized, reorged int, err error) {
+	rows, err := w.db.Query(ctx, `
+		SELECT event_id, vault_address, block_number, block_hash
+		FROM events_onchain
+		WHERE NOT finalized
+		ORDER BY block_number
+	`)
+	if err != nil {
+		return 0, 0, err
+	}
+	var pending []pendingFactoryRow
+	for rows.Next() {
+		var row pendingFactoryRow
+		var number int64
+		var hash string
+		if err := rows.Scan(&row.eventID, &row.vault, &number, &hash); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		row.block = contracts.BlockRef{Number: uint64(number), Hash: common.HexToHash(hash)}
+		pending = append(pending, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(pending) == 0 {
+		return 0, 0, nil
+	}
+
+	refs := make([]contracts.BlockRef, len(pending))
+	for i, row := range pending {
+		refs[i] = row.block
+	}
+	states, err := contracts.CheckFinality(ctx, w.client, w.confirmations, refs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var rescanFrom *uint64
+	for i, row := range pending {
+		switch states[i] {
+		case contracts.FinalityFinal:
+			result, err := w.db.Exec(ctx, `
+				UPDATE events_onchain SET finalized = true
+				WHERE event_id = $1 AND block_hash = $2 AND NOT finalized
+			`, row.eventID, row.block.Hash.Hex())
+			if err != nil {
+				return finalized, reorged, fmt.Errorf("failed to finalize event %d: %w", row.eventID, err)
+			}
+			finalized += int(result.RowsAffected())
+		case contracts.FinalityReorged:
+			removed, err := w.rollBack(ctx, row)
+			if err != nil {
+				return finalized, reorged, err
+			}
+			if removed {
+				reorged++
+				if rescanFrom == nil || row.block.Number < *rescanFrom {
+					from := row.block.Number
+					rescanFrom = &from
+				}
+			}
+		}
+	}
+
+	if rescanFrom != nil {
+		if err := w.rescan(ctx, *rescanFrom); err != nil {
+			return finalized, reorged, err
+		}
+	}
+	return finalized, reorged, nil
+}
+
+// rollBack deletes a reorged row and audits it in one transaction. It reports false when
+// the row changed since it was read.
+func (w *FactoryWatcher) rollBack(ctx context.Context, row pendingFactoryRow) (bool, error) {
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		DELETE FROM events_onchain
+		WHERE event_id = $1 AND block_hash = $2 AND NOT finalized
+	`, row.eventID, row.block.Hash.Hex())
+	if err != nil {
+		return false, fmt.Errorf("failed to roll back event %d: %w", row.eventID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	err = recordAudit(ctx, tx, factoryWatcherActor, "event_reorged", &row.eventID, map[string]interface{}{
+		"vault_address": row.vault,
+		"block_number":  row.block.Number,
+		"block_hash":    row.block.Hash.Hex(),
+	})
+	if err != nil {
+		return false, err
+	}
+	slog.WarnContext(ctx, "Factory watcher rolled back event, its block was reorged", "event_id", row.eventID, "block", row.block.Number, "block_hash", row.block.Hash.Hex())
+	return true, tx.Commit(ctx)
+}
+
+// rescan stores the factory's logs from block up to the head again, since the stream has
+// already moved past blocks a reorg replaced
+func (w *FactoryWatcher) rescan(ctx context.Context, block uint64) error {
+	latest, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	logs, err := contracts.FilterLogsChunked(ctx, w.client, ethereum.FilterQuery{
+		Addresses: []common.Address{w.factory},
+		Topics:    [][]common.Hash{{contracts.EventCreatedEventTopic}},
+	}, block, latest, contracts.DefaultLogChunkSize)
+	if err != nil {
+		return fmt.Errorf("failed to rescan factory logs from block %d: %w", block, err)
+	}
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	stored, err := storeFactoryEvents(ctx, tx, parseFactoryLogs(logs))
+	if err != nil {
+		return err
+	}
+	if stored > 0 {
+		slog.InfoContext(ctx, "Factory watcher stored events from blocks replaced by a reorg", "stored", stored)
+	}
+	return tx.Commit(ctx)
+}
+
+// parseFactoryLogs decodes EventCreated logs, skipping any that do not decode
+func parseFactoryLogs(logs []types.Log) []contracts.EventCreatedEvent {
+	var events []contracts.EventCreatedEvent
+	for _, vLog := range logs {
+		event, err := contracts.ParseEventCreated(vLog)
+		if err != nil {
+			slog.Warn("Skipping factory log", "tx_hash", vLog.TxHash.Hex(), "log_index", vLog.Index, "error", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// handle stores the events in logs and moves the checkpoint to through
+func (w *FactoryWatcher) handle(ctx context.Context, logs []types.Log, through uint64) error {
+	stored, err := w.store(ctx, parseFactoryLogs(logs), through)
+	if err != nil {
+		return err
+	}
+	if stored > 0 {
+		slog.InfoContext(ctx, "Factory watcher stored new events", "stored", stored)
+	}
+	return nil
+}
+
+// store upserts events and advances the checkpoint to block in one transaction
+func (w *FactoryWatcher) store(ctx context.Context, events []contracts.EventCreatedEvent, block uint64) (int, error) {
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	stored, err := storeFactoryEvents(ctx, tx, events)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := saveCheckpoint(ctx, tx, factoryCheckpoint, block); err != nil {
+		return 0, err
+	}
+	return stored, tx.Commit(ctx)
+}
+
+// storeFactoryEvents inserts events as pending rows, returning how many were new
+func storeFactoryEvents(ctx context.Context, q querier, events []contracts.EventCreatedEvent) (int, error) {
+	stored := 0
+	for _, event := range events {
+		result, err := q.Exec(ctx, `
+			INSERT INTO events_onchain (event_id, vault_address, organizer_address, stake_amount,
+			                            max_participant, registration_deadline, event_date, vault_verified,
+			                            finalized, block_number, block_hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, true, false, $8, $9)
+			ON CONFLICT DO NOTHING
+		`, event.EventID.Int64(), event.Vault.Hex(), event.Organizer.Hex(), event.StakeAmount.String(),
+			event.MaxParticipants.Int64(), event.RegistrationDeadline.String(), event.EventDate.String(),
+			int64(event.BlockNumber), event.BlockHash.Hex())
+		if err != nil {
+			return 0, fmt.Errorf("failed to store event %s: %w", event.EventID, err)
+		}
+		stored += int(result.RowsAffected())
+
+		// A row the indexer wrote first is verified when it names the same vault
+		_, err = q.Exec(ctx, `
+			UPDATE events_onchain SET vault_verified = true
+			WHERE event_id = $1 AND LOWER(vault_address) = LOWER($2) AND NOT vault_verified
+		`, event.EventID.Int64(), event.Vault.Hex())
+		if err != nil {
+			return 0, fmt.Errorf("failed to verify the vault of event %s: %w", event.EventID, err)
+		}
+	}
+	return stored, nil
+}
+
+// loadCheckpoint returns the last block the named watcher processed, or nil before its
+// first run
+func loadCheckpoint(ctx context.Context, q querier, name string) (*uint64, error) {
+	var block int64
+	err := q.QueryRow(ctx, `SELECT block_number FROM chain_checkpoints WHERE name = $1`, name).Scan(&block)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s checkpoint: %w", name, err)
+	}
+	processed := uint64(block)
+	return &processed, nil
+}
+
+// saveCheckpoint records block as the last one the named watcher processed
+func saveCheckpoint(ctx context.Context, q querier, name string, block uint64) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO chain_checkpoints (name, block_number, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (name) DO UPDATE SET block_number = EXCLUDED.block_number, updated_at = now()
+	`, name, int64(block))
+	if err != nil {
+		return fmt.Errorf("failed to save %s checkpoint: %w", name, err)
+	}
+	return nil
+}
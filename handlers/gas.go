@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+	"atfi-backend/pricing"
+)
+
+// Actions GetGasEstimate prices
+const (
+	gasActionRegister = "register"
+	gasActionClaim    = "claim"
+	gasActionSettle   = "settle"
+)
+
+// GetGasEstimate estimates the gas and fee of registering for, claiming from or settling
+// the event, as sent from wallet. A transaction that would revert returns 422 with the
+// revert reason rather than an estimate.
+func (h *EventHandler) GetGasEstimate(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	action := c.Query("action")
+	if action != gasActionRegister && action != gasActionClaim && action != gasActionSettle {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be register, claim or settle"})
+		return
+	}
+
+	// Settlement is sent by the organizer, so the wallet may be left out
+	walletAddress := c.Query("wallet")
+	if walletAddress != "" && !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet must be a valid address"})
+		return
+	}
+	if walletAddress == "" && action != gasActionSettle {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet is required"})
+		return
+	}
+
+	var vaultAddress *string
+	var organizer string
+	err = h.db.QueryRow(c, `
+		SELECT vault_address, organizer_address FROM events_onchain WHERE event_id = $1
+	`, eventID).Scan(&vaultAddress, &organizer)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for a gas estimate", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+	if walletAddress == "" {
+		walletAddress = organizer
+	}
+
+	var data []byte
+	switch action {
+	case gasActionRegister:
+		data, err = contracts.PackVaultCall(contracts.DepositFunction)
+	case gasActionClaim:
+		data, err = contracts.PackVaultCall(contracts.ClaimFunction)
+	case gasActionSettle:
+		if !h.requireTrustedVault(c, eventID, *vaultAddress) {
+			return
+		}
+		attendees, loadErr := settlementAttendees(c, h.db, eventID)
+		if loadErr != nil {
+			slog.ErrorContext(c, "Failed to load attended wallets", "event_id", eventID, "error", loadErr)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if len(attendees) == 0 {
+			respondNoAttendees(c)
+			return
+		}
+		data, err = contracts.PackSettleEvent(attendees)
+	}
+	if err != nil {
+		slog.ErrorContext(c, "Failed to encode call", "action", action, "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode the transaction"})
+		return
+	}
+
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+
+	from := common.HexToAddress(walletAddress)
+	to := common.HexToAddress(*vaultAddress)
+	gas, err := contracts.EstimateGas(c, h.client, ethereum.CallMsg{From: from, To: &to, Data: data})
+	if err != nil {
+		if revert, ok := contracts.RevertOf(err); ok {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":          "transaction_would_revert",
+				"message":        "The transaction would fail if sent",
+				"reason":         revert.Reason,
+				"onchain_revert": revert.Name,
+			})
+			return
+		}
+		slog.ErrorContext(c, "Failed to estimate gas", "action", action, "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to estimate gas", err))
+		return
+	}
+
+	price, err := h.gas.Price(c)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read gas price", "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read the gas price", err))
+		return
+	}
+
+	gasUnits := new(big.Int).SetUint64(gas)
+	fee := new(big.Int).Mul(gasUnits, price.GasPrice)
+	maxFee := new(big.Int).Mul(gasUnits, price.MaxFeePerGas)
+
+	response := gin.H{
+		"action":                   action,
+		"from":                     from.Hex(),
+		"to":                       to.Hex(),
+		"gas":                      gas,
+		"gas_price":                price.GasPrice.String(),
+		"max_fee_per_gas":          price.MaxFeePerGas.String(),
+		"max_priority_fee_per_gas": price.MaxPriorityFeePerGas.String(),
+		"fee_wei":                  fee.String(),
+		"max_fee_wei":              maxFee.String(),
+		"fee_usd":                  nil,
+		"max_fee_usd":              nil,
+	}
+
+	// The dollar figures are a courtesy; the estimate stands without them
+	if h.prices != nil {
+		if ethUSD, err := h.prices.USDPrice(c); err != nil {
+			slog.ErrorContext(c, "Failed to read the ETH price for a gas estimate", "error", err)
+		} else {
+			response["fee_usd"] = pricing.WeiToUSD(fee, ethUSD)
+			response["max_fee_usd"] = pricing.WeiToUSD(maxFee, ethUSD)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetCheckinHistory returns every validation, rejection and undo of a check-in, oldest
+// first, together with the check-in's current state (organizer or admin only). History
+// outlives the check-in, so undone check-ins still have a trail with "checkin": null.
+func (h *CheckinHandler) GetCheckinHistory(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	// Shares the participantID wildcard with the undo route; here it holds a check-in ID
+	checkinID := c.Param("participantID")
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can view check-in history"})
+		return
+	}
+
+	var current *models.Checkin
+	checkin, err := scanCheckin(h.db.QueryRow(c, "SELECT "+checkinColumns+" FROM checkins WHERE event_id = $1 AND id::text = $2", eventID, checkinID))
+	switch {
+	case err == nil:
+		current = &checkin
+	case err != pgx.ErrNoRows:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT id, checkin_id, event_id, action, actor, previous_validated, previous_validated_at,
+		       previous_validated_by, reason, device_label, ip_address, created_at
+		FROM checkin_audit
+		WHERE event_id = $1 AND checkin_id::text = $2
+		ORDER BY id
+	`, eventID, checkinID)
+	if err != nil {
+		slog.ErrorContext(c, "Error querying history", "checkin_id", checkinID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	history := []models.CheckinAuditEntry{}
+	for rows.Next() {
+		var entry models.CheckinAuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.CheckinID,
+			&entry.EventID,
+			&entry.Action,
+			&entry.Actor,
+			&entry.PreviousValidated,
+			&entry.PreviousValidatedAt,
+			&entry.PreviousValidatedBy,
+			&entry.Reason,
+			&entry.DeviceLabel,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning history", "checkin_id", checkinID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if current == nil && len(history) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check-in not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"checkin_id": checkinID,
+		"event_id":   eventID,
+		"checkin":    current,
+		"history":    history,
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Check-in idempotency keys are scoped per event and replayed for this long
+const (
+	idempotencyTTL         = 48 * time.Hour
+	maxIdempotencyKeyLen   = 200
+	headerIdempotencyKey   = "Idempotency-Key"
+	headerIdempotentReplay = "Idempotent-Replayed"
+)
+
+// idempotencyKey returns the request's operation key: the Idempotency-Key header, or
+// the client_uuid body field when the header is absent
+func idempotencyKey(c *gin.Context, bodyKey string) string {
+	if key := c.GetHeader(headerIdempotencyKey); key != "" {
+		return key
+	}
+	return bodyKey
+}
+
+// replayCheckin writes the stored response for a key seen in the last idempotencyTTL.
+// It reports whether a response was replayed.
+func replayCheckin(c *gin.Context, q querier, eventID int64, key string) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+
+	var status int
+	var body []byte
+	err := q.QueryRow(c, `
+		SELECT status_code, response
+		FROM checkin_idempotency
+		WHERE event_id = $1 AND key = $2 AND created_at > $3
+	`, eventID, key, time.Now().Add(-idempotencyTTL)).Scan(&status, &body)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	c.Header(headerIdempotentReplay, "true")
+	c.Data(status, "application/json; charset=utf-8", body)
+	return true, nil
+}
+
+// saveCheckinResponse stores the response for a key so retries get it back. An expired
+// key is overwritten; expired keys for the event are purged along the way.
+func saveCheckinResponse(ctx context.Context, q querier, eventID int64, key string, status int, response gin.H) error {
+	if key == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-idempotencyTTL)
+	if _, err := q.Exec(ctx, "DELETE FROM checkin_idempotency WHERE event_id = $1 AND created_at <= $2", eventID, cutoff); err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO checkin_idempotency (event_id, key, status_code, response)
+		VALUES ($1, $2, $3, $4)
+	`, eventID, key, status, body)
+	return err
+}
+
+// rejectIdempotencyKey validates the key length, responding 400 when it is too long
+func rejectIdempotencyKey(c *gin.Context, key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Idempotency key is too long"})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import "sync"
+
+// eventLocks hands out one mutex per event so long-running per-event jobs don't overlap.
+// An event's entry is dropped once nobody holds or waits for it.
+type eventLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*eventLock
+}
+
+// eventLock is an event's mutex and the number of callers holding or waiting for it
+type eventLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until the event's mutex is held and returns the matching unlock
+func (l *eventLocks) lock(eventID int64) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[int64]*eventLock{}
+	}
+	m, ok := l.locks[eventID]
+	if !ok {
+		m = &eventLock{}
+		l.locks[eventID] = m
+	}
+	m.refs++
+	l.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		l.mu.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(l.locks, eventID)
+		}
+		l.mu.Unlock()
+		m.Unlock()
+	}
+}
+
+// size returns the number of events with a lock entry
+func (l *eventLocks) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEventLocksSerializeAndRelease(t *testing.T) {
+	var locks eventLocks
+	const workers = 50
+
+	var wg sync.WaitGroup
+	running, maxRunning := 0, 0
+	var counter sync.Mutex
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(7)
+			defer unlock()
+
+			counter.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			counter.Unlock()
+
+			counter.Lock()
+			running--
+			counter.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 1 {
+		t.Errorf("%d holders at once, want 1", maxRunning)
+	}
+	if n := locks.size(); n != 0 {
+		t.Errorf("%d lock entries after every holder released, want 0", n)
+	}
+
+	// Other events don't wait, and each entry goes away with its holder
+	unlockA := locks.lock(1)
+	unlockB := locks.lock(2)
+	if n := locks.size(); n != 2 {
+		t.Errorf("%d lock entries with two events held, want 2", n)
+	}
+	unlockA()
+	unlockB()
+	if n := locks.size(); n != 0 {
+		t.Errorf("%d lock entries after release, want 0", n)
+	}
+}
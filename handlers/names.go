@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+
+	"atfi-backend/contracts"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// resolveName returns the ENS or Basename for a wallet, or "" when there is none or the
+// resolver is not configured. With wait it resolves on a cache miss; otherwise it only
+// reads the cache and leaves the lookup to the background refresh.
+func resolveName(ctx context.Context, names *contracts.NameResolver, walletAddress string, wait bool) string {
+	if names == nil || !common.IsHexAddress(walletAddress) {
+		return ""
+	}
+	wallet := common.HexToAddress(walletAddress)
+	if wait {
+		return names.Name(ctx, wallet)
+	}
+	return names.Cached(wallet)
+}
+
+// displayName picks what to show for a wallet: the profile name, then the resolved name,
+// then the shortened address
+func displayName(profileName *string, resolved, walletAddress string) string {
+	if profileName != nil && *profileName != "" {
+		return *profileName
+	}
+	if resolved != "" {
+		return resolved
+	}
+	return shortAddress(walletAddress)
+}
+
+// shortAddress abbreviates a wallet address as 0x1234...abcd
+func shortAddress(walletAddress string) string {
+	if len(walletAddress) <= 10 {
+		return walletAddress
+	}
+	return walletAddress[:6] + "..." + walletAddress[len(walletAddress)-4:]
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Limits that keep custom_fields a small annotation map rather than general storage
+const (
+	maxNotesLength          = 2000
+	maxCustomFieldsBytes    = 4096
+	maxCustomFieldsKeys     = 20
+	maxCustomFieldKeyLength = 64
+	maxCustomFieldsDepth    = 2
+)
+
+// validateCustomFields checks the size, key count and nesting depth of a custom_fields map
+func validateCustomFields(raw json.RawMessage) (map[string]interface{}, error) {
+	if len(raw) > maxCustomFieldsBytes {
+		return nil, fmt.Errorf("custom_fields must be at most %d bytes", maxCustomFieldsBytes)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("custom_fields must be a JSON object")
+	}
+	if len(fields) > maxCustomFieldsKeys {
+		return nil, fmt.Errorf("custom_fields may have at most %d keys", maxCustomFieldsKeys)
+	}
+	for key, value := range fields {
+		if key == "" || len(key) > maxCustomFieldKeyLength {
+			return nil, fmt.Errorf("custom_fields keys must be 1-%d characters", maxCustomFieldKeyLength)
+		}
+		if jsonDepth(value) > maxCustomFieldsDepth-1 {
+			return nil, fmt.Errorf("custom_fields may nest at most %d levels", maxCustomFieldsDepth)
+		}
+	}
+
+	return fields, nil
+}
+
+// jsonDepth returns how many object/array levels a decoded JSON value contains
+func jsonDepth(value interface{}) int {
+	deepest := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			if d := jsonDepth(child); d > deepest {
+				deepest = d
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if d := jsonDepth(child); d > deepest {
+				deepest = d
+			}
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+// UpdateParticipantNotes sets the organizer's notes and custom fields on a registration.
+// Omitted fields are left unchanged.
+func (h *CheckinHandler) UpdateParticipantNotes(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	participantID := c.Param("participantID")
+
+	var req struct {
+		Notes        *string         `json:"notes"`
+		CustomFields json.RawMessage `json:"custom_fields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Notes != nil && len(*req.Notes) > maxNotesLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("notes must be at most %d characters", maxNotesLength)})
+		return
+	}
+
+	var customFields map[string]interface{}
+	if len(req.CustomFields) > 0 && string(req.CustomFields) != "null" {
+		customFields, err = validateCustomFields(req.CustomFields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can edit participant notes"})
+		return
+	}
+
+	var notes *string
+	var storedFields map[string]interface{}
+	err = h.db.QueryRow(c, `
+		UPDATE participant
+		SET notes = CASE WHEN $3 THEN $4 ELSE notes END,
+		    custom_fields = COALESCE($5, custom_fields),
+		    updated_at = now()
+		WHERE event_id = $1 AND id::text = $2
+		RETURNING notes, custom_fields
+	`, eventID, participantID, req.Notes != nil, req.Notes, customFields).Scan(&notes, &storedFields)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Participant not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error updating notes", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update participant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"participant_id": participantID,
+		"notes":          notes,
+		"custom_fields":  storedFields,
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+)
+
+// GetOnchainState returns the event vault's live on-chain snapshot. Fields the vault could
+// not answer are null and listed under failed, so a partial read still returns 200. A
+// vault the factory does not vouch for is still read, with a warning.
+func (h *EventHandler) GetOnchainState(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var vaultAddress *string
+	err = h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for its on-chain state", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+
+	state, err := h.vaultState(c, *vaultAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read on-chain state", "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read the vault", err))
+		return
+	}
+
+	verified := h.vaultTrusted(c, eventID, *vaultAddress)
+	response := gin.H{
+		"event_id":       eventID,
+		"onchain":        state,
+		"complete":       state.Complete(),
+		"vault_verified": verified,
+	}
+	if !verified {
+		response["warning"] = "The vault address could not be verified against the event factory; these values may not belong to this event"
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// vaultState reads the snapshot of the vault at vaultAddress
+func (h *EventHandler) vaultState(c *gin.Context, vaultAddress string) (*contracts.VaultState, error) {
+	vault, err := contracts.NewVaultContract(h.client, vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	return vault.State(c)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"atfi-backend/middleware"
+)
+
+// getEventOrganizer returns the organizer wallet recorded on-chain for an event.
+// Returns pgx.ErrNoRows when the event does not exist.
+func getEventOrganizer(ctx context.Context, q querier, eventID int64) (string, error) {
+	var organizer string
+	err := q.QueryRow(ctx, "SELECT organizer_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&organizer)
+	return organizer, err
+}
+
+// isOrganizerOrAdmin reports whether the authenticated wallet organizes the event or is an admin
+func isOrganizerOrAdmin(c *gin.Context, organizer string) bool {
+	if middleware.IsAdmin(c) {
+		return true
+	}
+	userAddress := c.GetString(middleware.UserAddressKey)
+	return userAddress != "" && strings.EqualFold(userAddress, organizer)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Organizer page tuning: how long a page is served from memory, how late a settlement may
+// come and still count as on time, and the recent events list size
+const (
+	organizerCacheTTL        = 60 * time.Second
+	organizerCacheMaxEntries = 1000
+	settledOnTimeWindow      = 7 * 24 * time.Hour
+	defaultOrganizerEvents   = 10
+	maxOrganizerEvents       = 50
+)
+
+type organizerCacheEntry struct {
+	body      gin.H
+	expiresAt time.Time
+}
+
+// organizerCache keeps rendered organizer pages for organizerCacheTTL
+type organizerCache struct {
+	mu      sync.Mutex
+	entries map[string]organizerCacheEntry
+}
+
+func (oc *organizerCache) get(key string) (gin.H, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	entry, ok := oc.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// set stores a page, dropping expired entries first when the cache is full
+func (oc *organizerCache) set(key string, body gin.H) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.entries == nil {
+		oc.entries = map[string]organizerCacheEntry{}
+	}
+	if len(oc.entries) >= organizerCacheMaxEntries {
+		now := time.Now()
+		for k, entry := range oc.entries {
+			if now.After(entry.expiresAt) {
+				delete(oc.entries, k)
+			}
+		}
+	}
+	if len(oc.entries) < organizerCacheMaxEntries {
+		oc.entries[key] = organizerCacheEntry{body: body, expiresAt: time.Now().Add(organizerCacheTTL)}
+	}
+}
+
+// GetOrganizer returns an organizer's public page: profile, lifetime stats and recent events.
+// It is unauthenticated, so only public fields are returned, everything comes from the
+// database, and pages are cached for a minute. Wallets without a profile still get stats.
+func (h *EventHandler) GetOrganizer(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+	wallet := strings.ToLower(walletAddress)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultOrganizerEvents)))
+	if err != nil || limit < 1 {
+		limit = defaultOrganizerEvents
+	}
+	if limit > maxOrganizerEvents {
+		limit = maxOrganizerEvents
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	key := wallet + ":" + strconv.Itoa(limit)
+	if body, ok := h.organizers.get(key); ok {
+		c.JSON(http.StatusOK, body)
+		return
+	}
+
+	var name, avatarURL *string
+	err = h.db.QueryRow(c, `
+		SELECT name, avatar_url FROM profiles WHERE LOWER(wallet_address) = $1 AND deleted_at IS NULL
+	`, wallet).Scan(&name, &avatarURL)
+	hasProfile := err == nil
+	if err != nil && err != pgx.ErrNoRows {
+		slog.ErrorContext(c, "Database error loading organizer profile", "wallet", wallet, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var stats models.OrganizerStats
+	err = h.db.QueryRow(c, `
+		WITH hosted AS (
+			SELECT eo.event_id, eo.event_date, em.status, em.settled_at,
+			       (SELECT COUNT(*) FROM participant p WHERE p.event_id = eo.event_id) AS registered,
+			       (SELECT COUNT(*) FROM participant p WHERE p.event_id = eo.event_id AND p.is_attend) AS attended
+			FROM events_onchain eo
+			LEFT JOIN events_metadata em ON em.event_id = eo.event_id
+			WHERE LOWER(eo.organizer_address) = $1
+		)
+		SELECT COUNT(*),
+		       COALESCE(SUM(registered), 0),
+		       AVG(attended::float8 / registered) FILTER (WHERE status = 'SETTLED' AND registered > 0),
+		       COUNT(*) FILTER (WHERE status = 'SETTLED'),
+		       COUNT(*) FILTER (WHERE status = 'SETTLED' AND settled_at <= to_timestamp(event_date) + $2 * interval '1 second')
+		FROM hosted
+	`, wallet, settledOnTimeWindow.Seconds()).Scan(
+		&stats.EventsHosted,
+		&stats.TotalParticipants,
+		&stats.AverageAttendanceRate,
+		&stats.SettledEvents,
+		&stats.SettledOnTime,
+	)
+	if err != nil {
+		slog.ErrorContext(c, "Database error computing organizer stats", "wallet", wallet, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT eo.event_id, em.title, em.status, eo.event_date, eo.stake_amount::text, em.image_url,
+		       COUNT(p.id), COUNT(p.id) FILTER (WHERE p.is_attend)
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		LEFT JOIN participant p ON p.event_id = eo.event_id
+		WHERE LOWER(eo.organizer_address) = $1
+		GROUP BY eo.event_id, em.event_id
+		ORDER BY eo.event_date DESC, eo.event_id DESC
+		LIMIT $2
+	`, wallet, limit)
+	if err != nil {
+		slog.ErrorContext(c, "Database error listing organizer events", "wallet", wallet, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	events := []models.OrganizerEvent{}
+	for rows.Next() {
+		var event models.OrganizerEvent
+		if err := rows.Scan(&event.EventID, &event.Title, &event.Status, &event.EventDate, &event.StakeAmount,
+			&event.ImageURL, &event.Registered, &event.Attended); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan event"})
+			return
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	body := gin.H{
+		"wallet_address": wallet,
+		"profile": gin.H{
+			"exists":       hasProfile,
+			"name":         name,
+			"avatar_url":   avatarOrIdenticon(avatarURL, wallet),
+			"display_name": displayName(name, resolveName(c, h.names, wallet, false), wallet),
+		},
+		"stats":         stats,
+		"recent_events": events,
+	}
+	h.organizers.set(key, body)
+
+	c.JSON(http.StatusOK, body)
+}
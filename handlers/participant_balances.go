@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"atfi-backend/contracts"
+	"atfi-backend/models"
+	"atfi-backend/units"
+)
+
+// Participant balances are a fraud signal, not a ledger, so a read is reused for a while
+const participantBalanceTTL = 30 * time.Second
+
+type tokenBalanceKey struct {
+	token  common.Address
+	wallet common.Address
+}
+
+type tokenBalanceEntry struct {
+	balance   *big.Int
+	expiresAt time.Time
+}
+
+// tokenBalanceCache keeps wallets' token balances for participantBalanceTTL
+type tokenBalanceCache struct {
+	mu      sync.Mutex
+	entries map[tokenBalanceKey]tokenBalanceEntry
+}
+
+func (bc *tokenBalanceCache) get(key tokenBalanceKey) (*big.Int, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	entry, ok := bc.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.balance, true
+}
+
+// set stores a balance, dropping expired entries as it goes so the cache stays bounded by
+// the wallets listed within participantBalanceTTL
+func (bc *tokenBalanceCache) set(key tokenBalanceKey, balance *big.Int) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.entries == nil {
+		bc.entries = map[tokenBalanceKey]tokenBalanceEntry{}
+	}
+	now := time.Now()
+	for k, entry := range bc.entries {
+		if now.After(entry.expiresAt) {
+			delete(bc.entries, k)
+		}
+	}
+	bc.entries[key] = tokenBalanceEntry{balance: balance, expiresAt: now.Add(participantBalanceTTL)}
+}
+
+// includes reports whether the comma-separated include parameter lists expansion
+func includes(param, expansion string) bool {
+	for _, part := range strings.Split(param, ",") {
+		if strings.TrimSpace(part) == expansion {
+			return true
+		}
+	}
+	return false
+}
+
+// addParticipantBalances sets each listed wallet's live balance of the event vault's stake
+// token, read in Multicall3 batches and reused for participantBalanceTTL. Wallets that
+// cannot be read keep a nil balance. It returns the token, or an error when nothing could
+// be read.
+func (h *CheckinHandler) addParticipantBalances(c *gin.Context, eventID int64, participants []models.ParticipantListItem) (*contracts.Token, error) {
+	if h.client == nil {
+		return nil, errors.New("on-chain data unavailable")
+	}
+
+	var vaultAddress *string
+	var stakeAmount string
+	err := h.db.QueryRow(c, `
+		SELECT vault_address, stake_amount::text FROM events_onchain WHERE event_id = $1
+	`, eventID).Scan(&vaultAddress, &stakeAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the event's vault: %w", err)
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		return nil, errors.New("the event has no vault address yet")
+	}
+
+	token, err := h.stakeTokens.Of(c, common.HexToAddress(*vaultAddress))
+	if err != nil {
+		return nil, err
+	}
+	tokenAddress := common.HexToAddress(token.Address)
+
+	var missing []common.Address
+	for _, participant := range participants {
+		if !common.IsHexAddress(participant.WalletAddress) {
+			continue
+		}
+		wallet := common.HexToAddress(participant.WalletAddress)
+		if _, ok := h.balances.get(tokenBalanceKey{tokenAddress, wallet}); !ok {
+			missing = append(missing, wallet)
+		}
+	}
+	if len(missing) > 0 {
+		balances, err := contracts.NewMulticaller(h.client).BatchBalances(c, tokenAddress, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, balance := range balances {
+			if balance.Err == nil {
+				h.balances.set(tokenBalanceKey{tokenAddress, balance.Wallet}, balance.Balance)
+			}
+		}
+	}
+
+	for i := range participants {
+		participant := &participants[i]
+		if !common.IsHexAddress(participant.WalletAddress) {
+			continue
+		}
+		balance, ok := h.balances.get(tokenBalanceKey{tokenAddress, common.HexToAddress(participant.WalletAddress)})
+		if !ok {
+			continue
+		}
+		stake := stakeAmount
+		if participant.StakeAmount != nil {
+			stake = *participant.StakeAmount
+		}
+		required, ok := new(big.Int).SetString(stake, 10)
+		participant.Balance = &models.WalletTokenBalance{
+			Raw:         balance.String(),
+			Formatted:   units.FormatUnits(balance, token.Decimals),
+			CoversStake: ok && balance.Cmp(required) >= 0,
+		}
+	}
+	return &token, nil
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+)
+
+// GetParticipantCountAt returns the vault's participant count at a past block, given as
+// ?block= or as a Unix timestamp in ?at=, which is resolved to the last block mined by
+// then. Reads far back need an archive node; a node that pruned the state answers 501.
+func (h *EventHandler) GetParticipantCountAt(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	blockParam, atParam := c.Query("block"), c.Query("at")
+	if (blockParam == "") == (atParam == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Exactly one of block and at is required"})
+		return
+	}
+	var block, at uint64
+	if blockParam != "" {
+		block, err = strconv.ParseUint(blockParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "block must be a block number"})
+			return
+		}
+	} else {
+		at, err = strconv.ParseUint(atParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at must be a Unix timestamp"})
+			return
+		}
+	}
+
+	var vaultAddress *string
+	err = h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for its participant count", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+
+	if atParam != "" {
+		block, err = h.blockTimes.BlockAtTime(c, at)
+		if errors.Is(err, contracts.ErrBeforeGenesis) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at is before the chain's first block"})
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c, "Failed to find the block", "timestamp", at, "error", err)
+			c.JSON(chainReadStatus(err), chainError("Failed to find the block at that time", err))
+			return
+		}
+	} else {
+		latest, err := h.client.BlockNumber(c)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to read the latest block", "error", err)
+			c.JSON(chainReadStatus(err), chainError("Failed to read the latest block", err))
+			return
+		}
+		if block > latest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "block is not mined yet", "latest_block": latest})
+			return
+		}
+	}
+
+	vault, err := contracts.NewVaultContract(h.client, *vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid vault address"})
+		return
+	}
+	count, err := vault.ParticipantCountAt(c, new(big.Int).SetUint64(block))
+	if errors.Is(err, contracts.ErrArchiveUnavailable) {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error":   "archive_unavailable",
+			"message": "The RPC node no longer has the state of that block; historical reads need an archive node",
+			"block":   block,
+		})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read the participant count", "event_id", eventID, "block", block, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read the vault", err))
+		return
+	}
+
+	verified := h.vaultTrusted(c, eventID, *vaultAddress)
+	response := gin.H{
+		"event_id":          eventID,
+		"vault_address":     common.HexToAddress(*vaultAddress).Hex(),
+		"block":             block,
+		"participant_count": count.String(),
+		"vault_verified":    verified,
+	}
+	if atParam != "" {
+		response["at"] = at
+	}
+	if !verified {
+		response["warning"] = "The vault address could not be verified against the event factory; this count may not belong to this event"
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"atfi-backend/health"
+	"atfi-backend/models"
+)
+
+// Actor recorded in the audit log for status changes made by the phase worker
+const phaseWorkerActor = "system:phase-worker"
+
+// setDerivedPhase fills in the phase the event's on-chain timestamps imply and whether its
+// recorded status disagrees. settledOnchain is whether a settlement was confirmed on-chain.
+func setDerivedPhase(event *models.EventDetail, settledOnchain bool, now time.Time) {
+	event.DerivedPhase = models.DerivePhase(now, models.EventOnchain{
+		RegistrationDeadline: event.RegistrationDeadline,
+		EventDate:            event.EventDate,
+	}, settledOnchain)
+	event.StatusMismatch = models.StatusMismatch(event.Status, event.DerivedPhase)
+}
+
+// advanceEventPhases moves open and closed events whose timestamps have passed on to the
+// phase DerivePhase gives them, recording each step in the audit log. Events without an
+// event date are skipped rather than read as long past. It returns how many events were moved.
+func advanceEventPhases(ctx context.Context, db *pgxpool.Pool, now time.Time) (int, error) {
+	rows, err := db.Query(ctx, `
+		SELECT em.event_id, em.status, eo.registration_deadline, eo.event_date,
+		       em.settlement_block IS NOT NULL
+		FROM events_metadata em
+		JOIN events_onchain eo ON eo.event_id = em.event_id
+		WHERE em.status IN ('REGISTRATION_OPEN', 'REGISTRATION_CLOSED')
+		  AND eo.event_date > 0
+	`)
+	if err != nil {
+		return 0, err
+	}
+	type candidate struct {
+		eventID int64
+		status  string
+		phase   string
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var cand candidate
+		var onchain models.EventOnchain
+		var settled bool
+		if err := rows.Scan(&cand.eventID, &cand.status, &onchain.RegistrationDeadline, &onchain.EventDate, &settled); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		cand.phase = models.DerivePhase(now, onchain, settled)
+		if _, ok := models.PhaseAdvance(cand.status, cand.phase); ok {
+			candidates = append(candidates, cand)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, cand := range candidates {
+		ok, err := advanceEventPhase(ctx, db, cand.eventID, cand.status, cand.phase)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to advance event towards its phase", "event_id", cand.eventID, "status", cand.status, "phase", cand.phase, "error", err)
+			continue
+		}
+		if ok {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// advanceEventPhase steps one event from status towards phase in a transaction. It does
+// nothing when the status changed since it was read.
+func advanceEventPhase(ctx context.Context, db *pgxpool.Pool, eventID int64, status, phase string) (bool, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	from := status
+	for {
+		next, ok := models.PhaseAdvance(status, phase)
+		if !ok {
+			break
+		}
+		status = next
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE events_metadata SET status = $1, updated_at = now()
+		WHERE event_id = $2 AND status = $3
+	`, status, eventID, from)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	err = recordAudit(ctx, tx, phaseWorkerActor, "event_status_derived", &eventID, map[string]interface{}{
+		"from": from,
+		"to":   status,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// RunPhaseWorker advances event statuses to their derived phase every interval until ctx
+// is cancelled, beating heartbeat after each run
+func RunPhaseWorker(ctx context.Context, db *pgxpool.Pool, interval time.Duration, heartbeat *health.Heartbeat) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		moved, err := advanceEventPhases(ctx, db, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "Phase worker run failed", "error", err)
+		} else if moved > 0 {
+			slog.InfoContext(ctx, "Advanced events to their derived phase", "moved", moved)
+		}
+		heartbeat.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// attendanceHistoryFrom joins a wallet's registrations with their events and stakes across
+// every wallet linked to it. $1 holds the wallet.
+var attendanceHistoryFrom = `
+	FROM participant p
+	JOIN profiles pr ON pr.id = p.user_id
+	JOIN events_onchain eo ON eo.event_id = p.event_id
+	JOIN events_metadata em ON em.event_id = p.event_id
+	LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+	WHERE LOWER(pr.wallet_address) IN ` + linkedWalletsOf("$1")
+
+// GetAttendanceHistory lists the events a wallet registered for, newest first, with what it
+// staked and earned on each, plus a per-year summary over the full history. Voided events
+// are reported as such and never count as no-shows.
+func (h *UserHandler) GetAttendanceHistory(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxParticipantsPageSize {
+		limit = maxParticipantsPageSize
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT eo.event_id, em.title, eo.event_date, em.status,
+		       CASE
+		           WHEN em.status = 'VOIDED' THEN 'voided'
+		           WHEN p.is_attend THEN 'attended'
+		           WHEN to_timestamp(eo.event_date) > now() THEN 'upcoming'
+		           ELSE 'no_show'
+		       END,
+		       COALESCE(s.stake_amount, eo.stake_amount)::text,
+		       p.is_attend,
+		       COALESCE(s.reward_amount, p.reward_amount)::text,
+		       p.is_claim,
+		       eo.vault_address,
+		       COUNT(*) OVER()
+		`+attendanceHistoryFrom+`
+		ORDER BY eo.event_date DESC, eo.event_id DESC
+		LIMIT $2 OFFSET $3
+	`, walletAddress, limit, (page-1)*limit)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing attendance history", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	history := []models.AttendanceHistoryEntry{}
+	total := 0
+	for rows.Next() {
+		var entry models.AttendanceHistoryEntry
+		var vaultAddress *string
+		err := rows.Scan(
+			&entry.EventID,
+			&entry.Title,
+			&entry.EventDate,
+			&entry.Status,
+			&entry.Outcome,
+			&entry.StakeAmount,
+			&entry.Attended,
+			&entry.RewardAmount,
+			&entry.Claimed,
+			&vaultAddress,
+			&total,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning attendance history", "wallet", walletAddress, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan attendance history"})
+			return
+		}
+		token := vaultToken(c, h.stakeTokens, h.tokens, vaultAddress)
+		entry.StakeAmountFormatted = formatAmount(entry.StakeAmount, token)
+		entry.RewardAmountFormatted = formatAmount(entry.RewardAmount, token)
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Years cover the whole history regardless of the page; voided stakes were refunded
+	// so they are left out of the totals
+	rows, err = h.db.Query(c, `
+		SELECT EXTRACT(YEAR FROM to_timestamp(eo.event_date) AT TIME ZONE 'UTC')::int,
+		       COUNT(*) FILTER (WHERE p.is_attend AND em.status <> 'VOIDED'),
+		       COALESCE(SUM(COALESCE(s.stake_amount, eo.stake_amount)) FILTER (WHERE em.status <> 'VOIDED'), 0)::text,
+		       COALESCE(SUM(GREATEST(COALESCE(s.reward_amount, p.reward_amount) - COALESCE(s.stake_amount, eo.stake_amount), 0))
+		                FILTER (WHERE em.status = 'SETTLED' AND p.is_attend), 0)::text
+		`+attendanceHistoryFrom+`
+		GROUP BY 1
+		ORDER BY 1 DESC
+	`, walletAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error summarizing attendance history", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	// Years add up stakes across events, so they are shown in the chain's primary token
+	primary := primaryToken(h.tokens)
+	years := []models.AttendanceYear{}
+	for rows.Next() {
+		var year models.AttendanceYear
+		if err := rows.Scan(&year.Year, &year.EventsAttended, &year.TotalStaked, &year.TotalEarned); err != nil {
+			slog.ErrorContext(c, "Error scanning attendance summary", "wallet", walletAddress, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan attendance summary"})
+			return
+		}
+		year.TotalStakedFormatted = formatTokenAmount(year.TotalStaked, primary.Decimals)
+		year.TotalEarnedFormatted = formatTokenAmount(year.TotalEarned, primary.Decimals)
+		years = append(years, year)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address": walletAddress,
+		"history":        history,
+		"years":          years,
+		"total":          total,
+		"page":           page,
+		"limit":          limit,
+	})
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/skip2/go-qrcode"
+	"atfi-backend/middleware"
+)
+
+// errQRSigningDisabled is returned when no QR signing secret is configured
+var errQRSigningDisabled = errors.New("QR signing secret not configured")
+
+// Errors returned by verifyQRPayload
+var (
+	errQRMalformed        = errors.New("QR code is not a valid check-in code")
+	errQRInvalidSignature = errors.New("QR code signature is invalid")
+	errQRExpired          = errors.New("QR code has expired")
+	errQRRotationRequired = errors.New("event requires a rotating QR code")
+)
+
+// QR code rendering limits and the lifetime of on-demand QR payloads
+const (
+	qrCodeTTL         = 10 * time.Minute
+	defaultQRCodeSize = 256
+	minQRCodeSize     = 128
+	maxQRCodeSize     = 1024
+)
+
+// Rotating codes change every qrRotationPeriod; scans accept the current and previous window
+const (
+	qrRotationPeriod = 30 * time.Second
+	qrSecretBytes    = 20
+)
+
+// qrPayload is the JSON encoded in a participant's check-in QR code. ExpiresAt is a unix
+// timestamp, or 0 for the stored ticket payload which does not expire. Rotating codes
+// also carry the time window Counter and the participant's one-time Code for it.
+type qrPayload struct {
+	EventID       string `json:"eventId"`
+	ParticipantID string `json:"participantId"`
+	UserAddress   string `json:"userAddress"`
+	ExpiresAt     int64  `json:"expiresAt,omitempty"`
+	Counter       int64  `json:"counter,omitempty"`
+	Code          string `json:"code,omitempty"`
+	Nonce         string `json:"nonce"`
+	Signature     string `json:"sig"`
+}
+
+// qrSignature is the hex HMAC-SHA256 over every payload field except the signature.
+// The rotation fields are only included when set so static tickets keep verifying.
+func qrSignature(secret []byte, p qrPayload) string {
+	fields := []string{
+		p.EventID,
+		p.ParticipantID,
+		strings.ToLower(p.UserAddress),
+		strconv.FormatInt(p.ExpiresAt, 10),
+		p.Nonce,
+	}
+	if p.Counter != 0 {
+		fields = append(fields, strconv.FormatInt(p.Counter, 10), p.Code)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(fields, ":")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// qrCounter is the rotation window containing now
+func qrCounter(now time.Time) int64 {
+	return now.Unix() / int64(qrRotationPeriod/time.Second)
+}
+
+// qrWindowRemaining is how long the code for now's window stays current
+func qrWindowRemaining(now time.Time) time.Duration {
+	period := int64(qrRotationPeriod / time.Second)
+	return time.Duration(period-now.Unix()%period) * time.Second
+}
+
+// rotatingCode is the 6-digit HOTP (RFC 4226) of a participant secret for a window
+func rotatingCode(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// checkRotatingCode accepts a code for the window containing now or the one before it
+func checkRotatingCode(secret []byte, counter int64, code string, now time.Time) error {
+	current := qrCounter(now)
+	if counter != current && counter != current-1 {
+		return errQRExpired
+	}
+	if !hmac.Equal([]byte(code), []byte(rotatingCode(secret, counter))) {
+		return errQRInvalidSignature
+	}
+	return nil
+}
+
+// signQRPayload builds a new signed QR payload for a participant. A zero ttl produces a
+// payload that never expires. secret is the QR signing secret.
+func signQRPayload(secret string, eventID int64, participantID, userAddress string, ttl time.Duration) (string, error) {
+	if secret == "" {
+		return "", errQRSigningDisabled
+	}
+
+	nonceBytes := make([]byte, 8)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return "", err
+	}
+
+	payload := qrPayload{
+		EventID:       strconv.FormatInt(eventID, 10),
+		ParticipantID: participantID,
+		UserAddress:   strings.ToLower(userAddress),
+		Nonce:         hex.EncodeToString(nonceBytes),
+	}
+	if ttl > 0 {
+		payload.ExpiresAt = time.Now().Add(ttl).Unix()
+	}
+	payload.Signature = qrSignature([]byte(secret), payload)
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// verifyQRPayload parses a scanned QR string and checks its signature against secret and
+// its expiry
+func verifyQRPayload(secret, raw string, now time.Time) (qrPayload, error) {
+	if secret == "" {
+		return qrPayload{}, errQRSigningDisabled
+	}
+
+	var payload qrPayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return qrPayload{}, errQRMalformed
+	}
+	if payload.EventID == "" || payload.ParticipantID == "" || payload.UserAddress == "" || payload.Signature == "" {
+		return qrPayload{}, errQRMalformed
+	}
+
+	expected := qrSignature([]byte(secret), payload)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(payload.Signature))) {
+		return qrPayload{}, errQRInvalidSignature
+	}
+
+	if payload.ExpiresAt > 0 && now.Unix() > payload.ExpiresAt {
+		return payload, errQRExpired
+	}
+	return payload, nil
+}
+
+// signRotatingQRPayload builds the rotating QR payload for the window containing now,
+// signed with secret
+func signRotatingQRPayload(secret string, eventID int64, participantID, userAddress string, participantSecret []byte, now time.Time) (string, error) {
+	if secret == "" {
+		return "", errQRSigningDisabled
+	}
+
+	counter := qrCounter(now)
+	payload := qrPayload{
+		EventID:       strconv.FormatInt(eventID, 10),
+		ParticipantID: participantID,
+		UserAddress:   strings.ToLower(userAddress),
+		// Still scannable through the following window, matching checkRotatingCode
+		ExpiresAt: (counter + 2) * int64(qrRotationPeriod/time.Second),
+		Counter:   counter,
+		Code:      rotatingCode(participantSecret, counter),
+		Nonce:     strconv.FormatInt(counter, 16),
+	}
+	payload.Signature = qrSignature([]byte(secret), payload)
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// ensureQRSecret returns the participant's rotating-code secret, creating it on first use
+func ensureQRSecret(ctx context.Context, q querier, participantID string) ([]byte, error) {
+	var secret []byte
+	err := q.QueryRow(ctx, "SELECT qr_secret FROM participant WHERE id = $1", participantID).Scan(&secret)
+	if err != nil || secret != nil {
+		return secret, err
+	}
+
+	generated := make([]byte, qrSecretBytes)
+	if _, err := rand.Read(generated); err != nil {
+		return nil, err
+	}
+
+	err = q.QueryRow(ctx, `
+		UPDATE participant SET qr_secret = $2
+		WHERE id = $1 AND qr_secret IS NULL
+		RETURNING qr_secret
+	`, participantID, generated).Scan(&secret)
+	if err == pgx.ErrNoRows {
+		err = q.QueryRow(ctx, "SELECT qr_secret FROM participant WHERE id = $1", participantID).Scan(&secret)
+	}
+	return secret, err
+}
+
+// verifyRotation checks the rotating part of a verified payload as of at. Events with
+// rotating codes reject static tickets; static events accept either kind.
+func verifyRotation(ctx context.Context, q querier, payload qrPayload, rotating bool, at time.Time) error {
+	if payload.Counter == 0 {
+		if rotating {
+			return errQRRotationRequired
+		}
+		return nil
+	}
+
+	var secret []byte
+	err := q.QueryRow(ctx, "SELECT qr_secret FROM participant WHERE id = $1", payload.ParticipantID).Scan(&secret)
+	if err == pgx.ErrNoRows || (err == nil && secret == nil) {
+		return errQRInvalidSignature
+	}
+	if err != nil {
+		return err
+	}
+	return checkRotatingCode(secret, payload.Counter, payload.Code, at)
+}
+
+// ensureQRPayload returns the participant's stored QR payload, creating it on first use.
+// Concurrent callers all end up with the payload that was stored first.
+func ensureQRPayload(ctx context.Context, q querier, secret, participantID string, eventID int64, userAddress string) (string, error) {
+	var existing *string
+	err := q.QueryRow(ctx, "SELECT qr_payload FROM participant WHERE id = $1", participantID).Scan(&existing)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return *existing, nil
+	}
+
+	payload, err := signQRPayload(secret, eventID, participantID, userAddress, 0)
+	if err != nil {
+		return "", err
+	}
+
+	var stored string
+	err = q.QueryRow(ctx, `
+		UPDATE participant SET qr_payload = $2
+		WHERE id = $1 AND qr_payload IS NULL
+		RETURNING qr_payload
+	`, participantID, payload).Scan(&stored)
+	if err == pgx.ErrNoRows {
+		err = q.QueryRow(ctx, "SELECT qr_payload FROM participant WHERE id = $1", participantID).Scan(&stored)
+	}
+	return stored, err
+}
+
+// GetQRCode returns a short-lived signed check-in payload for the authenticated wallet,
+// as a string plus a PNG rendering. With format=png the image is returned directly.
+func (h *CheckinHandler) GetQRCode(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	walletAddress := c.Query("wallet")
+	if walletAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wallet is required"})
+		return
+	}
+	if !strings.EqualFold(c.GetString(middleware.UserAddressKey), walletAddress) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only fetch your own QR code"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultQRCodeSize)))
+	if err != nil || size < minQRCodeSize || size > maxQRCodeSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 128 and 1024"})
+		return
+	}
+
+	var participantID string
+	var rotating bool
+	err = h.db.QueryRow(c, `
+		SELECT p.id, COALESCE(em.rotating_qr, false)
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		LEFT JOIN events_metadata em ON em.event_id = p.event_id
+		WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)
+	`, eventID, walletAddress).Scan(&participantID, &rotating)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var payload string
+	expiresIn := qrCodeTTL
+	if rotating {
+		now := h.now()
+		var secret []byte
+		secret, err = ensureQRSecret(c, h.db, participantID)
+		if err == nil {
+			payload, err = signRotatingQRPayload(h.cfg.Auth.QRSigningSecret, eventID, participantID, walletAddress, secret, now)
+		}
+		expiresIn = qrWindowRemaining(now)
+	} else {
+		payload, err = signQRPayload(h.cfg.Auth.QRSigningSecret, eventID, participantID, walletAddress, qrCodeTTL)
+	}
+	if err != nil {
+		if err == errQRSigningDisabled {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "QR codes are not configured"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign QR payload"})
+		return
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, size)
+	if err != nil {
+		slog.ErrorContext(c, "Error rendering QR code", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	if c.Query("format") == "png" {
+		c.Data(http.StatusOK, "image/png", png)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"payload":    payload,
+		"png_base64": base64.StdEncoding.EncodeToString(png),
+		"size":       size,
+		"expires_in": int(expiresIn.Seconds()),
+		"rotating":   rotating,
+	})
+}
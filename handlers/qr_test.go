@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const testQRSecret = "test-qr-secret"
+
+func TestVerifyQRPayload(t *testing.T) {
+	now := time.Now()
+	valid, err := signQRPayload(testQRSecret, 7, "participant-1", "0xABCdef0000000000000000000000000000000001", qrCodeTTL)
+	if err != nil {
+		t.Fatalf("signQRPayload: %v", err)
+	}
+	ticket, err := signQRPayload(testQRSecret, 7, "participant-1", "0xabcdef0000000000000000000000000000000001", 0)
+	if err != nil {
+		t.Fatalf("signQRPayload: %v", err)
+	}
+
+	// Any field changed after signing breaks the signature
+	var tampered qrPayload
+	json.Unmarshal([]byte(valid), &tampered)
+	tampered.ParticipantID = "participant-2"
+	tamperedRaw, _ := json.Marshal(tampered)
+
+	tests := []struct {
+		name   string
+		secret string
+		raw    string
+		at     time.Time
+		want   error
+	}{
+		{"valid", testQRSecret, valid, now, nil},
+		{"ticket without expiry", testQRSecret, ticket, now.Add(365 * 24 * time.Hour), nil},
+		{"expired", testQRSecret, valid, now.Add(qrCodeTTL + time.Minute), errQRExpired},
+		{"other secret", "another-secret", valid, now, errQRInvalidSignature},
+		{"tampered", testQRSecret, string(tamperedRaw), now, errQRInvalidSignature},
+		{"not JSON", testQRSecret, "0xabcdef:7:1234", now, errQRMalformed},
+		{"missing fields", testQRSecret, `{"eventId":"7","sig":"00"}`, now, errQRMalformed},
+		{"signing disabled", "", valid, now, errQRSigningDisabled},
+	}
+	for _, tt := range tests {
+		payload, err := verifyQRPayload(tt.secret, tt.raw, tt.at)
+		if err != tt.want {
+			t.Errorf("%s: verifyQRPayload error = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if err == nil && (payload.EventID != "7" || payload.ParticipantID != "participant-1" || payload.UserAddress != "0xabcdef0000000000000000000000000000000001") {
+			t.Errorf("%s: payload = %+v, want event 7, participant-1 and the lowercased wallet", tt.name, payload)
+		}
+	}
+
+	if _, err := signQRPayload("", 7, "participant-1", "0xabc", qrCodeTTL); err != errQRSigningDisabled {
+		t.Errorf("signQRPayload without a secret = %v, want errQRSigningDisabled", err)
+	}
+}
+
+func TestGetQRCodeRejections(t *testing.T) {
+	owner := newTestWallet()
+	h := newCheckinTestHandler(nil)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"invalid event ID", "/events/abc/qr?wallet=" + owner.Hex(), http.StatusBadRequest},
+		{"missing wallet", "/events/1/qr", http.StatusBadRequest},
+		{"another wallet", "/events/1/qr?wallet=" + newTestWallet().Hex(), http.StatusForbidden},
+		{"too small", "/events/1/qr?size=64&wallet=" + owner.Hex(), http.StatusBadRequest},
+		{"too large", "/events/1/qr?size=4096&wallet=" + owner.Hex(), http.StatusBadRequest},
+		{"size not a number", "/events/1/qr?size=big&wallet=" + owner.Hex(), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(caller{wallet: owner})
+		router.GET("/events/:id/qr", h.GetQRCode)
+		if w := serveJSON(router, http.MethodGet, tt.path, nil); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestGetQRCode(t *testing.T) {
+	db := testDB(t)
+	owner := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{})
+	participantID := seedParticipant(t, db, eventID, seedProfile(t, db, owner, ""))
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/qr?wallet=" + owner.Hex()
+
+	router := newTestRouter(caller{wallet: newTestWallet()})
+	router.GET("/events/:id/qr", newCheckinTestHandler(db).GetQRCode)
+	if w := serveJSON(router, http.MethodGet, "/events/"+strconv.FormatInt(eventID, 10)+"/qr?wallet="+newTestWallet().Hex(), nil); w.Code != http.StatusForbidden {
+		t.Errorf("another wallet: status = %d, want 403", w.Code)
+	}
+
+	router = newTestRouter(caller{wallet: owner})
+	router.GET("/events/:id/qr", newCheckinTestHandler(db).GetQRCode)
+	w := serveJSON(router, http.MethodGet, path, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	body := decodeBody(t, w)
+	payload, err := verifyQRPayload(testConfig().Auth.QRSigningSecret, body["payload"].(string), time.Now())
+	if err != nil || payload.ParticipantID != participantID {
+		t.Errorf("payload verifies as %+v, %v; want participant %s", payload, err, participantID)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", w.Header().Get("Cache-Control"))
+	}
+
+	w = serveJSON(router, http.MethodGet, path+"&format=png", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || !strings.HasPrefix(w.Body.String(), "\x89PNG") {
+		t.Errorf("format=png: status = %d, Content-Type = %q; want a PNG", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// Without a signing secret nothing can be issued
+	h := NewCheckinHandler(db, nil, nil, nil, nil, testConfig())
+	h.cfg.Auth.QRSigningSecret = ""
+	router = newTestRouter(caller{wallet: owner})
+	router.GET("/events/:id/qr", h.GetQRCode)
+	if w := serveJSON(router, http.MethodGet, path, nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no signing secret: status = %d, want 503", w.Code)
+	}
+}
+
+// secretQuerier answers the qr_secret lookup of verifyRotation from a map of participant
+// IDs to secrets
+type secretQuerier map[string][]byte
+
+func (q secretQuerier) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("not supported")
+}
+
+func (q secretQuerier) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (q secretQuerier) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	return secretRow{q, args[0].(string)}
+}
+
+type secretRow struct {
+	secrets       secretQuerier
+	participantID string
+}
+
+func (r secretRow) Scan(dest ...any) error {
+	secret, ok := r.secrets[r.participantID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	*dest[0].(*[]byte) = secret
+	return nil
+}
+
+func TestRotatingCode(t *testing.T) {
+	// RFC 4226 appendix D test values
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := rotatingCode(secret, int64(counter)); got != code {
+			t.Errorf("rotatingCode(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestVerifyRotation(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	// Ten seconds into a window
+	issued := time.Unix(1_800_000_010, 0)
+	raw, err := signRotatingQRPayload(testQRSecret, 7, "participant-1", "0xabc", secret, issued)
+	if err != nil {
+		t.Fatalf("signRotatingQRPayload: %v", err)
+	}
+	rotating, err := verifyQRPayload(testQRSecret, raw, issued)
+	if err != nil {
+		t.Fatalf("verifyQRPayload of a fresh rotating code: %v", err)
+	}
+	static, _ := verifyQRPayload(testQRSecret, mustSignTicket(t), issued)
+
+	wrongCode := rotating
+	wrongCode.Code = "000000"
+	if wrongCode.Code == rotating.Code {
+		wrongCode.Code = "111111"
+	}
+	unknown := rotating
+	unknown.ParticipantID = "participant-2"
+	secrets := secretQuerier{"participant-1": secret, "participant-without-secret": nil}
+	noSecret := rotating
+	noSecret.ParticipantID = "participant-without-secret"
+
+	tests := []struct {
+		name     string
+		payload  qrPayload
+		rotating bool
+		at       time.Time
+		want     error
+	}{
+		{"static ticket, static event", static, false, issued, nil},
+		{"static ticket, rotating event", static, true, issued, errQRRotationRequired},
+		{"current window", rotating, true, issued, nil},
+		{"next window", rotating, true, issued.Add(qrRotationPeriod), nil},
+		{"two windows later", rotating, true, issued.Add(2 * qrRotationPeriod), errQRExpired},
+		{"before it was issued", rotating, true, issued.Add(-qrRotationPeriod), errQRExpired},
+		{"rotating code, static event", rotating, false, issued, nil},
+		{"wrong code", wrongCode, true, issued, errQRInvalidSignature},
+		{"unknown participant", unknown, true, issued, errQRInvalidSignature},
+		{"participant without a secret", noSecret, true, issued, errQRInvalidSignature},
+	}
+	for _, tt := range tests {
+		if err := verifyRotation(context.Background(), secrets, tt.payload, tt.rotating, tt.at); err != tt.want {
+			t.Errorf("%s: verifyRotation = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// The signed expiry matches the last window the code is accepted in
+	if _, err := verifyQRPayload(testQRSecret, raw, issued.Add(2*qrRotationPeriod)); err != errQRExpired {
+		t.Errorf("verifyQRPayload two windows later = %v, want errQRExpired", err)
+	}
+}
+
+func mustSignTicket(t *testing.T) string {
+	t.Helper()
+	ticket, err := signQRPayload(testQRSecret, 7, "participant-1", "0xabc", 0)
+	if err != nil {
+		t.Fatalf("signQRPayload: %v", err)
+	}
+	return ticket
+}
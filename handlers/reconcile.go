@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
+)
+
+// ReconcileParticipants compares the vault's on-chain depositors with the participant table
+// and reports missing rows (staked on-chain, unknown to us) and phantom rows (registered with
+// us, never staked), along with the vault's current state. With apply=true the differences
+// are fixed in one transaction.
+func (h *EventHandler) ReconcileParticipants(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	apply := c.Query("apply") == "true"
+
+	vaultAddress, fromBlock, depositors, ok := h.vaultDepositors(c, eventID)
+	if !ok {
+		return
+	}
+
+	registered, err := registeredWallets(c, h.db, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Error loading participants", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	missing := []string{}
+	for walletAddress := range depositors {
+		if !registered[walletAddress] {
+			missing = append(missing, walletAddress)
+		}
+	}
+	phantom := []string{}
+	for walletAddress := range registered {
+		if !depositors[walletAddress] {
+			phantom = append(phantom, walletAddress)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(phantom)
+
+	if apply && (len(missing) > 0 || len(phantom) > 0) {
+		if err := h.applyReconciliation(c, eventID, missing, phantom); err != nil {
+			slog.ErrorContext(c, "Failed to apply reconciliation", "event_id", eventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply reconciliation", "details": err.Error()})
+			return
+		}
+	}
+
+	// The vault's own view of itself, next to what its logs say. A failed read leaves it
+	// null rather than failing the reconciliation.
+	state, err := h.vaultState(c, vaultAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read vault state during reconciliation", "event_id", eventID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":      eventID,
+		"vault_address": vaultAddress,
+		"vault_state":   state,
+		"from_block":    fromBlock,
+		"onchain_count": len(depositors),
+		"db_count":      len(registered),
+		"missing":       missing,
+		"phantom":       phantom,
+		"applied":       apply,
+	})
+}
+
+// Participant sources recorded in participant.source
+const (
+	participantSourceAPI         = "api"
+	participantSourceOnchainSync = "onchain_sync"
+	participantSourceIndexer     = "indexer"
+)
+
+// SyncParticipants adds participant rows for wallets that staked directly on the vault
+// without calling RegisterUser (organizer or admin). Running it again adds nothing new.
+func (h *EventHandler) SyncParticipants(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can sync participants"})
+		return
+	}
+
+	unlock := h.participantSync.lock(eventID)
+	defer unlock()
+
+	_, _, depositors, ok := h.vaultDepositors(c, eventID)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	registered, err := registeredWallets(c, tx, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Error loading participants", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	added := []string{}
+	for walletAddress := range depositors {
+		if registered[walletAddress] {
+			continue
+		}
+
+		userID, err := ensureProfile(c, tx, walletAddress)
+		if err != nil {
+			slog.ErrorContext(c, "Error resolving profile during sync", "wallet", walletAddress, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create profile"})
+			return
+		}
+
+		result, err := tx.Exec(c, `
+			INSERT INTO participant (event_id, user_id, is_attend, is_claim, source, created_at, updated_at)
+			VALUES ($1, $2, false, false, $3, now(), now())
+			ON CONFLICT DO NOTHING
+		`, eventID, userID, participantSourceOnchainSync)
+		if err != nil {
+			slog.ErrorContext(c, "Error inserting synced participant", "wallet", walletAddress, "event_id", eventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert participant"})
+			return
+		}
+
+		if result.RowsAffected() > 0 {
+			added = append(added, walletAddress)
+		}
+	}
+	sort.Strings(added)
+
+	if len(added) > 0 {
+		err = recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "participants_synced", &eventID, map[string]interface{}{
+			"added": added,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync participants"})
+		return
+	}
+
+	slog.InfoContext(c, "Synced participants", "event_id", eventID, "onchain", len(depositors), "added", len(added))
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":      eventID,
+		"onchain_count": len(depositors),
+		"added_count":   len(added),
+		"added":         added,
+	})
+}
+
+// vaultDepositors loads the event's vault and the set of wallets that staked into it.
+// On failure it writes the error response and returns ok=false.
+func (h *EventHandler) vaultDepositors(c *gin.Context, eventID int64) (string, uint64, map[string]bool, bool) {
+	var vaultAddress string
+	err := h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return "", 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return "", 0, nil, false
+	}
+
+	if h.client == nil || !common.IsHexAddress(vaultAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable for this event"})
+		return "", 0, nil, false
+	}
+	// Depositors of a foreign contract must never become participants
+	if !h.requireTrustedVault(c, eventID, vaultAddress) {
+		return "", 0, nil, false
+	}
+	vault := common.HexToAddress(vaultAddress)
+
+	// Scan from the vault's deployment unless the caller narrows the range
+	var fromBlock uint64
+	if fromParam := c.Query("from_block"); fromParam != "" {
+		fromBlock, err = strconv.ParseUint(fromParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from_block"})
+			return "", 0, nil, false
+		}
+	} else if fromBlock, err = contracts.FindDeployBlock(c, h.client, vault); err != nil {
+		slog.WarnContext(c, "Could not find deploy block, scanning from genesis", "vault", vaultAddress, "error", err)
+		fromBlock = 0
+	}
+
+	depositors, err := contracts.FetchDepositors(c, h.client, vault, fromBlock)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to fetch depositors", "event_id", eventID, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read vault logs", "details": err.Error()})
+		return "", 0, nil, false
+	}
+
+	return vaultAddress, fromBlock, depositors, true
+}
+
+// registeredWallets returns the lowercase wallets with a participant row for the event
+func registeredWallets(ctx context.Context, q querier, eventID int64) (map[string]bool, error) {
+	rows, err := q.Query(ctx, `
+		SELECT LOWER(pr.wallet_address)
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		WHERE p.event_id = $1
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registered := map[string]bool{}
+	for rows.Next() {
+		var walletAddress string
+		if err := rows.Scan(&walletAddress); err != nil {
+			return nil, err
+		}
+		registered[walletAddress] = true
+	}
+
+	return registered, rows.Err()
+}
+
+// applyReconciliation inserts missing participants and removes phantom ones atomically
+func (h *EventHandler) applyReconciliation(c *gin.Context, eventID int64, missing, phantom []string) error {
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	for _, walletAddress := range missing {
+		userID, err := ensureProfile(c, tx, walletAddress)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(c, `
+			INSERT INTO participant (event_id, user_id, is_attend, is_claim, created_at, updated_at)
+			VALUES ($1, $2, false, false, now(), now())
+		`, eventID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(phantom) > 0 {
+		_, err = tx.Exec(c, `
+			DELETE FROM participant p
+			USING profiles pr
+			WHERE p.user_id = pr.id AND p.event_id = $1 AND LOWER(pr.wallet_address) = ANY($2)
+		`, eventID, phantom)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "participants_reconciled", &eventID, map[string]interface{}{
+		"added":   missing,
+		"removed": phantom,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(c)
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+)
+
+// Refund sources recorded in refunds.source
+const (
+	refundSourceConfirmed  = "confirmed_tx"
+	refundSourceReconciled = "withdrawal_logs"
+)
+
+// createRefunds records a pending refund for every participant of a voided event.
+// Participants without a stake row staked the event's fixed amount.
+func createRefunds(ctx context.Context, q querier, eventID int64) (int64, error) {
+	result, err := q.Exec(ctx, `
+		INSERT INTO refunds (event_id, wallet_address, amount, status)
+		SELECT p.event_id, LOWER(pr.wallet_address), COALESCE(s.stake_amount, eo.stake_amount), $2
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.event_id = $1
+		ON CONFLICT DO NOTHING
+	`, eventID, models.RefundPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// ConfirmRefund marks a participant's refund as complete once their vault withdrawal
+// transaction is verified on-chain. Only the organizer, an admin or the indexer can
+// confirm a refund.
+func (h *EventHandler) ConfirmRefund(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	walletAddress := strings.ToLower(c.Param("wallet"))
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+
+	var req struct {
+		TransactionHash string `json:"transaction_hash" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !middleware.IsIndexer(c) {
+		organizer, err := getEventOrganizer(c, h.db, eventID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !isOrganizerOrAdmin(c, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can confirm refunds"})
+			return
+		}
+	}
+
+	var status, vaultAddress string
+	err = h.db.QueryRow(c, `
+		SELECT r.status, eo.vault_address
+		FROM refunds r
+		JOIN events_onchain eo ON eo.event_id = r.event_id
+		WHERE r.event_id = $1 AND LOWER(r.wallet_address) = $2
+	`, eventID, walletAddress).Scan(&status, &vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if status == models.RefundRefunded {
+		c.JSON(http.StatusConflict, gin.H{"error": "Refund has already been confirmed"})
+		return
+	}
+
+	if h.client == nil || !common.IsHexAddress(vaultAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable for this event"})
+		return
+	}
+
+	verification, err := contracts.VerifyWithdrawTx(c, h.client, req.TransactionHash, common.HexToAddress(vaultAddress), common.HexToAddress(walletAddress), h.cfg.TxConfirmations)
+	if err != nil {
+		slog.WarnContext(c, "Withdrawal tx could not be verified", "tx_hash", req.TransactionHash, "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), gin.H{"error": "Failed to verify the withdrawal transaction"})
+		return
+	}
+	if status, details, ok := txRejection(verification, h.cfg.TxConfirmations); !ok {
+		if verification.State == contracts.TxPending {
+			details["error"] = "Withdrawal transaction is not confirmed yet"
+		} else {
+			details["error"] = "Transaction is not a withdrawal from this event's vault"
+		}
+		c.JSON(status, details)
+		return
+	}
+
+	var refund models.Refund
+	err = h.db.QueryRow(c, `
+		UPDATE refunds
+		SET status = $3, transaction_hash = $4, source = $5, refunded_at = now(), updated_at = now()
+		WHERE event_id = $1 AND LOWER(wallet_address) = $2 AND status <> $3
+		RETURNING id, event_id, wallet_address, amount::text, status, transaction_hash, source, refunded_at, created_at
+	`, eventID, walletAddress, models.RefundRefunded, req.TransactionHash, refundSourceConfirmed).Scan(
+		&refund.ID,
+		&refund.EventID,
+		&refund.WalletAddress,
+		&refund.Amount,
+		&refund.Status,
+		&refund.TransactionHash,
+		&refund.Source,
+		&refund.RefundedAt,
+		&refund.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Refund has already been confirmed"})
+			return
+		}
+		slog.ErrorContext(c, "Error confirming refund", "wallet", walletAddress, "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm refund"})
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+// GetRefunds lists an event's refunds with overall progress (organizer only)
+func (h *EventHandler) GetRefunds(c *gin.Context) {
+	eventID, ok := h.authorizeRefunds(c)
+	if !ok {
+		return
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT id, event_id, wallet_address, amount::text, status, transaction_hash, source, refunded_at, created_at
+		FROM refunds
+		WHERE event_id = $1
+		ORDER BY status, wallet_address
+	`, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing refunds", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	refunds := []models.Refund{}
+	refunded := 0
+	totalAmount, refundedAmount := new(big.Int), new(big.Int)
+	for rows.Next() {
+		var refund models.Refund
+		err := rows.Scan(
+			&refund.ID,
+			&refund.EventID,
+			&refund.WalletAddress,
+			&refund.Amount,
+			&refund.Status,
+			&refund.TransactionHash,
+			&refund.Source,
+			&refund.RefundedAt,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning refund row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan refund"})
+			return
+		}
+
+		amount, _ := new(big.Int).SetString(refund.Amount, 10)
+		if amount != nil {
+			totalAmount.Add(totalAmount, amount)
+		}
+		if refund.Status == models.RefundRefunded {
+			refunded++
+			if amount != nil {
+				refundedAmount.Add(refundedAmount, amount)
+			}
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"refunds": refunds,
+		"progress": gin.H{
+			"total":           len(refunds),
+			"refunded":        refunded,
+			"pending":         len(refunds) - refunded,
+			"total_amount":    totalAmount.String(),
+			"refunded_amount": refundedAmount.String(),
+		},
+	})
+}
+
+// ReconcileRefunds marks pending refunds complete for every wallet with a withdrawal log
+// on the event's vault, for participants who never called the confirm endpoint
+func (h *EventHandler) ReconcileRefunds(c *gin.Context) {
+	eventID, ok := h.authorizeRefunds(c)
+	if !ok {
+		return
+	}
+
+	var vaultAddress string
+	err := h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if h.client == nil || !common.IsHexAddress(vaultAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable for this event"})
+		return
+	}
+	vault := common.HexToAddress(vaultAddress)
+
+	fromBlock, err := contracts.FindDeployBlock(c, h.client, vault)
+	if err != nil {
+		slog.WarnContext(c, "Could not find deploy block, scanning from genesis", "vault", vaultAddress, "error", err)
+		fromBlock = 0
+	}
+
+	withdrawers, err := contracts.FetchWithdrawers(c, h.client, vault, fromBlock)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to fetch withdrawals", "event_id", eventID, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read vault logs", "details": err.Error()})
+		return
+	}
+
+	wallets := make([]string, 0, len(withdrawers))
+	for walletAddress := range withdrawers {
+		wallets = append(wallets, walletAddress)
+	}
+
+	result, err := h.db.Exec(c, `
+		UPDATE refunds
+		SET status = $3, source = $4, refunded_at = now(), updated_at = now()
+		WHERE event_id = $1 AND LOWER(wallet_address) = ANY($2) AND status <> $3
+	`, eventID, wallets, models.RefundRefunded, refundSourceReconciled)
+	if err != nil {
+		slog.ErrorContext(c, "Error reconciling refunds", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile refunds"})
+		return
+	}
+
+	if result.RowsAffected() > 0 {
+		err = recordAudit(c, h.db, c.GetString(middleware.UserAddressKey), "refunds_reconciled", &eventID, map[string]interface{}{
+			"marked_refunded": result.RowsAffected(),
+		})
+		if err != nil {
+			slog.ErrorContext(c, "Failed to audit refund reconciliation", "event_id", eventID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":         eventID,
+		"withdrawals_seen": len(withdrawers),
+		"marked_refunded":  result.RowsAffected(),
+	})
+}
+
+// authorizeRefunds parses the event ID and checks the caller organizes the event.
+// On failure it writes the error response and returns ok=false.
+func (h *EventHandler) authorizeRefunds(c *gin.Context) (int64, bool) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return 0, false
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return 0, false
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can manage refunds"})
+		return 0, false
+	}
+
+	return eventID, true
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"atfi-backend/models"
+)
+
+func TestConfirmRefundAuthorization(t *testing.T) {
+	db := testDB(t)
+	organizer, participant := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusVoided})
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO refunds (event_id, wallet_address, amount, status) VALUES ($1, $2, 10000000, $3)
+	`, eventID, strings.ToLower(participant.Hex()), models.RefundPending)
+	if err != nil {
+		t.Fatalf("seed refund: %v", err)
+	}
+
+	// No chain client: callers past the authorization check get 503
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/refunds/" + participant.Hex() + "/confirm"
+	tests := []struct {
+		name string
+		who  caller
+		path string
+		want int
+	}{
+		{"participant", caller{wallet: participant}, path, http.StatusForbidden},
+		{"stranger", caller{wallet: newTestWallet()}, path, http.StatusForbidden},
+		{"unknown event", caller{wallet: organizer}, "/events/" + strconv.FormatInt(newTestEventID(), 10) + "/refunds/" + participant.Hex() + "/confirm", http.StatusNotFound},
+		{"organizer", caller{wallet: organizer}, path, http.StatusServiceUnavailable},
+		{"admin", caller{wallet: newTestWallet(), admin: true}, path, http.StatusServiceUnavailable},
+		{"indexer", caller{indexer: true}, path, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.POST("/events/:id/refunds/:wallet/confirm", h.ConfirmRefund)
+		w := serveJSON(router, http.MethodPost, tt.path, map[string]any{"transaction_hash": "0x" + strings.Repeat("ab", 32)})
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"atfi-backend/contracts"
+	"atfi-backend/contracts/contractstest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// confirmedDeposit adds a confirmed deposit by wallet into vault to client and returns
+// its hash
+func confirmedDeposit(client *contractstest.Client, vault, wallet common.Address, nonce uint64) string {
+	tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &vault})
+	client.AddTransaction(tx, &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(100),
+		Logs: []*types.Log{{
+			Address: vault,
+			Topics:  []common.Hash{contracts.DepositedEventTopic, contracts.ParticipantTopic(wallet)},
+		}},
+	})
+	client.SetBlockNumber(110)
+	return tx.Hash().Hex()
+}
+
+// newRegistrationRouter serves RegisterUser backed by db and client
+func newRegistrationRouter(db *pgxpool.Pool, client *contractstest.Client) http.Handler {
+	h := NewEventHandler(db, client, nil, []contracts.Token{testUSDC}, nil, nil, nil, testConfig())
+	router := newTestRouter(caller{})
+	router.POST("/events/register", h.RegisterUser)
+	return router
+}
+
+func TestRegisterUserConcurrentDuplicates(t *testing.T) {
+	db := testDB(t)
+	vault, wallet := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Vault: vault})
+	client := contractstest.NewClient(84532)
+	txHash := confirmedDeposit(client, vault, wallet, 1)
+	router := newRegistrationRouter(db, client)
+
+	const attempts = 20
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := serveJSON(router, http.MethodPost, "/events/register", map[string]any{
+				"event_id":         eventID,
+				"user_address":     wallet.Hex(),
+				"transaction_hash": txHash,
+				"deposit_amount":   "10000000",
+			})
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("attempt %d: status = %d, want 201 or 409", i, code)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d registrations succeeded, want 1", created)
+	}
+
+	var participants, profiles int
+	err := db.QueryRow(context.Background(), `
+		SELECT (SELECT COUNT(*) FROM participant p JOIN profiles pr ON pr.id = p.user_id
+		        WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)),
+		       (SELECT COUNT(*) FROM profiles WHERE LOWER(wallet_address) = LOWER($2))
+	`, eventID, wallet.Hex()).Scan(&participants, &profiles)
+	if err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+	if participants != 1 || profiles != 1 {
+		t.Errorf("%d participant rows and %d profiles, want exactly one of each", participants, profiles)
+	}
+}
+
+func TestRegisterUserConcurrentLastSeat(t *testing.T) {
+	db := testDB(t)
+	vault := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Vault: vault, MaxParticipants: 2})
+	client := contractstest.NewClient(84532)
+	router := newRegistrationRouter(db, client)
+
+	const attempts = 10
+	bodies := make([]map[string]any, attempts)
+	for i := range bodies {
+		wallet := newTestWallet()
+		bodies[i] = map[string]any{
+			"event_id":         eventID,
+			"user_address":     wallet.Hex(),
+			"transaction_hash": confirmedDeposit(client, vault, wallet, uint64(i+1)),
+			"deposit_amount":   "10000000",
+		}
+	}
+
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serveJSON(router, http.MethodPost, "/events/register", bodies[i]).Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("attempt %d: status = %d, want 201 or 409", i, code)
+		}
+	}
+
+	var participants int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM participant WHERE event_id = $1", eventID).Scan(&participants); err != nil {
+		t.Fatalf("count participants: %v", err)
+	}
+	if created != 2 || participants != 2 {
+		t.Errorf("%d registrations succeeded and %d participants recorded, want 2 for 2 seats", created, participants)
+	}
+}
+
+func TestGetUserRegistrationRejections(t *testing.T) {
+	owner := newTestWallet()
+	h := NewEventHandler(nil, nil, nil, nil, nil, nil, nil, testConfig())
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"invalid event ID", "/events/abc/registration?user=" + owner.Hex(), http.StatusBadRequest},
+		{"missing user", "/events/1/registration", http.StatusBadRequest},
+		{"another wallet", "/events/1/registration?user=" + newTestWallet().Hex(), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(caller{wallet: owner})
+		router.GET("/events/:id/registration", h.GetUserRegistration)
+		if w := serveJSON(router, http.MethodGet, tt.path, nil); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestGetUserRegistration(t *testing.T) {
+	db := testDB(t)
+	registered, unregistered := newTestWallet(), newTestWallet()
+	eventID := seedEvent(t, db, testEvent{})
+	participantID := seedParticipant(t, db, eventID, seedProfile(t, db, registered, ""))
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/registration?user="
+
+	router := newTestRouter(caller{wallet: unregistered})
+	router.GET("/events/:id/registration", h.GetUserRegistration)
+	if w := serveJSON(router, http.MethodGet, path+unregistered.Hex(), nil); w.Code != http.StatusNotFound {
+		t.Errorf("unregistered wallet: status = %d, want 404", w.Code)
+	}
+
+	// The wallet is matched regardless of case
+	router = newTestRouter(caller{wallet: registered})
+	router.GET("/events/:id/registration", h.GetUserRegistration)
+	w := serveJSON(router, http.MethodGet, path+strings.ToLower(registered.Hex()), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("registered wallet: status = %d, want 200 (%s)", w.Code, w.Body)
+	}
+	body := decodeBody(t, w)
+	if body["registered"] != true || body["participant_id"] != participantID || body["checkin"] != nil || body["can_check_in"] != false {
+		t.Errorf("registration = %v, want participant %s registered, not checked in and outside the window", body, participantID)
+	}
+}
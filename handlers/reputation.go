@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"atfi-backend/contracts"
+	"atfi-backend/health"
+	"atfi-backend/reputation"
+	"atfi-backend/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// recomputeReputation scores a profile from its settled events and stores the result.
+// Voided and unsettled events are left out, so they never count against anyone. Stakes are
+// scored in whole tokens of the given decimals.
+func recomputeReputation(ctx context.Context, q querier, profileID uuid.UUID, decimals int, now time.Time) (*float64, error) {
+	rows, err := q.Query(ctx, `
+		SELECT eo.event_date, COALESCE(s.stake_amount, eo.stake_amount)::text, p.is_attend
+		FROM participant p
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		JOIN events_metadata em ON em.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.user_id = $1 AND em.status = 'SETTLED'
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []reputation.Event
+	for rows.Next() {
+		var eventDate int64
+		var stakeAmount string
+		var attended bool
+		if err := rows.Scan(&eventDate, &stakeAmount, &attended); err != nil {
+			return nil, err
+		}
+		events = append(events, reputation.Event{
+			Date:     time.Unix(eventDate, 0),
+			Stake:    wholeTokens(stakeAmount, decimals),
+			Attended: attended,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var score *float64
+	if value, ok := reputation.Score(events, now); ok {
+		score = &value
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE profiles SET reputation_score = $2, reputation_updated_at = $3 WHERE id = $1
+	`, profileID, score, now)
+	return score, err
+}
+
+// wholeTokens converts a base-unit amount to whole tokens for scoring, where float
+// precision is fine. Unparseable amounts count as zero.
+func wholeTokens(raw string, decimals int) float64 {
+	amount, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return 0
+	}
+	value, _ := strconv.ParseFloat(units.FormatUnits(amount, decimals), 64)
+	return value
+}
+
+// recomputeAllReputations rescores every profile with at least one registration
+func recomputeAllReputations(ctx context.Context, db *pgxpool.Pool, decimals int) (int, error) {
+	rows, err := db.Query(ctx, `SELECT DISTINCT user_id FROM participant`)
+	if err != nil {
+		return 0, err
+	}
+	profileIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, profileID := range profileIDs {
+		if _, err := recomputeReputation(ctx, db, profileID, decimals, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(profileIDs), nil
+}
+
+// RunReputationWorker recomputes every profile's reputation each interval (nightly in
+// production) until ctx is cancelled. Stakes are scored in the first of tokens, the chain's
+// primary token. heartbeat is beaten after each run.
+func RunReputationWorker(ctx context.Context, db *pgxpool.Pool, tokens []contracts.Token, interval time.Duration, heartbeat *health.Heartbeat) {
+	decimals := primaryToken(tokens).Decimals
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		scored, err := recomputeAllReputations(ctx, db, decimals)
+		if err != nil {
+			slog.ErrorContext(ctx, "Reputation run failed", "error", err)
+		} else {
+			slog.InfoContext(ctx, "Recomputed reputation profiles", "scored", scored)
+		}
+		heartbeat.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecomputeReputation rescores a single wallet's profile on demand (admin only) and
+// returns the raw score alongside the tier organizers see
+func (h *UserHandler) RecomputeReputation(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !common.IsHexAddress(walletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+
+	// Scores belong to the profile row registrations point at, so linked wallets are
+	// scored on their own
+	var profileID uuid.UUID
+	err := h.db.QueryRow(c, `SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($1)`, walletAddress).Scan(&profileID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	score, err := recomputeReputation(c, h.db, profileID, primaryToken(h.tokens).Decimals, time.Now())
+	if err != nil {
+		slog.ErrorContext(c, "Error recomputing reputation", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute reputation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address":   walletAddress,
+		"profile_id":       profileID,
+		"reputation_score": score,
+		"reputation_tier":  reputation.TierFor(score),
+	})
+}
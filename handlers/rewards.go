@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"atfi-backend/rewards"
+)
+
+// settleRewards calculates every participant's reward for a settled event and writes it
+// to the participant and stake rows. attended lists the wallets settled as present; at
+// self-service events only those with a validated check-in are counted.
+func settleRewards(ctx context.Context, q querier, eventID int64, attended []string) (rewards.Result, error) {
+	present := map[string]bool{}
+	for _, walletAddress := range attended {
+		present[strings.ToLower(walletAddress)] = true
+	}
+
+	// Participants without a stake row staked the event's fixed amount
+	rows, err := q.Query(ctx, `
+		SELECT LOWER(pr.wallet_address), COALESCE(s.stake_amount, eo.stake_amount)::text, `+selfServiceCountable+`
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_onchain eo ON eo.event_id = p.event_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE p.event_id = $1
+	`, eventID)
+	if err != nil {
+		return rewards.Result{}, err
+	}
+	defer rows.Close()
+
+	participants := []rewards.Participant{}
+	wallets := []string{}
+	for rows.Next() {
+		var walletAddress, stakeAmount string
+		var countable bool
+		if err := rows.Scan(&walletAddress, &stakeAmount, &countable); err != nil {
+			return rewards.Result{}, err
+		}
+		stake, ok := new(big.Int).SetString(stakeAmount, 10)
+		if !ok {
+			return rewards.Result{}, fmt.Errorf("invalid stake amount %q for %s", stakeAmount, walletAddress)
+		}
+		participants = append(participants, rewards.Participant{
+			WalletAddress: walletAddress,
+			Stake:         stake,
+			Attended:      present[walletAddress] && countable,
+		})
+		wallets = append(wallets, walletAddress)
+	}
+	if err := rows.Err(); err != nil {
+		return rewards.Result{}, err
+	}
+
+	var yieldAmount string
+	err = q.QueryRow(ctx, "SELECT COALESCE(SUM(yield_amount), 0)::text FROM vault_yield_records WHERE event_id = $1", eventID).Scan(&yieldAmount)
+	if err != nil {
+		return rewards.Result{}, err
+	}
+	yield, ok := new(big.Int).SetString(yieldAmount, 10)
+	if !ok {
+		return rewards.Result{}, fmt.Errorf("invalid yield amount %q", yieldAmount)
+	}
+
+	result := rewards.Calculate(participants, yield)
+
+	// No-shows are written as zero so a recalculation clears stale amounts
+	amounts := make([]string, len(wallets))
+	attendedFlags := make([]bool, len(wallets))
+	for i, walletAddress := range wallets {
+		amounts[i] = "0"
+		if reward, ok := result.Rewards[walletAddress]; ok {
+			amounts[i] = reward.String()
+			attendedFlags[i] = true
+		}
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE participant p
+		SET reward_amount = r.amount::numeric, updated_at = now()
+		FROM profiles pr, unnest($2::text[], $3::text[]) AS r(wallet, amount)
+		WHERE p.user_id = pr.id AND p.event_id = $1 AND LOWER(pr.wallet_address) = r.wallet
+	`, eventID, wallets, amounts)
+	if err != nil {
+		return rewards.Result{}, err
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE stakes s
+		SET reward_amount = r.amount::numeric, is_attended = r.attended, updated_at = now()
+		FROM unnest($2::text[], $3::text[], $4::bool[]) AS r(wallet, amount, attended)
+		WHERE s.event_id = $1 AND LOWER(s.wallet_address) = r.wallet
+	`, eventID, wallets, amounts, attendedFlags)
+	if err != nil {
+		return rewards.Result{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Scan failure codes returned to the scanner app so door staff can show the right message
+const (
+	scanInvalidCode      = "invalid_code"
+	scanInvalidSignature = "invalid_signature"
+	scanExpiredCode      = "expired_code"
+	scanWrongEvent       = "wrong_event"
+	scanNotRegistered    = "not_registered"
+	scanAlreadyCheckedIn = "already_checked_in"
+	scanCheckInClosed    = "checkin_closed"
+	scanRotatingRequired = "rotating_code_required"
+)
+
+// ScanCheckIn checks in a participant from the raw string scanned off their QR code. The
+// payload is verified server-side and must belong to the event being scanned for
+// (organizer only).
+func (h *CheckinHandler) ScanCheckIn(c *gin.Context) {
+	var req struct {
+		EventID     int64  `json:"event_id" binding:"required"`
+		QRData      string `json:"qr_data" binding:"required"`
+		DeviceLabel string `json:"device_label" binding:"max=100"`
+		ClientUUID  string `json:"client_uuid"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	opKey := idempotencyKey(c, req.ClientUUID)
+	if rejectIdempotencyKey(c, opKey) {
+		return
+	}
+
+	var status, organizer string
+	var eventDate int64
+	var rotating bool
+	err := h.db.QueryRow(c, `
+		SELECT em.status, eo.event_date, eo.organizer_address, em.rotating_qr
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE eo.event_id = $1
+	`, req.EventID).Scan(&status, &eventDate, &organizer, &rotating)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Only the event organizer can scan check-ins"})
+		return
+	}
+
+	// Replay before verifying the code: a retried rotating code may have expired since
+	if replayed, err := replayCheckin(c, h.db, req.EventID, opKey); err != nil || replayed {
+		if err != nil {
+			slog.ErrorContext(c, "Error looking up idempotency key", "event_id", req.EventID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		}
+		return
+	}
+
+	now := h.now()
+	payload, err := verifyQRPayload(h.cfg.Auth.QRSigningSecret, strings.TrimSpace(req.QRData), now)
+	if err == errQRSigningDisabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "message": "QR codes are not configured"})
+		return
+	}
+	if err != nil {
+		status, code, message := qrScanFailure(err)
+		response := gin.H{"success": false, "code": code, "message": message}
+		if err == errQRExpired {
+			response["expired_at"] = time.Unix(payload.ExpiresAt, 0).UTC()
+		}
+		c.JSON(status, response)
+		return
+	}
+
+	if payload.EventID != strconv.FormatInt(req.EventID, 10) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":         false,
+			"code":            scanWrongEvent,
+			"message":         "This QR code is for a different event",
+			"ticket_event_id": payload.EventID,
+		})
+		return
+	}
+
+	if err := verifyRotation(c, h.db, payload, rotating, now); err != nil {
+		if err != errQRExpired && err != errQRInvalidSignature && err != errQRRotationRequired {
+			slog.ErrorContext(c, "Error checking rotating code", "participant_id", payload.ParticipantID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+			return
+		}
+		status, code, message := qrScanFailure(err)
+		c.JSON(status, gin.H{"success": false, "code": code, "message": message})
+		return
+	}
+
+	// The participant must still be registered under the wallet the code was issued to
+	var participantID, walletAddress string
+	var name *string
+	var isAttend bool
+	err = h.db.QueryRow(c, `
+		SELECT p.id, pr.wallet_address, pr.name, p.is_attend
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		WHERE p.id = $1 AND p.event_id = $2 AND LOWER(pr.wallet_address) = LOWER($3)
+	`, payload.ParticipantID, req.EventID, payload.UserAddress).Scan(&participantID, &walletAddress, &name, &isAttend)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":        false,
+				"code":           scanNotRegistered,
+				"message":        "This attendee is not registered for this event",
+				"wallet_address": payload.UserAddress,
+			})
+			return
+		}
+		slog.ErrorContext(c, "Error resolving scanned participant", "participant_id", payload.ParticipantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+
+	attendee := gin.H{
+		"participant_id": participantID,
+		"wallet_address": walletAddress,
+		"name":           name,
+	}
+
+	if isAttend {
+		if replayed, _ := replayCheckin(c, h.db, req.EventID, opKey); replayed {
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"success":  false,
+			"code":     scanAlreadyCheckedIn,
+			"message":  "This attendee has already checked in",
+			"attendee": attendee,
+		})
+		return
+	}
+
+	if !checkInOpen(status, eventDate, now, h.cfg.CheckInWindow) {
+		opens, closes := checkInWindow(eventDate, h.cfg.CheckInWindow)
+		c.JSON(http.StatusConflict, gin.H{
+			"success":        false,
+			"code":           scanCheckInClosed,
+			"message":        "Check-in is not open for this event",
+			"current_status": status,
+			"window_opens":   opens,
+			"window_closes":  closes,
+		})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Guard on is_attend so two scanners racing on the same code check in only once
+	var checkedInAt time.Time
+	err = tx.QueryRow(c, `
+		UPDATE participant
+		SET is_attend = true, updated_at = $1
+		WHERE id = $2 AND is_attend = false
+		RETURNING updated_at
+	`, now, participantID).Scan(&checkedInAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			tx.Rollback(c)
+			if replayed, _ := replayCheckin(c, h.db, req.EventID, opKey); replayed {
+				return
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"success":  false,
+				"code":     scanAlreadyCheckedIn,
+				"message":  "This attendee has already checked in",
+				"attendee": attendee,
+			})
+			return
+		}
+		slog.ErrorContext(c, "Error checking in scanned participant", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	scanner := c.GetString(middleware.UserAddressKey)
+	record := models.CreateCheckinRequest{
+		EventID:       req.EventID,
+		WalletAddress: walletAddress,
+		QRData:        req.QRData,
+		CheckedBy:     scanner,
+		IPAddress:     c.ClientIP(),
+		DeviceLabel:   req.DeviceLabel,
+	}
+	if err := recordCheckin(c, tx, record, checkedInAt); err != nil {
+		slog.ErrorContext(c, "Failed to record scanned check-in", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	attendee["is_attend"] = true
+	attendee["checked_in_at"] = checkedInAt
+	response := gin.H{
+		"success":          true,
+		"message":          "Successfully checked in to event",
+		"attendee":         attendee,
+		"attendance_proof": h.issueAttendanceProof(c, req.EventID, walletAddress, checkedInAt),
+	}
+
+	if err := saveCheckinResponse(c, tx, req.EventID, opKey, http.StatusOK, response); err != nil {
+		if isUniqueViolation(err, "checkin_idempotency_pkey") {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "message": "Idempotency key was already used for a different check-in"})
+			return
+		}
+		slog.ErrorContext(c, "Failed to store idempotency key", "event_id", req.EventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to check in participant"})
+		return
+	}
+
+	slog.InfoContext(c, "Scanned check-in", "event_id", req.EventID, "participant_id", participantID, "scanner", scanner)
+	h.recordActivity(c, req.EventID, activityCheckedIn, &participantID, &walletAddress)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// qrScanFailure maps a verifyQRPayload error to its HTTP status, scan code and message
+func qrScanFailure(err error) (int, string, string) {
+	switch err {
+	case errQRExpired:
+		return http.StatusGone, scanExpiredCode, "This QR code has expired. Ask the attendee to refresh it."
+	case errQRInvalidSignature:
+		return http.StatusUnprocessableEntity, scanInvalidSignature, "This QR code was not issued by ATFi"
+	case errQRRotationRequired:
+		return http.StatusUnprocessableEntity, scanRotatingRequired, "This event needs the live QR code from the app, not a saved ticket or screenshot"
+	default:
+		return http.StatusBadRequest, scanInvalidCode, "This is not an ATFi check-in code"
+	}
+}
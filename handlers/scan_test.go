@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"atfi-backend/models"
+)
+
+func TestScanCheckIn(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	organizer, attendee := newTestWallet(), newTestWallet()
+	start := time.Now()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive, EventDate: start})
+	if _, err := db.Exec(ctx, "UPDATE events_metadata SET rotating_qr = true WHERE event_id = $1", eventID); err != nil {
+		t.Fatalf("enable rotating codes: %v", err)
+	}
+	participantID := seedParticipant(t, db, eventID, seedProfile(t, db, attendee, ""))
+
+	secret, err := ensureQRSecret(ctx, db, participantID)
+	if err != nil {
+		t.Fatalf("ensureQRSecret: %v", err)
+	}
+	code, err := signRotatingQRPayload(testQRSecret, eventID, participantID, attendee.Hex(), secret, start)
+	if err != nil {
+		t.Fatalf("signRotatingQRPayload: %v", err)
+	}
+	ticket, err := signQRPayload(testQRSecret, eventID, participantID, attendee.Hex(), 0)
+	if err != nil {
+		t.Fatalf("signQRPayload: %v", err)
+	}
+
+	h := newCheckinTestHandler(db)
+	tests := []struct {
+		name     string
+		who      caller
+		qr       string
+		at       time.Time
+		want     int
+		wantCode string
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, code, start, http.StatusForbidden, ""},
+		{"attendee scanning themselves", caller{wallet: attendee}, code, start, http.StatusForbidden, ""},
+		{"saved ticket", caller{wallet: organizer}, ticket, start, http.StatusUnprocessableEntity, scanRotatingRequired},
+		{"expired code", caller{wallet: organizer}, code, start.Add(3 * qrRotationPeriod), http.StatusGone, scanExpiredCode},
+		{"not a check-in code", caller{wallet: organizer}, "hello", start, http.StatusBadRequest, scanInvalidCode},
+		{"current code", caller{wallet: organizer}, code, start, http.StatusOK, ""},
+		{"scanned again", caller{wallet: organizer}, code, start, http.StatusConflict, scanAlreadyCheckedIn},
+	}
+	for _, tt := range tests {
+		at := tt.at
+		h.now = func() time.Time { return at }
+		router := newTestRouter(tt.who)
+		router.POST("/checkin/scan", h.ScanCheckIn)
+		w := serveJSON(router, http.MethodPost, "/checkin/scan", map[string]any{"event_id": eventID, "qr_data": tt.qr})
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+			continue
+		}
+		if tt.wantCode != "" {
+			if body := decodeBody(t, w); body["code"] != tt.wantCode {
+				t.Errorf("%s: code = %v, want %s", tt.name, body["code"], tt.wantCode)
+			}
+		}
+	}
+}
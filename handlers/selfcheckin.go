@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+// Venue codes are short enough to type by hand and skip look-alike characters
+const (
+	venueCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	venueCodeLength   = 8
+)
+
+const activitySelfCheckedIn = "self_checked_in"
+
+// selfServiceCountable is a predicate over participant p and profile pr that is true when
+// p's attendance counts toward settlement. Self-service check-ins only count once the
+// organizer has validated them; staff-scan events are unaffected.
+const selfServiceCountable = `(
+		NOT EXISTS (SELECT 1 FROM events_metadata m WHERE m.event_id = p.event_id AND m.checkin_mode = 'self_service')
+		OR EXISTS (
+			SELECT 1 FROM checkins ck
+			WHERE ck.event_id = p.event_id AND LOWER(ck.user_address) = LOWER(pr.wallet_address) AND ck.is_validated
+		)
+	)`
+
+// generateVenueCode returns a random venue code
+func generateVenueCode() (string, error) {
+	buf := make([]byte, venueCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// The alphabet has 32 symbols, so taking each byte mod 32 is unbiased
+	for i, b := range buf {
+		buf[i] = venueCodeAlphabet[int(b)%len(venueCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// venueCodeMatches compares a submitted venue code in constant time, ignoring case and
+// surrounding whitespace
+func venueCodeMatches(expected *string, submitted string) bool {
+	if expected == nil || *expected == "" {
+		return false
+	}
+	submitted = strings.ToUpper(strings.TrimSpace(submitted))
+	return subtle.ConstantTimeCompare([]byte(*expected), []byte(submitted)) == 1
+}
+
+// MintVenueCode generates a new venue code for a self-service event (organizer only),
+// replacing any previous code. The code is returned as text and as a PNG to display at
+// the door.
+func (h *CheckinHandler) MintVenueCode(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultQRCodeSize)))
+	if err != nil || size < minQRCodeSize || size > maxQRCodeSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 128 and 1024"})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var organizer, status, mode string
+	err = tx.QueryRow(c, `
+		SELECT eo.organizer_address, em.status, em.checkin_mode
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		WHERE eo.event_id = $1
+		FOR UPDATE OF em
+	`, eventID).Scan(&organizer, &status, &mode)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can mint a venue code"})
+		return
+	}
+	if mode != models.CheckinModeSelfService {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event does not use self-service check-in", "checkin_mode": mode})
+		return
+	}
+	if status == models.StatusSettled || status == models.StatusVoided {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is already " + status, "current_status": status})
+		return
+	}
+
+	code, err := generateVenueCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate venue code"})
+		return
+	}
+
+	if _, err := tx.Exec(c, "UPDATE events_metadata SET venue_code = $1, updated_at = now() WHERE event_id = $2", code, eventID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save venue code"})
+		return
+	}
+
+	// The code itself is not logged so the audit trail cannot be used to check in
+	if err := recordAudit(c, tx, c.GetString(middleware.UserAddressKey), "venue_code_minted", &eventID, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save venue code"})
+		return
+	}
+
+	png, err := qrcode.Encode(code, qrcode.Medium, size)
+	if err != nil {
+		slog.ErrorContext(c, "Error rendering venue code", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"event_id":   eventID,
+		"venue_code": code,
+		"png_base64": base64.StdEncoding.EncodeToString(png),
+		"size":       size,
+	})
+}
+
+// SelfCheckIn lets a registered participant check themselves in at a self-service event
+// by submitting the venue code. The check-in is stored unvalidated and does not mark the
+// participant attended until the organizer approves it through ValidateCheckIn.
+func (h *CheckinHandler) SelfCheckIn(c *gin.Context) {
+	var req struct {
+		EventID     int64  `json:"event_id" binding:"required"`
+		VenueCode   string `json:"venue_code" binding:"required"`
+		DeviceLabel string `json:"device_label" binding:"max=100"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	walletAddress := c.GetString(middleware.UserAddressKey)
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Locking the participant row serializes repeated submissions from the same wallet
+	var status, mode string
+	var eventDate int64
+	var venueCode, participantID *string
+	var isAttend *bool
+	err = tx.QueryRow(c, `
+		SELECT em.status, em.checkin_mode, em.venue_code, eo.event_date, p.id, p.is_attend
+		FROM events_onchain eo
+		JOIN events_metadata em ON em.event_id = eo.event_id
+		LEFT JOIN profiles pr ON LOWER(pr.wallet_address) = LOWER($2)
+		LEFT JOIN participant p ON p.event_id = eo.event_id AND p.user_id = pr.id
+		WHERE eo.event_id = $1
+		FOR UPDATE OF p
+	`, req.EventID, walletAddress).Scan(&status, &mode, &venueCode, &eventDate, &participantID, &isAttend)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Event not found", "code": "event_not_found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+
+	if mode != models.CheckinModeSelfService {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "This event does not allow self check-in", "code": "self_checkin_disabled"})
+		return
+	}
+	if !venueCodeMatches(venueCode, req.VenueCode) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Invalid venue code", "code": "invalid_venue_code"})
+		return
+	}
+	if !checkInOpen(status, eventDate, h.now(), h.cfg.CheckInWindow) {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Check-in is not open for this event", "code": "checkin_closed"})
+		return
+	}
+	if participantID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Wallet is not registered for this event", "code": "not_registered"})
+		return
+	}
+	if *isAttend {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Already checked in", "code": "already_checked_in"})
+		return
+	}
+
+	var pending bool
+	err = tx.QueryRow(c, `
+		SELECT EXISTS (
+			SELECT 1 FROM checkins
+			WHERE event_id = $1 AND LOWER(user_address) = LOWER($2) AND NOT is_validated AND validated_by IS NULL
+		)
+	`, req.EventID, walletAddress).Scan(&pending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Database error"})
+		return
+	}
+	if pending {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Check-in is already awaiting organizer approval", "code": "pending_approval"})
+		return
+	}
+
+	now := h.now()
+	err = recordCheckin(c, tx, models.CreateCheckinRequest{
+		EventID:       req.EventID,
+		WalletAddress: walletAddress,
+		CheckedBy:     walletAddress,
+		IPAddress:     c.ClientIP(),
+		DeviceLabel:   req.DeviceLabel,
+	}, now)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to record self check-in", "event_id", req.EventID, "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to record check-in"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to record check-in"})
+		return
+	}
+
+	h.recordActivity(c, req.EventID, activitySelfCheckedIn, participantID, &walletAddress)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":       true,
+		"message":       "Check-in submitted for organizer approval",
+		"status":        "pending_approval",
+		"event_id":      req.EventID,
+		"checked_in_at": now,
+	})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+	"atfi-backend/models"
+	"atfi-backend/rewards"
+)
+
+// attendedWallets lists the wallets that attended the event and count towards settlement.
+// Self-service events only count participants whose check-in the organizer validated.
+func attendedWallets(ctx context.Context, q querier, eventID string) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT pr.wallet_address
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		WHERE p.event_id = $1 AND p.is_attend = true AND `+selfServiceCountable+`
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []string
+	for rows.Next() {
+		var walletAddress string
+		if err := rows.Scan(&walletAddress); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, walletAddress)
+	}
+	return wallets, rows.Err()
+}
+
+// settlementAttendees is the sorted, deduplicated list of attended wallets the vault is
+// settled with
+func settlementAttendees(ctx context.Context, q querier, eventID int64) ([]common.Address, error) {
+	wallets, err := attendedWallets(ctx, q, strconv.FormatInt(eventID, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	var attendees []common.Address
+	for _, wallet := range wallets {
+		if !common.IsHexAddress(wallet) {
+			slog.WarnContext(ctx, "Skipping invalid attendee wallet", "wallet", wallet, "event_id", eventID)
+			continue
+		}
+		attendees = append(attendees, common.HexToAddress(wallet))
+	}
+	return contracts.SortAddresses(attendees), nil
+}
+
+// respondNoAttendees refuses to settle an event nobody attended
+func respondNoAttendees(c *gin.Context) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   "no_attendees",
+		"message": "No participant has checked in, so there is no one to settle for",
+	})
+}
+
+// countDistinctWallets counts the distinct valid addresses in wallets, ignoring case
+func countDistinctWallets(wallets []string) int {
+	seen := map[common.Address]bool{}
+	for _, wallet := range wallets {
+		if common.IsHexAddress(wallet) {
+			seen[common.HexToAddress(wallet)] = true
+		}
+	}
+	return len(seen)
+}
+
+// settlementOutcome is what recordSettlement did with a verified settlement: either it
+// settled the event and calculated Rewards, or it held the settlement for Review
+type settlementOutcome struct {
+	Review    string // why the settlement awaits admin review, empty once settled
+	Submitted int    // distinct attendees submitted
+	Rewards   rewards.Result
+}
+
+// recordSettlement marks the event settled by the verified transaction txHash and
+// calculates rewards for attended. A vault that settled a different number of attendees
+// would pay out differently from the rewards we calculate, so such a settlement is
+// recorded and flagged for admin review instead.
+func (h *EventHandler) recordSettlement(ctx context.Context, actor string, eventID int64, txHash string,
+	verification *contracts.TxVerification, settled contracts.SettledEvent, attended []string, claimDeadline, now time.Time) (*settlementOutcome, error) {
+	outcome := &settlementOutcome{Submitted: countDistinctWallets(attended)}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if settled.AttendedCount.Cmp(big.NewInt(int64(outcome.Submitted))) != 0 {
+		outcome.Review = fmt.Sprintf("vault settled %s attendees, %d were submitted", settled.AttendedCount, outcome.Submitted)
+		if err := flagSettlementForReview(ctx, tx, actor, eventID, txHash, verification.BlockNumber, outcome.Review); err != nil {
+			return nil, fmt.Errorf("failed to flag for review: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "Settlement held for review", "event_id", eventID, "review", outcome.Review)
+		return outcome, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE events_metadata
+		SET status = 'SETTLED', claim_deadline = $3, settled_at = $1, updated_at = $1,
+		    settlement_tx_hash = $4, settlement_block = $5, settlement_review = NULL
+		WHERE event_id = $2
+	`, now, eventID, claimDeadline, txHash, verification.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event status: %w", err)
+	}
+
+	outcome.Rewards, err = settleRewards(ctx, tx, eventID, attended)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate rewards: %w", err)
+	}
+
+	err = recordAudit(ctx, tx, actor, "rewards_calculated", &eventID, map[string]interface{}{
+		"transaction_hash": txHash,
+		"attendees":        len(outcome.Rewards.Rewards),
+		"forfeited":        outcome.Rewards.Forfeited.String(),
+		"yield":            outcome.Rewards.Yield.String(),
+		"dust":             outcome.Rewards.Dust.String(),
+		"dust_to":          outcome.Rewards.DustTo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Successfully updated event status to SETTLED", "event_id", eventID, "rewarded", len(outcome.Rewards.Rewards))
+	return outcome, nil
+}
+
+// flagSettlementForReview records a verified settlement transaction without settling the
+// event, with the reason an admin has to look at it
+func flagSettlementForReview(ctx context.Context, q querier, actor string, eventID int64, txHash string, block uint64, reason string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE events_metadata
+		SET settlement_tx_hash = $2, settlement_block = $3, settlement_review = $4, updated_at = NOW()
+		WHERE event_id = $1
+	`, eventID, txHash, block, reason)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(ctx, q, actor, "settlement_flagged", &eventID, map[string]interface{}{
+		"transaction_hash": txHash,
+		"block_number":     block,
+		"reason":           reason,
+	})
+}
+
+// isSettleable reports whether an event in status may be settled on-chain
+func isSettleable(status string) bool {
+	return status == models.StatusLive || status == models.StatusRegistrationClosed
+}
+
+// BuildSettlementTx returns the unsigned settleEvent transaction for the event's vault, built
+// from the attended wallets, for the organizer's wallet to sign and send (organizer only)
+func (h *EventHandler) BuildSettlementTx(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var status, organizer string
+	var vaultAddress *string
+	err = h.db.QueryRow(c, `
+		SELECT em.status, eo.organizer_address, eo.vault_address
+		FROM events_metadata em
+		JOIN events_onchain eo ON eo.event_id = em.event_id
+		WHERE em.event_id = $1
+	`, eventID).Scan(&status, &organizer, &vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for its settlement transaction", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can settle the event"})
+		return
+	}
+
+	if !isSettleable(status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "event_not_settleable",
+			"message":        "Only LIVE or REGISTRATION_CLOSED events can be settled",
+			"current_status": status,
+		})
+		return
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+	if !h.requireTrustedVault(c, eventID, *vaultAddress) {
+		return
+	}
+
+	attendees, err := settlementAttendees(c, h.db, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to load attended wallets", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if len(attendees) == 0 {
+		respondNoAttendees(c)
+		return
+	}
+
+	data, err := contracts.PackSettleEvent(attendees)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to encode settlement", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode the settlement transaction"})
+		return
+	}
+
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+	chainID, err := h.client.ChainID(c)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to read chain ID for the settlement", "event_id", eventID, "error", err)
+		c.JSON(chainReadStatus(err), chainError("Failed to read the chain ID", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"to":             common.HexToAddress(*vaultAddress).Hex(),
+		"data":           hexutil.Encode(data),
+		"value":          "0",
+		"chain_id":       chainID,
+		"attendee_count": len(attendees),
+		"function":       contracts.SettleFunction,
+	})
+}
+
+// ListSettlementReviews lists events whose settlement confirmation was held back because
+// the chain disagreed with the submitted attendees (admin only). Confirming the settlement
+// again with the right attendees clears the flag.
+func (h *EventHandler) ListSettlementReviews(c *gin.Context) {
+	rows, err := h.db.Query(c, `
+		SELECT event_id, title, status, settlement_tx_hash, settlement_block, settlement_review, updated_at
+		FROM events_metadata
+		WHERE settlement_review IS NOT NULL
+		ORDER BY updated_at DESC
+	`)
+	if err != nil {
+		slog.ErrorContext(c, "Database error listing settlement reviews", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	reviews := []gin.H{}
+	for rows.Next() {
+		var eventID int64
+		var title, status, reason string
+		var txHash *string
+		var block *int64
+		var flaggedAt time.Time
+		if err := rows.Scan(&eventID, &title, &status, &txHash, &block, &reason, &flaggedAt); err != nil {
+			slog.ErrorContext(c, "Error scanning settlement review row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		reviews = append(reviews, gin.H{
+			"event_id":         eventID,
+			"title":            title,
+			"status":           status,
+			"transaction_hash": txHash,
+			"block_number":     block,
+			"reason":           reason,
+			"flagged_at":       flaggedAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(c, "Database error listing settlement reviews", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviews": reviews, "count": len(reviews)})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"atfi-backend/models"
+)
+
+func TestSettleRequiresConfirmedSettlement(t *testing.T) {
+	db := testDB(t)
+	organizer := newTestWallet()
+	eventID := seedEvent(t, db, testEvent{Organizer: organizer, Status: models.StatusLive})
+	h := NewEventHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	path := "/events/" + strconv.FormatInt(eventID, 10) + "/settle"
+
+	tests := []struct {
+		name string
+		who  caller
+		body any
+		want int
+	}{
+		{"stranger", caller{wallet: newTestWallet()}, map[string]any{"transaction_hash": "0x01", "attended_participants": []string{}}, http.StatusForbidden},
+		{"organizer without a transaction", caller{wallet: organizer}, map[string]any{}, http.StatusBadRequest},
+		{"organizer without a chain client", caller{wallet: organizer}, map[string]any{"transaction_hash": "0x01", "attended_participants": []string{}}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(tt.who)
+		router.PUT("/events/:id/settle", h.ConfirmSettlement)
+		if w := serveJSON(router, http.MethodPut, path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	var status string
+	if err := db.QueryRow(context.Background(), "SELECT status FROM events_metadata WHERE event_id = $1", eventID).Scan(&status); err != nil {
+		t.Fatalf("load status: %v", err)
+	}
+	if status != models.StatusLive {
+		t.Errorf("status = %s after rejected settlements, want LIVE", status)
+	}
+}
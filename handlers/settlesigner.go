@@ -0,0 +1,598 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
+)
+
+// Delegation signatures older (or further in the future) than this are rejected
+const settlementDelegationMaxSkew = 5 * time.Minute
+
+// How often a submitted settlement is checked, how long it may sit unmined before it is
+// replaced with higher fees, and how long before it is left for a later POST /settle or
+// confirm-settlement to pick up
+const (
+	settlementPollInterval   = 15 * time.Second
+	settlementReplaceAfter   = 5 * time.Minute
+	settlementMonitorTimeout = 30 * time.Minute
+)
+
+// settlementDelegationMessage builds the message an organizer signs to let signer settle
+// the event, or to take that back
+func settlementDelegationMessage(eventID int64, signer string, enabled bool, timestamp int64) string {
+	return fmt.Sprintf("ATFi settlement delegation\nEvent: %d\nSigner: %s\nEnabled: %t\nTimestamp: %d",
+		eventID, strings.ToLower(signer), enabled, timestamp)
+}
+
+// respondSignerDisabled answers settlement signer requests when no SETTLEMENT_PRIVATE_KEY
+// is configured
+func respondSignerDisabled(c *gin.Context) {
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":   "settlement_signer_disabled",
+		"message": "Server-side settlement is not enabled",
+	})
+}
+
+// signerActor is the audit actor of actions the settlement signer takes on its own
+func (h *EventHandler) signerActor() string {
+	return strings.ToLower(h.signer.Address().Hex())
+}
+
+// GetSettlementDelegation returns the settlement signer's address and whether the event's
+// organizer delegated settlement to it
+func (h *EventHandler) GetSettlementDelegation(c *gin.Context) {
+	if h.signer == nil {
+		respondSignerDisabled(c)
+		return
+	}
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var delegate *string
+	var delegatedAt *time.Time
+	err = h.db.QueryRow(c, `
+		SELECT settlement_delegate, settlement_delegated_at FROM events_metadata WHERE event_id = $1
+	`, eventID).Scan(&delegate, &delegatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	delegated := delegate != nil && strings.EqualFold(*delegate, h.signer.Address().Hex())
+	response := gin.H{
+		"event_id":     eventID,
+		"signer":       h.signer.Address().Hex(),
+		"chain_id":     h.signer.ChainID().String(),
+		"delegated":    delegated,
+		"delegated_at": nil,
+	}
+	if delegated {
+		response["delegated_at"] = delegatedAt
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SetSettlementDelegation lets the server-side signer settle the event, or stops it. The
+// request must be signed by the organizer's wallet; an admin cannot delegate for them.
+func (h *EventHandler) SetSettlementDelegation(c *gin.Context) {
+	if h.signer == nil {
+		respondSignerDisabled(c)
+		return
+	}
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req struct {
+		Enabled   *bool  `json:"enabled" binding:"required"`
+		Signature string `json:"signature" binding:"required"`
+		Timestamp int64  `json:"timestamp" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !strings.EqualFold(c.GetString(middleware.UserAddressKey), organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can delegate settlement"})
+		return
+	}
+
+	skew := time.Since(time.Unix(req.Timestamp, 0))
+	if skew > settlementDelegationMaxSkew || skew < -settlementDelegationMaxSkew {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Delegation signature expired"})
+		return
+	}
+	signer := h.signer.Address().Hex()
+	message := settlementDelegationMessage(eventID, signer, *req.Enabled, req.Timestamp)
+	if err := h.signatures.VerifyPersonal(c, organizer, message, req.Signature); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid delegation signature", "details": err.Error()})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var delegate *string
+	var delegatedAt *time.Time
+	if *req.Enabled {
+		delegate = &signer
+		now := time.Now()
+		delegatedAt = &now
+	}
+	_, err = tx.Exec(c, `
+		UPDATE events_metadata
+		SET settlement_delegate = $2, settlement_delegated_at = $3, updated_at = NOW()
+		WHERE event_id = $1
+	`, eventID, delegate, delegatedAt)
+	if err != nil {
+		slog.ErrorContext(c, "Database error updating settlement delegation", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	action := "settlement_delegated"
+	if !*req.Enabled {
+		action = "settlement_delegation_revoked"
+	}
+	err = recordAudit(c, tx, strings.ToLower(organizer), action, &eventID, map[string]interface{}{
+		"signer":    signer,
+		"timestamp": req.Timestamp,
+	})
+	if err != nil {
+		slog.ErrorContext(c, "Failed to audit settlement delegation", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":     eventID,
+		"signer":       signer,
+		"delegated":    *req.Enabled,
+		"delegated_at": delegatedAt,
+	})
+}
+
+// SettleOnChain builds, signs and sends the event's settleEvent transaction with the
+// server-side signer, once the organizer has delegated settlement to it (organizer only).
+// The event is marked settled in the background once the transaction confirms, through
+// the same checks as ConfirmSettlement. With ?dry_run=true the priced transaction is
+// returned without being signed or sent.
+func (h *EventHandler) SettleOnChain(c *gin.Context) {
+	if h.signer == nil {
+		respondSignerDisabled(c)
+		return
+	}
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+	actor := c.GetString(middleware.UserAddressKey)
+
+	// One submission per event at a time
+	unlock := h.settlements.lock(eventID)
+	defer unlock()
+
+	var status, organizer string
+	var vaultAddress, delegate, pendingTx *string
+	err = h.db.QueryRow(c, `
+		SELECT em.status, eo.organizer_address, eo.vault_address, em.settlement_delegate,
+		       CASE WHEN em.settlement_block IS NULL THEN em.settlement_tx_hash END
+		FROM events_metadata em
+		JOIN events_onchain eo ON eo.event_id = em.event_id
+		WHERE em.event_id = $1
+	`, eventID).Scan(&status, &organizer, &vaultAddress, &delegate, &pendingTx)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for server-side settlement", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can settle the event"})
+		return
+	}
+	if delegate == nil || !strings.EqualFold(*delegate, h.signer.Address().Hex()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "settlement_not_delegated",
+			"message": "The organizer has not delegated settlement to the server-side signer",
+			"signer":  h.signer.Address().Hex(),
+		})
+		return
+	}
+	if !isSettleable(status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "event_not_settleable",
+			"message":        "Only LIVE or REGISTRATION_CLOSED events can be settled",
+			"current_status": status,
+		})
+		return
+	}
+	if vaultAddress == nil || !common.IsHexAddress(*vaultAddress) || common.HexToAddress(*vaultAddress) == (common.Address{}) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "vault_not_deployed",
+			"message": "The event has no vault address yet",
+		})
+		return
+	}
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		return
+	}
+	if !h.requireTrustedVault(c, eventID, *vaultAddress) {
+		return
+	}
+	vault := common.HexToAddress(*vaultAddress)
+
+	// A transaction we sent earlier may still be on its way; sending another would fail
+	// on-chain once the first lands
+	if pendingTx != nil && !dryRun {
+		verification, err := contracts.VerifySettlementTx(c, h.client, *pendingTx, vault, h.cfg.TxConfirmations)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to check settlement tx", "tx_hash", *pendingTx, "event_id", eventID, "error", err)
+			c.JSON(chainReadStatus(err), gin.H{"error": "Failed to check the pending settlement transaction"})
+			return
+		}
+		if verification.State == contracts.TxPending || verification.State == contracts.TxConfirmed {
+			go h.monitorSettlement(eventID, *pendingTx, vault)
+			c.JSON(http.StatusAccepted, withTxLink(gin.H{
+				"message":          "A settlement transaction was already submitted",
+				"transaction_hash": *pendingTx,
+				"tx_state":         verification.State,
+			}, *pendingTx, h.cfg.Chain))
+			return
+		}
+		slog.WarnContext(c, "Earlier settlement tx did not settle, sending another", "tx_hash", *pendingTx, "event_id", eventID, "reason", verification.Reason)
+	}
+
+	attendees, err := settlementAttendees(c, h.db, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to load attended wallets", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if len(attendees) == 0 {
+		respondNoAttendees(c)
+		return
+	}
+	data, err := contracts.PackSettleEvent(attendees)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to encode settlement", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode the settlement transaction"})
+		return
+	}
+
+	if dryRun {
+		tx, err := h.signer.Build(c, vault, data)
+		if err != nil {
+			h.respondSettleError(c, eventID, err)
+			return
+		}
+		if err := recordAudit(c, h.db, actor, "settlement_dry_run", &eventID, map[string]interface{}{
+			"signer":    h.signer.Address().Hex(),
+			"attendees": len(attendees),
+			"gas":       tx.Gas(),
+		}); err != nil {
+			slog.ErrorContext(c, "Failed to audit settlement dry run", "event_id", eventID, "error", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":                  true,
+			"from":                     h.signer.Address().Hex(),
+			"to":                       vault.Hex(),
+			"data":                     hexutil.Encode(data),
+			"value":                    "0",
+			"chain_id":                 h.signer.ChainID().String(),
+			"nonce":                    tx.Nonce(),
+			"gas":                      tx.Gas(),
+			"max_fee_per_gas":          tx.GasFeeCap().String(),
+			"max_priority_fee_per_gas": tx.GasTipCap().String(),
+			"attendee_count":           len(attendees),
+			"function":                 contracts.SettleFunction,
+		})
+		return
+	}
+
+	tx, err := h.signer.Send(c, vault, data)
+	if err != nil {
+		h.respondSettleError(c, eventID, err)
+		return
+	}
+	txHash := tx.Hash().Hex()
+	slog.InfoContext(c, "Sent settlement tx", "tx_hash", txHash, "event_id", eventID, "attendees", len(attendees), "nonce", tx.Nonce())
+
+	// The transaction is out: failing to record it must not stop the monitor
+	_, err = h.db.Exec(c, `
+		UPDATE events_metadata
+		SET settlement_tx_hash = $2, settlement_block = NULL, settlement_review = NULL, updated_at = NOW()
+		WHERE event_id = $1
+	`, eventID, txHash)
+	if err != nil {
+		slog.ErrorContext(c, "Failed to record settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+	}
+	if err := recordAudit(c, h.db, actor, "settlement_submitted", &eventID, map[string]interface{}{
+		"transaction_hash":         txHash,
+		"signer":                   h.signer.Address().Hex(),
+		"nonce":                    tx.Nonce(),
+		"gas":                      tx.Gas(),
+		"max_fee_per_gas":          tx.GasFeeCap().String(),
+		"max_priority_fee_per_gas": tx.GasTipCap().String(),
+		"attendees":                len(attendees),
+	}); err != nil {
+		slog.ErrorContext(c, "Failed to audit settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+	}
+
+	go h.monitorSettlement(eventID, txHash, vault)
+
+	c.JSON(http.StatusAccepted, withTxLink(gin.H{
+		"message":          "Settlement transaction submitted",
+		"transaction_hash": txHash,
+		"from":             h.signer.Address().Hex(),
+		"nonce":            tx.Nonce(),
+		"attendee_count":   len(attendees),
+	}, txHash, h.cfg.Chain))
+}
+
+// respondSettleError maps a failure to price or send the settlement transaction
+func (h *EventHandler) respondSettleError(c *gin.Context, eventID int64, err error) {
+	if revert, ok := contracts.RevertOf(err); ok {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":          "transaction_would_revert",
+			"message":        "The settlement transaction would fail if sent",
+			"reason":         revert.Reason,
+			"onchain_revert": revert.Name,
+		})
+		return
+	}
+	if errors.Is(err, contracts.ErrGasCapExceeded) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "gas_cap_exceeded",
+			"message": "Gas is above the configured caps. Try again later.",
+			"details": err.Error(),
+		})
+		return
+	}
+	slog.ErrorContext(c, "Failed to send the settlement", "event_id", eventID, "error", err)
+	c.JSON(chainReadStatus(err), chainError("Failed to send the settlement transaction", err))
+}
+
+// monitorSettlement waits for a settlement transaction the signer sent and records it the
+// way ConfirmSettlement would. A transaction left unmined for settlementReplaceAfter is
+// replaced with higher fees; whichever of them is mined decides. A reverted transaction is
+// cleared so the next POST /settle sends a new one. Only one monitor runs per transaction.
+func (h *EventHandler) monitorSettlement(eventID int64, txHash string, vault common.Address) {
+	if _, running := h.settlementMonitors.LoadOrStore(txHash, true); running {
+		return
+	}
+	// Every transaction sent for this settlement, the latest last
+	hashes := []string{txHash}
+	defer func() {
+		for _, hash := range hashes {
+			h.settlementMonitors.Delete(hash)
+			h.signer.Forget(common.HexToHash(hash))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), settlementMonitorTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(settlementPollInterval)
+	defer ticker.Stop()
+
+	for {
+		hash, verification, err := h.checkSettlementTxs(ctx, hashes, vault)
+		if err == nil && verification.State != contracts.TxPending && hash != hashes[len(hashes)-1] {
+			// A replaced transaction was mined after all: the event points at it again
+			_, err = h.db.Exec(ctx, `
+				UPDATE events_metadata SET settlement_tx_hash = $3, updated_at = NOW()
+				WHERE event_id = $1 AND settlement_tx_hash = $2 AND settlement_block IS NULL
+			`, eventID, hashes[len(hashes)-1], hash)
+		}
+		switch {
+		case err != nil:
+			slog.Error("Failed to check settlement tx", "tx_hash", hash, "event_id", eventID, "error", err)
+		case verification.State == contracts.TxConfirmed:
+			h.completeSettlement(ctx, eventID, hash, vault, verification)
+			return
+		case verification.State != contracts.TxPending:
+			h.abandonSettlement(ctx, eventID, hash, verification.Reason)
+			return
+		default:
+			if replacement := h.replaceStuckSettlement(ctx, eventID, hash); replacement != "" {
+				hashes = append(hashes, replacement)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			latest := hashes[len(hashes)-1]
+			slog.Warn("Settlement tx unconfirmed", "tx_hash", latest, "event_id", eventID, "timeout", settlementMonitorTimeout)
+			if err := recordAudit(context.Background(), h.db, h.signerActor(), "settlement_unconfirmed", &eventID, map[string]interface{}{
+				"transaction_hash": latest,
+			}); err != nil {
+				slog.Error("Failed to audit unconfirmed settlement tx", "tx_hash", latest, "event_id", eventID, "error", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSettlementTxs verifies the transactions sent for one settlement, latest first, and
+// returns the first that is no longer pending. Only one of them can be mined. While all
+// are pending it returns the latest.
+func (h *EventHandler) checkSettlementTxs(ctx context.Context, hashes []string, vault common.Address) (string, *contracts.TxVerification, error) {
+	latest := hashes[len(hashes)-1]
+	var pending *contracts.TxVerification
+	for i := len(hashes) - 1; i >= 0; i-- {
+		verification, err := contracts.VerifySettlementTx(ctx, h.client, hashes[i], vault, h.cfg.TxConfirmations)
+		if err != nil {
+			return hashes[i], nil, err
+		}
+		if verification.State != contracts.TxPending {
+			return hashes[i], verification, nil
+		}
+		if i == len(hashes)-1 {
+			pending = verification
+		}
+	}
+	return latest, pending, nil
+}
+
+// replaceStuckSettlement re-sends the signer's settlement txHash with higher fees once it
+// has been unmined for settlementReplaceAfter, and records the new hash in its place. It
+// returns the new hash, or "" when nothing was sent.
+func (h *EventHandler) replaceStuckSettlement(ctx context.Context, eventID int64, txHash string) string {
+	tx, err := h.signer.ReplaceStuck(ctx, common.HexToHash(txHash), settlementReplaceAfter)
+	if errors.Is(err, contracts.ErrNotStuck) {
+		return ""
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to replace stuck settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+		return ""
+	}
+	replacement := tx.Hash().Hex()
+	h.settlementMonitors.Store(replacement, true)
+	slog.InfoContext(ctx, "Replaced stuck settlement tx", "tx_hash", txHash, "event_id", eventID, "replacement_tx_hash", replacement, "nonce", tx.Nonce())
+
+	// The replacement is out: failing to record it must not stop the monitor
+	_, err = h.db.Exec(ctx, `
+		UPDATE events_metadata
+		SET settlement_tx_hash = $3, updated_at = NOW()
+		WHERE event_id = $1 AND settlement_tx_hash = $2 AND settlement_block IS NULL
+	`, eventID, txHash, replacement)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record replacement settlement tx", "replacement_tx_hash", replacement, "event_id", eventID, "error", err)
+	}
+	if err := recordAudit(ctx, h.db, h.signerActor(), "settlement_replaced", &eventID, map[string]interface{}{
+		"replaced_transaction_hash": txHash,
+		"transaction_hash":          replacement,
+		"nonce":                     tx.Nonce(),
+		"max_fee_per_gas":           tx.GasFeeCap().String(),
+		"max_priority_fee_per_gas":  tx.GasTipCap().String(),
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to audit replacement settlement tx", "replacement_tx_hash", replacement, "event_id", eventID, "error", err)
+	}
+	return replacement
+}
+
+// completeSettlement records a confirmed settlement transaction of the signer's. Attendance
+// is read again rather than remembered from the send, so a monitor resumed by a later POST
+// /settle works the same; a check-in that changed since is caught by the attendee count
+// check and flagged for review.
+func (h *EventHandler) completeSettlement(ctx context.Context, eventID int64, txHash string, vault common.Address, verification *contracts.TxVerification) {
+	unlock := h.settlements.lock(eventID)
+	defer unlock()
+
+	// Another monitor or ConfirmSettlement may have got there first
+	var status string
+	var recordedBlock *int64
+	err := h.db.QueryRow(ctx, `
+		SELECT status, settlement_block FROM events_metadata WHERE event_id = $1
+	`, eventID).Scan(&status, &recordedBlock)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load event to record settlement tx", "event_id", eventID, "tx_hash", txHash, "error", err)
+		return
+	}
+	if !isSettleable(status) || recordedBlock != nil {
+		return
+	}
+
+	settled, err := contracts.SettledInReceipt(verification.Receipt, vault)
+	if err != nil {
+		h.abandonSettlement(ctx, eventID, txHash, err.Error())
+		return
+	}
+	attended, err := attendedWallets(ctx, h.db, strconv.FormatInt(eventID, 10))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load attended wallets to record settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+		return
+	}
+
+	now := time.Now()
+	outcome, err := h.recordSettlement(ctx, h.signerActor(), eventID, txHash, verification, settled, attended, claimDeadlineFor(now, nil, h.cfg.ClaimWindow), now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+		return
+	}
+	if outcome.Review == "" {
+		slog.InfoContext(ctx, "Settlement tx confirmed", "tx_hash", txHash, "event_id", eventID, "block_number", verification.BlockNumber)
+	}
+}
+
+// abandonSettlement clears a signer's settlement transaction that did not settle the
+// vault, so that a new one can be sent
+func (h *EventHandler) abandonSettlement(ctx context.Context, eventID int64, txHash, reason string) {
+	slog.ErrorContext(ctx, "Settlement tx failed", "tx_hash", txHash, "event_id", eventID, "reason", reason)
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to clear settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE events_metadata
+		SET settlement_tx_hash = NULL, updated_at = NOW()
+		WHERE event_id = $1 AND settlement_tx_hash = $2 AND settlement_block IS NULL
+	`, eventID, txHash)
+	if err == nil {
+		err = recordAudit(ctx, tx, h.signerActor(), "settlement_failed", &eventID, map[string]interface{}{
+			"transaction_hash": txHash,
+			"reason":           reason,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to clear settlement tx", "tx_hash", txHash, "event_id", eventID, "error", err)
+	}
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/contracts"
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+)
+
+type StakeHandler struct {
+	db          *pgxpool.Pool
+	client      contracts.Caller
+	tokens      []contracts.Token      // first entry is the chain's primary token
+	stakeTokens *contracts.StakeTokens // nil without a client
+}
+
+func NewStakeHandler(db *pgxpool.Pool, client contracts.Caller, tokens []contracts.Token) *StakeHandler {
+	h := &StakeHandler{db: db, client: client, tokens: tokens}
+	if client != nil {
+		h.stakeTokens = contracts.NewStakeTokens(client)
+	}
+	return h
+}
+
+// stakeColumns selects a stakes row in the order scanStake expects; nullable columns
+// are coalesced so rows written by RegisterUser (no block data yet) still scan
+const stakeColumns = `
+	s.id, s.event_id, s.user_id, s.wallet_address, s.is_attended, s.stake_amount::text,
+	s.stake_transaction_hash, COALESCE(s.created_at_block, 0), COALESCE(s.created_at_timestamp, s.created_at),
+	COALESCE(s.reward_amount::text, ''), s.claimed, COALESCE(s.claimed_transaction_hash, ''), s.claimed_at,
+	s.created_at, s.updated_at
+`
+
+func scanStake(row pgx.Row, stake *models.Stake) error {
+	return row.Scan(
+		&stake.ID,
+		&stake.EventID,
+		&stake.UserID,
+		&stake.WalletAddress,
+		&stake.IsAttended,
+		&stake.StakeAmount,
+		&stake.StakeTransactionHash,
+		&stake.CreatedAtBlock,
+		&stake.CreatedAtTimestamp,
+		&stake.RewardAmount,
+		&stake.Claimed,
+		&stake.ClaimedTransactionHash,
+		&stake.ClaimedAt,
+		&stake.CreatedAt,
+		&stake.UpdatedAt,
+	)
+}
+
+// CreateStake records an on-chain stake reported by the indexer and upserts the matching
+// participant row so the two tables cannot diverge
+func (h *StakeHandler) CreateStake(c *gin.Context) {
+	var req models.CreateStakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isBaseUnitAmount(req.StakeAmount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stake_amount must be a non-negative integer in token base units"})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// A registration may already have created the stake without block data; fill it in
+	query := `
+		INSERT INTO stakes AS s (event_id, user_id, wallet_address, stake_amount, stake_transaction_hash,
+		                         created_at_block, created_at_timestamp, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT ON CONSTRAINT stakes_event_user_key DO UPDATE SET
+			wallet_address = EXCLUDED.wallet_address,
+			stake_amount = EXCLUDED.stake_amount,
+			stake_transaction_hash = EXCLUDED.stake_transaction_hash,
+			created_at_block = EXCLUDED.created_at_block,
+			created_at_timestamp = EXCLUDED.created_at_timestamp,
+			updated_at = EXCLUDED.updated_at
+		RETURNING ` + stakeColumns
+
+	var stake models.Stake
+	now := time.Now()
+	err = scanStake(tx.QueryRow(c, query,
+		req.EventID,
+		req.UserID,
+		req.WalletAddress,
+		req.StakeAmount,
+		req.StakeTransactionHash,
+		req.CreatedAtBlock,
+		req.CreatedAtTimestamp,
+		now,
+	), &stake)
+	if err != nil {
+		if isUniqueViolation(err, "stakes_stake_transaction_hash_key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Stake transaction already recorded for another registration"})
+			return
+		}
+		slog.ErrorContext(c, "Error recording stake", "event_id", req.EventID, "profile_id", req.UserID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record stake", "details": err.Error()})
+		return
+	}
+
+	_, err = tx.Exec(c, `
+		INSERT INTO participant (event_id, user_id, is_attend, is_claim, created_at, updated_at)
+		VALUES ($1, $2, false, false, $3, $3)
+		ON CONFLICT DO NOTHING
+	`, req.EventID, req.UserID, now)
+	if err != nil {
+		slog.ErrorContext(c, "Error upserting participant", "stake_id", stake.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record participant"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record stake"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, stake)
+}
+
+// UpdateStake writes attendance, reward and claim bookkeeping back onto a stake
+// (event organizer or indexer only), keeping the sibling participant row in step
+func (h *StakeHandler) UpdateStake(c *gin.Context) {
+	stakeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stake ID"})
+		return
+	}
+
+	var req models.UpdateStakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.RewardAmount != nil && !isBaseUnitAmount(*req.RewardAmount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reward_amount must be a non-negative integer in token base units"})
+		return
+	}
+
+	if req.Claimed != nil && *req.Claimed && (req.ClaimedTransactionHash == nil || *req.ClaimedTransactionHash == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "claimed_transaction_hash is required when claimed is true"})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var current models.Stake
+	err = scanStake(tx.QueryRow(c, "SELECT "+stakeColumns+" FROM stakes s WHERE s.id = $1 FOR UPDATE", stakeID), &current)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Stake not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !middleware.IsIndexer(c) {
+		organizer, err := getEventOrganizer(c, tx, current.EventID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !isOrganizerOrAdmin(c, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can update stakes"})
+			return
+		}
+	}
+
+	// A claim is final: it can be recorded once and never reverted
+	if req.Claimed != nil && current.Claimed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Stake has already been claimed", "claimed_transaction_hash": current.ClaimedTransactionHash})
+		return
+	}
+
+	var claimedAt *time.Time
+	if req.Claimed != nil && *req.Claimed {
+		now := time.Now()
+		claimedAt = &now
+	}
+
+	query := `
+		UPDATE stakes AS s SET
+			is_attended = COALESCE($2, is_attended),
+			reward_amount = COALESCE($3::numeric, reward_amount),
+			claimed = COALESCE($4, claimed),
+			claimed_transaction_hash = COALESCE($5, claimed_transaction_hash),
+			claimed_at = COALESCE($6, claimed_at),
+			updated_at = now()
+		WHERE s.id = $1
+		RETURNING ` + stakeColumns
+
+	var stake models.Stake
+	err = scanStake(tx.QueryRow(c, query,
+		stakeID,
+		req.IsAttended,
+		req.RewardAmount,
+		req.Claimed,
+		req.ClaimedTransactionHash,
+		claimedAt,
+	), &stake)
+	if err != nil {
+		slog.ErrorContext(c, "Error updating stake", "stake_id", stakeID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stake"})
+		return
+	}
+
+	_, err = tx.Exec(c, `
+		UPDATE participant
+		SET is_attend = $3, is_claim = $4, updated_at = now()
+		WHERE event_id = $1 AND user_id = $2
+	`, stake.EventID, stake.UserID, stake.IsAttended, stake.Claimed)
+	if err != nil {
+		slog.ErrorContext(c, "Error syncing participant", "stake_id", stakeID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update participant"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stake"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stake)
+}
+
+// GetEventStakesStats aggregates participation and stake totals for the settlement UI.
+// Amounts are summed as numeric in the database and returned as base-unit strings.
+func (h *StakeHandler) GetEventStakesStats(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Attendance counts the way settlement does, so unvalidated self check-ins are no-shows
+	query := `
+		SELECT
+			em.status,
+			COUNT(p.id),
+			COUNT(p.id) FILTER (WHERE p.is_attend AND `+selfServiceCountable+`),
+			COALESCE(SUM(s.stake_amount), 0)::text,
+			COALESCE(SUM(s.reward_amount), 0)::text,
+			COALESCE(SUM(s.reward_amount) FILTER (WHERE s.claimed), 0)::text
+		FROM events_metadata em
+		LEFT JOIN participant p ON p.event_id = em.event_id
+		LEFT JOIN profiles pr ON pr.id = p.user_id
+		LEFT JOIN stakes s ON s.event_id = p.event_id AND s.user_id = p.user_id
+		WHERE em.event_id = $1
+		GROUP BY em.status
+	`
+
+	var stats models.StakesStats
+	err = h.db.QueryRow(c, query, eventID).Scan(
+		&stats.EventStatus,
+		&stats.TotalParticipants,
+		&stats.AttendedParticipants,
+		&stats.TotalStaked,
+		&stats.TotalRewards,
+		&stats.TotalClaimed,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error computing stake stats", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	stats.NoShowParticipants = stats.TotalParticipants - stats.AttendedParticipants
+	if stats.TotalParticipants > 0 {
+		stats.AttendanceRate = float64(stats.AttendedParticipants) / float64(stats.TotalParticipants)
+	}
+	stats.IsSettled = stats.EventStatus == models.StatusSettled
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetEventStakes lists the stakes for an event, newest first
+func (h *StakeHandler) GetEventStakes(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	h.listStakes(c, "s.event_id = $1", eventID)
+}
+
+// GetUserStakes lists the stakes made by a wallet across events, newest first
+func (h *StakeHandler) GetUserStakes(c *gin.Context) {
+	h.listStakes(c, "LOWER(s.wallet_address) = LOWER($1)", c.Param("walletAddress"))
+}
+
+func (h *StakeHandler) listStakes(c *gin.Context, where string, arg interface{}) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxParticipantsPageSize {
+		limit = maxParticipantsPageSize
+	}
+
+	query := `
+		SELECT ` + stakeColumns + `, COUNT(*) OVER()
+		FROM stakes s
+		WHERE ` + where + `
+		ORDER BY s.created_at DESC, s.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := h.db.Query(c, query, arg, limit, (page-1)*limit)
+	if err != nil {
+		slog.ErrorContext(c, "Error listing stakes", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	stakes := []models.Stake{}
+	total := 0
+	for rows.Next() {
+		var stake models.Stake
+		err := rows.Scan(
+			&stake.ID,
+			&stake.EventID,
+			&stake.UserID,
+			&stake.WalletAddress,
+			&stake.IsAttended,
+			&stake.StakeAmount,
+			&stake.StakeTransactionHash,
+			&stake.CreatedAtBlock,
+			&stake.CreatedAtTimestamp,
+			&stake.RewardAmount,
+			&stake.Claimed,
+			&stake.ClaimedTransactionHash,
+			&stake.ClaimedAt,
+			&stake.CreatedAt,
+			&stake.UpdatedAt,
+			&total,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning stake row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan stake"})
+			return
+		}
+		stakes = append(stakes, stake)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stakes": stakes,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Check-in activity kinds pushed to the organizer stream
+const (
+	activityCheckedIn = "checked_in"
+	activityValidated = "validated"
+	activityUndone    = "undone"
+)
+
+// Stream limits: subscribers per event, buffered activity per subscriber and heartbeat interval
+const (
+	maxStreamSubscribersPerEvent = 20
+	streamBufferSize             = 64
+	streamHeartbeatInterval      = 15 * time.Second
+)
+
+var errTooManySubscribers = errors.New("too many stream subscribers for this event")
+
+// checkinActivity is one entry in an event's check-in feed
+type checkinActivity struct {
+	ID            int64     `json:"id"`
+	EventID       int64     `json:"event_id"`
+	Kind          string    `json:"kind"`
+	ParticipantID *string   `json:"participant_id"`
+	WalletAddress *string   `json:"wallet_address"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// checkinBroadcaster fans check-in activity out to the stream subscribers of each event
+type checkinBroadcaster struct {
+	mu     sync.Mutex
+	subs   map[int64]map[chan checkinActivity]struct{}
+	closed chan struct{} // closed once streams must end
+}
+
+// subscribe registers a subscriber for an event and returns its channel and unsubscribe func
+func (b *checkinBroadcaster) subscribe(eventID int64) (chan checkinActivity, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs == nil {
+		b.subs = map[int64]map[chan checkinActivity]struct{}{}
+	}
+	if len(b.subs[eventID]) >= maxStreamSubscribersPerEvent {
+		return nil, nil, errTooManySubscribers
+	}
+	if b.subs[eventID] == nil {
+		b.subs[eventID] = map[chan checkinActivity]struct{}{}
+	}
+
+	ch := make(chan checkinActivity, streamBufferSize)
+	b.subs[eventID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[eventID], ch)
+		if len(b.subs[eventID]) == 0 {
+			delete(b.subs, eventID)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// publish delivers activity to every subscriber of its event. Slow subscribers miss
+// entries rather than block the publisher; they catch up by reconnecting with Last-Event-ID.
+func (b *checkinBroadcaster) publish(activity checkinActivity) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[activity.EventID] {
+		select {
+		case ch <- activity:
+		default:
+		}
+	}
+}
+
+// done returns a channel that is closed when every stream must end
+func (b *checkinBroadcaster) done() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed == nil {
+		b.closed = make(chan struct{})
+	}
+	return b.closed
+}
+
+// closeAll ends every stream
+func (b *checkinBroadcaster) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed == nil {
+		b.closed = make(chan struct{})
+	}
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+}
+
+// CloseStreams ends every check-in stream, so shutdown does not wait on connections that
+// never finish. Clients reconnect with Last-Event-ID and miss nothing.
+func (h *CheckinHandler) CloseStreams() {
+	h.broadcaster.closeAll()
+}
+
+// recordActivity stores check-in activity for replay and publishes it to live streams.
+// Failures are logged only; the check-in itself has already succeeded.
+func (h *CheckinHandler) recordActivity(ctx context.Context, eventID int64, kind string, participantID, walletAddress *string) {
+	activity := checkinActivity{EventID: eventID, Kind: kind, ParticipantID: participantID, WalletAddress: walletAddress}
+	err := h.db.QueryRow(ctx, `
+		INSERT INTO checkin_activity (event_id, kind, participant_id, wallet_address)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, eventID, kind, participantID, walletAddress).Scan(&activity.ID, &activity.CreatedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record activity", "kind", kind, "event_id", eventID, "error", err)
+		return
+	}
+
+	h.broadcaster.publish(activity)
+}
+
+// StreamCheckins holds a server-sent events connection that pushes check-in activity for
+// an event as it happens (organizer only). Clients reconnecting with Last-Event-ID get the
+// activity they missed replayed first.
+func (h *CheckinHandler) StreamCheckins(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can stream check-ins"})
+		return
+	}
+
+	var lastID int64
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		lastID, err = strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing published in between is lost
+	activities, unsubscribe, err := h.broadcaster.subscribe(eventID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if lastID > 0 {
+		lastID, err = h.replayActivity(c, eventID, lastID)
+		if err != nil {
+			slog.ErrorContext(c, "Failed to replay check-in activity", "event_id", eventID, "error", err)
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	closed := h.broadcaster.done()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case activity := <-activities:
+			if activity.ID <= lastID {
+				continue
+			}
+			if err := writeActivity(c, activity); err != nil {
+				return
+			}
+			lastID = activity.ID
+			c.Writer.Flush()
+		}
+	}
+}
+
+// replayActivity writes the event's activity after lastID and returns the newest ID sent
+func (h *CheckinHandler) replayActivity(c *gin.Context, eventID, lastID int64) (int64, error) {
+	rows, err := h.db.Query(c, `
+		SELECT id, event_id, kind, participant_id, wallet_address, created_at
+		FROM checkin_activity
+		WHERE event_id = $1 AND id > $2
+		ORDER BY id
+	`, eventID, lastID)
+	if err != nil {
+		return lastID, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var activity checkinActivity
+		err := rows.Scan(&activity.ID, &activity.EventID, &activity.Kind, &activity.ParticipantID, &activity.WalletAddress, &activity.CreatedAt)
+		if err != nil {
+			return lastID, err
+		}
+		if err := writeActivity(c, activity); err != nil {
+			return lastID, err
+		}
+		lastID = activity.ID
+	}
+	return lastID, rows.Err()
+}
+
+// writeActivity writes one SSE message with the activity ID as the event ID
+func writeActivity(c *gin.Context, activity checkinActivity) error {
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", activity.ID, activity.Kind, data)
+	return err
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Upper bound on check-ins accepted in one offline sync upload
+const maxSyncBatchSize = 500
+
+// Outcome of each synced entry
+const (
+	syncCheckedIn = "checked_in"
+	syncDuplicate = "duplicate"
+	syncRejected  = "rejected"
+)
+
+// Rejection codes specific to offline sync, alongside the scan codes
+const (
+	syncInvalidEntry  = "invalid_entry"
+	syncEventNotFound = "event_not_found"
+	syncForbidden     = "forbidden"
+	syncEventSettled  = "event_settled"
+	syncOutsideWindow = "outside_window"
+	syncQRUnavailable = "qr_unavailable"
+	syncServerError   = "server_error" // safe to retry
+)
+
+// syncEntry is one check-in queued by the scanner app while offline
+type syncEntry struct {
+	EventID       int64     `json:"event_id"`
+	QRData        string    `json:"qr_data"`
+	WalletAddress string    `json:"wallet_address"`
+	ScannedAt     time.Time `json:"scanned_at"`
+	DeviceID      string    `json:"device_id"`
+	ClientUUID    string    `json:"client_uuid"`
+}
+
+// syncResult reports what happened to one entry so the app can clear it from its queue
+type syncResult struct {
+	ClientUUID    string     `json:"client_uuid"`
+	Status        string     `json:"status"`
+	Code          string     `json:"code,omitempty"`
+	Message       string     `json:"message,omitempty"`
+	ParticipantID string     `json:"participant_id,omitempty"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+}
+
+// syncEvent caches the event fields needed to validate entries in one upload
+type syncEvent struct {
+	status    string
+	eventDate int64
+	rotating  bool
+	allowed   bool
+}
+
+func rejectSync(entry syncEntry, code, message string) syncResult {
+	return syncResult{ClientUUID: entry.ClientUUID, Status: syncRejected, Code: code, Message: message}
+}
+
+// SyncCheckins applies a batch of check-ins the scanner app recorded while offline. Each
+// entry is validated like a live scan, deduplicated by client_uuid, and stored with the
+// time it was scanned rather than the time it was uploaded (organizer only).
+func (h *CheckinHandler) SyncCheckins(c *gin.Context) {
+	var entries []syncEntry
+	if err := c.ShouldBindJSON(&entries); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(entries) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No check-ins to sync"})
+		return
+	}
+	if len(entries) > maxSyncBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many check-ins in one sync", "max": maxSyncBatchSize})
+		return
+	}
+
+	events := map[int64]*syncEvent{}
+	seen := map[uuid.UUID]bool{}
+	results := make([]syncResult, 0, len(entries))
+	counts := map[string]int{}
+
+	for _, entry := range entries {
+		result := h.syncCheckin(c, entry, events, seen)
+		counts[result.Status]++
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":    results,
+		"checked_in": counts[syncCheckedIn],
+		"duplicates": counts[syncDuplicate],
+		"rejected":   counts[syncRejected],
+	})
+}
+
+// syncCheckin validates and applies a single offline entry
+func (h *CheckinHandler) syncCheckin(c *gin.Context, entry syncEntry, events map[int64]*syncEvent, seen map[uuid.UUID]bool) syncResult {
+	clientUUID, err := uuid.Parse(entry.ClientUUID)
+	if err != nil {
+		return rejectSync(entry, syncInvalidEntry, "client_uuid must be a UUID")
+	}
+	if seen[clientUUID] {
+		return syncResult{ClientUUID: entry.ClientUUID, Status: syncDuplicate}
+	}
+	seen[clientUUID] = true
+
+	if entry.ScannedAt.IsZero() {
+		return rejectSync(entry, syncInvalidEntry, "scanned_at is required")
+	}
+	if entry.QRData == "" && entry.WalletAddress == "" {
+		return rejectSync(entry, syncInvalidEntry, "qr_data or wallet_address is required")
+	}
+
+	event, ok := events[entry.EventID]
+	if !ok {
+		event = &syncEvent{}
+		var organizer string
+		err := h.db.QueryRow(c, `
+			SELECT em.status, eo.event_date, eo.organizer_address, em.rotating_qr
+			FROM events_onchain eo
+			JOIN events_metadata em ON em.event_id = eo.event_id
+			WHERE eo.event_id = $1
+		`, entry.EventID).Scan(&event.status, &event.eventDate, &organizer, &event.rotating)
+		if err != nil && err != pgx.ErrNoRows {
+			slog.ErrorContext(c, "Error loading event for sync", "event_id", entry.EventID, "error", err)
+			return rejectSync(entry, syncServerError, "Database error")
+		}
+		if err == nil {
+			event.allowed = isOrganizerOrAdmin(c, organizer)
+		} else {
+			event = nil
+		}
+		events[entry.EventID] = event
+	}
+	if event == nil {
+		return rejectSync(entry, syncEventNotFound, "Event not found")
+	}
+	if !event.allowed {
+		return rejectSync(entry, syncForbidden, "Only the event organizer can sync check-ins")
+	}
+
+	var existing bool
+	err = h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM checkins WHERE client_uuid = $1)", clientUUID).Scan(&existing)
+	if err != nil {
+		slog.ErrorContext(c, "Error checking synced check-in", "client_uuid", clientUUID, "error", err)
+		return rejectSync(entry, syncServerError, "Database error")
+	}
+	if existing {
+		return syncResult{ClientUUID: entry.ClientUUID, Status: syncDuplicate}
+	}
+
+	now := h.now()
+	if !offlineScanAccepted(event.status, event.eventDate, entry.ScannedAt, now, h.cfg.CheckInWindow) {
+		if event.status != models.StatusLive {
+			return rejectSync(entry, syncEventSettled, "The event is no longer live ("+strings.ToLower(event.status)+")")
+		}
+		return rejectSync(entry, syncOutsideWindow, "scanned_at is outside the event's check-in window")
+	}
+
+	// Resolve the participant from the signed QR payload, checked as of the scan time, or the wallet
+	participantQuery := `
+		SELECT p.id, pr.wallet_address
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)`
+	args := []interface{}{entry.EventID, entry.WalletAddress}
+	if entry.QRData != "" {
+		payload, err := verifyQRPayload(h.cfg.Auth.QRSigningSecret, strings.TrimSpace(entry.QRData), entry.ScannedAt)
+		if err == errQRSigningDisabled {
+			return rejectSync(entry, syncQRUnavailable, "QR codes are not configured")
+		}
+		if err != nil {
+			_, code, message := qrScanFailure(err)
+			return rejectSync(entry, code, message)
+		}
+		if payload.EventID != strconv.FormatInt(entry.EventID, 10) {
+			return rejectSync(entry, scanWrongEvent, "This QR code is for a different event")
+		}
+		if err := verifyRotation(c, h.db, payload, event.rotating, entry.ScannedAt); err != nil {
+			if err != errQRExpired && err != errQRInvalidSignature && err != errQRRotationRequired {
+				slog.ErrorContext(c, "Error checking rotating code", "participant_id", payload.ParticipantID, "error", err)
+				return rejectSync(entry, syncServerError, "Database error")
+			}
+			_, code, message := qrScanFailure(err)
+			return rejectSync(entry, code, message)
+		}
+		participantQuery += " AND p.id = $3"
+		args = []interface{}{entry.EventID, payload.UserAddress, payload.ParticipantID}
+	}
+
+	var participantID, walletAddress string
+	err = h.db.QueryRow(c, participantQuery, args...).Scan(&participantID, &walletAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return rejectSync(entry, scanNotRegistered, "This attendee is not registered for this event")
+		}
+		slog.ErrorContext(c, "Error resolving synced participant", "event_id", entry.EventID, "error", err)
+		return rejectSync(entry, syncServerError, "Database error")
+	}
+
+	checkedInAt := entry.ScannedAt.UTC()
+	if checkedInAt.After(now) {
+		checkedInAt = now
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		return rejectSync(entry, syncServerError, "Database error")
+	}
+	defer tx.Rollback(c)
+
+	tag, err := tx.Exec(c, `
+		UPDATE participant
+		SET is_attend = true, updated_at = $1
+		WHERE id = $2 AND is_attend = false
+	`, checkedInAt, participantID)
+	if err != nil {
+		slog.ErrorContext(c, "Error syncing check-in", "participant_id", participantID, "error", err)
+		return rejectSync(entry, syncServerError, "Failed to check in participant")
+	}
+	if tag.RowsAffected() == 0 {
+		result := rejectSync(entry, scanAlreadyCheckedIn, "This attendee has already checked in")
+		result.ParticipantID = participantID
+		return result
+	}
+
+	record := models.CreateCheckinRequest{
+		EventID:       entry.EventID,
+		WalletAddress: walletAddress,
+		QRData:        entry.QRData,
+		CheckedBy:     c.GetString(middleware.UserAddressKey),
+		IPAddress:     c.ClientIP(),
+		DeviceID:      entry.DeviceID,
+		ClientUUID:    &clientUUID,
+	}
+	if err := recordCheckin(c, tx, record, checkedInAt); err != nil {
+		if isUniqueViolation(err, "checkins_client_uuid_key") {
+			return syncResult{ClientUUID: entry.ClientUUID, Status: syncDuplicate}
+		}
+		slog.ErrorContext(c, "Error recording synced check-in", "client_uuid", clientUUID, "error", err)
+		return rejectSync(entry, syncServerError, "Failed to check in participant")
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return rejectSync(entry, syncServerError, "Failed to check in participant")
+	}
+
+	h.recordActivity(c, entry.EventID, activityCheckedIn, &participantID, &walletAddress)
+
+	return syncResult{
+		ClientUUID:    entry.ClientUUID,
+		Status:        syncCheckedIn,
+		ParticipantID: participantID,
+		CheckedInAt:   &checkedInAt,
+	}
+}
@@ -0,0 +1,50 @@
+-- The tables that predate the migrations, as described under "Actual SQL Schema" in the
+-- README, with the timestamps the handlers read and without the single-row uniqueness on
+-- participant that production no longer has. Tests create them in an empty database
+-- before applying the migrations. events_metadata.status is an enum in production; text
+-- accepts the same values.
+CREATE TABLE IF NOT EXISTS profiles (
+  id uuid NOT NULL,
+  wallet_address text NOT NULL UNIQUE,
+  name text,
+  email text UNIQUE,
+  created_at timestamptz,
+  updated_at timestamptz,
+  CONSTRAINT profiles_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS events_onchain (
+  event_id bigint NOT NULL,
+  vault_address text NOT NULL UNIQUE,
+  organizer_address text NOT NULL,
+  stake_amount numeric NOT NULL,
+  max_participant bigint NOT NULL,
+  registration_deadline numeric NOT NULL,
+  event_date numeric NOT NULL,
+  CONSTRAINT events_onchain_pkey PRIMARY KEY (event_id)
+);
+
+CREATE TABLE IF NOT EXISTS events_metadata (
+  event_id bigint NOT NULL,
+  title text NOT NULL,
+  description text,
+  image_url text,
+  status text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT events_metadata_pkey PRIMARY KEY (event_id),
+  CONSTRAINT events_metadata_event_id_fkey FOREIGN KEY (event_id) REFERENCES events_onchain(event_id)
+);
+
+CREATE TABLE IF NOT EXISTS participant (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL,
+  user_id uuid NOT NULL,
+  is_attend boolean NOT NULL DEFAULT false,
+  is_claim boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT participant_pkey PRIMARY KEY (id),
+  CONSTRAINT participant_event_id_fkey FOREIGN KEY (event_id) REFERENCES events_onchain(event_id),
+  CONSTRAINT participant_user_id_fkey FOREIGN KEY (user_id) REFERENCES profiles(id)
+);
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"atfi-backend/contracts"
+)
+
+// txRejection maps a verification the handler cannot accept to its status code and the
+// response fields describing it: 202 while pending (retry later), 400 for a reverted
+// transaction and 409 for one that did something else. ok is true once confirmed.
+// required is the confirmation count the transaction was checked against.
+func txRejection(v *contracts.TxVerification, required uint64) (status int, details gin.H, ok bool) {
+	details = gin.H{
+		"tx_state":               v.State,
+		"reason":                 v.Reason,
+		"confirmations":          v.Confirmations,
+		"required_confirmations": required,
+	}
+
+	switch v.State {
+	case contracts.TxConfirmed:
+		return http.StatusOK, details, true
+	case contracts.TxPending:
+		return http.StatusAccepted, details, false
+	case contracts.TxFailed:
+		return http.StatusBadRequest, details, false
+	default:
+		return http.StatusConflict, details, false
+	}
+}
+
+// withTxLink adds the chain ID and the block explorer link of transaction hash to a
+// response, so the frontend can link to it. explorer_url is null on chains without an
+// explorer.
+func withTxLink(body gin.H, hash string, chain contracts.Chain) gin.H {
+	body["chain_id"] = chain.ID
+	if url := chain.TxURL(hash); url != "" {
+		body["explorer_url"] = url
+	} else {
+		body["explorer_url"] = nil
+	}
+	return body
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+)
+
+// UndoCheckIn reverses a mistaken check-in before the event is settled (organizer only).
+// The participant's check-in rows are removed and the reason is written to the audit log
+// and to each removed check-in's history.
+func (h *CheckinHandler) UndoCheckIn(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	participantID := c.Param("participantID")
+
+	var req struct {
+		Reason      string `json:"reason" binding:"required"`
+		DeviceLabel string `json:"device_label" binding:"max=100"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can undo check-ins"})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Lock the participant so a concurrent claim can't slip in between the checks and the update
+	var status, walletAddress string
+	var isAttend, isClaim bool
+	err = tx.QueryRow(c, `
+		SELECT em.status, pr.wallet_address, p.is_attend, p.is_claim
+		FROM participant p
+		JOIN profiles pr ON p.user_id = pr.id
+		JOIN events_metadata em ON em.event_id = p.event_id
+		WHERE p.event_id = $1 AND p.id::text = $2
+		FOR UPDATE OF p
+	`, eventID, participantID).Scan(&status, &walletAddress, &isAttend, &isClaim)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Participant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	switch {
+	case status == models.StatusSettled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Check-ins cannot be undone after the event is settled"})
+		return
+	case isClaim:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant has already claimed their reward"})
+		return
+	case !isAttend:
+		c.JSON(http.StatusConflict, gin.H{"error": "Participant is not checked in"})
+		return
+	}
+
+	var participant struct {
+		ID        string    `json:"id"`
+		EventID   int64     `json:"event_id"`
+		UserID    string    `json:"user_id"`
+		IsAttend  bool      `json:"is_attend"`
+		IsClaim   bool      `json:"is_claim"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	err = tx.QueryRow(c, `
+		UPDATE participant
+		SET is_attend = false, updated_at = now()
+		WHERE event_id = $1 AND id::text = $2
+		RETURNING id, event_id, user_id, is_attend, is_claim, created_at, updated_at
+	`, eventID, participantID).Scan(
+		&participant.ID,
+		&participant.EventID,
+		&participant.UserID,
+		&participant.IsAttend,
+		&participant.IsClaim,
+		&participant.CreatedAt,
+		&participant.UpdatedAt,
+	)
+	if err != nil {
+		slog.ErrorContext(c, "Error undoing check-in", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+
+	rows, err := tx.Query(c, `
+		DELETE FROM checkins
+		WHERE event_id = $1 AND LOWER(user_address) = LOWER($2)
+		RETURNING `+checkinColumns, eventID, walletAddress)
+	if err != nil {
+		slog.ErrorContext(c, "Error removing check-ins", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+	var removed []models.Checkin
+	for rows.Next() {
+		checkin, err := scanCheckin(rows)
+		if err != nil {
+			rows.Close()
+			slog.ErrorContext(c, "Error reading removed check-ins", "participant_id", participantID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+			return
+		}
+		removed = append(removed, checkin)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(c, "Error removing check-ins", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+
+	actor := c.GetString(middleware.UserAddressKey)
+	for _, checkin := range removed {
+		if err := recordCheckinAudit(c, tx, checkin, models.CheckinAuditUndone, actor, req.Reason, req.DeviceLabel, c.ClientIP()); err != nil {
+			slog.ErrorContext(c, "Error auditing undone check-in", "checkin_id", checkin.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+			return
+		}
+	}
+
+	_, err = tx.Exec(c, `
+		UPDATE stakes SET is_attended = false, updated_at = now()
+		WHERE event_id = $1 AND user_id::text = $2
+	`, eventID, participant.UserID)
+	if err != nil {
+		slog.ErrorContext(c, "Error syncing stake attendance", "participant_id", participantID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+
+	err = recordAudit(c, tx, actor, "checkin_undone", &eventID, map[string]interface{}{
+		"participant_id":   participantID,
+		"wallet_address":   walletAddress,
+		"reason":           req.Reason,
+		"checkins_removed": len(removed),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit entry"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+
+	slog.InfoContext(c, "Check-in undone", "event_id", eventID, "participant_id", participantID, "reason", req.Reason)
+	h.recordActivity(c, eventID, activityUndone, &participant.ID, &walletAddress)
+
+	c.JSON(http.StatusOK, gin.H{"participant": participant})
+}
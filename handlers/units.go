@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"atfi-backend/contracts"
+	"atfi-backend/units"
+)
+
+// fallbackToken is assumed for amounts when the chain has no configured tokens and the
+// vault's own token cannot be read
+var fallbackToken = contracts.Token{Symbol: "USDC", Decimals: 6}
+
+// primaryToken returns the chain's primary token, the first of tokens
+func primaryToken(tokens []contracts.Token) contracts.Token {
+	if len(tokens) > 0 {
+		return tokens[0]
+	}
+	return fallbackToken
+}
+
+// vaultToken returns the token to format a vault's amounts with: its stake token when that
+// can be read, otherwise the chain's primary token
+func vaultToken(ctx context.Context, stakeTokens *contracts.StakeTokens, tokens []contracts.Token, vaultAddress *string) contracts.Token {
+	if stakeTokens != nil && vaultAddress != nil && common.IsHexAddress(*vaultAddress) {
+		token, err := stakeTokens.Of(ctx, common.HexToAddress(*vaultAddress))
+		if err == nil {
+			return token
+		}
+		slog.WarnContext(ctx, "Failed to read the stake token", "vault", *vaultAddress, "error", err)
+	}
+	return primaryToken(tokens)
+}
+
+// formatTokenAmount renders a base-unit integer string as a decimal string with the given
+// number of decimals, or "" when raw is not an integer
+func formatTokenAmount(raw string, decimals int) string {
+	amount, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return ""
+	}
+	return units.FormatUnits(amount, decimals)
+}
+
+// formatAmount renders an optional base-unit amount of token, returning nil when raw is nil
+func formatAmount(raw *string, token contracts.Token) *string {
+	if raw == nil {
+		return nil
+	}
+	formatted := formatTokenAmount(*raw, token.Decimals)
+	return &formatted
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import "testing"
+
+func TestFormatTokenAmount(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals int
+		want     string
+	}{
+		{"2500000", 6, "2.5"},
+		{"1000000", 6, "1"},
+		{"1", 6, "0.000001"},
+		{"0", 6, "0"},
+		{"-1500000", 6, "-1.5"},
+		{"2500000000000000000", 18, "2.5"},
+		{"1000000000000000000", 18, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"123456789012345678901234567890", 18, "123456789012.34567890123456789"},
+		{"not a number", 6, ""},
+	}
+	for _, tt := range tests {
+		if got := formatTokenAmount(tt.raw, tt.decimals); got != tt.want {
+			t.Errorf("formatTokenAmount(%q, %d) = %q, want %q", tt.raw, tt.decimals, got, tt.want)
+		}
+	}
+}
@@ -2,33 +2,54 @@ package handlers
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"log"
-	"math/big"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"atfi-backend/cache"
+	"atfi-backend/config"
+	"atfi-backend/contracts"
 	"atfi-backend/models"
+	"atfi-backend/storage"
+	"atfi-backend/units"
 )
 
 type UserHandler struct {
-	db     *pgxpool.Pool
-	client *ethclient.Client
+	db          *pgxpool.Pool
+	client      contracts.Caller
+	names       *contracts.NameResolver // nil when the chain has no name registry
+	store       storage.Store
+	balances    *balanceCache
+	tokens      []contracts.Token      // first entry is the primary token
+	stakeTokens *contracts.StakeTokens // each vault's stake token; nil without a client
+	signatures  *contracts.SignatureVerifier
+	activity    *ActivityTracker
+	cfg         *config.Config
 }
 
-func NewUserHandler(db *pgxpool.Pool, client *ethclient.Client) *UserHandler {
-	return &UserHandler{
-		db:     db,
-		client: client,
+func NewUserHandler(db *pgxpool.Pool, client contracts.Caller, names *contracts.NameResolver, store storage.Store, balances cache.BalanceStore, tokens []contracts.Token, activity *ActivityTracker, cfg *config.Config) *UserHandler {
+	h := &UserHandler{
+		db:         db,
+		client:     client,
+		names:      names,
+		store:      store,
+		tokens:     tokens,
+		signatures: contracts.NewSignatureVerifier(client),
+		activity:   activity,
+		cfg:        cfg,
 	}
+	h.balances = newBalanceCache(balances, h.getPrimaryTokenBalance)
+	if client != nil {
+		h.stakeTokens = contracts.NewStakeTokens(client)
+	}
+	return h
 }
 
 // Profile handlers using profiles table
@@ -39,86 +60,68 @@ func (h *UserHandler) CreateProfile(c *gin.Context) {
 		return
 	}
 
-	// Check if profile already exists
-	var exists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM profiles WHERE wallet_address = $1)", req.WalletAddress).Scan(&exists)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check if profile exists" + err.Error(),})
-		return
-	}
-
-	if exists {
-		c.JSON(http.StatusConflict, gin.H{"error": "Profile already exists"})
-		return
-	}
-
-	// Create profile
-	query := `
-		INSERT INTO profiles (id, wallet_address, name, email)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, wallet_address, name, email
-	`
-	log.Printf("GetProfile called for wallet address: %s", req.Email)
-
-	var profile models.Profile
-	err = h.db.QueryRow(c, query,
-		uuid.New(),
-		req.WalletAddress,
-		req.Name,
-		req.Email,
-	).Scan(
-		&profile.ID,
-		&profile.WalletAddress,
-		&profile.Name,
-		&profile.Email,
-	)
-
+	profile, err := h.createProfile(c, req)
 	if err != nil {
+		if isUniqueViolation(err, "profiles_wallet_address_lower_key") || isUniqueViolation(err, "profiles_wallet_address_key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Profile already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create profile: " + err.Error(),})
 		return
 	}
 
-	profile.Balance = ""
-
 	c.JSON(http.StatusCreated, profile)
 }
 
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	walletAddress := c.Param("walletAddress")
-	log.Printf("GetProfile called for wallet address: %s", walletAddress)
+	slog.DebugContext(c, "GetProfile called", "wallet", walletAddress)
 
+	// Linked wallets resolve to the profile they were linked to
 	var profile models.Profile
+	var deletedAt *time.Time
 	query := `
-		SELECT id, wallet_address, name, email
+		SELECT id, wallet_address, name, email, email_verified_at IS NOT NULL, avatar_url, created_at, updated_at, deleted_at
 		FROM profiles
-		WHERE wallet_address = $1
-	`
+		WHERE id = ` + ownerProfileOf("$1")
 
 	err := h.db.QueryRow(c, query, walletAddress).Scan(
 		&profile.ID,
 		&profile.WalletAddress,
 		&profile.Name,
 		&profile.Email,
+		&profile.EmailVerified,
+		&profile.AvatarURL,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+		&deletedAt,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("Profile not found for wallet: %s", walletAddress)
+		if err == pgx.ErrNoRows {
+			slog.DebugContext(c, "Profile not found", "wallet", walletAddress)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
 		}
-		log.Printf("Database error getting profile for %s: %v", walletAddress, err)
+		slog.ErrorContext(c, "Database error getting profile", "wallet", walletAddress, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
 		return
 	}
 
-	// Get USDC balance from smart contract
+	// 410 rather than 404 so clients can tell a deleted profile from one that never existed
+	if deletedAt != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "Profile has been deleted", "deleted_at": deletedAt})
+		return
+	}
+
+	// Get the primary token (USDC) balance, from the cache unless fresh=true forces a live read
 	balance := "0"
-	if usdcBalance, err := h.getUSDCBalanceFromContract(walletAddress); err == nil {
-		balance = usdcBalance
-		log.Printf("Retrieved USDC balance for %s: %s", walletAddress, balance)
+	var balanceAsOf *time.Time
+	if usdcBalance, err := h.balances.Get(c, walletAddress, c.Query("fresh") == "true"); err == nil {
+		balance = usdcBalance.Value
+		balanceAsOf = &usdcBalance.AsOf
 	} else {
-		log.Printf("Failed to get USDC balance for %s: %v", walletAddress, err)
+		slog.ErrorContext(c, "Failed to get USDC balance", "wallet", walletAddress, "error", err)
 	}
 	profile.Balance = balance
 
@@ -128,12 +131,59 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		"wallet_address": profile.WalletAddress,
 		"name":          profile.Name,
 		"email":         profile.Email,
+		"email_verified": profile.EmailVerified,
+		"avatar_url":    avatarOrIdenticon(profile.AvatarURL, profile.WalletAddress),
 		"balance":       profile.Balance,
+		"balance_as_of": balanceAsOf,
+		"created_at":    profile.CreatedAt,
+		"updated_at":    profile.UpdatedAt,
+		"profile_complete": profile.IsComplete(),
+	}
+
+	linkedWallets, err := getLinkedWallets(c, h.db, profile.ID)
+	if err != nil {
+		slog.ErrorContext(c, "Database error getting linked wallets", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+		return
+	}
+	response["linked_wallets"] = linkedWallets
+
+	// Profiles without a name fall back to the wallet's ENS or Basename
+	ensName := resolveName(c, h.names, profile.WalletAddress, true)
+	response["ens_name"] = nullIfEmpty(ensName)
+	response["display_name"] = displayName(profile.Name, ensName, profile.WalletAddress)
+
+	// Callers that only need the name can skip the aggregate with include_stats=false
+	if c.DefaultQuery("include_stats", "true") != "false" {
+		stats, err := getProfileStats(c, h.db, profile.ID)
+		if err != nil {
+			slog.ErrorContext(c, "Database error getting profile stats", "wallet", walletAddress, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+			return
+		}
+		response["stats"] = stats
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// getProfileStats counts the events a profile registered for, attended and claimed, and
+// the events it organized, across its primary and linked wallets. No history gets zeros.
+func getProfileStats(ctx context.Context, q querier, profileID uuid.UUID) (models.ProfileStats, error) {
+	var stats models.ProfileStats
+	err := q.QueryRow(ctx, `
+		WITH wallets AS `+profileWalletsOf("$1")+`
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE p.is_attend),
+		       COUNT(*) FILTER (WHERE p.is_claim),
+		       (SELECT COUNT(*) FROM events_onchain eo WHERE LOWER(eo.organizer_address) IN (SELECT * FROM wallets))
+		FROM participant p
+		JOIN profiles pr ON pr.id = p.user_id
+		WHERE LOWER(pr.wallet_address) IN (SELECT * FROM wallets)
+	`, profileID).Scan(&stats.Registered, &stats.Attended, &stats.Claimed, &stats.Organized)
+	return stats, err
+}
+
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	walletAddress := c.Param("walletAddress")
 
@@ -143,102 +193,164 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Check if profile exists
-	var exists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM profiles WHERE wallet_address = $1)", walletAddress).Scan(&exists)
+	profile, err := h.updateProfile(c, walletAddress, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpsertProfile creates the wallet's profile or applies a partial update to it in a single
+// statement, so concurrent upserts for the same wallet cannot collide
+func (h *UserHandler) UpsertProfile(c *gin.Context) {
+	var req models.CreateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update profile - allow updating both name and email
-	query := `
-		UPDATE profiles
-		SET name = COALESCE($2, name),
-		    email = COALESCE($3, email)
-		WHERE wallet_address = $1
-		RETURNING id, wallet_address, name, email
-	`
+	profile, created, err := h.upsertProfile(c, req)
+	if err != nil {
+		slog.ErrorContext(c, "Error upserting profile", "wallet", req.WalletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save profile"})
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, profile)
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// profileReturning lists the profile columns in the order scanProfile reads them
+const profileReturning = `id, wallet_address, name, email, email_verified_at IS NOT NULL, avatar_url, created_at, updated_at`
 
+// scanProfile reads a row returned with profileReturning, followed by any extra columns,
+// fills in Complete and falls back to the identicon when there is no avatar. Balance is
+// left empty; the frontend reads it from the contract.
+func scanProfile(row pgx.Row, extra ...interface{}) (models.Profile, error) {
 	var profile models.Profile
-	err = h.db.QueryRow(c, query,
-		walletAddress,
-		nullIfEmpty(req.Name),
-		nullIfEmpty(req.Email),
-	).Scan(
+	dest := []interface{}{
 		&profile.ID,
 		&profile.WalletAddress,
 		&profile.Name,
 		&profile.Email,
-	)
-
+		&profile.EmailVerified,
+		&profile.AvatarURL,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
-		return
+		return profile, err
 	}
-
-	// Balance will be populated from smart contract in frontend
-	profile.Balance = ""
-
-	c.JSON(http.StatusOK, profile)
+	profile.Complete = profile.IsComplete()
+	profile.AvatarURL = avatarOrIdenticon(profile.AvatarURL, profile.WalletAddress)
+	return profile, nil
 }
 
-func (h *UserHandler) UpsertProfile(c *gin.Context) {
-	var req models.CreateProfileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+// createProfile inserts a new profile. A wallet that already has one yields a unique violation.
+func (h *UserHandler) createProfile(ctx context.Context, req models.CreateProfileRequest) (models.Profile, error) {
+	profile, err := scanProfile(h.db.QueryRow(ctx, `
+		INSERT INTO profiles (id, wallet_address, name, email, avatar_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING `+profileReturning,
+		uuid.New(),
+		req.WalletAddress,
+		req.Name,
+		req.Email,
+		nullIfEmpty(req.AvatarURL),
+	))
+	if err != nil {
+		return profile, err
 	}
 
-	// Check if profile already exists
-	var exists bool
-	err := h.db.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM profiles WHERE wallet_address = $1)", req.WalletAddress).Scan(&exists)
+	if req.Email != "" {
+		h.sendEmailVerification(ctx, profile.ID, req.Email)
+	}
+	return profile, nil
+}
+
+// updateProfile applies a partial update; empty fields are left unchanged. pgx.ErrNoRows
+// means the wallet has no profile.
+func (h *UserHandler) updateProfile(ctx context.Context, walletAddress string, req models.UpdateProfileRequest) (models.Profile, error) {
+	var replacedKey, previousEmail *string
+	profile, err := scanProfile(h.db.QueryRow(ctx, `
+		WITH old AS (SELECT avatar_key, email FROM profiles WHERE wallet_address = $1 FOR UPDATE)
+		UPDATE profiles
+		SET name = COALESCE($2, name),
+		    email = COALESCE($3, email),
+		    email_verified_at = CASE WHEN $3::text IS NULL OR $3 = email THEN email_verified_at END,
+		    avatar_url = COALESCE($4, avatar_url),
+		    avatar_key = CASE WHEN $4::text IS NULL THEN avatar_key END,
+		    deleted_at = NULL,
+		    updated_at = NOW()
+		WHERE wallet_address = $1
+		RETURNING `+profileReturning+`, (SELECT avatar_key FROM old), (SELECT email FROM old)
+	`,
+		walletAddress,
+		nullIfEmpty(req.Name),
+		nullIfEmpty(req.Email),
+		nullIfEmpty(req.AvatarURL),
+	), &replacedKey, &previousEmail)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return profile, err
 	}
 
-	if exists {
-		// Update existing profile - allow updating both name and email
-		query := `
-			UPDATE profiles
-			SET name = COALESCE($2, name),
-			    email = COALESCE($3, email)
-			WHERE wallet_address = $1
-			RETURNING id, wallet_address, name, email
-		`
-
-		var profile models.Profile
-		err = h.db.QueryRow(c, query,
-			req.WalletAddress,
-			nullIfEmpty(req.Name),
-			nullIfEmpty(req.Email),
-		).Scan(
-			&profile.ID,
-			&profile.WalletAddress,
-			&profile.Name,
-			&profile.Email,
-		)
+	h.afterProfileSaved(ctx, profile, req.Email, req.AvatarURL, replacedKey, previousEmail)
+	return profile, nil
+}
 
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
-			return
-		}
+// upsertProfile creates or partially updates the wallet's profile in one statement and
+// reports whether it was created
+func (h *UserHandler) upsertProfile(ctx context.Context, req models.CreateProfileRequest) (models.Profile, bool, error) {
+	var created bool
+	var replacedKey, previousEmail *string
+	profile, err := scanProfile(h.db.QueryRow(ctx, `
+		WITH old AS (SELECT avatar_key, email FROM profiles WHERE LOWER(wallet_address) = LOWER($2) FOR UPDATE)
+		INSERT INTO profiles (id, wallet_address, name, email, avatar_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT ((LOWER(wallet_address))) DO UPDATE
+		SET name = COALESCE(EXCLUDED.name, profiles.name),
+		    email = COALESCE(EXCLUDED.email, profiles.email),
+		    email_verified_at = CASE WHEN EXCLUDED.email IS NULL OR EXCLUDED.email = profiles.email
+		                             THEN profiles.email_verified_at END,
+		    avatar_url = COALESCE(EXCLUDED.avatar_url, profiles.avatar_url),
+		    avatar_key = CASE WHEN EXCLUDED.avatar_url IS NULL THEN profiles.avatar_key END,
+		    deleted_at = NULL,
+		    updated_at = NOW()
+		RETURNING `+profileReturning+`, xmax = 0, (SELECT avatar_key FROM old), (SELECT email FROM old)
+	`,
+		uuid.New(),
+		req.WalletAddress,
+		nullIfEmpty(req.Name),
+		nullIfEmpty(req.Email),
+		nullIfEmpty(req.AvatarURL),
+	), &created, &replacedKey, &previousEmail)
+	if err != nil {
+		return profile, false, err
+	}
 
-		// Balance will be populated from smart contract in frontend
-		profile.Balance = ""
+	h.afterProfileSaved(ctx, profile, req.Email, req.AvatarURL, replacedKey, previousEmail)
+	return profile, created, nil
+}
 
-		c.JSON(http.StatusOK, profile)
-		return
+// afterProfileSaved deletes an uploaded avatar replaced by an avatar URL and starts
+// verification when the email changed
+func (h *UserHandler) afterProfileSaved(ctx context.Context, profile models.Profile, email, avatarURL string, replacedKey, previousEmail *string) {
+	if avatarURL != "" {
+		h.deleteAvatarFile(ctx, replacedKey)
+	}
+	if email != "" && email != derefString(previousEmail) {
+		h.sendEmailVerification(ctx, profile.ID, email)
 	}
-
-	// Create new profile
-	h.CreateProfile(c)
 }
 
 func nullIfEmpty(s string) interface{} {
@@ -248,65 +360,164 @@ func nullIfEmpty(s string) interface{} {
 	return s
 }
 
-// Helper function to get USDC balance from smart contract
-func (h *UserHandler) getUSDCBalanceFromContract(walletAddress string) (string, error) {
+// getPrimaryTokenBalance reads the wallet's balance of the chain's primary token (USDC)
+// as a decimal string
+func (h *UserHandler) getPrimaryTokenBalance(ctx context.Context, walletAddress string) (string, error) {
 	if h.client == nil {
 		return "0", fmt.Errorf("ethereum client not initialized")
 	}
+	if len(h.tokens) == 0 {
+		return "0", fmt.Errorf("no tokens configured for this chain")
+	}
+	if !common.IsHexAddress(walletAddress) {
+		return "0", fmt.Errorf("invalid wallet address: %s", walletAddress)
+	}
 
-	// USDC contract address on Base Sepolia
-	usdcAddress := "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
-	log.Printf("Getting USDC balance for wallet %s from contract %s", walletAddress, usdcAddress)
+	balances, err := contracts.BalancesOf(ctx, h.client, common.HexToAddress(walletAddress), h.tokens[:1])
+	if err != nil {
+		return "0", err
+	}
+	primary := balances[0]
+	if primary.Err != nil {
+		return "0", fmt.Errorf("%s balance: %w", primary.Token.Symbol, primary.Err)
+	}
 
-	// ERC20 balanceOf function ABI
-	erc20ABI := `[{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	return units.FormatUnits(primary.Raw, primary.Token.Decimals), nil
+}
 
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+// ensureProfile returns the profile ID for a wallet, creating a bare profile when none exists.
+// Safe under concurrent calls for the same wallet.
+func ensureProfile(ctx context.Context, q querier, walletAddress string) (string, error) {
+	insertProfileQuery := `
+		INSERT INTO profiles (id, wallet_address, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT ((LOWER(wallet_address))) DO NOTHING
+		RETURNING id
+	`
+
+	var userID string
+	now := time.Now()
+	err := q.QueryRow(ctx, insertProfileQuery, uuid.New(), walletAddress, now, now).Scan(&userID)
+	if err == nil {
+		slog.InfoContext(ctx, "Created new profile", "wallet", walletAddress, "profile_id", userID)
+		return userID, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	// The profile already existed (or a concurrent request just created it)
+	err = q.QueryRow(ctx, "SELECT id FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", walletAddress).Scan(&userID)
 	if err != nil {
-		return "0", fmt.Errorf("failed to parse USDC ABI: %w", err)
+		return "", fmt.Errorf("failed to look up profile: %w", err)
 	}
 
-	// Validate and pack the function call with wallet address
+	return userID, nil
+}
+
+// RefreshENS re-resolves a wallet's ENS or Basename, bypassing the cache
+func (h *UserHandler) RefreshENS(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
 	if !common.IsHexAddress(walletAddress) {
-		return "0", fmt.Errorf("invalid wallet address: %s", walletAddress)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+	if h.names == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Name resolution is not available on this chain"})
+		return
 	}
 
-	callData, err := parsedABI.Pack("balanceOf", common.HexToAddress(walletAddress))
+	ensName, err := h.names.Refresh(c, common.HexToAddress(walletAddress))
 	if err != nil {
-		return "0", fmt.Errorf("failed to pack balanceOf call data: %w", err)
+		slog.ErrorContext(c, "Failed to refresh name", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Name lookup failed", "details": err.Error()})
+		return
 	}
 
-	log.Printf("Calling USDC contract with data length: %d", len(callData))
-
-	// Call the USDC smart contract
-	toAddress := common.HexToAddress(usdcAddress)
-	result, err := h.client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   &toAddress,
-		Data: callData,
-	}, nil)
-	if err != nil {
-		return "0", fmt.Errorf("failed to call balanceOf: %w", err)
+	var profileName *string
+	err = h.db.QueryRow(c, "SELECT name FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", walletAddress).Scan(&profileName)
+	if err != nil && err != pgx.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
 	}
 
-	log.Printf("Contract call returned result length: %d", len(result))
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address": walletAddress,
+		"ens_name":       nullIfEmpty(ensName),
+		"display_name":   displayName(profileName, ensName, walletAddress),
+	})
+}
+
+// Most addresses BatchGetProfiles resolves in one request
+const maxProfileBatch = 200
 
-	if len(result) == 0 {
-		return "0", fmt.Errorf("empty result from contract call")
+// BatchGetProfiles resolves many wallets to their public profile fields in one query,
+// without balance lookups. Every requested address appears in the result, keyed by its
+// lowercase form, with exists=false when there is no profile.
+func (h *UserHandler) BatchGetProfiles(c *gin.Context) {
+	var req struct {
+		Addresses []string `json:"addresses" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Addresses) > maxProfileBatch {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("At most %d addresses per batch", maxProfileBatch)})
+		return
+	}
+
+	wallets := make([]string, 0, len(req.Addresses))
+	profiles := make(map[string]gin.H, len(req.Addresses))
+	for _, address := range req.Addresses {
+		if !common.IsHexAddress(address) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address", "details": address})
+			return
+		}
+		wallet := strings.ToLower(address)
+		if _, seen := profiles[wallet]; seen {
+			continue
+		}
+		wallets = append(wallets, wallet)
+		profiles[wallet] = gin.H{"exists": false, "name": nil, "avatar_url": identiconURL(wallet)}
 	}
 
-	// Unpack the result
-	var balance *big.Int
-	err = parsedABI.UnpackIntoInterface(&balance, "balanceOf", result)
+	// Linked wallets report the profile they were linked to
+	rows, err := h.db.Query(c, `
+		SELECT w.wallet, pr.name, pr.avatar_url
+		FROM unnest($1::text[]) AS w(wallet)
+		JOIN profiles pr ON pr.id = `+ownerProfileOf("w.wallet")+`
+	`, wallets)
 	if err != nil {
-		return "0", fmt.Errorf("failed to unpack balanceOf result: %w", err)
+		slog.ErrorContext(c, "Database error resolving profile batch", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
 	}
+	defer rows.Close()
 
-	log.Printf("Raw balance: %s", balance.String())
+	for rows.Next() {
+		var wallet string
+		var name, avatarURL *string
+		if err := rows.Scan(&wallet, &name, &avatarURL); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		profiles[wallet] = gin.H{"exists": true, "name": name, "avatar_url": avatarOrIdenticon(avatarURL, wallet)}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
-	// Convert from wei (6 decimals for USDC) to regular USDC
-	balanceUSDC := new(big.Float).SetInt(balance)
-	balanceUSDC.Quo(balanceUSDC, big.NewFloat(1000000)) // USDC has 6 decimals
+	// Only unnamed wallets need the cached ENS name
+	for wallet, profile := range profiles {
+		name, _ := profile["name"].(*string)
+		resolved := ""
+		if name == nil || *name == "" {
+			resolved = resolveName(c, h.names, wallet, false)
+		}
+		profile["display_name"] = displayName(name, resolved, wallet)
+	}
 
-	log.Printf("USDC balance for %s: %s", walletAddress, balanceUSDC.String())
-	return balanceUSDC.String(), nil
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
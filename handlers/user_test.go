@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpsertProfileRejections(t *testing.T) {
+	router := newTestRouter(caller{})
+	router.POST("/profiles/upsert", NewUserHandler(nil, nil, nil, nil, nil, nil, nil, testConfig()).UpsertProfile)
+
+	wallet := newTestWallet().Hex()
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"no wallet", map[string]any{"name": "Ada"}},
+		{"no name", map[string]any{"wallet_address": wallet}},
+		{"avatar not a URL", map[string]any{"wallet_address": wallet, "name": "Ada", "avatar_url": "avatar.png"}},
+	}
+	for _, tt := range tests {
+		if w := serveJSON(router, http.MethodPost, "/profiles/upsert", tt.body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", tt.name, w.Code)
+		}
+	}
+}
+
+func TestUpsertProfile(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	wallet := newTestWallet()
+	email := strings.ToLower(wallet.Hex()) + "@example.com"
+	router := newTestRouter(caller{})
+	router.POST("/profiles/upsert", NewUserHandler(db, nil, nil, nil, nil, nil, nil, testConfig()).UpsertProfile)
+
+	upsert := func(body map[string]any) (int, map[string]any) {
+		w := serveJSON(router, http.MethodPost, "/profiles/upsert", body)
+		return w.Code, decodeBody(t, w)
+	}
+	outbox := func() int {
+		var n int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM email_outbox WHERE recipient = $1", email).Scan(&n); err != nil {
+			t.Fatalf("count verification emails: %v", err)
+		}
+		return n
+	}
+
+	status, created := upsert(map[string]any{"wallet_address": wallet.Hex(), "name": "Ada", "email": email})
+	if status != http.StatusCreated || created["name"] != "Ada" || created["email"] != email {
+		t.Fatalf("create: status = %d, profile = %v; want 201 with the name and email", status, created)
+	}
+	if outbox() != 1 {
+		t.Errorf("create queued %d verification emails, want 1", outbox())
+	}
+	if _, err := db.Exec(ctx, "UPDATE profiles SET email_verified_at = NOW() WHERE id = $1", created["id"]); err != nil {
+		t.Fatalf("verify email: %v", err)
+	}
+
+	// Another spelling of the wallet updates the same profile; an omitted email is kept
+	// along with its verification, and no new verification mail goes out
+	status, updated := upsert(map[string]any{"wallet_address": strings.ToLower(wallet.Hex()), "name": "Ada L."})
+	if status != http.StatusOK || updated["id"] != created["id"] {
+		t.Fatalf("update: status = %d, profile = %v; want 200 for the same profile", status, updated)
+	}
+	if updated["name"] != "Ada L." || updated["email"] != email || updated["email_verified"] != true {
+		t.Errorf("update: profile = %v, want the new name and the verified email kept", updated)
+	}
+	if outbox() != 1 {
+		t.Errorf("name change queued %d verification emails, want still 1", outbox())
+	}
+
+	// Changing the email drops the verification and mails the new address
+	email = "new." + email
+	status, updated = upsert(map[string]any{"wallet_address": wallet.Hex(), "name": "Ada L.", "email": email})
+	if status != http.StatusOK || updated["email"] != email || updated["email_verified"] != false {
+		t.Errorf("email change: status = %d, profile = %v; want 200 with the new email unverified", status, updated)
+	}
+	if outbox() != 1 {
+		t.Errorf("email change queued %d verification emails to the new address, want 1", outbox())
+	}
+
+	// Upserting a deleted profile restores it
+	if _, err := db.Exec(ctx, "UPDATE profiles SET deleted_at = NOW() WHERE id = $1", created["id"]); err != nil {
+		t.Fatalf("delete profile: %v", err)
+	}
+	if status, _ := upsert(map[string]any{"wallet_address": wallet.Hex(), "name": "Ada"}); status != http.StatusOK {
+		t.Errorf("restore: status = %d, want 200", status)
+	}
+	var deleted bool
+	if err := db.QueryRow(ctx, "SELECT deleted_at IS NOT NULL FROM profiles WHERE id = $1", created["id"]).Scan(&deleted); err != nil || deleted {
+		t.Errorf("deleted = %v, %v after upsert; want restored", deleted, err)
+	}
+}
+
+func TestUpsertProfileConcurrent(t *testing.T) {
+	db := testDB(t)
+	wallet := newTestWallet()
+	router := newTestRouter(caller{})
+	router.POST("/profiles/upsert", NewUserHandler(db, nil, nil, nil, nil, nil, nil, testConfig()).UpsertProfile)
+
+	const attempts = 20
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := serveJSON(router, http.MethodPost, "/profiles/upsert", map[string]any{
+				"wallet_address": wallet.Hex(),
+				"name":           "Ada",
+			})
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusOK:
+		default:
+			t.Errorf("attempt %d: status = %d, want 201 or 200", i, code)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d upserts created the profile, want 1", created)
+	}
+
+	var profiles int
+	if err := db.QueryRow(context.Background(), "SELECT COUNT(*) FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", wallet.Hex()).Scan(&profiles); err != nil {
+		t.Fatalf("count profiles: %v", err)
+	}
+	if profiles != 1 {
+		t.Errorf("%d profiles for the wallet, want 1", profiles)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"atfi-backend/contracts"
+)
+
+// vaultRecheckInterval is how long a vault the factory did not vouch for is trusted to
+// stay that way before it is asked again
+const vaultRecheckInterval = 5 * time.Minute
+
+// vaultVerifier checks events_onchain vault addresses against the factory the first time
+// they are read. Verified vaults are recorded in vault_verified and never checked again;
+// rejections are remembered for vaultRecheckInterval.
+type vaultVerifier struct {
+	factory *contracts.FactoryContract // nil when no factory is configured
+
+	mu       sync.Mutex
+	rejected map[int64]time.Time
+}
+
+func newVaultVerifier(factory *contracts.FactoryContract) *vaultVerifier {
+	return &vaultVerifier{factory: factory, rejected: map[int64]time.Time{}}
+}
+
+// trusted reports whether vaultAddress may be read as eventID's vault. Without a factory
+// there is nothing to check against, so every vault is trusted.
+func (v *vaultVerifier) trusted(ctx context.Context, q querier, eventID int64, vaultAddress string) bool {
+	if v.factory == nil {
+		return true
+	}
+	if !common.IsHexAddress(vaultAddress) {
+		return false
+	}
+
+	var verified bool
+	err := q.QueryRow(ctx, "SELECT vault_verified FROM events_onchain WHERE event_id = $1", eventID).Scan(&verified)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load vault_verified", "event_id", eventID, "error", err)
+		return false
+	}
+	if verified {
+		return true
+	}
+
+	v.mu.Lock()
+	rejectedAt, ok := v.rejected[eventID]
+	v.mu.Unlock()
+	if ok && time.Since(rejectedAt) < vaultRecheckInterval {
+		return false
+	}
+
+	vault := common.HexToAddress(vaultAddress)
+	verified, err = v.factory.VerifyVault(ctx, eventID, vault)
+	if err != nil {
+		// Not cached: the next read asks again
+		slog.ErrorContext(ctx, "Failed to verify the vault against the factory", "event_id", eventID, "error", err)
+		return false
+	}
+	if !verified {
+		slog.WarnContext(ctx, "Vault was not deployed by the factory", "vault", vault.Hex(), "event_id", eventID, "factory", v.factory.Address().Hex())
+		v.mu.Lock()
+		v.rejected[eventID] = time.Now()
+		v.mu.Unlock()
+		return false
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE events_onchain SET vault_verified = true
+		WHERE event_id = $1 AND LOWER(vault_address) = LOWER($2)
+	`, eventID, vault.Hex())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record the verified vault", "event_id", eventID, "error", err)
+	}
+	return true
+}
+
+// vaultTrusted reports whether the event's vault may be read
+func (h *EventHandler) vaultTrusted(c *gin.Context, eventID int64, vaultAddress string) bool {
+	return h.vaults.trusted(c, h.db, eventID, vaultAddress)
+}
+
+// requireTrustedVault answers 409 vault_unverified and returns false unless the event's
+// vault may be acted on. Settlement tooling refuses unverified vaults outright.
+func (h *EventHandler) requireTrustedVault(c *gin.Context, eventID int64, vaultAddress string) bool {
+	if h.vaultTrusted(c, eventID, vaultAddress) {
+		return true
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "vault_unverified",
+		"message": "The event's vault address could not be verified against the event factory",
+	})
+	return false
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"atfi-backend/mailer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// emailVerificationTTL is how long an emailed verification link stays valid
+const emailVerificationTTL = 24 * time.Hour
+
+// Verification failure codes returned by VerifyEmail
+const (
+	verifyCodeInvalid = "invalid_token"
+	verifyCodeExpired = "token_expired"
+	verifyCodeUsed    = "token_used"
+	verifyCodeStale   = "email_changed"
+)
+
+// hashVerificationToken is the form tokens are stored and looked up in
+func hashVerificationToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// verifyEmailURL links to the page of the frontend at appBaseURL that submits the token
+func verifyEmailURL(appBaseURL, token string) string {
+	return strings.TrimRight(appBaseURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+}
+
+// startEmailVerification issues a token for the profile's email and queues the mail
+// carrying it, linking into the frontend at appBaseURL. Earlier unused tokens for the
+// profile stop working. Call it inside the transaction that set the email.
+func startEmailVerification(ctx context.Context, q querier, appBaseURL string, profileID uuid.UUID, email string) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	_, err := q.Exec(ctx, "UPDATE email_verifications SET used_at = NOW() WHERE profile_id = $1 AND used_at IS NULL", profileID)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO email_verifications (token_hash, profile_id, email, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, hashVerificationToken(token), profileID, email, time.Now().Add(emailVerificationTTL))
+	if err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	link := verifyEmailURL(appBaseURL, token)
+	return enqueueEmail(ctx, q, mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address",
+		Text:    fmt.Sprintf("Confirm this address for your ATFi profile:\n\n%s\n\nThe link expires in 24 hours.\n", link),
+		HTML: fmt.Sprintf(
+			"<p>Confirm this address for your ATFi profile.</p><p><a href=\"%s\">Confirm email</a></p><p>The link expires in 24 hours.</p>",
+			html.EscapeString(link),
+		),
+	})
+}
+
+// sendEmailVerification runs startEmailVerification in its own transaction. The profile
+// change is already saved, so failures are logged and the owner can request a new mail.
+func (h *UserHandler) sendEmailVerification(ctx context.Context, profileID uuid.UUID, email string) {
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to start email verification", "profile_id", profileID, "error", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := startEmailVerification(ctx, tx, h.cfg.AppBaseURL, profileID, email); err != nil {
+		slog.ErrorContext(ctx, "Failed to start email verification", "profile_id", profileID, "error", err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to start email verification", "profile_id", profileID, "error", err)
+	}
+}
+
+// ResendEmailVerification mails a new verification link for the profile's current email,
+// invalidating earlier links
+func (h *UserHandler) ResendEmailVerification(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var profileID uuid.UUID
+	var email *string
+	var verifiedAt *time.Time
+	err = tx.QueryRow(c, `
+		SELECT id, email, email_verified_at
+		FROM profiles
+		WHERE LOWER(wallet_address) = LOWER($1) AND deleted_at IS NULL
+		FOR UPDATE
+	`, walletAddress).Scan(&profileID, &email, &verifiedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if email == nil || *email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile has no email address"})
+		return
+	}
+	if verifiedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := startEmailVerification(c, tx, h.cfg.AppBaseURL, profileID, *email); err != nil {
+		slog.ErrorContext(c, "Error resending email verification", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// VerifyEmail confirms the address a token was issued for. Each token works once, within
+// 24 hours, and only while the profile still has that address.
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required", "code": verifyCodeInvalid})
+		return
+	}
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var profileID uuid.UUID
+	var email string
+	var expiresAt time.Time
+	var usedAt *time.Time
+	err = tx.QueryRow(c, `
+		SELECT profile_id, email, expires_at, used_at
+		FROM email_verifications
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, hashVerificationToken(token)).Scan(&profileID, &email, &expiresAt, &usedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification token", "code": verifyCodeInvalid})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if usedAt != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "Verification token has already been used or replaced", "code": verifyCodeUsed})
+		return
+	}
+	if time.Now().After(expiresAt) {
+		c.JSON(http.StatusGone, gin.H{"error": "Verification token has expired", "code": verifyCodeExpired})
+		return
+	}
+
+	var walletAddress string
+	err = tx.QueryRow(c, `
+		UPDATE profiles SET email_verified_at = NOW()
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+		RETURNING wallet_address
+	`, profileID, email).Scan(&walletAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "The profile's email has changed since this link was sent", "code": verifyCodeStale})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if _, err := tx.Exec(c, "UPDATE email_verifications SET used_at = NOW() WHERE token_hash = $1", hashVerificationToken(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	slog.InfoContext(c, "Verified email", "profile_id", profileID)
+	c.JSON(http.StatusOK, gin.H{"wallet_address": walletAddress, "email": email, "email_verified": true})
+}
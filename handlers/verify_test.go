@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestVerifyEmailURL(t *testing.T) {
+	tests := []struct {
+		base, token, want string
+	}{
+		{"https://atfi.app", "abc123", "https://atfi.app/verify-email?token=abc123"},
+		{"https://atfi.app/", "abc123", "https://atfi.app/verify-email?token=abc123"},
+		{"https://atfi.app", "a b&c", "https://atfi.app/verify-email?token=a+b%26c"},
+	}
+	for _, tt := range tests {
+		if got := verifyEmailURL(tt.base, tt.token); got != tt.want {
+			t.Errorf("verifyEmailURL(%q, %q) = %q, want %q", tt.base, tt.token, got, tt.want)
+		}
+	}
+
+	if string(hashVerificationToken("abc")) != string(hashVerificationToken("abc")) ||
+		string(hashVerificationToken("abc")) == string(hashVerificationToken("abd")) {
+		t.Error("hashVerificationToken is not a stable per-token hash")
+	}
+}
+
+func TestEmailVerificationRejections(t *testing.T) {
+	h := NewUserHandler(nil, nil, nil, nil, nil, nil, nil, testConfig())
+	wallet := newTestWallet()
+
+	router := newTestRouter(caller{wallet: wallet})
+	router.GET("/profiles/verify-email", h.VerifyEmail)
+	w := serveJSON(router, http.MethodGet, "/profiles/verify-email", nil)
+	if w.Code != http.StatusBadRequest || decodeBody(t, w)["code"] != verifyCodeInvalid {
+		t.Errorf("missing token: status = %d (%s), want 400 %s", w.Code, w.Body, verifyCodeInvalid)
+	}
+
+	tests := []struct {
+		name   string
+		wallet string
+		want   int
+	}{
+		{"not a wallet", "alice", http.StatusBadRequest},
+		{"another wallet", newTestWallet().Hex(), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(caller{wallet: wallet})
+		router.POST("/profiles/:walletAddress/verify-email", h.ResendEmailVerification)
+		if w := serveJSON(router, http.MethodPost, "/profiles/"+tt.wallet+"/verify-email", nil); w.Code != tt.want {
+			t.Errorf("resend, %s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+// verificationToken returns the token in the latest verification email queued for email
+func verificationToken(t *testing.T, db *pgxpool.Pool, email string) string {
+	t.Helper()
+	var body string
+	err := db.QueryRow(context.Background(), `
+		SELECT text_body FROM email_outbox WHERE recipient = $1 ORDER BY id DESC LIMIT 1
+	`, email).Scan(&body)
+	if err != nil {
+		t.Fatalf("load verification email: %v", err)
+	}
+	match := regexp.MustCompile(`token=([0-9a-f]{64})`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("no token in verification email %q", body)
+	}
+	return match[1]
+}
+
+// seedEmail sets the email of wallet's profile and returns it
+func seedEmail(t *testing.T, db *pgxpool.Pool, wallet common.Address) string {
+	t.Helper()
+	email := strings.ToLower(wallet.Hex()) + "@example.com"
+	_, err := db.Exec(context.Background(), "UPDATE profiles SET email = $1 WHERE LOWER(wallet_address) = LOWER($2)", email, wallet.Hex())
+	if err != nil {
+		t.Fatalf("seed email: %v", err)
+	}
+	return email
+}
+
+func TestEmailVerification(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	h := NewUserHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	wallet, withoutEmail := newTestWallet(), newTestWallet()
+	seedProfile(t, db, wallet, "")
+	seedProfile(t, db, withoutEmail, "")
+	email := seedEmail(t, db, wallet)
+
+	resend := func(who common.Address) int {
+		router := newTestRouter(caller{wallet: who})
+		router.POST("/profiles/:walletAddress/verify-email", h.ResendEmailVerification)
+		return serveJSON(router, http.MethodPost, "/profiles/"+who.Hex()+"/verify-email", nil).Code
+	}
+	verify := func(token string) (int, map[string]any) {
+		router := newTestRouter(caller{})
+		router.GET("/profiles/verify-email", h.VerifyEmail)
+		w := serveJSON(router, http.MethodGet, "/profiles/verify-email?token="+token, nil)
+		return w.Code, decodeBody(t, w)
+	}
+
+	if got := resend(newTestWallet()); got != http.StatusNotFound {
+		t.Errorf("resend without a profile: status = %d, want 404", got)
+	}
+	if got := resend(withoutEmail); got != http.StatusBadRequest {
+		t.Errorf("resend without an email: status = %d, want 400", got)
+	}
+
+	if got := resend(wallet); got != http.StatusAccepted {
+		t.Fatalf("resend: status = %d, want 202", got)
+	}
+	replaced := verificationToken(t, db, email)
+	if got := resend(wallet); got != http.StatusAccepted {
+		t.Fatalf("second resend: status = %d, want 202", got)
+	}
+	token := verificationToken(t, db, email)
+
+	tests := []struct {
+		name     string
+		token    string
+		want     int
+		wantCode string
+	}{
+		{"unknown token", strings.Repeat("0", 64), http.StatusBadRequest, verifyCodeInvalid},
+		{"replaced token", replaced, http.StatusGone, verifyCodeUsed},
+		{"current token", token, http.StatusOK, ""},
+		{"current token again", token, http.StatusGone, verifyCodeUsed},
+	}
+	for _, tt := range tests {
+		status, body := verify(tt.token)
+		if status != tt.want || (tt.wantCode != "" && body["code"] != tt.wantCode) {
+			t.Errorf("%s: status = %d, code = %v; want %d %s", tt.name, status, body["code"], tt.want, tt.wantCode)
+		}
+	}
+
+	var verified bool
+	err := db.QueryRow(ctx, "SELECT email_verified_at IS NOT NULL FROM profiles WHERE LOWER(wallet_address) = LOWER($1)", wallet.Hex()).Scan(&verified)
+	if err != nil || !verified {
+		t.Errorf("email verified = %v, %v; want verified", verified, err)
+	}
+	if got := resend(wallet); got != http.StatusConflict {
+		t.Errorf("resend once verified: status = %d, want 409", got)
+	}
+}
+
+func TestVerifyEmailStaleAndExpired(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	h := NewUserHandler(db, nil, nil, nil, nil, nil, nil, testConfig())
+	changed, late := newTestWallet(), newTestWallet()
+
+	tokens := map[common.Address]string{}
+	for _, wallet := range []common.Address{changed, late} {
+		profileID := seedProfile(t, db, wallet, "")
+		email := seedEmail(t, db, wallet)
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		if err := startEmailVerification(ctx, tx, "", uuid.MustParse(profileID), email); err != nil {
+			t.Fatalf("startEmailVerification: %v", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+		tokens[wallet] = verificationToken(t, db, email)
+	}
+
+	if _, err := db.Exec(ctx, "UPDATE profiles SET email = 'new.' || email WHERE LOWER(wallet_address) = LOWER($1)", changed.Hex()); err != nil {
+		t.Fatalf("change email: %v", err)
+	}
+	if _, err := db.Exec(ctx, "UPDATE email_verifications SET expires_at = NOW() - interval '1 minute' WHERE token_hash = $1", hashVerificationToken(tokens[late])); err != nil {
+		t.Fatalf("expire token: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		want     int
+		wantCode string
+	}{
+		{"email changed", tokens[changed], http.StatusConflict, verifyCodeStale},
+		{"expired", tokens[late], http.StatusGone, verifyCodeExpired},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(caller{})
+		router.GET("/profiles/verify-email", h.VerifyEmail)
+		w := serveJSON(router, http.MethodGet, "/profiles/verify-email?token="+tt.token, nil)
+		if body := decodeBody(t, w); w.Code != tt.want || body["code"] != tt.wantCode {
+			t.Errorf("%s: status = %d, code = %v; want %d %s", tt.name, w.Code, body["code"], tt.want, tt.wantCode)
+		}
+	}
+}
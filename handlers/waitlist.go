@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"atfi-backend/middleware"
+	"atfi-backend/models"
+	"atfi-backend/reputation"
+)
+
+// How long a promoted waitlist entry holds its registration slot
+const waitlistPromotionWindow = 24 * time.Hour
+
+// JoinWaitlist queues the authenticated wallet for an event
+func (h *EventHandler) JoinWaitlist(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	userAddress := c.GetString(middleware.UserAddressKey)
+
+	// Already registered wallets have no reason to queue
+	var registered bool
+	err = h.db.QueryRow(c, `
+		SELECT EXISTS(
+			SELECT 1 FROM participant p
+			JOIN profiles pr ON p.user_id = pr.id
+			WHERE p.event_id = $1 AND LOWER(pr.wallet_address) = LOWER($2)
+		)
+	`, eventID, userAddress).Scan(&registered)
+	if err != nil {
+		slog.ErrorContext(c, "Error checking registration before joining waitlist", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if registered {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already registered for this event"})
+		return
+	}
+
+	var entry models.WaitlistEntry
+	err = h.db.QueryRow(c, `
+		INSERT INTO waitlist (event_id, wallet_address, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING id, event_id, wallet_address, status, created_at
+	`, eventID, userAddress, models.WaitlistWaiting).Scan(
+		&entry.ID,
+		&entry.EventID,
+		&entry.WalletAddress,
+		&entry.Status,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Already on the waitlist for this event"})
+			return
+		}
+		if strings.Contains(err.Error(), "waitlist_event_id_fkey") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Error joining waitlist", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		return
+	}
+
+	err = h.db.QueryRow(c, `
+		SELECT COUNT(*) FROM waitlist
+		WHERE event_id = $1 AND status = $2 AND created_at <= $3
+	`, eventID, models.WaitlistWaiting, entry.CreatedAt).Scan(&entry.Position)
+	if err != nil {
+		slog.ErrorContext(c, "Error computing waitlist position", "event_id", eventID, "error", err)
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetWaitlist lists the waitlist for an event in queue order (organizer view)
+func (h *EventHandler) GetWaitlist(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	organizer, err := getEventOrganizer(c, h.db, eventID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !isOrganizerOrAdmin(c, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event organizer can view the waitlist"})
+		return
+	}
+
+	rows, err := h.db.Query(c, `
+		SELECT w.id, w.event_id, w.wallet_address, w.status, w.created_at, w.promoted_at, w.promotion_expires_at,
+		       pr.reputation_score
+		FROM waitlist w
+		LEFT JOIN profiles pr ON LOWER(pr.wallet_address) = LOWER(w.wallet_address)
+		WHERE w.event_id = $1
+		ORDER BY w.created_at ASC
+	`, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Error loading waitlist", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.WaitlistEntry{}
+	position := 0
+	for rows.Next() {
+		var entry models.WaitlistEntry
+		var reputationScore *float64
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EventID,
+			&entry.WalletAddress,
+			&entry.Status,
+			&entry.CreatedAt,
+			&entry.PromotedAt,
+			&entry.PromotionExpiresAt,
+			&reputationScore,
+		)
+		if err != nil {
+			slog.ErrorContext(c, "Error scanning waitlist row", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan waitlist entry"})
+			return
+		}
+
+		entry.ReputationTier = reputation.TierFor(reputationScore)
+
+		if entry.Status == models.WaitlistWaiting {
+			position++
+			entry.Position = position
+		}
+		entries = append(entries, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"waitlist": entries,
+		"waiting":  position,
+	})
+}
+
+// promoteNextWaitlisted gives the earliest waiting wallet a time-limited registration slot.
+// Returns the promoted wallet, or "" when nobody is waiting.
+func promoteNextWaitlisted(ctx context.Context, q querier, eventID int64) (string, error) {
+	now := time.Now()
+
+	var walletAddress string
+	err := q.QueryRow(ctx, `
+		UPDATE waitlist
+		SET status = $2, promoted_at = $3, promotion_expires_at = $4
+		WHERE id = (
+			SELECT id FROM waitlist
+			WHERE event_id = $1 AND status = $5
+			ORDER BY created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING wallet_address
+	`, eventID, models.WaitlistPromoted, now, now.Add(waitlistPromotionWindow), models.WaitlistWaiting).Scan(&walletAddress)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to promote waitlist entry: %w", err)
+	}
+
+	// TODO: notify the promoted wallet once notifications are available
+	slog.InfoContext(ctx, "Promoted waitlisted wallet", "wallet", walletAddress, "event_id", eventID)
+	return walletAddress, nil
+}
+
+// checkWaitlistSlot decides whether walletAddress may register given the event's waitlist.
+// Registration is open to anyone while nobody is queued; otherwise only the holder of an
+// unexpired promotion may register. Expired promotions hand their slot to the next in line.
+func checkWaitlistSlot(ctx context.Context, q querier, eventID int64, walletAddress string) (bool, error) {
+	expired, err := q.Exec(ctx, `
+		UPDATE waitlist SET status = $2
+		WHERE event_id = $1 AND status = $3 AND promotion_expires_at < $4
+	`, eventID, models.WaitlistExpired, models.WaitlistPromoted, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to expire waitlist promotions: %w", err)
+	}
+
+	for i := int64(0); i < expired.RowsAffected(); i++ {
+		if _, err := promoteNextWaitlisted(ctx, q, eventID); err != nil {
+			return false, err
+		}
+	}
+
+	var queued, holdsSlot bool
+	err = q.QueryRow(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM waitlist WHERE event_id = $1 AND status IN ($3, $4)),
+			EXISTS(SELECT 1 FROM waitlist WHERE event_id = $1 AND status = $4 AND LOWER(wallet_address) = LOWER($2))
+	`, eventID, walletAddress, models.WaitlistWaiting, models.WaitlistPromoted).Scan(&queued, &holdsSlot)
+	if err != nil {
+		return false, fmt.Errorf("failed to check waitlist: %w", err)
+	}
+
+	return !queued || holdsSlot, nil
+}
+
+// markWaitlistRegistered closes out a promoted entry once its holder registers
+func markWaitlistRegistered(ctx context.Context, q querier, eventID int64, walletAddress string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE waitlist SET status = $3
+		WHERE event_id = $1 AND LOWER(wallet_address) = LOWER($2) AND status = $4
+	`, eventID, walletAddress, models.WaitlistRegistered, models.WaitlistPromoted)
+	return err
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"atfi-backend/contracts"
+	"atfi-backend/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Wallet link signatures older (or further in the future) than this are rejected
+const walletLinkMaxSkew = 5 * time.Minute
+
+// ownerProfileOf is the ID of the profile a wallet belongs to: the profile it is linked
+// to, else its own profile. param is the placeholder holding the wallet.
+func ownerProfileOf(param string) string {
+	return `COALESCE(
+		(SELECT lpw.profile_id FROM profile_wallets lpw WHERE lpw.wallet_address = LOWER(` + param + `)),
+		(SELECT lpr.id FROM profiles lpr WHERE LOWER(lpr.wallet_address) = LOWER(` + param + `))
+	)`
+}
+
+// profileWalletsOf selects the lowercase primary and linked wallets of the profile whose
+// ID is in param
+func profileWalletsOf(param string) string {
+	return `(
+		SELECT LOWER(wpr.wallet_address) FROM profiles wpr WHERE wpr.id = ` + param + `
+		UNION SELECT wpw.wallet_address FROM profile_wallets wpw WHERE wpw.profile_id = ` + param + `
+	)`
+}
+
+// linkedWalletsOf selects every wallet sharing a profile with the wallet in param,
+// including that wallet itself even when it has no profile yet
+func linkedWalletsOf(param string) string {
+	return `(
+		SELECT LOWER(` + param + `)
+		UNION SELECT LOWER(wpr.wallet_address) FROM profiles wpr WHERE wpr.id = ` + ownerProfileOf(param) + `
+		UNION SELECT wpw.wallet_address FROM profile_wallets wpw WHERE wpw.profile_id = ` + ownerProfileOf(param) + `
+	)`
+}
+
+// resolveProfileID returns the profile a wallet belongs to, directly or through a link.
+// pgx.ErrNoRows means the wallet has no profile.
+func resolveProfileID(ctx context.Context, q querier, walletAddress string) (uuid.UUID, error) {
+	var profileID *uuid.UUID
+	if err := q.QueryRow(ctx, "SELECT "+ownerProfileOf("$1"), walletAddress).Scan(&profileID); err != nil {
+		return uuid.Nil, err
+	}
+	if profileID == nil {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	return *profileID, nil
+}
+
+// getLinkedWallets lists the wallets linked to a profile, oldest link first
+func getLinkedWallets(ctx context.Context, q querier, profileID uuid.UUID) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT wallet_address FROM profile_wallets WHERE profile_id = $1 ORDER BY linked_at, wallet_address
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []string{}
+	for rows.Next() {
+		var wallet string
+		if err := rows.Scan(&wallet); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, rows.Err()
+}
+
+// walletLinkMessage builds the message a wallet signs to join or leave a profile
+func walletLinkMessage(action, profileWallet, walletAddress string, timestamp int64) string {
+	return fmt.Sprintf("ATFi %s wallet\nProfile: %s\nAddress: %s\nTimestamp: %d",
+		action, strings.ToLower(profileWallet), strings.ToLower(walletAddress), timestamp)
+}
+
+// verifyWalletLink checks the request was signed by the wallet being linked or unlinked,
+// writing the error response otherwise
+func verifyWalletLink(c *gin.Context, signatures *contracts.SignatureVerifier, action, profileWallet string, req models.WalletLinkRequest) bool {
+	if !common.IsHexAddress(req.WalletAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return false
+	}
+
+	skew := time.Since(time.Unix(req.Timestamp, 0))
+	if skew > walletLinkMaxSkew || skew < -walletLinkMaxSkew {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Wallet signature expired"})
+		return false
+	}
+
+	message := walletLinkMessage(action, profileWallet, req.WalletAddress, req.Timestamp)
+	if err := signatures.VerifyPersonal(c, req.WalletAddress, message, req.Signature); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid wallet signature", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// LinkWallet adds another wallet to the caller's profile. The request must be signed by the
+// wallet being linked. The linked wallet keeps its own registrations; they count towards
+// this profile's stats from now on.
+func (h *UserHandler) LinkWallet(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	var req models.WalletLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !verifyWalletLink(c, h.signatures, "link", walletAddress, req) {
+		return
+	}
+	linked := strings.ToLower(req.WalletAddress)
+
+	tx, err := h.db.Begin(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	profileID, err := resolveProfileID(c, tx, walletAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error resolving profile", "wallet", walletAddress, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Serialize link changes per profile
+	var primary string
+	err = tx.QueryRow(c, "SELECT LOWER(wallet_address) FROM profiles WHERE id = $1 FOR UPDATE", profileID).Scan(&primary)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if linked == primary {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Wallet is already the profile's primary wallet"})
+		return
+	}
+
+	// A wallet that already has wallets linked to it cannot itself be linked away
+	var ownsLinks bool
+	err = tx.QueryRow(c, `
+		SELECT EXISTS(
+			SELECT 1 FROM profile_wallets pw
+			JOIN profiles pr ON pr.id = pw.profile_id
+			WHERE LOWER(pr.wallet_address) = $1
+		)
+	`, linked).Scan(&ownsLinks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ownsLinks {
+		c.JSON(http.StatusConflict, gin.H{"error": "Wallet has its own linked wallets; unlink them first"})
+		return
+	}
+
+	_, err = tx.Exec(c, "INSERT INTO profile_wallets (wallet_address, profile_id) VALUES ($1, $2)", linked, profileID)
+	if err != nil {
+		if isUniqueViolation(err, "profile_wallets_pkey") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Wallet is already linked to a profile"})
+			return
+		}
+		slog.ErrorContext(c, "Database error linking wallet", "linked_wallet", linked, "profile_id", profileID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link wallet"})
+		return
+	}
+
+	wallets, err := getLinkedWallets(c, tx, profileID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link wallet"})
+		return
+	}
+
+	slog.InfoContext(c, "Linked wallet", "linked_wallet", linked, "profile_id", profileID)
+	c.JSON(http.StatusOK, gin.H{"profile_id": profileID, "wallet_address": primary, "linked_wallets": wallets})
+}
+
+// UnlinkWallet removes a linked wallet from a profile. The request must be signed by the
+// wallet being removed. Its registrations stay on its own profile row, so nothing is orphaned;
+// they simply stop counting towards this profile.
+func (h *UserHandler) UnlinkWallet(c *gin.Context) {
+	walletAddress := c.Param("walletAddress")
+	if !h.canEditProfile(c, walletAddress) {
+		return
+	}
+
+	var req models.WalletLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !verifyWalletLink(c, h.signatures, "unlink", walletAddress, req) {
+		return
+	}
+	unlinked := strings.ToLower(req.WalletAddress)
+
+	profileID, err := resolveProfileID(c, h.db, walletAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	tag, err := h.db.Exec(c, "DELETE FROM profile_wallets WHERE wallet_address = $1 AND profile_id = $2", unlinked, profileID)
+	if err != nil {
+		slog.ErrorContext(c, "Database error unlinking wallet", "unlinked_wallet", unlinked, "profile_id", profileID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink wallet"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		// The primary wallet lives on the profile row itself and cannot be unlinked
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet is not linked to this profile"})
+		return
+	}
+
+	wallets, err := getLinkedWallets(c, h.db, profileID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	slog.InfoContext(c, "Unlinked wallet", "unlinked_wallet", unlinked, "profile_id", profileID)
+	c.JSON(http.StatusOK, gin.H{"profile_id": profileID, "linked_wallets": wallets})
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"atfi-backend/contracts"
+)
+
+// Yield figures are for display, so a minute-old read is good enough
+const yieldCacheTTL = time.Minute
+
+type yieldCacheEntry struct {
+	body      gin.H
+	expiresAt time.Time
+}
+
+// yieldCache keeps each event's on-chain yield response for yieldCacheTTL
+type yieldCache struct {
+	mu      sync.Mutex
+	entries map[int64]yieldCacheEntry
+}
+
+func (yc *yieldCache) get(eventID int64) (gin.H, bool) {
+	yc.mu.Lock()
+	defer yc.mu.Unlock()
+	entry, ok := yc.entries[eventID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// set stores a response, dropping expired entries as it goes so the cache stays bounded
+// by the number of events viewed within a minute
+func (yc *yieldCache) set(eventID int64, body gin.H) {
+	yc.mu.Lock()
+	defer yc.mu.Unlock()
+	if yc.entries == nil {
+		yc.entries = map[int64]yieldCacheEntry{}
+	}
+	now := time.Now()
+	for id, entry := range yc.entries {
+		if now.After(entry.expiresAt) {
+			delete(yc.entries, id)
+		}
+	}
+	yc.entries[eventID] = yieldCacheEntry{body: body, expiresAt: now.Add(yieldCacheTTL)}
+}
+
+// recordedYield sums an event's vault_yield_records: what was deposited, the yield
+// recorded on it and the protocol of the latest deposit
+type recordedYield struct {
+	Records   int
+	Principal string
+	Yield     string
+	Protocol  *string
+}
+
+func (h *EventHandler) loadRecordedYield(c *gin.Context, eventID int64) (recordedYield, error) {
+	var recorded recordedYield
+	err := h.db.QueryRow(c, `
+		SELECT COUNT(*), COALESCE(SUM(deposit_amount), 0)::text, COALESCE(SUM(yield_amount), 0)::text,
+		       (ARRAY_AGG(yield_protocol_used ORDER BY deposit_time DESC))[1]
+		FROM vault_yield_records
+		WHERE event_id = $1
+	`, eventID).Scan(&recorded.Records, &recorded.Principal, &recorded.Yield, &recorded.Protocol)
+	return recorded, err
+}
+
+// GetEventYield returns what the event's vault holds against what was staked, and the
+// yield accrued so far. It reads the vault's totalAssets, cached for a minute, and falls
+// back to the recorded yield deposits when the vault cannot be read.
+func (h *EventHandler) GetEventYield(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if body, ok := h.yields.get(eventID); ok {
+		c.JSON(http.StatusOK, body)
+		return
+	}
+
+	var vaultAddress *string
+	err = h.db.QueryRow(c, "SELECT vault_address FROM events_onchain WHERE event_id = $1", eventID).Scan(&vaultAddress)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		slog.ErrorContext(c, "Database error loading event for its yield", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	deployed := vaultAddress != nil && common.IsHexAddress(*vaultAddress) && common.HexToAddress(*vaultAddress) != (common.Address{})
+
+	recorded, err := h.loadRecordedYield(c, eventID)
+	if err != nil {
+		slog.ErrorContext(c, "Database error loading yield records", "event_id", eventID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Without a vault there is no token to read, and the chain's primary token is assumed
+	tokenVault := vaultAddress
+	if !deployed {
+		tokenVault = nil
+	}
+	token := h.displayToken(c, tokenVault)
+	symbol := token.Symbol
+	amount := func(raw string) gin.H {
+		return gin.H{"raw": raw, "formatted": formatTokenAmount(raw, token.Decimals)}
+	}
+
+	// Unverified vaults are not worth advertising, so only the records are shown for them
+	var readErr error
+	if deployed && h.client != nil && h.vaultTrusted(c, eventID, *vaultAddress) {
+		position, err := h.vaultYield(c, *vaultAddress)
+		if err == nil {
+			body := gin.H{
+				"event_id":      eventID,
+				"vault_address": common.HexToAddress(*vaultAddress).Hex(),
+				"token":         symbol,
+				"principal":     amount(position.Principal.String()),
+				"current_value": amount(position.TotalAssets.String()),
+				"accrued_yield": amount(position.Accrued().String()),
+				"protocol":      recorded.Protocol,
+				"source":        "onchain",
+				"as_of":         time.Now().UTC(),
+			}
+			h.yields.set(eventID, body)
+			c.JSON(http.StatusOK, body)
+			return
+		}
+		slog.WarnContext(c, "Failed to read the yield, falling back to recorded figures", "event_id", eventID, "error", err)
+		readErr = err
+	}
+
+	if recorded.Records == 0 {
+		switch {
+		case !deployed:
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "vault_not_deployed",
+				"message": "The event has no vault address yet",
+			})
+		case readErr != nil:
+			c.JSON(chainReadStatus(readErr), chainError("Failed to read the vault", readErr))
+		default:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain data unavailable"})
+		}
+		return
+	}
+
+	// Amounts are whole base units; anything else is counted as nothing
+	principal, ok := new(big.Int).SetString(recorded.Principal, 10)
+	if !ok {
+		principal = new(big.Int)
+	}
+	accrued, ok := new(big.Int).SetString(recorded.Yield, 10)
+	if !ok {
+		accrued = new(big.Int)
+	}
+	response := gin.H{
+		"event_id":      eventID,
+		"vault_address": vaultAddress,
+		"token":         symbol,
+		"principal":     amount(recorded.Principal),
+		"current_value": amount(new(big.Int).Add(principal, accrued).String()),
+		"accrued_yield": amount(recorded.Yield),
+		"protocol":      recorded.Protocol,
+		"source":        "recorded",
+		"as_of":         time.Now().UTC(),
+	}
+	if deployed {
+		response["vault_address"] = common.HexToAddress(*vaultAddress).Hex()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// vaultYield reads the yield position of the vault at vaultAddress
+func (h *EventHandler) vaultYield(c *gin.Context, vaultAddress string) (*contracts.YieldPosition, error) {
+	vault, err := contracts.NewVaultContract(h.client, vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	return vault.Yield(c)
+}
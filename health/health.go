@@ -0,0 +1,195 @@
+// Package health checks the server's dependencies for the health endpoint. Checks run
+// concurrently within a shared budget, so a hung dependency slows the endpoint by at most
+// the budget. A failed critical check makes the server unhealthy; any other failure only
+// degrades it.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Overall statuses and per-check statuses reported by Run
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+
+	CheckOK     = "ok"
+	CheckFailed = "failed"
+)
+
+// DefaultBudget bounds how long the checks of one Run may take together
+const DefaultBudget = 2 * time.Second
+
+// errTimedOut is reported for checks still running when the budget ran out
+var errTimedOut = errors.New("timed out")
+
+// Check is one dependency check. Run returns details to report, such as the latest
+// block, and an error when the dependency is unusable.
+type Check struct {
+	Name string
+	// Critical checks make the server unhealthy when they fail; others degrade it
+	Critical bool
+	Run      func(ctx context.Context) (any, error)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	Details   any     `json:"details,omitempty"`
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Status    string            `json:"status"`
+	Timestamp int64             `json:"timestamp"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// HTTPStatus is 503 when the server is unhealthy and 200 otherwise, so load balancers
+// keep routing to a degraded server
+func (r Report) HTTPStatus() int {
+	if r.Status == StatusUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// Checker runs a fixed set of checks
+type Checker struct {
+	budget time.Duration
+	checks []Check
+}
+
+// NewChecker returns a checker running checks within budget
+func NewChecker(budget time.Duration, checks ...Check) *Checker {
+	return &Checker{budget: budget, checks: checks}
+}
+
+// Add adds a check. It must not be called once the checker is in use.
+func (c *Checker) Add(check Check) {
+	c.checks = append(c.checks, check)
+}
+
+type outcome struct {
+	index  int
+	result Result
+}
+
+// Run runs every check concurrently and reports their results. Checks still running once
+// the budget is spent are reported as failed; they see ctx cancelled and are not waited for.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.budget)
+	defer cancel()
+
+	// Buffered so that checks finishing after the budget do not block
+	outcomes := make(chan outcome, len(c.checks))
+	for i, check := range c.checks {
+		go func(i int, check Check) {
+			start := time.Now()
+			details, err := check.Run(ctx)
+			result := Result{
+				Status:    CheckOK,
+				Critical:  check.Critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+				Details:   details,
+			}
+			if err != nil {
+				result.Status, result.Error = CheckFailed, err.Error()
+			}
+			outcomes <- outcome{index: i, result: result}
+		}(i, check)
+	}
+
+	results := make(map[int]Result, len(c.checks))
+	for len(results) < len(c.checks) {
+		select {
+		case o := <-outcomes:
+			results[o.index] = o.result
+		case <-ctx.Done():
+			for i, check := range c.checks {
+				if _, ok := results[i]; !ok {
+					results[i] = Result{
+						Status:    CheckFailed,
+						Critical:  check.Critical,
+						LatencyMS: float64(c.budget.Microseconds()) / 1000,
+						Error:     errTimedOut.Error(),
+					}
+				}
+			}
+		}
+	}
+
+	report := Report{Status: StatusHealthy, Timestamp: time.Now().Unix(), Checks: make(map[string]Result, len(c.checks))}
+	for i, check := range c.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == CheckOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// BlockNumberReader is the part of an Ethereum client ChainHead needs
+type BlockNumberReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// ChainHead checks the Ethereum client by reading the latest block number. A block read
+// within maxAge is reused, so frequent health checks do not each cost an RPC call.
+type ChainHead struct {
+	client BlockNumberReader
+	maxAge time.Duration
+
+	mu     sync.Mutex
+	block  uint64
+	readAt time.Time
+}
+
+// NewChainHead returns a check of client reusing block numbers read within maxAge
+func NewChainHead(client BlockNumberReader, maxAge time.Duration) *ChainHead {
+	return &ChainHead{client: client, maxAge: maxAge}
+}
+
+// ChainHeadDetails reports the latest block read and how long ago it was read
+type ChainHeadDetails struct {
+	Block      uint64  `json:"block"`
+	AgeSeconds float64 `json:"age_seconds"`
+}
+
+// Check reads the latest block unless a recent enough one is cached. When the read fails,
+// the last block read, if any, is still reported.
+func (h *ChainHead) Check(ctx context.Context) (any, error) {
+	h.mu.Lock()
+	block, readAt := h.block, h.readAt
+	h.mu.Unlock()
+	if !readAt.IsZero() && time.Since(readAt) < h.maxAge {
+		return ChainHeadDetails{Block: block, AgeSeconds: time.Since(readAt).Seconds()}, nil
+	}
+
+	latest, err := h.client.BlockNumber(ctx)
+	if err != nil {
+		if readAt.IsZero() {
+			return nil, err
+		}
+		return ChainHeadDetails{Block: block, AgeSeconds: time.Since(readAt).Seconds()}, err
+	}
+
+	h.mu.Lock()
+	h.block, h.readAt = latest, time.Now()
+	h.mu.Unlock()
+	return ChainHeadDetails{Block: latest}, nil
+}
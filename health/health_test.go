@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) (any, error) { return nil, nil }
+
+func failing(context.Context) (any, error) { return nil, errors.New("connection refused") }
+
+func TestRunReportsWorstStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		checks []Check
+		want   string
+		code   int
+	}{
+		{"all ok", []Check{{Name: "database", Critical: true, Run: ok}, {Name: "ethereum", Run: ok}}, StatusHealthy, http.StatusOK},
+		{"soft failure", []Check{{Name: "database", Critical: true, Run: ok}, {Name: "ethereum", Run: failing}}, StatusDegraded, http.StatusOK},
+		{"hard failure", []Check{{Name: "database", Critical: true, Run: failing}, {Name: "ethereum", Run: failing}}, StatusUnhealthy, http.StatusServiceUnavailable},
+	} {
+		report := NewChecker(time.Second, tc.checks...).Run(context.Background())
+		if report.Status != tc.want || report.HTTPStatus() != tc.code {
+			t.Errorf("%s: status %s (%d), want %s (%d)", tc.name, report.Status, report.HTTPStatus(), tc.want, tc.code)
+		}
+		if len(report.Checks) != len(tc.checks) {
+			t.Errorf("%s: got %d results for %d checks", tc.name, len(report.Checks), len(tc.checks))
+		}
+	}
+}
+
+func TestRunStaysWithinBudget(t *testing.T) {
+	hung := func(ctx context.Context) (any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+	checker := NewChecker(50*time.Millisecond,
+		Check{Name: "database", Critical: true, Run: ok},
+		Check{Name: "ethereum", Run: hung},
+		Check{Name: "migrations", Run: hung},
+	)
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Run took %s with a 50ms budget; checks must run concurrently and be cut off", elapsed)
+	}
+	if report.Status != StatusDegraded {
+		t.Errorf("status = %s, want degraded", report.Status)
+	}
+	if got := report.Checks["ethereum"]; got.Status != CheckFailed || got.Error != "timed out" {
+		t.Errorf("hung check = %+v, want failed with a timeout", got)
+	}
+	if got := report.Checks["database"]; got.Status != CheckOK {
+		t.Errorf("database = %+v, want ok", got)
+	}
+}
+
+type fakeChain struct {
+	block uint64
+	err   error
+	calls int
+}
+
+func (f *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	f.calls++
+	return f.block, f.err
+}
+
+func TestChainHeadReusesRecentBlock(t *testing.T) {
+	chain := &fakeChain{block: 100}
+	head := NewChainHead(chain, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		details, err := head.Check(context.Background())
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		if got := details.(ChainHeadDetails).Block; got != 100 {
+			t.Errorf("block = %d, want 100", got)
+		}
+	}
+	if chain.calls != 1 {
+		t.Errorf("BlockNumber called %d times, want once within maxAge", chain.calls)
+	}
+
+	// Once the block is stale it is read again, and a failed read still reports it
+	head.readAt = time.Now().Add(-2 * time.Minute)
+	chain.err = errors.New("503 Service Unavailable")
+	details, err := head.Check(context.Background())
+	if err == nil {
+		t.Fatal("Check succeeded although the node is down")
+	}
+	if got := details.(ChainHeadDetails); got.Block != 100 || got.AgeSeconds < 120 {
+		t.Errorf("details = %+v, want the last block read two minutes ago", got)
+	}
+}
+
+func TestWorkersCheck(t *testing.T) {
+	workers := NewWorkers()
+	phase := workers.Register("phase", time.Minute)
+	outbox := workers.Register("email-outbox", 30*time.Second)
+
+	// Workers that have not run yet are fine until they miss their interval
+	if _, err := workers.Check(context.Background()); err != nil {
+		t.Fatalf("freshly started workers: %v", err)
+	}
+
+	phase.Beat(nil)
+	outbox.Beat(errors.New("smtp: 421 try again later"))
+	details, err := workers.Check(context.Background())
+	if err == nil || err.Error() != "email-outbox failed its last run" {
+		t.Errorf("error = %v, want the failed outbox run", err)
+	}
+	if status := details.(map[string]WorkerStatus)["phase"]; status.LastRun == nil || status.Stale {
+		t.Errorf("phase = %+v, want a recent run", status)
+	}
+
+	outbox.Beat(nil)
+	phase.lastRun = time.Now().Add(-time.Hour)
+	if _, err := workers.Check(context.Background()); err == nil || err.Error() != "phase is stale" {
+		t.Errorf("error = %v, want the phase worker stale", err)
+	}
+
+	// Workers run without a heartbeat ignore beats
+	var none *Heartbeat
+	none.Beat(nil)
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleGrace is added to a worker's interval before a missed run counts as stale, covering
+// runs that take a while
+const staleGrace = time.Minute
+
+// Heartbeat records the runs of one background worker. A nil Heartbeat ignores beats, so
+// workers can be run without one.
+type Heartbeat struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	lastRun time.Time
+	lastErr error
+}
+
+// Beat records that the worker finished a run, with the error the run failed with if any
+func (h *Heartbeat) Beat(err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastRun, h.lastErr = time.Now(), err
+}
+
+// WorkerStatus is one worker's state as reported by the health endpoint
+type WorkerStatus struct {
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Stale     bool       `json:"stale"`
+}
+
+// status reports the worker as stale when neither a run nor its start happened within
+// twice its interval
+func (h *Heartbeat) status(now time.Time) WorkerStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var status WorkerStatus
+	since := h.started
+	if !h.lastRun.IsZero() {
+		lastRun := h.lastRun
+		status.LastRun, since = &lastRun, lastRun
+	}
+	if h.lastErr != nil {
+		status.LastError = h.lastErr.Error()
+	}
+	status.Stale = now.Sub(since) > 2*h.interval+staleGrace
+	return status
+}
+
+// Workers tracks the heartbeats of the background workers
+type Workers struct {
+	mu    sync.Mutex
+	beats map[string]*Heartbeat
+}
+
+// NewWorkers returns an empty set of workers
+func NewWorkers() *Workers {
+	return &Workers{beats: make(map[string]*Heartbeat)}
+}
+
+// Register adds a worker expected to run every interval and returns its heartbeat
+func (w *Workers) Register(name string, interval time.Duration) *Heartbeat {
+	heartbeat := &Heartbeat{interval: interval, started: time.Now()}
+	w.mu.Lock()
+	w.beats[name] = heartbeat
+	w.mu.Unlock()
+	return heartbeat
+}
+
+// Check reports every worker's status, failing when a worker is stale or its last run
+// failed
+func (w *Workers) Check(ctx context.Context) (any, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	statuses := make(map[string]WorkerStatus, len(w.beats))
+	var unwell []string
+	for name, heartbeat := range w.beats {
+		status := heartbeat.status(now)
+		statuses[name] = status
+		if status.Stale {
+			unwell = append(unwell, name+" is stale")
+		} else if status.LastError != "" {
+			unwell = append(unwell, name+" failed its last run")
+		}
+	}
+	if len(unwell) > 0 {
+		sort.Strings(unwell)
+		return statuses, errors.New(strings.Join(unwell, ", "))
+	}
+	return statuses, nil
+}
@@ -0,0 +1,125 @@
+// Package identicon renders blockies-style avatars derived from a wallet address, so
+// profiles without an uploaded image still get a stable, recognisable picture.
+package identicon
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Grid is the number of cells along each side of an identicon
+const Grid = 8
+
+// MaxSize is the largest image side, in pixels, Render accepts
+const MaxSize = 512
+
+// PNG renders the identicon of address as a size×size PNG. The address is lowercased
+// first, so checksummed and lowercase spellings give the same image. The output depends
+// only on its inputs.
+func PNG(address string, size int) ([]byte, error) {
+	img, err := Render(address, size)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode identicon: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws the identicon of address, following the ethereum-blockies layout: a
+// seeded generator picks a foreground, background and spot colour, then fills the left
+// half of the grid and mirrors it
+func Render(address string, size int) (*image.Paletted, error) {
+	if size < 1 || size > MaxSize {
+		return nil, fmt.Errorf("size must be between 1 and %d", MaxSize)
+	}
+
+	rng := newSeededRand(strings.ToLower(address))
+	foreground := rng.color()
+	background := rng.color()
+	spot := rng.color()
+
+	var cells [Grid][Grid]uint8
+	half := (Grid + 1) / 2
+	for y := 0; y < Grid; y++ {
+		for x := 0; x < half; x++ {
+			// 0 background, 1 foreground, 2 spot
+			cells[y][x] = uint8(math.Floor(rng.next() * 2.3))
+			cells[y][Grid-1-x] = cells[y][x]
+		}
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{background, foreground, spot})
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			img.SetColorIndex(px, py, cells[py*Grid/size][px*Grid/size])
+		}
+	}
+	return img, nil
+}
+
+// seededRand is the xorshift generator used by ethereum-blockies, seeded from a string
+type seededRand struct {
+	seed [4]int32
+}
+
+func newSeededRand(s string) *seededRand {
+	r := &seededRand{}
+	for i := 0; i < len(s); i++ {
+		r.seed[i%4] = (r.seed[i%4] << 5) - r.seed[i%4] + int32(s[i])
+	}
+	return r
+}
+
+// next returns a value in [0, 2)
+func (r *seededRand) next() float64 {
+	t := r.seed[0] ^ (r.seed[0] << 11)
+	r.seed[0], r.seed[1], r.seed[2] = r.seed[1], r.seed[2], r.seed[3]
+	r.seed[3] = r.seed[3] ^ (r.seed[3] >> 19) ^ t ^ (t >> 8)
+	return float64(uint32(r.seed[3])) / float64(uint32(1)<<31)
+}
+
+// color picks a saturated colour of random hue and lightness
+func (r *seededRand) color() color.RGBA {
+	hue := math.Floor(r.next() * 360)
+	saturation := r.next()*60 + 40
+	lightness := (r.next() + r.next() + r.next() + r.next()) * 25
+	return hslToRGB(hue, saturation/100, lightness/100)
+}
+
+// hslToRGB converts a hue in degrees and saturation and lightness in [0, 1]
+func hslToRGB(h, s, l float64) color.RGBA {
+	l = math.Min(l, 1)
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	m := l - c/2
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v+m)) * 255))
+	}
+	return color.RGBA{R: channel(r), G: channel(g), B: channel(b), A: 0xff}
+}
@@ -0,0 +1,48 @@
+package identicon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPNGIsDeterministicPerAddress(t *testing.T) {
+	const a = "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	const b = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+
+	first, err := PNG(a, 64)
+	if err != nil {
+		t.Fatalf("PNG(a): %v", err)
+	}
+	again, err := PNG(a, 64)
+	if err != nil {
+		t.Fatalf("PNG(a) again: %v", err)
+	}
+	if !bytes.Equal(first, again) {
+		t.Error("same address produced different images")
+	}
+
+	lower, err := PNG("0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", 64)
+	if err != nil {
+		t.Fatalf("PNG(lowercase a): %v", err)
+	}
+	if !bytes.Equal(first, lower) {
+		t.Error("checksummed and lowercase address produced different images")
+	}
+
+	other, err := PNG(b, 64)
+	if err != nil {
+		t.Fatalf("PNG(b): %v", err)
+	}
+	if bytes.Equal(first, other) {
+		t.Error("different addresses produced identical images")
+	}
+}
+
+func TestRenderRejectsOversizedImages(t *testing.T) {
+	if _, err := Render("0x0000000000000000000000000000000000000001", MaxSize+1); err == nil {
+		t.Errorf("Render accepted size %d", MaxSize+1)
+	}
+	if _, err := Render("0x0000000000000000000000000000000000000001", MaxSize); err != nil {
+		t.Errorf("Render rejected size %d: %v", MaxSize, err)
+	}
+}
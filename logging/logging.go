@@ -0,0 +1,83 @@
+// Package logging builds the server's structured logger. Attributes attached to a context
+// with WithAttrs, such as the request ID, are added to every record logged with that
+// context, so a request's log lines can be found together.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Output formats accepted by New
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// ParseFormat checks a LOG_FORMAT value, defaulting to JSON when it is empty
+func ParseFormat(raw string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(raw)); format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatText:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q: must be %s or %s", raw, FormatJSON, FormatText)
+	}
+}
+
+// New returns a logger writing records at level and above to w, as JSON or as text
+func New(w io.Writer, level slog.Leveler, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == FormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx carrying attrs in addition to those ctx already carries
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := Attrs(ctx)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// Attrs returns the attributes ctx carries
+func Attrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes carried by a record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
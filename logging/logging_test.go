@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestContextAttrsAreLogged(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, slog.LevelInfo, FormatJSON)
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = WithAttrs(ctx, slog.String("path", "/api/v1/events"))
+	logger.InfoContext(ctx, "Loaded events", "count", 3)
+	logger.DebugContext(ctx, "Executing query", "query", "SELECT 1")
+
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("want exactly one JSON record, got %q: %v", out.String(), err)
+	}
+	want := map[string]any{"msg": "Loaded events", "level": "INFO", "request_id": "req-1", "path": "/api/v1/events", "count": float64(3)}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+
+	// Attributes of one context do not leak into records of another
+	out.Reset()
+	logger.Info("Worker ran")
+	record = nil
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if _, ok := record["request_id"]; ok {
+		t.Errorf("record without a request context has request_id: %v", record)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for raw, want := range map[string]string{"": FormatJSON, "JSON": FormatJSON, "text": FormatText} {
+		if got, err := ParseFormat(raw); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("logfmt"); err == nil {
+		t.Error("ParseFormat accepted an unknown format")
+	}
+}
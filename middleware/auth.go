@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"atfi-backend/contracts"
+)
+
+// Context keys populated by Authenticate
+const (
+	UserAddressKey = "user_address"
+	IsAdminKey     = "is_admin"
+)
+
+// Request headers carrying the wallet signature
+const (
+	HeaderWalletAddress   = "X-Wallet-Address"
+	HeaderWalletSignature = "X-Wallet-Signature"
+	HeaderAuthTimestamp   = "X-Auth-Timestamp"
+)
+
+// Signatures older (or further in the future) than this are rejected
+const authMaxSkew = 5 * time.Minute
+
+// AuthMessage builds the message a wallet signs to authenticate a request
+func AuthMessage(walletAddress string, timestamp int64) string {
+	return fmt.Sprintf("ATFi authentication\nAddress: %s\nTimestamp: %d", strings.ToLower(walletAddress), timestamp)
+}
+
+// Authenticate verifies the wallet signature headers when present and stores the
+// authenticated wallet in the context. Requests without the headers pass through
+// unauthenticated; requests with invalid headers are rejected. Smart contract wallets are
+// verified through signatures' EIP-1271 check. Wallets in adminAddresses are marked as
+// admins.
+func Authenticate(signatures *contracts.SignatureVerifier, adminAddresses []string) gin.HandlerFunc {
+	admins := map[string]bool{}
+	for _, addr := range adminAddresses {
+		admins[strings.ToLower(addr)] = true
+	}
+
+	return func(c *gin.Context) {
+		walletAddress := c.GetHeader(HeaderWalletAddress)
+		signature := c.GetHeader(HeaderWalletSignature)
+		if walletAddress == "" && signature == "" {
+			c.Next()
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(c.GetHeader(HeaderAuthTimestamp), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication timestamp"})
+			return
+		}
+
+		skew := time.Since(time.Unix(timestamp, 0))
+		if skew > authMaxSkew || skew < -authMaxSkew {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication signature expired"})
+			return
+		}
+
+		if err := signatures.VerifyPersonal(c, walletAddress, AuthMessage(walletAddress, timestamp), signature); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid wallet signature", "details": err.Error()})
+			return
+		}
+
+		c.Set(UserAddressKey, strings.ToLower(walletAddress))
+		c.Set(IsAdminKey, admins[strings.ToLower(walletAddress)])
+		c.Next()
+	}
+}
+
+// RequireWallet rejects requests that did not authenticate with a wallet signature
+func RequireWallet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(UserAddressKey) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects requests not authenticated as one of ADMIN_ADDRESSES
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsAdmin reports whether the authenticated wallet is an admin
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool(IsAdminKey)
+}
+
+// HeaderAPIKey carries the shared key used by the indexer service
+const HeaderAPIKey = "X-API-Key"
+
+// IsIndexerKey is set in the context when the request carried a valid indexer key
+const IsIndexerKey = "is_indexer"
+
+// IndexerKey marks requests carrying apiKey, the indexer's shared key, as coming from the
+// indexer. An empty apiKey matches nothing. It never rejects; pair it with RequireIndexer
+// or check IsIndexer in the handler.
+func IndexerKey(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(HeaderAPIKey)
+		if apiKey != "" && provided != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) == 1 {
+			c.Set(IsIndexerKey, true)
+		}
+		c.Next()
+	}
+}
+
+// RequireIndexer rejects requests that did not present the indexer API key
+func RequireIndexer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsIndexer(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Indexer API key required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsIndexer reports whether the request was authenticated with the indexer API key
+func IsIndexer(c *gin.Context) bool {
+	return c.GetBool(IsIndexerKey)
+}
+
+// RequireWalletOrIndexer rejects requests that neither authenticated with a wallet
+// signature nor presented the indexer API key
+func RequireWalletOrIndexer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(UserAddressKey) == "" && !IsIndexer(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// TrackActivity calls touch with the authenticated wallet after each request that
+// succeeded. touch runs on the request path, so it must not block.
+func TrackActivity(touch func(walletAddress string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if walletAddress := c.GetString(UserAddressKey); walletAddress != "" && c.Writer.Status() < http.StatusBadRequest {
+			touch(walletAddress)
+		}
+	}
+}
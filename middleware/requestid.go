@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"atfi-backend/logging"
+)
+
+// HeaderRequestID carries the request ID in both directions
+const HeaderRequestID = "X-Request-ID"
+
+// RequestIDKey is set in the context to the request's ID
+const RequestIDKey = "request_id"
+
+// validRequestID matches incoming IDs worth keeping, such as UUIDs from a load balancer
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestLogger gives every request an ID, reusing a well-formed X-Request-ID from the
+// client or proxy, and returns it in the X-Request-ID header and in JSON error bodies.
+// Records logged with the request's context carry the ID, method and path, and a line
+// with the status and duration is logged once the request completes. The engine must
+// have ContextWithFallback set for the gin context to carry them.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(HeaderRequestID, requestID)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(),
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+		))
+		c.Writer = &errorEnvelopeWriter{ResponseWriter: c.Writer, requestID: requestID}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request.Context(), level, "Request completed",
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		)
+	}
+}
+
+// RequestID returns the ID RequestLogger gave the request
+func RequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+func newRequestID() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(raw)
+}
+
+// errorEnvelopeWriter adds request_id to JSON error bodies, so the ID a user reports can be
+// matched to the server's logs
+type errorEnvelopeWriter struct {
+	gin.ResponseWriter
+	requestID string
+}
+
+func (w *errorEnvelopeWriter) Write(body []byte) (int, error) {
+	// Only the first write of an error response holds the start of the JSON object
+	if w.Size() > 0 || w.Status() < http.StatusBadRequest ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") ||
+		!bytes.HasPrefix(body, []byte("{")) {
+		return w.ResponseWriter.Write(body)
+	}
+
+	id, _ := json.Marshal(w.requestID)
+	envelope := append([]byte(`{"request_id":`), id...)
+	if rest := bytes.TrimSpace(body[1:]); !bytes.HasPrefix(rest, []byte("}")) {
+		envelope = append(envelope, ',')
+	}
+	envelope = append(envelope, body[1:]...)
+	if _, err := w.ResponseWriter.Write(envelope); err != nil {
+		return 0, err
+	}
+	return len(body), nil
+}
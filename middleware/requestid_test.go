@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"atfi-backend/logging"
+	"atfi-backend/middleware"
+)
+
+// captureLogs sends the default logger's JSON records to the returned buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&out, slog.LevelDebug, logging.FormatJSON))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &out
+}
+
+// records decodes one JSON log record per line
+func records(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var decoded []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		decoded = append(decoded, record)
+	}
+	return decoded
+}
+
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.RequestLogger())
+	router.GET("/events/:id", func(c *gin.Context) {
+		slog.ErrorContext(c, "Database error loading event", "event_id", c.Param("id"))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{})
+	})
+	router.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func TestRequestLoggerAttachesRequestFields(t *testing.T) {
+	out := captureLogs(t)
+	router := newRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/42", nil))
+
+	requestID := w.Header().Get(middleware.HeaderRequestID)
+	if requestID == "" {
+		t.Fatal("response has no X-Request-ID")
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body %q is not JSON: %v", w.Body.String(), err)
+	}
+	if body["request_id"] != requestID || body["error"] != "Event not found" {
+		t.Errorf("error body = %v, want the error and request_id %s", body, requestID)
+	}
+
+	logged := records(t, out)
+	if len(logged) != 2 {
+		t.Fatalf("got %d log records, want the handler's and the request's: %v", len(logged), logged)
+	}
+	for _, record := range logged {
+		if record["request_id"] != requestID || record["method"] != "GET" || record["path"] != "/events/42" {
+			t.Errorf("record %v lacks the request's ID, method or path", record)
+		}
+	}
+	if handler := logged[0]; handler["level"] != "ERROR" || handler["event_id"] != "42" {
+		t.Errorf("handler record = %v", handler)
+	}
+	completed := logged[1]
+	if completed["status"] != float64(http.StatusNotFound) || completed["level"] != "WARN" {
+		t.Errorf("completion record = %v, want status 404 at WARN", completed)
+	}
+	if _, ok := completed["duration_ms"]; !ok {
+		t.Errorf("completion record has no duration: %v", completed)
+	}
+}
+
+func TestRequestLoggerPropagatesIncomingID(t *testing.T) {
+	captureLogs(t)
+	router := newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/empty", nil)
+	req.Header.Set(middleware.HeaderRequestID, "lb-1234")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(middleware.HeaderRequestID); got != "lb-1234" {
+		t.Errorf("X-Request-ID = %q, want the incoming lb-1234", got)
+	}
+	if got := w.Body.String(); got != `{"request_id":"lb-1234"}` {
+		t.Errorf("empty error body = %s", got)
+	}
+
+	// Malformed IDs are replaced rather than echoed into headers and logs
+	req = httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(middleware.HeaderRequestID, "bad id\twith spaces")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(middleware.HeaderRequestID); got == "" || strings.Contains(got, " ") {
+		t.Errorf("X-Request-ID = %q, want a generated ID", got)
+	}
+	// Successful responses are left as they are
+	if got := w.Body.String(); got != `{"status":"ok"}` {
+		t.Errorf("success body = %s", got)
+	}
+}
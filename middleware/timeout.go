@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutBody replaces the body of a response that failed because its deadline passed
+var timeoutBody = []byte(`{"error":"Request timed out"}`)
+
+// Timeout gives every request a deadline of timeout, or of routes[c.FullPath()] for the
+// routes listed there; a zero route timeout exempts the route, which streaming endpoints
+// need. Handlers pass the request's context, or the gin context with the engine's
+// ContextWithFallback set, to pgx and ethclient, so queries and calls still running at
+// the deadline are cancelled. A server error written after the deadline, typically the
+// handler reporting the cancelled query, is answered as 504 Gateway Timeout, as is a
+// handler that returns without responding.
+func Timeout(timeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deadline := timeout
+		if route, ok := routes[c.FullPath()]; ok {
+			deadline = route
+		}
+		if deadline <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), deadline)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+
+		c.Next()
+
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.Data(http.StatusGatewayTimeout, "application/json; charset=utf-8", timeoutBody)
+		}
+	}
+}
+
+// timeoutWriter turns server errors written after the request's deadline into 504s with
+// a consistent error body
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+	replaced bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError && !w.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut, code = true, http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(body []byte) (int, error) {
+	if !w.timedOut {
+		return w.ResponseWriter.Write(body)
+	}
+	// The handler's own body describes the failed query; only the timeout is reported
+	if !w.replaced {
+		w.replaced = true
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := w.ResponseWriter.Write(timeoutBody); err != nil {
+			return 0, err
+		}
+	}
+	return len(body), nil
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,178 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"atfi-backend/middleware"
+)
+
+// newTimeoutRouter mirrors main's setup: handlers receive the gin context as their context
+func newTimeoutRouter(timeout time.Duration, routes map[string]time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.RequestLogger(), middleware.Timeout(timeout, routes))
+	return router
+}
+
+// slowQuery stands in for a query that takes a second, unless ctx is cancelled first
+func slowQuery(ctx context.Context, cancelled chan<- error) error {
+	select {
+	case <-time.After(time.Second):
+		return nil
+	case <-ctx.Done():
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	}
+}
+
+func TestTimeoutAnswersSlowQueryWith504(t *testing.T) {
+	captureLogs(t)
+	cancelled := make(chan error, 1)
+	router := newTimeoutRouter(50*time.Millisecond, nil)
+	router.GET("/events/:id", func(c *gin.Context) {
+		if err := slowQuery(c, cancelled); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/42", nil))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %s with a 50ms deadline", elapsed)
+	}
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", w.Code)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q is not JSON: %v", w.Body.String(), err)
+	}
+	if body["error"] != "Request timed out" || body["request_id"] != w.Header().Get(middleware.HeaderRequestID) || body["details"] != nil {
+		t.Errorf("body = %v, want the timeout error and request_id only", body)
+	}
+	select {
+	case err := <-cancelled:
+		if err != context.DeadlineExceeded {
+			t.Errorf("query cancelled with %v, want the deadline", err)
+		}
+	default:
+		t.Error("query was not cancelled")
+	}
+}
+
+func TestTimeoutAnswersSilentHandlerWith504(t *testing.T) {
+	captureLogs(t)
+	router := newTimeoutRouter(20*time.Millisecond, nil)
+	router.GET("/stalled", func(c *gin.Context) {
+		<-c.Done()
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stalled", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", w.Code)
+	}
+}
+
+func TestTimeoutRouteOverrides(t *testing.T) {
+	captureLogs(t)
+	router := newTimeoutRouter(20*time.Millisecond, map[string]time.Duration{
+		"/events/:id/stream": 0,
+		"/events/:id/export": time.Minute,
+	})
+	deadlines := make(map[string]time.Duration)
+	handler := func(c *gin.Context) {
+		if deadline, ok := c.Deadline(); ok {
+			deadlines[c.FullPath()] = time.Until(deadline)
+		}
+		// Longer than the default deadline
+		time.Sleep(50 * time.Millisecond)
+		if err := c.Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "done")
+	}
+	router.GET("/events/:id/stream", handler)
+	router.GET("/events/:id/export", handler)
+
+	for _, path := range []string{"/events/1/stream", "/events/1/export"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200 past the default deadline", path, w.Code)
+		}
+	}
+	if _, ok := deadlines["/events/:id/stream"]; ok {
+		t.Error("exempt stream has a deadline")
+	}
+	if got := deadlines["/events/:id/export"]; got < 50*time.Second {
+		t.Errorf("export deadline = %s, want about a minute", got)
+	}
+}
+
+// TestTimeoutCancelsDatabaseQuery runs against a real database when TEST_DATABASE_URL is
+// set, checking that PostgreSQL stops running the query once the request times out
+func TestTimeoutCancelsDatabaseQuery(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer pool.Close()
+
+	captureLogs(t)
+	marker := fmt.Sprintf("timeout-test-%d", time.Now().UnixNano())
+	router := newTimeoutRouter(200*time.Millisecond, nil)
+	router.GET("/slow", func(c *gin.Context) {
+		if _, err := pool.Exec(c, "SELECT pg_sleep(10) /* "+marker+" */"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request took %s with a 200ms deadline", elapsed)
+	}
+
+	// The cancel request reaches the server asynchronously
+	const running = "SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE '%' || $1 || '%' AND pid <> pg_backend_pid()"
+	for attempt := 0; ; attempt++ {
+		var count int
+		if err := pool.QueryRow(context.Background(), running, marker).Scan(&count); err != nil {
+			t.Fatalf("pg_stat_activity: %v", err)
+		}
+		if count == 0 {
+			break
+		}
+		if attempt == 20 {
+			t.Fatal("pg_sleep is still running on the server after the request timed out")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,12 @@
+-- Generic audit trail for privileged or state-changing actions
+CREATE TABLE IF NOT EXISTS audit_log (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  actor text NOT NULL,
+  action text NOT NULL,
+  event_id bigint,
+  details jsonb NOT NULL DEFAULT '{}'::jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT audit_log_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_event_id_idx ON audit_log (event_id, created_at);
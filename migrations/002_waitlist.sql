@@ -0,0 +1,15 @@
+-- Overflow queue for events at capacity
+CREATE TABLE IF NOT EXISTS waitlist (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL,
+  wallet_address text NOT NULL,
+  status text NOT NULL DEFAULT 'WAITING',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  promoted_at timestamptz,
+  promotion_expires_at timestamptz,
+  CONSTRAINT waitlist_pkey PRIMARY KEY (id),
+  CONSTRAINT waitlist_event_id_fkey FOREIGN KEY (event_id) REFERENCES public.events_onchain(event_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_event_wallet_key ON waitlist (event_id, LOWER(wallet_address));
+CREATE INDEX IF NOT EXISTS waitlist_event_status_idx ON waitlist (event_id, status, created_at);
@@ -0,0 +1,23 @@
+-- Deposit records backing each registration
+CREATE TABLE IF NOT EXISTS stakes (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL,
+  user_id uuid NOT NULL,
+  wallet_address text NOT NULL,
+  is_attended boolean NOT NULL DEFAULT false,
+  stake_amount numeric NOT NULL,
+  stake_transaction_hash text NOT NULL,
+  created_at_block bigint,
+  created_at_timestamp timestamptz,
+  reward_amount numeric,
+  claimed boolean NOT NULL DEFAULT false,
+  claimed_transaction_hash text,
+  claimed_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT stakes_pkey PRIMARY KEY (id),
+  CONSTRAINT stakes_event_user_key UNIQUE (event_id, user_id),
+  CONSTRAINT stakes_stake_transaction_hash_key UNIQUE (stake_transaction_hash),
+  CONSTRAINT stakes_event_id_fkey FOREIGN KEY (event_id) REFERENCES public.events_onchain(event_id),
+  CONSTRAINT stakes_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.profiles(id)
+);
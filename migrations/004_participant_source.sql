@@ -0,0 +1,2 @@
+-- Where a participant row came from: the registration API or an on-chain sync
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS source text NOT NULL DEFAULT 'api';
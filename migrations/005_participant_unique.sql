@@ -0,0 +1,74 @@
+-- One registration per user per event, and one profile per wallet regardless of case.
+-- Deployments that raced before these constraints hold duplicates, which are merged into
+-- the earliest row first so that the constraints can be added.
+
+-- Profiles whose wallets differ only in case: references move to the earliest profile
+CREATE TEMP TABLE duplicate_profiles ON COMMIT DROP AS
+SELECT id, keep_id
+FROM (
+  SELECT id,
+         first_value(id) OVER w AS keep_id
+  FROM profiles
+  WINDOW w AS (PARTITION BY LOWER(wallet_address) ORDER BY created_at NULLS FIRST, id)
+) ranked
+WHERE id <> keep_id;
+
+-- One stake per user per event survives: the kept profile's own, else the earliest
+DELETE FROM stakes s
+USING (
+  SELECT st.id,
+         row_number() OVER (PARTITION BY st.event_id, COALESCE(d.keep_id, st.user_id)
+                            ORDER BY d.id IS NOT NULL, st.created_at, st.id) AS n
+  FROM stakes st
+  LEFT JOIN duplicate_profiles d ON d.id = st.user_id
+) ranked
+WHERE s.id = ranked.id AND ranked.n > 1;
+UPDATE stakes s SET user_id = d.keep_id FROM duplicate_profiles d WHERE s.user_id = d.id;
+
+UPDATE participant p SET user_id = d.keep_id FROM duplicate_profiles d WHERE p.user_id = d.id;
+
+DO $$
+BEGIN
+  -- Older deployments have a checkins table, not always with a user_id column
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'checkins' AND column_name = 'user_id') THEN
+    UPDATE checkins ck SET user_id = d.keep_id FROM duplicate_profiles d WHERE ck.user_id = d.id;
+  END IF;
+END
+$$;
+
+DELETE FROM profiles pr USING duplicate_profiles d WHERE pr.id = d.id;
+
+-- Duplicate registrations: the earliest row is kept and takes on any attendance or claim
+-- recorded on the others. Stakes are keyed by (event_id, user_id) and need no repointing.
+CREATE TEMP TABLE duplicate_participants ON COMMIT DROP AS
+SELECT id, keep_id
+FROM (
+  SELECT id,
+         first_value(id) OVER w AS keep_id
+  FROM participant
+  WINDOW w AS (PARTITION BY event_id, user_id ORDER BY created_at NULLS FIRST, id)
+) ranked
+WHERE id <> keep_id;
+
+UPDATE participant p
+SET is_attend = p.is_attend OR merged.is_attend,
+    is_claim = p.is_claim OR merged.is_claim
+FROM (
+  SELECT d.keep_id, bool_or(dup.is_attend) AS is_attend, bool_or(dup.is_claim) AS is_claim
+  FROM duplicate_participants d
+  JOIN participant dup ON dup.id = d.id
+  GROUP BY d.keep_id
+) merged
+WHERE p.id = merged.keep_id;
+
+DELETE FROM participant p USING duplicate_participants d WHERE p.id = d.id;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'participant_event_user_key') THEN
+    ALTER TABLE participant ADD CONSTRAINT participant_event_user_key UNIQUE (event_id, user_id);
+  END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS profiles_wallet_address_lower_key ON profiles (LOWER(wallet_address));
@@ -0,0 +1,3 @@
+-- How a claim was verified before is_claim was set, and the claim tx when there was one
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS claim_source text;
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS claim_transaction_hash text;
@@ -0,0 +1,3 @@
+-- Claim window set at settlement, and participants whose window closed unclaimed
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS claim_deadline timestamptz;
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS claim_expired boolean NOT NULL DEFAULT false;
@@ -0,0 +1,17 @@
+-- Reward owed to each attendee once an event settles, and yield earned by event vaults
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS reward_amount numeric;
+
+CREATE TABLE IF NOT EXISTS vault_yield_records (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL REFERENCES events_onchain(event_id),
+  vault_address text NOT NULL,
+  deposit_amount numeric NOT NULL,
+  deposit_transaction_hash text NOT NULL,
+  deposit_time timestamptz NOT NULL DEFAULT now(),
+  yield_protocol_used text NOT NULL,
+  yield_amount numeric,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT vault_yield_records_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS vault_yield_records_event_id_idx ON vault_yield_records (event_id);
@@ -0,0 +1,2 @@
+-- Signed check-in QR payload, created on first ticket lookup and reused afterwards
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS qr_payload text;
@@ -0,0 +1,3 @@
+-- Organizer-only notes and custom fields on each registration
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS notes text;
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS custom_fields jsonb NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,16 @@
+-- Stake refunds owed to participants of voided events
+CREATE TABLE IF NOT EXISTS refunds (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL REFERENCES events_onchain(event_id),
+  wallet_address text NOT NULL,
+  amount numeric NOT NULL,
+  status text NOT NULL DEFAULT 'PENDING',
+  transaction_hash text,
+  source text,
+  refunded_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT refunds_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS refunds_event_wallet_key ON refunds (event_id, LOWER(wallet_address));
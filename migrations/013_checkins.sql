@@ -0,0 +1,16 @@
+-- Check-ins recorded at the door. Deployments that predate migrations already have the
+-- table, possibly in an older shape.
+CREATE TABLE IF NOT EXISTS checkins (
+  id uuid NOT NULL DEFAULT gen_random_uuid(),
+  event_id bigint NOT NULL,
+  user_address text NOT NULL,
+  qr_data text NOT NULL DEFAULT '',
+  checked_in_at timestamptz NOT NULL DEFAULT now(),
+  is_validated boolean NOT NULL DEFAULT false,
+  validated_at timestamptz,
+  validated_by text,
+  CONSTRAINT checkins_pkey PRIMARY KEY (id)
+);
+
+-- Older shapes call the check-in time checkin_time; the list is sorted on checked_in_at
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS checked_in_at timestamptz;
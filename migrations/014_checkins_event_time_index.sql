@@ -0,0 +1,2 @@
+-- Serves the paginated check-in list and the organizer's since= polling
+CREATE INDEX IF NOT EXISTS checkins_event_checked_in_at_idx ON checkins (event_id, checked_in_at DESC);
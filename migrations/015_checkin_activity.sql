@@ -0,0 +1,11 @@
+-- Ordered feed of check-in activity per event, replayed to stream clients that reconnect
+CREATE TABLE IF NOT EXISTS checkin_activity (
+  id bigserial PRIMARY KEY,
+  event_id bigint NOT NULL,
+  kind text NOT NULL,
+  participant_id text,
+  wallet_address text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checkin_activity_event_id_idx ON checkin_activity (event_id, id);
@@ -0,0 +1,4 @@
+-- Record who admitted each attendee, from where and on which device
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS checked_by text;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS ip_address text;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS device_label text;
@@ -0,0 +1,5 @@
+-- Offline scanner sync: client-generated IDs make retried uploads idempotent
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS client_uuid uuid;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS device_id text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS checkins_client_uuid_key ON checkins (client_uuid) WHERE client_uuid IS NOT NULL;
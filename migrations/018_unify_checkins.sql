@@ -0,0 +1,43 @@
+-- Bring checkins in line with the canonical shape shared by live scans, offline sync
+-- and QR validation. Older deployments created checkins with a different shape; the
+-- block below copies the legacy columns (wallet_address, qr_code_data, checkin_time)
+-- into their canonical names. The legacy columns are left in place for one release and
+-- then dropped.
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS user_id uuid REFERENCES profiles(id);
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS user_address text;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS qr_data text;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS checked_in_at timestamptz;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS is_validated boolean;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS validated_at timestamptz;
+ALTER TABLE checkins ADD COLUMN IF NOT EXISTS validated_by text;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'checkins' AND column_name = 'wallet_address') THEN
+    UPDATE checkins SET user_address = COALESCE(user_address, wallet_address);
+  END IF;
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'checkins' AND column_name = 'qr_code_data') THEN
+    UPDATE checkins SET qr_data = COALESCE(qr_data, qr_code_data);
+  END IF;
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'checkins' AND column_name = 'checkin_time') THEN
+    UPDATE checkins SET checked_in_at = COALESCE(checked_in_at, checkin_time);
+  END IF;
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'checkins' AND column_name = 'event_id' AND data_type <> 'bigint') THEN
+    ALTER TABLE checkins ALTER COLUMN event_id TYPE bigint USING event_id::bigint;
+  END IF;
+END $$;
+
+UPDATE checkins ck SET user_id = pr.id
+FROM profiles pr
+WHERE ck.user_id IS NULL AND LOWER(pr.wallet_address) = LOWER(ck.user_address);
+
+UPDATE checkins SET qr_data = '' WHERE qr_data IS NULL;
+UPDATE checkins SET checked_in_at = now() WHERE checked_in_at IS NULL;
+UPDATE checkins SET is_validated = false WHERE is_validated IS NULL;
+
+ALTER TABLE checkins ALTER COLUMN qr_data SET DEFAULT '';
+ALTER TABLE checkins ALTER COLUMN qr_data SET NOT NULL;
+ALTER TABLE checkins ALTER COLUMN checked_in_at SET DEFAULT now();
+ALTER TABLE checkins ALTER COLUMN checked_in_at SET NOT NULL;
+ALTER TABLE checkins ALTER COLUMN is_validated SET DEFAULT false;
+ALTER TABLE checkins ALTER COLUMN is_validated SET NOT NULL;
@@ -0,0 +1,5 @@
+-- Events can require rotating time-based QR codes instead of static tickets
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS rotating_qr boolean NOT NULL DEFAULT false;
+
+-- Per-participant seed for rotating codes; never returned by the API
+ALTER TABLE participant ADD COLUMN IF NOT EXISTS qr_secret bytea;
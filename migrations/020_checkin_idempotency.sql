@@ -0,0 +1,9 @@
+-- Responses of check-in requests sent with an idempotency key, replayed on retry
+CREATE TABLE IF NOT EXISTS checkin_idempotency (
+  event_id bigint NOT NULL,
+  key text NOT NULL,
+  status_code integer NOT NULL,
+  response jsonb NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT checkin_idempotency_pkey PRIMARY KEY (event_id, key)
+);
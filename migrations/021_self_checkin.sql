@@ -0,0 +1,7 @@
+-- Events choose between staff scanning attendee tickets and attendees scanning a venue code
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS checkin_mode text NOT NULL DEFAULT 'staff_scan';
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS venue_code text;
+
+ALTER TABLE events_metadata DROP CONSTRAINT IF EXISTS events_metadata_checkin_mode_check;
+ALTER TABLE events_metadata ADD CONSTRAINT events_metadata_checkin_mode_check
+  CHECK (checkin_mode IN ('staff_scan', 'self_service'));
@@ -0,0 +1,19 @@
+-- Per check-in history of validations, rejections and undos, kept after the check-in
+-- row itself is deleted so disputes can be traced
+CREATE TABLE IF NOT EXISTS checkin_audit (
+  id bigserial PRIMARY KEY,
+  checkin_id uuid NOT NULL,
+  event_id bigint NOT NULL,
+  action text NOT NULL CHECK (action IN ('validated', 'rejected', 'undone')),
+  actor text NOT NULL,
+  previous_validated boolean NOT NULL,
+  previous_validated_at timestamptz,
+  previous_validated_by text,
+  reason text,
+  device_label text,
+  ip_address text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checkin_audit_checkin_id_idx ON checkin_audit (checkin_id, id);
+CREATE INDEX IF NOT EXISTS checkin_audit_event_id_idx ON checkin_audit (event_id, created_at);
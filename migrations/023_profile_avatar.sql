@@ -0,0 +1,4 @@
+-- Profile pictures: avatar_url is what clients display, avatar_key names the uploaded
+-- file in storage (NULL when the URL points somewhere we do not host)
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatar_url text;
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatar_key text;
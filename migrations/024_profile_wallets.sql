@@ -0,0 +1,10 @@
+-- Extra wallets linked to a profile. The profile's own wallet_address stays its primary
+-- wallet and is not listed here. Linked wallets keep their own profiles row so their
+-- participant and stake records still match the wallet that staked on-chain.
+CREATE TABLE IF NOT EXISTS profile_wallets (
+  wallet_address text PRIMARY KEY CHECK (wallet_address = LOWER(wallet_address)),
+  profile_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+  linked_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS profile_wallets_profile_id_idx ON profile_wallets (profile_id);
@@ -0,0 +1,3 @@
+-- Set when the owner deleted their profile. Personal fields are cleared; the row stays
+-- because participant and stake records reference it.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
@@ -0,0 +1,14 @@
+-- Email verification: only the SHA-256 of each emailed token is stored. A token confirms
+-- the address it was issued for, so changing the email makes older tokens useless.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+  token_hash bytea PRIMARY KEY,
+  profile_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+  email text NOT NULL,
+  expires_at timestamptz NOT NULL,
+  used_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS email_verifications_profile_id_idx ON email_verifications (profile_id);
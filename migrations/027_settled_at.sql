@@ -0,0 +1,5 @@
+-- When the event was settled, for organizer track records. Events settled before this
+-- column existed use their last update as the best available estimate.
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settled_at timestamptz;
+
+UPDATE events_metadata SET settled_at = updated_at WHERE status = 'SETTLED' AND settled_at IS NULL;
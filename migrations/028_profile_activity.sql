@@ -0,0 +1,16 @@
+-- Activity tracking: when a profile last made an authenticated request (updated at most
+-- every 10 minutes), and a compact log of sign-ins
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS last_active_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS profiles_last_active_at_idx ON profiles (last_active_at);
+
+CREATE TABLE IF NOT EXISTS login_events (
+  id bigserial PRIMARY KEY,
+  wallet_address text NOT NULL,
+  ip_address text,
+  user_agent text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS login_events_created_at_idx ON login_events (created_at);
+CREATE INDEX IF NOT EXISTS login_events_wallet_idx ON login_events (LOWER(wallet_address), created_at);
@@ -0,0 +1,4 @@
+-- Attendance reputation, recomputed nightly from settled events. A null score means the
+-- profile does not have enough settled events yet.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS reputation_score double precision;
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS reputation_updated_at timestamptz;
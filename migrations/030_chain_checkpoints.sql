@@ -0,0 +1,7 @@
+-- Last block each built-in chain watcher has fully processed, so it resumes where it
+-- stopped after a restart
+CREATE TABLE IF NOT EXISTS chain_checkpoints (
+  name text PRIMARY KEY,
+  block_number bigint NOT NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
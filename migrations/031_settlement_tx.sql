@@ -0,0 +1,5 @@
+-- The verified settlement transaction of each settled event, and why a confirmation was
+-- held back for admin review when the chain disagreed with the submitted attendee list
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settlement_tx_hash text;
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settlement_block bigint;
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settlement_review text;
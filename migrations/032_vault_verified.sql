@@ -0,0 +1,3 @@
+-- Whether the event's vault address is the one the factory deployed for it. Rows copied
+-- from the factory's own logs are verified on insert; others are checked on first use.
+ALTER TABLE events_onchain ADD COLUMN IF NOT EXISTS vault_verified boolean NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- The server-side signer an organizer delegated settlement to, by its address so that
+-- rotating SETTLEMENT_PRIVATE_KEY revokes every delegation
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settlement_delegate text;
+ALTER TABLE events_metadata ADD COLUMN IF NOT EXISTS settlement_delegated_at timestamptz;
@@ -0,0 +1,8 @@
+-- Rows the factory watcher copies from a log stay pending until the log's block is
+-- FACTORY_CONFIRMATIONS deep, and are removed if a reorg drops the block. Rows from the
+-- external indexer and from before this migration are final.
+ALTER TABLE events_onchain ADD COLUMN IF NOT EXISTS finalized boolean NOT NULL DEFAULT true;
+ALTER TABLE events_onchain ADD COLUMN IF NOT EXISTS block_number bigint;
+ALTER TABLE events_onchain ADD COLUMN IF NOT EXISTS block_hash text;
+
+CREATE INDEX IF NOT EXISTS events_onchain_pending_idx ON events_onchain (block_number) WHERE NOT finalized;
@@ -1,27 +1,107 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-type CheckIn struct {
-	ID        string    `json:"id" db:"id"`
-	EventID   string    `json:"event_id" db:"event_id"`
-	UserAddress string  `json:"user_address" db:"user_address"`
-	QRData    string    `json:"qr_data" db:"qr_data"`
-	CheckedInAt time.Time `json:"checked_in_at" db:"checked_in_at"`
-	IsValidated bool   `json:"is_validated" db:"is_validated"`
-	ValidatedAt  *time.Time `json:"validated_at,omitempty" db:"validated_at"`
-	ValidatedBy  string  `json:"validated_by,omitempty" db:"validated_by"`
+// Checkin is a row of the checkins table: one attendee admitted at the door, by live
+// scan, manual check-in or offline sync, plus its later validation by the organizer
+type Checkin struct {
+	ID            string     `json:"id" db:"id"`
+	EventID       int64      `json:"event_id" db:"event_id"`
+	UserID        *uuid.UUID `json:"user_id" db:"user_id"`
+	WalletAddress string     `json:"wallet_address" db:"user_address"`
+	QRData        string     `json:"qr_data" db:"qr_data"`
+	CheckedInAt   time.Time  `json:"checked_in_at" db:"checked_in_at"`
+	IsValidated   bool       `json:"is_validated" db:"is_validated"`
+	ValidatedAt   *time.Time `json:"validated_at,omitempty" db:"validated_at"`
+	ValidatedBy   *string    `json:"validated_by,omitempty" db:"validated_by"`
+	// Scanner details, only returned to the event organizer
+	CheckedBy   *string    `json:"checked_by,omitempty" db:"checked_by"`
+	IPAddress   *string    `json:"ip_address,omitempty" db:"ip_address"`
+	DeviceLabel *string    `json:"device_label,omitempty" db:"device_label"`
+	DeviceID    *string    `json:"device_id,omitempty" db:"device_id"`
+	ClientUUID  *uuid.UUID `json:"client_uuid,omitempty" db:"client_uuid"`
+}
+
+// HideScanner clears the fields that identify the scanning device and its network
+func (c *Checkin) HideScanner() {
+	c.CheckedBy, c.IPAddress, c.DeviceLabel, c.DeviceID = nil, nil, nil, nil
+}
+
+// Redact hides the wallet, QR data and IP address of a check-in by a deleted profile
+func (c *Checkin) Redact() {
+	c.WalletAddress, c.QRData, c.IPAddress = RedactedWallet, "", nil
+}
+
+// MarshalJSON keeps the user_address alias of wallet_address for clients built against
+// the old check-in shape.
+// Deprecated alias: remove user_address in the next release.
+func (c Checkin) MarshalJSON() ([]byte, error) {
+	type checkin Checkin
+	return json.Marshal(struct {
+		checkin
+		UserAddress string `json:"user_address"`
+	}{checkin(c), c.WalletAddress})
 }
 
-type CheckInRequest struct {
-	EventID    string `json:"event_id" binding:"required"`
-	QRData     string `json:"qr_data" binding:"required"`
-	UserAddress string `json:"user_address" binding:"required"`
+// CreateCheckinRequest describes a check-in to record. It is filled in by the check-in,
+// scan and sync handlers; CheckedBy and IPAddress come from the authenticated request.
+type CreateCheckinRequest struct {
+	EventID       int64      `json:"event_id"`
+	WalletAddress string     `json:"wallet_address"`
+	QRData        string     `json:"qr_data"`
+	CheckedBy     string     `json:"checked_by"`
+	IPAddress     string     `json:"ip_address"`
+	DeviceLabel   string     `json:"device_label"`
+	DeviceID      string     `json:"device_id"`
+	ClientUUID    *uuid.UUID `json:"client_uuid"`
 }
 
+// ValidateCheckInRequest validates a single check-in (CheckInID) or approves a batch from
+// the self check-in queue (CheckInIDs)
 type ValidateCheckInRequest struct {
-	CheckInID string `json:"checkin_id" binding:"required"`
-	IsValid   bool   `json:"is_valid"`
-}
\ No newline at end of file
+	CheckInID   string   `json:"checkin_id"`
+	CheckInIDs  []string `json:"checkin_ids" binding:"max=500"`
+	IsValid     bool     `json:"is_valid"`
+	Reason      string   `json:"reason" binding:"max=500"`
+	DeviceLabel string   `json:"device_label" binding:"max=100"`
+}
+
+// Check-in audit actions
+const (
+	CheckinAuditValidated = "validated"
+	CheckinAuditRejected  = "rejected"
+	CheckinAuditUndone    = "undone"
+)
+
+// CheckinAuditEntry is one change to a check-in's validation, with the state it replaced
+type CheckinAuditEntry struct {
+	ID                  int64      `json:"id" db:"id"`
+	CheckinID           string     `json:"checkin_id" db:"checkin_id"`
+	EventID             int64      `json:"event_id" db:"event_id"`
+	Action              string     `json:"action" db:"action"`
+	Actor               string     `json:"actor" db:"actor"`
+	PreviousValidated   bool       `json:"previous_validated" db:"previous_validated"`
+	PreviousValidatedAt *time.Time `json:"previous_validated_at,omitempty" db:"previous_validated_at"`
+	PreviousValidatedBy *string    `json:"previous_validated_by,omitempty" db:"previous_validated_by"`
+	Reason              *string    `json:"reason,omitempty" db:"reason"`
+	DeviceLabel         *string    `json:"device_label,omitempty" db:"device_label"`
+	IPAddress           *string    `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// GetCheckinsRequest for querying an event's check-ins (event ID comes from the path).
+// Since and Until are RFC 3339 timestamps.
+type GetCheckinsRequest struct {
+	EventID   int64      `form:"-"`
+	Validated *bool      `form:"validated"`
+	Since     *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Search    string     `form:"search"`
+	Page      int        `form:"page,default=1"`
+	Limit     int        `form:"limit,default=50"`
+}
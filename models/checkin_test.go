@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCheckinJSON(t *testing.T) {
+	scanner, ip, label := "0xorganizer", "203.0.113.7", "Door 1"
+	checkin := Checkin{
+		ID:            "c1",
+		EventID:       7,
+		WalletAddress: "0xabc",
+		QRData:        "payload",
+		CheckedInAt:   time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC),
+		CheckedBy:     &scanner,
+		IPAddress:     &ip,
+		DeviceLabel:   &label,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Checkin)
+		present map[string]any
+		absent  []string
+	}{
+		{
+			name:    "organizer view",
+			modify:  func(*Checkin) {},
+			present: map[string]any{"wallet_address": "0xabc", "user_address": "0xabc", "checked_by": scanner, "ip_address": ip, "device_label": label},
+		},
+		{
+			name:    "scanner hidden",
+			modify:  (*Checkin).HideScanner,
+			present: map[string]any{"wallet_address": "0xabc", "qr_data": "payload"},
+			absent:  []string{"checked_by", "ip_address", "device_label", "device_id"},
+		},
+		{
+			name:    "deleted profile",
+			modify:  (*Checkin).Redact,
+			present: map[string]any{"wallet_address": RedactedWallet, "user_address": RedactedWallet, "qr_data": "", "checked_by": scanner},
+			absent:  []string{"ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		c := checkin
+		tt.modify(&c)
+		encoded, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", tt.name, err)
+		}
+		var fields map[string]any
+		json.Unmarshal(encoded, &fields)
+		for key, want := range tt.present {
+			if fields[key] != want {
+				t.Errorf("%s: %s = %v, want %v", tt.name, key, fields[key], want)
+			}
+		}
+		for _, key := range tt.absent {
+			if _, ok := fields[key]; ok {
+				t.Errorf("%s: %s is present, want it omitted", tt.name, key)
+			}
+		}
+	}
+}
@@ -15,6 +15,13 @@ const (
 	StatusVoided = "VOIDED"
 )
 
+// Check-in modes: staff scan attendee tickets, or attendees scan a venue code and the
+// organizer approves the result
+const (
+	CheckinModeStaffScan   = "staff_scan"
+	CheckinModeSelfService = "self_service"
+)
+
 // EventOnchain represents on-chain event data (matches new database schema)
 type EventOnchain struct {
 	EventID              int64      `json:"event_id" db:"event_id"`
@@ -33,6 +40,8 @@ type EventMetadata struct {
 	Status     string    `json:"status" db:"status"`
 	Description *string   `json:"description,omitempty" db:"description"`
 	ImageURL   *string   `json:"image_url,omitempty" db:"image_url"`
+	RotatingQR bool      `json:"rotating_qr" db:"rotating_qr"`
+	CheckinMode string   `json:"checkin_mode" db:"checkin_mode"`
 }
 
 // EventDetail combines on-chain and off-chain data
@@ -47,9 +56,24 @@ type EventDetail struct {
 	EventDate          int64  `json:"event_date"`
 	Title              string `json:"title"`
 	Status             string `json:"status"`
+	DerivedPhase       string `json:"derived_phase,omitempty"` // status the on-chain timestamps imply; see DerivePhase
+	StatusMismatch     bool   `json:"status_mismatch"`
 	Description        *string `json:"description,omitempty"`
 	ImageURL           *string `json:"image_url,omitempty"`
 	OrganizerName      string `json:"organizer_name"`
+	OrganizerAvatarURL *string `json:"organizer_avatar_url"`
+	ClaimDeadline      *time.Time `json:"claim_deadline,omitempty"`
+	CanRegister        *bool  `json:"can_register,omitempty"` // detail view only
+	VaultVerified      *bool  `json:"vault_verified,omitempty"` // false means on-chain reads were skipped
+	Counts             *EventCounts `json:"counts,omitempty"`
+}
+
+// EventCounts summarizes registration and check-in progress (detail view only)
+type EventCounts struct {
+	Registered int `json:"registered"`
+	Attended   int `json:"attended"`
+	Validated  int `json:"validated"`
+	Pending    int `json:"pending"`
 }
 
 // CreateEventMetadataRequest for creating off-chain metadata
@@ -76,6 +100,7 @@ type VaultYieldRecord struct {
 	DepositTransactionHash  string    `json:"deposit_transaction_hash" db:"deposit_transaction_hash"`
 	DepositTime             time.Time `json:"deposit_time" db:"deposit_time"`
 	YieldProtocolUsed       string    `json:"yield_protocol_used" db:"yield_protocol_used"`
+	YieldAmount             *string   `json:"yield_amount" db:"yield_amount"` // Earned on top of the deposit, once known
 	CreatedAt               time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -119,4 +144,64 @@ type EventStats struct {
 type EventWithStats struct {
 	*Event
 	Stats *EventStats `json:"stats,omitempty"`
-}
\ No newline at end of file
+}
+// Waitlist entry statuses
+const (
+	WaitlistWaiting    = "WAITING"
+	WaitlistPromoted   = "PROMOTED"
+	WaitlistRegistered = "REGISTERED"
+	WaitlistExpired    = "EXPIRED"
+)
+
+// WaitlistEntry is a wallet queued for a full event
+type WaitlistEntry struct {
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	EventID            int64      `json:"event_id" db:"event_id"`
+	WalletAddress      string     `json:"wallet_address" db:"wallet_address"`
+	Status             string     `json:"status" db:"status"`
+	Position           int        `json:"position,omitempty"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	PromotedAt         *time.Time `json:"promoted_at,omitempty" db:"promoted_at"`
+	PromotionExpiresAt *time.Time `json:"promotion_expires_at,omitempty" db:"promotion_expires_at"`
+	ReputationTier     string     `json:"reputation_tier"`
+}
+
+// Refund statuses
+const (
+	RefundPending  = "PENDING"
+	RefundRefunded = "REFUNDED"
+)
+
+// Refund is a stake owed back to a participant of a voided event
+type Refund struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	EventID         int64      `json:"event_id" db:"event_id"`
+	WalletAddress   string     `json:"wallet_address" db:"wallet_address"`
+	Amount          string     `json:"amount" db:"amount"`
+	Status          string     `json:"status" db:"status"`
+	TransactionHash *string    `json:"transaction_hash" db:"transaction_hash"`
+	Source          *string    `json:"source" db:"source"`
+	RefundedAt      *time.Time `json:"refunded_at" db:"refunded_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OrganizerStats is an organizer's public track record
+type OrganizerStats struct {
+	EventsHosted          int      `json:"events_hosted"`
+	TotalParticipants     int      `json:"total_participants"`
+	AverageAttendanceRate *float64 `json:"average_attendance_rate"` // over settled events with participants
+	SettledEvents         int      `json:"settled_events"`
+	SettledOnTime         int      `json:"settled_on_time"`
+}
+
+// OrganizerEvent is an event summary on the organizer page
+type OrganizerEvent struct {
+	EventID     int64   `json:"event_id"`
+	Title       string  `json:"title"`
+	Status      string  `json:"status"`
+	EventDate   int64   `json:"event_date"`
+	StakeAmount string  `json:"stake_amount"`
+	ImageURL    *string `json:"image_url,omitempty"`
+	Registered  int     `json:"registered"`
+	Attended    int     `json:"attended"`
+}
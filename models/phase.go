@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// DerivePhase returns the status the chain puts an event in at now. The vault's
+// registration_deadline and event_date are the truth for the timed phases: registration
+// is open up to and including the deadline, closed until the event starts and live from
+// event_date on. A settlement always wins over the timestamps.
+//
+// VOIDED is never derived, since cancelling is a decision and not a point in time; see
+// StatusMismatch for how a recorded VOIDED or SETTLED status is treated.
+func DerivePhase(now time.Time, onchain EventOnchain, settled bool) string {
+	switch {
+	case settled:
+		return StatusSettled
+	case now.Unix() <= onchain.RegistrationDeadline:
+		return StatusRegistrationOpen
+	case now.Unix() < onchain.EventDate:
+		return StatusRegistrationClosed
+	default:
+		return StatusLive
+	}
+}
+
+// StatusMismatch reports whether a recorded status disagrees with the derived phase.
+// VOIDED and SETTLED always win: an event recorded as either never mismatches.
+func StatusMismatch(status, phase string) bool {
+	if status == StatusVoided || status == StatusSettled {
+		return false
+	}
+	return status != phase
+}
+
+// PhaseAdvance returns the status an event recorded as status should be moved to so it
+// catches up with phase, stepping through the status machine one legal transition at a
+// time. Only the timed phases are advanced to; settling stays with the settlement flow and
+// no status is ever moved backwards. ok is false when there is nothing to do.
+func PhaseAdvance(status, phase string) (next string, ok bool) {
+	if !StatusMismatch(status, phase) || phase == StatusSettled {
+		return "", false
+	}
+	for _, step := range []string{StatusRegistrationClosed, StatusLive} {
+		if CanTransition(status, step) {
+			return step, phaseOrder[step] <= phaseOrder[phase]
+		}
+	}
+	return "", false
+}
+
+// phaseOrder ranks the timed phases so a status is only ever advanced
+var phaseOrder = map[string]int{
+	StatusRegistrationOpen:   0,
+	StatusRegistrationClosed: 1,
+	StatusLive:               2,
+}
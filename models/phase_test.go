@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDerivePhase(t *testing.T) {
+	onchain := EventOnchain{RegistrationDeadline: 1000, EventDate: 2000}
+
+	tests := []struct {
+		name    string
+		now     int64
+		settled bool
+		want    string
+	}{
+		{"before the deadline", 999, false, StatusRegistrationOpen},
+		{"at the deadline", 1000, false, StatusRegistrationOpen},
+		{"after the deadline", 1001, false, StatusRegistrationClosed},
+		{"just before the event", 1999, false, StatusRegistrationClosed},
+		{"at the event date", 2000, false, StatusLive},
+		{"long after the event", 1_000_000, false, StatusLive},
+		{"settled before the deadline", 999, true, StatusSettled},
+		{"settled while closed", 1500, true, StatusSettled},
+		{"settled while live", 2000, true, StatusSettled},
+	}
+	for _, tt := range tests {
+		if got := DerivePhase(time.Unix(tt.now, 0), onchain, tt.settled); got != tt.want {
+			t.Errorf("%s: DerivePhase = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// An event starting at its deadline skips the closed phase
+	sameTime := EventOnchain{RegistrationDeadline: 1000, EventDate: 1000}
+	if got := DerivePhase(time.Unix(1001, 0), sameTime, false); got != StatusLive {
+		t.Errorf("DerivePhase after a deadline equal to the event date = %s, want %s", got, StatusLive)
+	}
+	// Unset timestamps read as long past
+	if got := DerivePhase(time.Unix(1, 0), EventOnchain{}, false); got != StatusLive {
+		t.Errorf("DerivePhase without timestamps = %s, want %s", got, StatusLive)
+	}
+}
+
+func TestStatusMismatch(t *testing.T) {
+	phases := []string{StatusRegistrationOpen, StatusRegistrationClosed, StatusLive, StatusSettled}
+
+	for _, status := range []string{StatusVoided, StatusSettled} {
+		for _, phase := range phases {
+			if StatusMismatch(status, phase) {
+				t.Errorf("StatusMismatch(%s, %s) = true, want %s to win", status, phase, status)
+			}
+		}
+	}
+
+	for _, status := range []string{StatusRegistrationOpen, StatusRegistrationClosed, StatusLive} {
+		for _, phase := range phases {
+			if got, want := StatusMismatch(status, phase), status != phase; got != want {
+				t.Errorf("StatusMismatch(%s, %s) = %t, want %t", status, phase, got, want)
+			}
+		}
+	}
+}
+
+func TestPhaseAdvance(t *testing.T) {
+	tests := []struct {
+		status, phase string
+		next          string
+		ok            bool
+	}{
+		{StatusRegistrationOpen, StatusRegistrationOpen, "", false},
+		{StatusRegistrationOpen, StatusRegistrationClosed, StatusRegistrationClosed, true},
+		// Two phases behind: the first step is through REGISTRATION_CLOSED
+		{StatusRegistrationOpen, StatusLive, StatusRegistrationClosed, true},
+		{StatusRegistrationClosed, StatusLive, StatusLive, true},
+		{StatusRegistrationClosed, StatusRegistrationClosed, "", false},
+		// Statuses are never moved backwards, e.g. after a deadline was extended
+		{StatusRegistrationClosed, StatusRegistrationOpen, "", false},
+		{StatusLive, StatusRegistrationOpen, "", false},
+		{StatusLive, StatusRegistrationClosed, "", false},
+		// Settling is left to the settlement flow
+		{StatusLive, StatusSettled, "", false},
+		{StatusRegistrationOpen, StatusSettled, "", false},
+		{StatusSettled, StatusLive, "", false},
+		{StatusVoided, StatusLive, "", false},
+	}
+	for _, tt := range tests {
+		next, ok := PhaseAdvance(tt.status, tt.phase)
+		if ok != tt.ok || (ok && next != tt.next) {
+			t.Errorf("PhaseAdvance(%s, %s) = %q, %t; want %q, %t", tt.status, tt.phase, next, ok, tt.next, tt.ok)
+		}
+	}
+}
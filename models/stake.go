@@ -50,35 +50,12 @@ type CreateStakeRequest struct {
 	CreatedAtTimestamp    time.Time `json:"created_at_timestamp" binding:"required"`
 }
 
-// UpdateStakeRequest for updating stake information
+// UpdateStakeRequest for updating stake information; omitted fields are left unchanged
 type UpdateStakeRequest struct {
-	IsAttended            bool      `json:"is_attended"`
-	RewardAmount          string    `json:"reward_amount"`
-	Claimed               bool      `json:"claimed"`
-	ClaimedTransactionHash string   `json:"claimed_transaction_hash"`
-}
-
-// Checkin represents QR code check-in records
-type Checkin struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	EventID      int64     `json:"event_id" db:"event_id"`
-	UserID       uuid.UUID `json:"user_id" db:"user_id"`
-	WalletAddress string   `json:"wallet_address" db:"wallet_address"`
-	QRCodeData   string    `json:"qr_code_data" db:"qr_code_data"`
-	CheckinTime  time.Time `json:"checkin_time" db:"checkin_time"`
-	CheckedBy    string    `json:"checked_by" db:"checked_by"`
-	IPAddress    string    `json:"ip_address" db:"ip_address"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-}
-
-// CreateCheckinRequest for creating a new check-in
-type CreateCheckinRequest struct {
-	EventID      int64     `json:"event_id" binding:"required"`
-	UserID       uuid.UUID `json:"user_id" binding:"required"`
-	WalletAddress string   `json:"wallet_address" binding:"required"`
-	QRCodeData   string    `json:"qr_code_data" binding:"required"`
-	CheckedBy    string    `json:"checked_by" binding:"required"`
-	IPAddress    string    `json:"ip_address"`
+	IsAttended            *bool     `json:"is_attended"`
+	RewardAmount          *string   `json:"reward_amount"`
+	Claimed               *bool     `json:"claimed"`
+	ClaimedTransactionHash *string  `json:"claimed_transaction_hash"`
 }
 
 // EventParticipant represents participant information for events
@@ -93,12 +70,54 @@ type EventParticipant struct {
 	CheckedInAt   *time.Time `json:"checked_in_at"`
 }
 
-// GetEventParticipantsRequest for querying event participants
+// GetEventParticipantsRequest for querying event participants (event ID comes from the path)
 type GetEventParticipantsRequest struct {
-	EventID     int64 `form:"event_id" binding:"required"`
-	AttendedOnly bool `form:"attended_only"`
-	Page        int   `form:"page,default=1"`
-	Limit       int   `form:"limit,default=50"`
+	EventID      int64  `form:"-"`
+	AttendedOnly bool   `form:"attended_only"`
+	ClaimedOnly  bool   `form:"claimed_only"`
+	Search       string `form:"search"`
+	Sort         string `form:"sort,default=registered_at"`
+	Order        string `form:"order,default=desc"`
+	Page         int    `form:"page,default=1"`
+	Limit        int    `form:"limit,default=50"`
+}
+
+// ParticipantListItem is a participant row joined with profile information
+type ParticipantListItem struct {
+	ID            string    `json:"id"`
+	EventID       int64     `json:"event_id"`
+	UserID        string    `json:"user_id"`
+	IsAttend      bool      `json:"is_attend"`
+	IsClaim       bool      `json:"is_claim"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	WalletAddress string    `json:"user_address"`
+	Email         *string   `json:"email,omitempty"`
+	Name          *string   `json:"name"`
+	DisplayName   string    `json:"display_name"` // name, else ENS/Basename, else short address
+	AvatarURL     *string   `json:"avatar_url"`
+	StakeAmount   *string   `json:"stake_amount"`
+	TransactionHash *string `json:"transaction_hash"`
+	RewardAmount  *string   `json:"reward_amount"`
+	ReputationTier string   `json:"reputation_tier"`
+	Notes         *string   `json:"notes,omitempty"`
+	CustomFields  map[string]interface{} `json:"custom_fields,omitempty"`
+	Balance       *WalletTokenBalance `json:"balance,omitempty"` // only with include=balances
+}
+
+// WalletTokenBalance is a wallet's live balance of an event's stake token
+type WalletTokenBalance struct {
+	Raw       string `json:"raw"`
+	Formatted string `json:"formatted"`
+	// CoversStake is whether the wallet holds at least the event's stake amount
+	CoversStake bool `json:"covers_stake"`
+}
+
+// NoShowParticipant is a registered participant who did not check in
+type NoShowParticipant struct {
+	WalletAddress string  `json:"wallet_address"`
+	Name          *string `json:"name"`
+	StakeAmount   string  `json:"stake_amount"`
 }
 
 // SettleEventRequest for settling an event with attended participants
@@ -128,4 +147,108 @@ type StakesStats struct {
 	TotalRewards           string  `json:"total_rewards"`
 	TotalClaimed           string  `json:"total_claimed"`
 	AttendanceRate         float64 `json:"attendance_rate"`
-}
\ No newline at end of file
+	EventStatus            string  `json:"event_status"`
+	IsSettled              bool    `json:"is_settled"`
+}
+// ClaimableReward is a settled event where the wallet attended and can still claim
+type ClaimableReward struct {
+	EventID               int64      `json:"event_id"`
+	Title                 string     `json:"title"`
+	ImageURL              *string    `json:"image_url,omitempty"`
+	EventDate             int64      `json:"event_date"`
+	VaultAddress          string     `json:"vault_address"`
+	RewardAmount          *string    `json:"reward_amount"`
+	RewardAmountFormatted *string    `json:"reward_amount_formatted"`
+	ClaimDeadline         *time.Time `json:"claim_deadline"`
+	OnchainClaimable      *string    `json:"onchain_claimable"` // what the vault would pay now, null when it could not be read
+	Mismatch              bool       `json:"mismatch"`          // the vault and reward_amount disagree beyond dust
+}
+
+// LockedStake is a stake held in the vault of an event that has not settled or been voided
+type LockedStake struct {
+	EventID              int64  `json:"event_id"`
+	Title                string `json:"title"`
+	EventDate            int64  `json:"event_date"`
+	Status               string `json:"status"`
+	StakeAmount          string `json:"stake_amount"`
+	StakeAmountFormatted string `json:"stake_amount_formatted"`
+}
+
+// Attendance outcomes of a history entry. Voided events are neither attended nor missed.
+const (
+	OutcomeAttended = "attended"
+	OutcomeNoShow   = "no_show"
+	OutcomeUpcoming = "upcoming"
+	OutcomeVoided   = "voided"
+)
+
+// AttendanceHistoryEntry is one event in a profile's attendance history
+type AttendanceHistoryEntry struct {
+	EventID               int64   `json:"event_id"`
+	Title                 string  `json:"title"`
+	EventDate             int64   `json:"event_date"`
+	Status                string  `json:"status"`
+	Outcome               string  `json:"outcome"`
+	StakeAmount           *string `json:"stake_amount"`
+	StakeAmountFormatted  *string `json:"stake_amount_formatted"`
+	Attended              bool    `json:"attended"`
+	RewardAmount          *string `json:"reward_amount"`
+	RewardAmountFormatted *string `json:"reward_amount_formatted"`
+	Claimed               bool    `json:"claimed"`
+}
+
+// AttendanceYear summarizes a calendar year (UTC) of attendance history. Earned is the
+// reward above the stake on settled events that paid out.
+type AttendanceYear struct {
+	Year                 int    `json:"year"`
+	EventsAttended       int    `json:"events_attended"`
+	TotalStaked          string `json:"total_staked"`
+	TotalStakedFormatted string `json:"total_staked_formatted"`
+	TotalEarned          string `json:"total_earned"`
+	TotalEarnedFormatted string `json:"total_earned_formatted"`
+}
+
+// BulkParticipantImport is a single on-chain registration pushed by the indexer
+type BulkParticipantImport struct {
+	EventID       int64     `json:"event_id"`
+	WalletAddress string    `json:"wallet_address"`
+	TxHash        string    `json:"tx_hash"`
+	Amount        string    `json:"amount"`
+	Block         int64     `json:"block"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// BulkParticipantImportRequest batches registrations for import
+type BulkParticipantImportRequest struct {
+	Atomic       bool                    `json:"atomic"`
+	Participants []BulkParticipantImport `json:"participants" binding:"required"`
+}
+
+// BulkParticipantImportResult reports the outcome for one imported row
+type BulkParticipantImportResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"` // created, duplicate or invalid
+	Error  string `json:"error,omitempty"`
+}
+
+// ParticipantStatus is a participant's own view of their registration, stake and reward
+type ParticipantStatus struct {
+	ID                     string    `json:"id"`
+	EventID                int64     `json:"event_id"`
+	UserID                 string    `json:"user_id"`
+	UserAddress            string    `json:"user_address"`
+	IsAttend               bool      `json:"is_attend"`
+	IsClaim                bool      `json:"is_claim"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+	EventStatus            string    `json:"event_status"`
+	StakeAmount            *string   `json:"stake_amount"`
+	StakeAmountFormatted   *string   `json:"stake_amount_formatted"`
+	RewardAmount           *string   `json:"reward_amount"`
+	RewardAmountFormatted  *string   `json:"reward_amount_formatted"`
+	ClaimedTransactionHash *string   `json:"claimed_transaction_hash"`
+	ClaimDeadline          *time.Time `json:"claim_deadline"`
+	ClaimExpired           bool      `json:"claim_expired"`
+	OnchainClaimable       *string   `json:"onchain_claimable"` // what the vault would pay now, null when it could not be read
+	Mismatch               bool      `json:"mismatch"`          // the vault and reward_amount disagree beyond dust
+}
@@ -0,0 +1,32 @@
+package models
+
+// statusTransitions lists the legal moves out of each event status.
+// SETTLED and VOIDED are terminal.
+var statusTransitions = map[string][]string{
+	StatusRegistrationOpen:   {StatusRegistrationClosed, StatusVoided},
+	StatusRegistrationClosed: {StatusLive, StatusVoided},
+	StatusLive:               {StatusSettled, StatusVoided},
+	StatusSettled:            {},
+	StatusVoided:             {},
+}
+
+// IsValidStatus reports whether status is one of the known event statuses
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// AllowedTransitions returns the statuses an event may move to from the given status
+func AllowedTransitions(from string) []string {
+	return append([]string{}, statusTransitions[from]...)
+}
+
+// CanTransition reports whether an event may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
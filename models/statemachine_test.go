@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	statuses := []string{StatusRegistrationOpen, StatusRegistrationClosed, StatusLive, StatusSettled, StatusVoided}
+	legal := map[[2]string]bool{
+		{StatusRegistrationOpen, StatusRegistrationClosed}: true,
+		{StatusRegistrationOpen, StatusVoided}:             true,
+		{StatusRegistrationClosed, StatusLive}:             true,
+		{StatusRegistrationClosed, StatusVoided}:           true,
+		{StatusLive, StatusSettled}:                        true,
+		{StatusLive, StatusVoided}:                         true,
+	}
+
+	// Every pair of known statuses, including staying put and leaving a terminal status
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := legal[[2]string{from, to}]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{"unknown source", "DRAFT", StatusLive},
+		{"unknown target", StatusRegistrationOpen, "DRAFT"},
+		{"empty source", "", StatusRegistrationClosed},
+		{"empty target", StatusLive, ""},
+		{"lower case", "registration_open", "registration_closed"},
+	}
+	for _, tt := range tests {
+		if CanTransition(tt.from, tt.to) {
+			t.Errorf("%s: CanTransition(%q, %q) = true, want false", tt.name, tt.from, tt.to)
+		}
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		from string
+		want []string
+	}{
+		{StatusRegistrationOpen, []string{StatusRegistrationClosed, StatusVoided}},
+		{StatusRegistrationClosed, []string{StatusLive, StatusVoided}},
+		{StatusLive, []string{StatusSettled, StatusVoided}},
+		{StatusSettled, nil},
+		{StatusVoided, nil},
+		{"DRAFT", nil},
+	}
+	for _, tt := range tests {
+		got := AllowedTransitions(tt.from)
+		if len(got) != len(tt.want) {
+			t.Errorf("AllowedTransitions(%s) = %v, want %v", tt.from, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("AllowedTransitions(%s) = %v, want %v", tt.from, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// Callers get a copy they may modify
+	AllowedTransitions(StatusLive)[0] = StatusVoided
+	if !CanTransition(StatusLive, StatusSettled) {
+		t.Error("modifying the result of AllowedTransitions changed the state machine")
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, status := range []string{StatusRegistrationOpen, StatusRegistrationClosed, StatusLive, StatusSettled, StatusVoided} {
+		if !IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%s) = false, want true", status)
+		}
+	}
+	for _, status := range []string{"", "DRAFT", "live"} {
+		if IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%q) = true, want false", status)
+		}
+	}
+}
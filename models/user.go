@@ -10,18 +10,49 @@ type Profile struct {
 	WalletAddress string    `json:"wallet_address" db:"wallet_address"`
 	Name          *string   `json:"name" db:"name"`
 	Email         *string   `json:"email" db:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	AvatarURL     *string   `json:"avatar_url" db:"avatar_url"`
 	Balance       string    `json:"balance"` // Calculated from smart contract, not stored in DB
+	CreatedAt     *time.Time `json:"created_at" db:"created_at"` // null on rows written before timestamps were kept
+	UpdatedAt     *time.Time `json:"updated_at" db:"updated_at"`
+	Complete      bool      `json:"profile_complete"`
+	LastActiveAt  *time.Time `json:"last_active_at,omitempty"` // admin listings only
+}
+
+// IsComplete reports whether the profile has a name, a verified email and an avatar
+func (p Profile) IsComplete() bool {
+	return p.Name != nil && *p.Name != "" && p.EmailVerified && p.AvatarURL != nil && *p.AvatarURL != ""
+}
+
+// RedactedWallet replaces the wallet of a deleted profile in check-in lists and exports
+const RedactedWallet = "[deleted]"
+
+// ProfileStats counts the event history of a profile and its linked wallets
+type ProfileStats struct {
+	Registered int `json:"registered"`
+	Attended   int `json:"attended"`
+	Claimed    int `json:"claimed"`
+	Organized  int `json:"organized"`
 }
 
 type CreateProfileRequest struct {
 	WalletAddress string `json:"wallet_address" binding:"required"`
 	Name          string `json:"name" binding:"required"`
 	Email         string `json:"email"`
+	AvatarURL     string `json:"avatar_url" binding:"omitempty,url"`
 }
 
 type UpdateProfileRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url" binding:"omitempty,url"`
+}
+
+// WalletLinkRequest links or unlinks a wallet, signed by that wallet
+type WalletLinkRequest struct {
+	WalletAddress string `json:"wallet_address" binding:"required"`
+	Signature     string `json:"signature" binding:"required"`
+	Timestamp     int64  `json:"timestamp" binding:"required"`
 }
 
 // Legacy User struct for backward compatibility
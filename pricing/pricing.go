@@ -0,0 +1,110 @@
+// Package pricing converts native gas costs into approximate US dollar figures.
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long an HTTPSource reuses a fetched price
+const DefaultTTL = time.Minute
+
+// Source quotes the price of the chain's native token (ETH on Base) in US dollars.
+// Implementations must be safe for concurrent use.
+type Source interface {
+	USDPrice(ctx context.Context) (float64, error)
+}
+
+// Static always quotes the same price
+type Static float64
+
+// USDPrice implements Source
+func (s Static) USDPrice(ctx context.Context) (float64, error) {
+	return float64(s), nil
+}
+
+// HTTPSource fetches the price from a Coinbase-style spot price endpoint answering
+// {"data": {"amount": "1234.56"}}, and reuses it for TTL
+type HTTPSource struct {
+	url    string
+	client *http.Client
+
+	// TTL is how long a fetched price is reused
+	TTL time.Duration
+
+	mu      sync.Mutex
+	price   float64
+	fetched time.Time
+}
+
+// NewHTTPSource creates an HTTPSource fetching url
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{url: url, client: &http.Client{Timeout: 5 * time.Second}, TTL: DefaultTTL}
+}
+
+// USDPrice implements Source. Failures are not cached.
+func (s *HTTPSource) USDPrice(ctx context.Context) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fetched.IsZero() && time.Since(s.fetched) < s.TTL {
+		return s.price, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build price request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("price source returned %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Amount string `json:"amount"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode price: %w", err)
+	}
+	price, err := strconv.ParseFloat(body.Data.Amount, 64)
+	if err != nil || price <= 0 {
+		return 0, fmt.Errorf("price source returned an invalid amount %q", body.Data.Amount)
+	}
+
+	s.price, s.fetched = price, time.Now()
+	return price, nil
+}
+
+// FromEnv returns a Static source for ETH_USD_PRICE when it is set, an HTTPSource for
+// ETH_USD_PRICE_URL otherwise, and nil when neither is configured
+func FromEnv() (Source, error) {
+	if raw := os.Getenv("ETH_USD_PRICE"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("invalid ETH_USD_PRICE %q", raw)
+		}
+		return Static(price), nil
+	}
+	if url := os.Getenv("ETH_USD_PRICE_URL"); url != "" {
+		return NewHTTPSource(url), nil
+	}
+	return nil, nil
+}
+
+// WeiToUSD converts an amount of wei into dollars at price, in dollars per ETH
+func WeiToUSD(wei *big.Int, price float64) float64 {
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return eth * price
+}
@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSourceCachesPrice(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"data":{"base":"ETH","currency":"USD","amount":"2500.50"}}`)
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL)
+	for i := 0; i < 2; i++ {
+		price, err := source.USDPrice(context.Background())
+		if err != nil || price != 2500.50 {
+			t.Fatalf("USDPrice = %v, %v; want 2500.50", price, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("fetched the price %d times, want once", requests)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("ETH_USD_PRICE", "")
+	t.Setenv("ETH_USD_PRICE_URL", "")
+	if source, err := FromEnv(); source != nil || err != nil {
+		t.Errorf("FromEnv = %v, %v; want nothing configured", source, err)
+	}
+
+	t.Setenv("ETH_USD_PRICE", "3000")
+	if source, err := FromEnv(); err != nil || source != Static(3000) {
+		t.Errorf("FromEnv = %v, %v; want Static(3000)", source, err)
+	}
+
+	t.Setenv("ETH_USD_PRICE", "free")
+	if _, err := FromEnv(); err == nil {
+		t.Error("FromEnv accepted a non-numeric price")
+	}
+}
+
+func TestWeiToUSD(t *testing.T) {
+	// 0.001 ETH at $2000
+	wei, _ := new(big.Int).SetString("1000000000000000", 10)
+	if usd := WeiToUSD(wei, 2000); usd < 1.9999 || usd > 2.0001 {
+		t.Errorf("WeiToUSD = %v, want 2", usd)
+	}
+}
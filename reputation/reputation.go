@@ -0,0 +1,74 @@
+// Package reputation scores how reliably a wallet shows up to the events it stakes on.
+package reputation
+
+import (
+	"math"
+	"time"
+)
+
+// Tiers shown to organizers instead of the raw score
+const (
+	TierNew    = "new"
+	TierBronze = "bronze"
+	TierSilver = "silver"
+	TierGold   = "gold"
+)
+
+// Scoring parameters: how many settled events a wallet needs before it gets a score, how
+// quickly old events fade, and the score each tier starts at
+const (
+	MinEvents      = 3
+	HalfLife       = 180 * 24 * time.Hour
+	SilverMinScore = 0.7
+	GoldMinScore   = 0.9
+)
+
+// Event is one settled registration. Voided events must not be passed in, so they never
+// count against anyone. Stake is in whole tokens.
+type Event struct {
+	Date     time.Time
+	Stake    float64
+	Attended bool
+}
+
+// Score is the attendance rate over events, each weighted by recency (halving every
+// HalfLife) and by stake size (logarithmically, so a large stake counts more without
+// drowning out the rest). ok is false when there are fewer than MinEvents events.
+func Score(events []Event, now time.Time) (score float64, ok bool) {
+	if len(events) < MinEvents {
+		return 0, false
+	}
+
+	var attended, total float64
+	for _, event := range events {
+		age := now.Sub(event.Date)
+		if age < 0 {
+			age = 0
+		}
+		recency := math.Pow(0.5, float64(age)/float64(HalfLife))
+		weight := recency * (1 + math.Log1p(math.Max(event.Stake, 0)))
+
+		total += weight
+		if event.Attended {
+			attended += weight
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return attended / total, true
+}
+
+// TierFor maps a stored score to its tier; nil means the wallet has no score yet
+func TierFor(score *float64) string {
+	switch {
+	case score == nil:
+		return TierNew
+	case *score >= GoldMinScore:
+		return TierGold
+	case *score >= SilverMinScore:
+		return TierSilver
+	default:
+		return TierBronze
+	}
+}
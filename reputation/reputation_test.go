@@ -0,0 +1,130 @@
+package reputation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	tests := []struct {
+		name   string
+		events []Event
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "no history",
+			events: nil,
+			wantOK: false,
+		},
+		{
+			name: "below minimum event count",
+			events: []Event{
+				{Date: daysAgo(10), Stake: 10, Attended: true},
+				{Date: daysAgo(20), Stake: 10, Attended: true},
+			},
+			wantOK: false,
+		},
+		{
+			name: "always attended",
+			events: []Event{
+				{Date: daysAgo(10), Stake: 10, Attended: true},
+				{Date: daysAgo(20), Stake: 10, Attended: true},
+				{Date: daysAgo(30), Stake: 10, Attended: true},
+			},
+			want:   1,
+			wantOK: true,
+		},
+		{
+			name: "never attended",
+			events: []Event{
+				{Date: daysAgo(10), Stake: 10},
+				{Date: daysAgo(20), Stake: 10},
+				{Date: daysAgo(30), Stake: 10},
+			},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name: "equal weights give the plain rate",
+			events: []Event{
+				{Date: now, Stake: 5, Attended: true},
+				{Date: now, Stake: 5, Attended: true},
+				{Date: now, Stake: 5},
+				{Date: now, Stake: 5, Attended: true},
+			},
+			want:   0.75,
+			wantOK: true,
+		},
+		{
+			name: "an old no-show counts half after one half-life",
+			events: []Event{
+				{Date: now, Stake: 0, Attended: true},
+				{Date: now, Stake: 0, Attended: true},
+				{Date: now.Add(-HalfLife), Stake: 0},
+			},
+			want:   2 / 2.5,
+			wantOK: true,
+		},
+		{
+			name: "a larger stake weighs more",
+			events: []Event{
+				{Date: now, Stake: math.E - 1, Attended: true}, // weight 2
+				{Date: now, Stake: 0},                          // weight 1
+				{Date: now, Stake: 0},                          // weight 1
+			},
+			want:   0.5,
+			wantOK: true,
+		},
+		{
+			name: "future dates count as today",
+			events: []Event{
+				{Date: now.AddDate(0, 0, 5), Stake: 0, Attended: true},
+				{Date: now, Stake: 0},
+				{Date: now, Stake: 0, Attended: true},
+				{Date: now, Stake: 0, Attended: true},
+			},
+			want:   0.75,
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Score(tt.events, now)
+			if ok != tt.wantOK {
+				t.Fatalf("Score() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTierFor(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		score *float64
+		want  string
+	}{
+		{nil, TierNew},
+		{score(0), TierBronze},
+		{score(0.69), TierBronze},
+		{score(SilverMinScore), TierSilver},
+		{score(0.89), TierSilver},
+		{score(GoldMinScore), TierGold},
+		{score(1), TierGold},
+	}
+
+	for _, tt := range tests {
+		if got := TierFor(tt.score); got != tt.want {
+			t.Errorf("TierFor(%v) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package rewards splits a settled event's pot between its attendees.
+package rewards
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// DustTolerance is how far, in base units, two computations of the same reward may drift
+// apart through rounding alone. Integer division leaves under one base unit per attendee,
+// so this covers events of up to a thousand attendees.
+var DustTolerance = big.NewInt(1000)
+
+// Participant is one staker in a settled event. Stake is in token base units.
+type Participant struct {
+	WalletAddress string
+	Stake         *big.Int
+	Attended      bool
+}
+
+// Result holds the reward per attendee wallet (lowercase) and what was redistributed
+type Result struct {
+	Rewards   map[string]*big.Int
+	Forfeited *big.Int
+	Yield     *big.Int
+	Dust      *big.Int
+	DustTo    string
+}
+
+// Calculate returns each attendee's stake plus an equal share of the no-show stakes and
+// the vault yield. The remainder of the integer division goes to the attendee with the
+// lowest wallet address so repeated runs give the same answer. With no attendees the
+// forfeited pot is left unassigned.
+func Calculate(participants []Participant, yield *big.Int) Result {
+	result := Result{
+		Rewards:   map[string]*big.Int{},
+		Forfeited: new(big.Int),
+		Yield:     new(big.Int),
+		Dust:      new(big.Int),
+	}
+	if yield != nil {
+		result.Yield.Set(yield)
+	}
+
+	attendees := []string{}
+	for _, p := range participants {
+		wallet := strings.ToLower(p.WalletAddress)
+		stake := new(big.Int)
+		if p.Stake != nil {
+			stake.Set(p.Stake)
+		}
+
+		if !p.Attended {
+			result.Forfeited.Add(result.Forfeited, stake)
+			continue
+		}
+
+		if existing, ok := result.Rewards[wallet]; ok {
+			existing.Add(existing, stake)
+			continue
+		}
+		result.Rewards[wallet] = stake
+		attendees = append(attendees, wallet)
+	}
+
+	if len(attendees) == 0 {
+		return result
+	}
+	sort.Strings(attendees)
+
+	pot := new(big.Int).Add(result.Forfeited, result.Yield)
+	share, dust := new(big.Int).QuoRem(pot, big.NewInt(int64(len(attendees))), new(big.Int))
+	for _, wallet := range attendees {
+		result.Rewards[wallet].Add(result.Rewards[wallet], share)
+	}
+
+	if dust.Sign() > 0 {
+		result.Rewards[attendees[0]].Add(result.Rewards[attendees[0]], dust)
+		result.Dust = dust
+		result.DustTo = attendees[0]
+	}
+
+	return result
+}
+
+// WithinDust reports whether a and b differ by at most tolerance. A nil amount is zero.
+func WithinDust(a, b, tolerance *big.Int) bool {
+	diff := new(big.Int)
+	if a != nil {
+		diff.Set(a)
+	}
+	if b != nil {
+		diff.Sub(diff, b)
+	}
+	return diff.CmpAbs(tolerance) <= 0
+}
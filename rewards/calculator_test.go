@@ -0,0 +1,154 @@
+package rewards
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	const (
+		alice = "0x00000000000000000000000000000000000000a1"
+		bob   = "0x00000000000000000000000000000000000000b2"
+		carol = "0x00000000000000000000000000000000000000c3"
+	)
+	stake := big.NewInt(10_000_000)
+
+	tests := []struct {
+		name      string
+		people    []Participant
+		yield     *big.Int
+		want      map[string]int64
+		forfeited int64
+		dust      int64
+		dustTo    string
+	}{
+		{
+			name:   "zero no-shows",
+			people: []Participant{{alice, stake, true}, {bob, stake, true}},
+			want:   map[string]int64{alice: 10_000_000, bob: 10_000_000},
+		},
+		{
+			name:   "zero no-shows with yield",
+			people: []Participant{{alice, stake, true}, {bob, stake, true}},
+			yield:  big.NewInt(500),
+			want:   map[string]int64{alice: 10_000_250, bob: 10_000_250},
+		},
+		{
+			name:      "all no-shows",
+			people:    []Participant{{alice, stake, false}, {bob, stake, false}},
+			yield:     big.NewInt(500),
+			want:      map[string]int64{},
+			forfeited: 20_000_000,
+		},
+		{
+			name:      "one no-show split evenly",
+			people:    []Participant{{alice, stake, true}, {bob, stake, true}, {carol, stake, false}},
+			want:      map[string]int64{alice: 15_000_000, bob: 15_000_000},
+			forfeited: 10_000_000,
+		},
+		{
+			name:      "indivisible remainder goes to the lowest wallet",
+			people:    []Participant{{carol, stake, true}, {bob, stake, true}, {alice, stake, true}, {"0xdead", big.NewInt(10), false}},
+			want:      map[string]int64{alice: 10_000_004, bob: 10_000_003, carol: 10_000_003},
+			forfeited: 10,
+			dust:      1,
+			dustTo:    alice,
+		},
+		{
+			name:      "mixed-case wallets are one attendee",
+			people:    []Participant{{"0x00000000000000000000000000000000000000A1", stake, true}, {alice, stake, true}, {bob, stake, false}},
+			want:      map[string]int64{alice: 30_000_000},
+			forfeited: 10_000_000,
+		},
+		{
+			name:   "nil stake counts as zero",
+			people: []Participant{{alice, nil, true}, {bob, nil, false}},
+			want:   map[string]int64{alice: 0},
+		},
+	}
+	for _, tt := range tests {
+		result := Calculate(tt.people, tt.yield)
+		if len(result.Rewards) != len(tt.want) {
+			t.Errorf("%s: rewards = %v, want %v", tt.name, result.Rewards, tt.want)
+			continue
+		}
+		for wallet, want := range tt.want {
+			if got, ok := result.Rewards[wallet]; !ok || got.Int64() != want {
+				t.Errorf("%s: reward for %s = %v, want %d", tt.name, wallet, got, want)
+			}
+		}
+		if result.Forfeited.Int64() != tt.forfeited {
+			t.Errorf("%s: forfeited = %s, want %d", tt.name, result.Forfeited, tt.forfeited)
+		}
+		if result.Dust.Int64() != tt.dust || result.DustTo != tt.dustTo {
+			t.Errorf("%s: dust = %s to %q, want %d to %q", tt.name, result.Dust, result.DustTo, tt.dust, tt.dustTo)
+		}
+	}
+}
+
+func TestCalculatePaysOutThePot(t *testing.T) {
+	// Every base unit staked or earned is paid out when someone attended
+	participants := []Participant{
+		{"0x01", big.NewInt(7), true},
+		{"0x02", big.NewInt(7), true},
+		{"0x03", big.NewInt(7), true},
+		{"0x04", big.NewInt(7), false},
+		{"0x05", big.NewInt(7), false},
+	}
+	result := Calculate(participants, big.NewInt(3))
+
+	paid := new(big.Int)
+	for _, reward := range result.Rewards {
+		paid.Add(paid, reward)
+	}
+	if paid.Int64() != 5*7+3 {
+		t.Errorf("paid out %s, want the full 38", paid)
+	}
+}
+
+func TestWithinDust(t *testing.T) {
+	huge, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	hugePlusDust := new(big.Int).Add(huge, big.NewInt(999))
+
+	tests := []struct {
+		name      string
+		a, b      *big.Int
+		tolerance *big.Int
+		want      bool
+	}{
+		{"equal", big.NewInt(12_500_000), big.NewInt(12_500_000), DustTolerance, true},
+		{"below by dust", big.NewInt(12_499_001), big.NewInt(12_500_000), DustTolerance, true},
+		{"above by dust", big.NewInt(12_501_000), big.NewInt(12_500_000), DustTolerance, true},
+		{"just beyond dust", big.NewInt(12_501_001), big.NewInt(12_500_000), DustTolerance, false},
+		{"beyond dust the other way", big.NewInt(12_500_000), big.NewInt(12_501_001), DustTolerance, false},
+		{"past int64", hugePlusDust, huge, DustTolerance, true},
+		{"nil is zero", nil, big.NewInt(1000), DustTolerance, true},
+		{"nil against a reward", nil, big.NewInt(12_500_000), DustTolerance, false},
+		{"no tolerance", big.NewInt(1), big.NewInt(2), new(big.Int), false},
+	}
+	for _, tt := range tests {
+		if got := WithinDust(tt.a, tt.b, tt.tolerance); got != tt.want {
+			t.Errorf("%s: WithinDust(%v, %v, %v) = %t, want %t", tt.name, tt.a, tt.b, tt.tolerance, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateDustStaysWithinTolerance(t *testing.T) {
+	// 1000 attendees split a pot that leaves the largest possible remainder
+	var participants []Participant
+	for i := 0; i < 1000; i++ {
+		participants = append(participants, Participant{WalletAddress: fmt.Sprintf("0x%040x", i+1), Stake: big.NewInt(1), Attended: true})
+	}
+	participants = append(participants, Participant{WalletAddress: "0xffffffffffffffffffffffffffffffffffffffff", Stake: big.NewInt(1999), Attended: false})
+
+	result := Calculate(participants, nil)
+	if result.Dust.Int64() != 999 {
+		t.Fatalf("dust = %s, want 999", result.Dust)
+	}
+	// A vault that leaves the remainder unpaid differs from us by the dust only
+	share := big.NewInt(1 + 1)
+	if !WithinDust(result.Rewards[result.DustTo], share, DustTolerance) {
+		t.Errorf("reward %s of the dust recipient is beyond dust of the plain share %s", result.Rewards[result.DustTo], share)
+	}
+}
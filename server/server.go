@@ -0,0 +1,89 @@
+// Package server runs the HTTP server until the process is told to stop, then shuts it
+// down gracefully: new connections are refused while in-flight requests finish.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long in-flight requests get to finish once shutdown
+// begins, when SHUTDOWN_TIMEOUT is unset
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Server is an HTTP server that drains on SIGINT or SIGTERM
+type Server struct {
+	HTTP *http.Server
+	// ShutdownTimeout bounds the wait for in-flight requests; requests still running
+	// after it are cut off
+	ShutdownTimeout time.Duration
+
+	draining atomic.Bool
+}
+
+// New returns a server for handler listening on addr
+func New(addr string, handler http.Handler, shutdownTimeout time.Duration) *Server {
+	return &Server{
+		HTTP:            &http.Server{Addr: addr, Handler: handler},
+		ShutdownTimeout: shutdownTimeout,
+	}
+}
+
+// Draining reports whether shutdown has begun. Health checks answer not-ready from then
+// on, so load balancers stop routing to the instance.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Run listens on the server's address and serves until ctx is done or the process gets
+// SIGINT or SIGTERM, then shuts down as Serve does
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.HTTP.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is done or the process gets SIGINT or SIGTERM. It
+// then stops accepting connections and waits up to ShutdownTimeout for in-flight requests.
+// It returns nil once they all finished, or an error when some had to be cut off or the
+// server failed.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.HTTP.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	slog.Info("Shutting down, waiting for in-flight requests", "timeout", s.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	if err := s.HTTP.Shutdown(shutdownCtx); err != nil {
+		s.HTTP.Close()
+		return fmt.Errorf("in-flight requests did not finish: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
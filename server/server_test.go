@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeDrainsInFlightRequestsOnSIGTERM(t *testing.T) {
+	started := make(chan struct{})
+	var drainingDuringRequest bool
+	var srv *Server
+	srv = New("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		drainingDuringRequest = srv.Draining()
+		io.WriteString(w, "registered")
+	}), 5*time.Second)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	url := "http://" + listener.Addr().String()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.Serve(context.Background(), listener)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+
+	got := <-responses
+	if got.err != nil || got.body != "registered" {
+		t.Fatalf("in-flight request = %q, %v; want it to complete", got.body, got.err)
+	}
+	if !drainingDuringRequest {
+		t.Error("Draining was false while shutting down")
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Serve = %v, want a clean shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the request finished")
+	}
+
+	if _, err := http.Get(url); err == nil {
+		t.Error("a new request was accepted after shutdown")
+	}
+}
+
+func TestServeCutsOffRequestsAfterShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	srv := New("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}), 50*time.Millisecond)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.Serve(ctx, listener)
+	}()
+	go http.Get("http://" + listener.Addr().String())
+
+	<-started
+	cancel()
+	select {
+	case err := <-stopped:
+		if err == nil {
+			t.Error("Serve = nil, want an error for the request that did not finish")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not give up on the request")
+	}
+}
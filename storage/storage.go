@@ -0,0 +1,105 @@
+// Package storage keeps user-uploaded files such as profile avatars.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalURLPrefix is the path the API serves LocalStore files under
+const LocalURLPrefix = "/uploads"
+
+// Store saves and removes files by key. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put writes r under key and returns the public URL of the file
+	Put(ctx context.Context, key, contentType string, r io.Reader) (string, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStore keeps files on the local disk, served by the API under LocalURLPrefix
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore creates a LocalStore writing under dir. baseURL is prepended to keys to
+// form public URLs.
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// NewLocalStoreFromEnv reads UPLOAD_DIR (default ./uploads) and UPLOAD_BASE_URL (default
+// LocalURLPrefix, relative to the API host)
+func NewLocalStoreFromEnv() *LocalStore {
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	baseURL := os.Getenv("UPLOAD_BASE_URL")
+	if baseURL == "" {
+		baseURL = LocalURLPrefix
+	}
+	return NewLocalStore(dir, baseURL)
+}
+
+// Dir is the directory files are written to
+func (s *LocalStore) Dir() string {
+	return s.dir
+}
+
+// Put implements Store. The file is written to a temporary name first so readers never
+// see a partial upload.
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, r io.Reader) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write upload: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write upload: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store upload: %w", err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// Delete implements Store
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// path maps a key to a file under dir, refusing keys that would escape it
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
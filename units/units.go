@@ -0,0 +1,86 @@
+// Package units converts token amounts between base units and decimal strings. It uses
+// integer and string arithmetic only, so amounts are never rounded through a float.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidAmount is returned by ParseUnits for a string that is not a decimal number
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FormatUnits renders raw base units as a decimal string with the given number of
+// decimals, e.g. 2500000 with 6 decimals as "2.5". The result is exact: trailing zeros of
+// the fraction are trimmed and nothing is rounded. A nil amount renders as "0".
+func FormatUnits(raw *big.Int, decimals int) string {
+	if raw == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return raw.String()
+	}
+
+	digits := new(big.Int).Abs(raw).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	result := digits[:len(digits)-decimals]
+	if fraction := strings.TrimRight(digits[len(digits)-decimals:], "0"); fraction != "" {
+		result += "." + fraction
+	}
+	if raw.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+// ParseUnits converts a decimal string to base units with the given number of decimals,
+// e.g. "2.5" with 6 decimals to 2500000. An amount more precise than one base unit is
+// refused rather than rounded; zeros past the last decimal are accepted.
+func ParseUnits(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("negative decimals %d", decimals)
+	}
+
+	value := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if (whole == "" && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+
+	if len(fraction) > decimals {
+		if strings.Trim(fraction[decimals:], "0") != "" {
+			return nil, fmt.Errorf("%w %q: more than %d decimals", ErrInvalidAmount, s, decimals)
+		}
+		fraction = fraction[:decimals]
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+
+	amount, ok := new(big.Int).SetString("0"+whole+fraction, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+	if negative {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
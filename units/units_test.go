@@ -0,0 +1,172 @@
+package units
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func mustInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("bad test amount %q", s)
+	}
+	return value
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals int
+		want     string
+	}{
+		// Whole and fractional amounts
+		{"0", 6, "0"},
+		{"1000000", 6, "1"},
+		{"2500000", 6, "2.5"},
+		{"1234567", 6, "1.234567"},
+		{"10000000", 6, "10"},
+		{"2500000000000000000", 18, "2.5"},
+		{"1000000000000000000", 18, "1"},
+
+		// Less than one unit
+		{"1", 6, "0.000001"},
+		{"999999", 6, "0.999999"},
+		{"100000", 6, "0.1"},
+		{"1", 18, "0.000000000000000001"},
+		{"10", 18, "0.00000000000000001"},
+		{"999999999999999999", 18, "0.999999999999999999"},
+
+		// Never rounded: one base unit either side of a whole amount stays visible
+		{"1000001", 6, "1.000001"},
+		{"1999999", 6, "1.999999"},
+		{"1000000000000000001", 18, "1.000000000000000001"},
+		{"999999999999999999999999", 18, "999999.999999999999999999"},
+
+		// Very large values
+		{"123456789012345678901234567890", 18, "123456789012.34567890123456789"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 18,
+			"115792089237316195423570985008687907853269984665640564039457.584007913129639935"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 6,
+			"115792089237316195423570985008687907853269984665640564039457584007913129.639935"},
+
+		// Negative amounts
+		{"-1500000", 6, "-1.5"},
+		{"-1", 6, "-0.000001"},
+		{"-1000000000000000000", 18, "-1"},
+
+		// No decimals
+		{"42", 0, "42"},
+		{"-42", 0, "-42"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(mustInt(t, tt.raw), tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %q, want %q", tt.raw, tt.decimals, got, tt.want)
+		}
+	}
+
+	if got := FormatUnits(nil, 6); got != "0" {
+		t.Errorf("FormatUnits(nil, 6) = %q, want 0", got)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		s        string
+		decimals int
+		want     string
+	}{
+		{"0", 6, "0"},
+		{"1", 6, "1000000"},
+		{"2.5", 6, "2500000"},
+		{"2.50", 6, "2500000"},
+		{"1.234567", 6, "1234567"},
+		{"0.1", 18, "100000000000000000"},
+		{"2.5", 18, "2500000000000000000"},
+		{"007", 6, "7000000"},
+		{" 3 ", 6, "3000000"},
+		{"+3", 6, "3000000"},
+		{"-1.5", 6, "-1500000"},
+		{"1.", 6, "1000000"},
+		{".5", 6, "500000"},
+		{"42", 0, "42"},
+
+		// Less than one unit
+		{"0.000001", 6, "1"},
+		{"0.000000000000000001", 18, "1"},
+		{"0.999999", 6, "999999"},
+
+		// Not rounded: zeros past the last decimal are exact
+		{"1.0000000", 6, "1000000"},
+		{"0.1000000000", 6, "100000"},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.s, tt.decimals)
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d) failed: %v", tt.s, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.s, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnitsRefusesRounding(t *testing.T) {
+	tests := []struct {
+		s        string
+		decimals int
+	}{
+		{"0.0000001", 6},
+		{"1.0000005", 6},
+		{"1.9999999", 6},
+		{"0.0000000000000000001", 18},
+		{"1.5", 0},
+	}
+	for _, tt := range tests {
+		if got, err := ParseUnits(tt.s, tt.decimals); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseUnits(%q, %d) = %v, %v; want ErrInvalidAmount", tt.s, tt.decimals, got, err)
+		}
+	}
+}
+
+func TestParseUnitsRejectsMalformed(t *testing.T) {
+	for _, s := range []string{"", " ", "-", ".", "abc", "1e6", "1,000", "1.2.3", "0x10", "--1", "1 000", "∞"} {
+		if got, err := ParseUnits(s, 6); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseUnits(%q, 6) = %v, %v; want ErrInvalidAmount", s, got, err)
+		}
+	}
+	if _, err := ParseUnits("1", -1); err == nil {
+		t.Error("ParseUnits with negative decimals succeeded")
+	}
+}
+
+func TestParseUnitsVeryLarge(t *testing.T) {
+	maxUint256 := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	got, err := ParseUnits("115792089237316195423570985008687907853269984665640564039457.584007913129639935", 18)
+	if err != nil || got.String() != maxUint256 {
+		t.Errorf("ParseUnits(max uint256 / 1e18) = %v, %v; want %s", got, err, maxUint256)
+	}
+
+	// Larger than any token supply still parses exactly
+	huge := strings.Repeat("9", 100)
+	got, err = ParseUnits(huge, 18)
+	if err != nil || got.String() != huge+strings.Repeat("0", 18) {
+		t.Errorf("ParseUnits(%s) = %v, %v", huge, got, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, decimals := range []int{0, 2, 6, 8, 18, 24} {
+		for _, raw := range []string{"0", "1", "9", "10", "123456789", "1000000000000000000", "-5", "340282366920938463463374607431768211455"} {
+			amount := mustInt(t, raw)
+			formatted := FormatUnits(amount, decimals)
+			parsed, err := ParseUnits(formatted, decimals)
+			if err != nil || parsed.Cmp(amount) != 0 {
+				t.Errorf("round trip of %s with %d decimals: %q parsed to %v, %v", raw, decimals, formatted, parsed, err)
+			}
+		}
+	}
+}